@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jonwraymond/metatools-mcp/internal/catalog"
+	"github.com/jonwraymond/metatools-mcp/internal/config"
+	"github.com/jonwraymond/metatools-mcp/internal/skills"
+	"github.com/jonwraymond/metatools-mcp/internal/toolset"
+	"github.com/spf13/cobra"
+)
+
+// CatalogExportConfig holds catalog export command configuration.
+type CatalogExportConfig struct {
+	Config string
+	Format string
+	Output string
+}
+
+// CatalogDiffConfig holds catalog diff command configuration.
+type CatalogDiffConfig struct {
+	Format         string
+	FailOnBreaking bool
+}
+
+func newCatalogCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "catalog",
+		Short: "Export and compare the tool catalog",
+	}
+
+	cmd.AddCommand(newCatalogExportCmd())
+	cmd.AddCommand(newCatalogDiffCmd())
+	return cmd
+}
+
+func newCatalogExportCmd() *cobra.Command {
+	cfg := &CatalogExportConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Dump tools, schemas, backends, toolsets and skills",
+		Long: `Export the tool catalog a server would expose.
+
+The catalog is built the same way as serve (local tools, MCP backends,
+toolsets and skills) and written as JSON, YAML or Markdown. JSON and YAML
+exports can be compared later with "metatools catalog diff".
+
+Examples:
+  metatools catalog export --config metatools.yaml > catalog.json
+  metatools catalog export --format markdown --output CATALOG.md`,
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			if !slices.Contains(catalog.Formats, cfg.Format) {
+				return fmt.Errorf("invalid format %q, must be one of: %s", cfg.Format, strings.Join(catalog.Formats, ", "))
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runCatalogExport(cmd.Context(), cmd.OutOrStdout(), cfg)
+		},
+	}
+
+	cmd.Flags().StringVarP(&cfg.Config, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVarP(&cfg.Format, "format", "f", catalog.FormatJSON, "Output format (json, yaml, markdown)")
+	cmd.Flags().StringVarP(&cfg.Output, "output", "o", "", "Write to this file instead of stdout")
+
+	return cmd
+}
+
+func newCatalogDiffCmd() *cobra.Command {
+	cfg := &CatalogDiffConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "diff <old> <new>",
+		Short: "Report added, removed and schema-changed tools",
+		Long: `Compare two exported catalogs (JSON or YAML).
+
+Schema changes are classified as breaking when existing callers may fail:
+removed tools, removed required fields, newly required inputs, type changes
+and narrowed input enums. With --fail-on-breaking, the command exits non-zero
+when any breaking change is found.
+
+Examples:
+  metatools catalog diff old.json new.json
+  metatools catalog diff old.json new.json --format json --fail-on-breaking`,
+		Args: cobra.ExactArgs(2),
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			if cfg.Format != "text" && cfg.Format != "json" {
+				return fmt.Errorf("invalid format %q, must be one of: text, json", cfg.Format)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCatalogDiff(cmd.OutOrStdout(), args[0], args[1], cfg)
+		},
+	}
+
+	cmd.Flags().StringVarP(&cfg.Format, "format", "f", "text", "Output format (text, json)")
+	cmd.Flags().BoolVar(&cfg.FailOnBreaking, "fail-on-breaking", false, "Exit non-zero when breaking changes are found")
+
+	return cmd
+}
+
+func runCatalogExport(ctx context.Context, out io.Writer, cfg *CatalogExportConfig) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	appCfg, err := config.Load(cfg.Config)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	cat, err := buildCatalog(ctx, appCfg)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Output != "" {
+		f, err := os.Create(cfg.Output)
+		if err != nil {
+			return fmt.Errorf("create output: %w", err)
+		}
+		if err := catalog.Write(f, cat, cfg.Format); err != nil {
+			_ = f.Close()
+			return err
+		}
+		return f.Close()
+	}
+	return catalog.Write(out, cat, cfg.Format)
+}
+
+func runCatalogDiff(out io.Writer, oldPath, newPath string, cfg *CatalogDiffConfig) error {
+	oldCat, err := catalog.Load(oldPath)
+	if err != nil {
+		return err
+	}
+	newCat, err := catalog.Load(newPath)
+	if err != nil {
+		return err
+	}
+	diff := catalog.Compare(oldCat, newCat)
+
+	if cfg.Format == "json" {
+		err = catalog.WriteDiffJSON(out, diff)
+	} else {
+		err = catalog.WriteDiffText(out, diff)
+	}
+	if err != nil {
+		return err
+	}
+	if cfg.FailOnBreaking && diff.Breaking() {
+		return fmt.Errorf("catalog has breaking changes")
+	}
+	return nil
+}
+
+// buildCatalog records the tools, toolsets and skills serve would register.
+func buildCatalog(ctx context.Context, appCfg config.AppConfig) (catalog.Catalog, error) {
+	idx, err := buildIndex(ctx, appCfg)
+	if err != nil {
+		return catalog.Catalog{}, err
+	}
+
+	appCfg, err = withDefinitionDirs(appCfg)
+	if err != nil {
+		return catalog.Catalog{}, err
+	}
+	toolsets, err := toolset.BuildRegistry(idx, toolsetSpecsFromConfig(appCfg))
+	if err != nil {
+		return catalog.Catalog{}, fmt.Errorf("build toolsets: %w", err)
+	}
+	skillRegistry, err := skills.BuildRegistry(toolsets, skillSpecsFromConfig(appCfg))
+	if err != nil {
+		return catalog.Catalog{}, fmt.Errorf("build skills: %w", err)
+	}
+	return catalog.Build(idx, toolsets.List(), skillRegistry.List())
+}
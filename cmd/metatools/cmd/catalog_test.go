@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeCatalogConfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "metatools.yaml")
+	cfg := `
+backends:
+  local:
+    enabled: true
+toolsets:
+  - name: core
+    namespace_filters: [local]
+skills:
+  - name: ping_check
+    toolset_id: "toolset:core"
+    steps:
+      - id: ping
+        tool_id: local:ping
+`
+	if err := os.WriteFile(path, []byte(cfg), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func TestCatalogExportCmd(t *testing.T) {
+	configPath := writeCatalogConfig(t)
+
+	for _, tt := range []struct {
+		format string
+		want   string
+	}{
+		{"json", `"toolsets": [`},
+		{"yaml", "toolsetId: toolset:core"},
+		{"markdown", "### `local:ping`"},
+	} {
+		t.Run(tt.format, func(t *testing.T) {
+			cmd := NewRootCmd()
+			buf := new(bytes.Buffer)
+			cmd.SetOut(buf)
+			cmd.SetArgs([]string{"catalog", "export", "--config", configPath, "--format", tt.format})
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if !contains(buf.String(), tt.want) {
+				t.Errorf("output missing %q: %s", tt.want, buf.String())
+			}
+		})
+	}
+}
+
+func TestCatalogExportCmd_InvalidFormat(t *testing.T) {
+	cmd := NewRootCmd()
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{"catalog", "export", "--format", "xml"})
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error for invalid format")
+	}
+}
+
+func TestCatalogDiffCmd_Breaking(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.json")
+
+	cmd := NewRootCmd()
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetArgs([]string{"catalog", "export", "--config", writeCatalogConfig(t), "--output", oldPath})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("export error = %v", err)
+	}
+
+	data, err := os.ReadFile(oldPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	newPath := filepath.Join(dir, "new.json")
+	changed := strings.Replace(string(data), `"type": "string"`, `"type": "integer"`, 1)
+	if err := os.WriteFile(newPath, []byte(changed), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cmd = NewRootCmd()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{"catalog", "diff", oldPath, newPath})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("diff error = %v", err)
+	}
+	if !contains(buf.String(), "type_changed output.message") {
+		t.Errorf("unexpected diff output: %s", buf.String())
+	}
+
+	cmd = NewRootCmd()
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{"catalog", "diff", oldPath, newPath, "--fail-on-breaking"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error for breaking changes")
+	}
+}
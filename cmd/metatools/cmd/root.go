@@ -30,6 +30,8 @@ Use subcommands to start the server or manage configuration.`,
 	rootCmd.AddCommand(newServeCmd())
 	rootCmd.AddCommand(newVersionCmd())
 	rootCmd.AddCommand(newConfigCmd())
+	rootCmd.AddCommand(newSearchCmd())
+	rootCmd.AddCommand(newCatalogCmd())
 
 	return rootCmd
 }
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/jonwraymond/metatools-mcp/internal/bootstrap"
+	"github.com/jonwraymond/metatools-mcp/internal/config"
+	"github.com/jonwraymond/metatools-mcp/internal/mcpbackend"
+	"github.com/jonwraymond/metatools-mcp/internal/searcheval"
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/spf13/cobra"
+)
+
+// SearchEvalConfig holds search eval command configuration.
+type SearchEvalConfig struct {
+	Config         string
+	Golden         string
+	Snapshot       string
+	RecordSnapshot string
+	Strategies     []string
+	K              int
+	Format         string
+	Baseline       string
+	WriteBaseline  string
+	Tolerance      float64
+}
+
+func newSearchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "search",
+		Short: "Inspect and evaluate tool search",
+	}
+
+	cmd.AddCommand(newSearchEvalCmd())
+	return cmd
+}
+
+func newSearchEvalCmd() *cobra.Command {
+	cfg := &SearchEvalConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "eval",
+		Short: "Score search strategies against a golden query set",
+		Long: `Evaluate search quality against a golden query file.
+
+The tool catalog is built the same way as serve (local tools and MCP backends),
+or loaded from a recorded snapshot. Every strategy is scored with recall@k,
+MRR and nDCG@k. With --baseline, the command exits non-zero when any metric
+drops below the stored baseline by more than --tolerance.
+
+Examples:
+  metatools search eval --config metatools.yaml --golden queries.yaml
+  metatools search eval --golden queries.yaml --snapshot catalog.json --format json
+  metatools search eval --golden queries.yaml --baseline search-baseline.json`,
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			if cfg.Golden == "" {
+				return fmt.Errorf("--golden is required")
+			}
+			if cfg.Format != "table" && cfg.Format != "json" {
+				return fmt.Errorf("invalid format %q, must be one of: table, json", cfg.Format)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runSearchEval(cmd.Context(), cmd.OutOrStdout(), cfg)
+		},
+	}
+
+	cmd.Flags().StringVarP(&cfg.Config, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVarP(&cfg.Golden, "golden", "g", "", "Path to golden query file (YAML)")
+	cmd.Flags().StringVar(&cfg.Snapshot, "snapshot", "", "Load the tool catalog from a recorded snapshot instead of backends")
+	cmd.Flags().StringVar(&cfg.RecordSnapshot, "record-snapshot", "", "Write the evaluated tool catalog to this file")
+	cmd.Flags().StringSliceVar(&cfg.Strategies, "strategies", nil, "Strategies to evaluate (default: lexical,bm25,semantic,hybrid)")
+	cmd.Flags().IntVarP(&cfg.K, "k", "k", 0, "Result cutoff (default: golden file k, or 5)")
+	cmd.Flags().StringVarP(&cfg.Format, "format", "f", "table", "Output format (table, json)")
+	cmd.Flags().StringVar(&cfg.Baseline, "baseline", "", "Fail if metrics regress against this baseline report")
+	cmd.Flags().StringVar(&cfg.WriteBaseline, "write-baseline", "", "Write the report as a new baseline")
+	cmd.Flags().Float64Var(&cfg.Tolerance, "tolerance", 0, "Allowed drop per metric before a regression is reported")
+
+	return cmd
+}
+
+func runSearchEval(ctx context.Context, out io.Writer, cfg *SearchEvalConfig) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	appCfg, err := config.Load(cfg.Config)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	golden, err := searcheval.LoadGolden(cfg.Golden)
+	if err != nil {
+		return err
+	}
+
+	var catalog searcheval.Snapshot
+	if cfg.Snapshot != "" {
+		catalog, err = searcheval.LoadSnapshot(cfg.Snapshot)
+	} else {
+		catalog, err = buildEvalCatalog(ctx, appCfg)
+	}
+	if err != nil {
+		return err
+	}
+	if cfg.RecordSnapshot != "" {
+		if err := searcheval.WriteSnapshot(cfg.RecordSnapshot, catalog); err != nil {
+			return fmt.Errorf("write snapshot: %w", err)
+		}
+	}
+
+	report, err := searcheval.Evaluate(catalog, golden, searcheval.Options{
+		Search:     appCfg.Search,
+		Strategies: cfg.Strategies,
+		K:          cfg.K,
+	})
+	if err != nil {
+		return fmt.Errorf("evaluate: %w", err)
+	}
+
+	if cfg.Format == "json" {
+		err = searcheval.WriteJSON(out, report)
+	} else {
+		err = searcheval.WriteTable(out, report)
+	}
+	if err != nil {
+		return err
+	}
+
+	if cfg.WriteBaseline != "" {
+		if err := searcheval.WriteBaseline(cfg.WriteBaseline, report); err != nil {
+			return fmt.Errorf("write baseline: %w", err)
+		}
+	}
+	if cfg.Baseline == "" {
+		return nil
+	}
+	baseline, err := searcheval.LoadBaseline(cfg.Baseline)
+	if err != nil {
+		return err
+	}
+	if baseline.K != report.K {
+		return fmt.Errorf("baseline k=%d does not match evaluation k=%d", baseline.K, report.K)
+	}
+	regressions := searcheval.Compare(report, baseline, cfg.Tolerance)
+	if len(regressions) == 0 {
+		return nil
+	}
+	if cfg.Format == "table" {
+		writeRegressions(out, regressions)
+	}
+	return fmt.Errorf("search quality regressed: %d metric(s) below baseline", len(regressions))
+}
+
+// buildEvalCatalog records the tools serve would register as a snapshot.
+func buildEvalCatalog(ctx context.Context, appCfg config.AppConfig) (searcheval.Snapshot, error) {
+	idx, err := buildIndex(ctx, appCfg)
+	if err != nil {
+		return searcheval.Snapshot{}, err
+	}
+	return searcheval.SnapshotIndex(idx)
+}
+
+// buildIndex registers the same local and MCP backend tools serve would into
+// a lexical index. Backend connections are closed before it returns.
+func buildIndex(ctx context.Context, appCfg config.AppConfig) (index.Index, error) {
+	idx := index.NewInMemoryIndex()
+	if appCfg.Backends.Local.Enabled {
+		if _, err := bootstrap.RegisterDefaultLocalTools(idx); err != nil {
+			return nil, fmt.Errorf("register local tools: %w", err)
+		}
+	}
+
+	if len(appCfg.Backends.MCP) > 0 {
+		closeSecrets, err := resolveMCPBackendSecrets(ctx, &appCfg)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = closeSecrets() }()
+
+		mcpManager, err := mcpbackend.NewManager(mcpBackendConfigs(appCfg))
+		if err != nil {
+			return nil, fmt.Errorf("mcp backends: %w", err)
+		}
+		defer func() { _ = mcpManager.Close() }()
+		if err := mcpManager.ConnectAll(ctx); err != nil {
+			return nil, fmt.Errorf("connect mcp backends: %w", err)
+		}
+		if err := mcpManager.RegisterTools(idx); err != nil {
+			return nil, fmt.Errorf("register mcp tools: %w", err)
+		}
+	}
+	return idx, nil
+}
+
+func writeRegressions(w io.Writer, regressions []searcheval.Regression) {
+	_, _ = fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "REGRESSION\tMETRIC\tBASELINE\tCURRENT")
+	for _, r := range regressions {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%.3f\t%.3f\n", r.Strategy, r.Metric, r.Baseline, r.Current)
+	}
+	_ = tw.Flush()
+}
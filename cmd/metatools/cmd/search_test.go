@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeSearchEvalFixtures(t *testing.T, expected string) (configPath, goldenPath string) {
+	t.Helper()
+	dir := t.TempDir()
+	configPath = filepath.Join(dir, "metatools.yaml")
+	goldenPath = filepath.Join(dir, "golden.yaml")
+
+	cfg := `
+backends:
+  local:
+    enabled: true
+`
+	golden := `
+k: 3
+queries:
+  - query: ping
+    expected: [` + expected + `]
+`
+	if err := os.WriteFile(configPath, []byte(cfg), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.WriteFile(goldenPath, []byte(golden), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return configPath, goldenPath
+}
+
+func TestSearchEvalCmd_Table(t *testing.T) {
+	configPath, goldenPath := writeSearchEvalFixtures(t, "local:ping")
+	snapshotPath := filepath.Join(t.TempDir(), "catalog.json")
+
+	cmd := NewRootCmd()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"search", "eval", "--config", configPath, "--golden", goldenPath,
+		"--strategies", "lexical", "--record-snapshot", snapshotPath})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !contains(buf.String(), "RECALL@3") || !contains(buf.String(), "1.000") {
+		t.Errorf("unexpected table output: %s", buf.String())
+	}
+	if _, err := os.Stat(snapshotPath); err != nil {
+		t.Errorf("snapshot not recorded: %v", err)
+	}
+}
+
+func TestSearchEvalCmd_BaselineRegression(t *testing.T) {
+	configPath, goldenPath := writeSearchEvalFixtures(t, "local:ping")
+	baselinePath := filepath.Join(t.TempDir(), "baseline.json")
+
+	cmd := NewRootCmd()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"search", "eval", "-c", configPath, "-g", goldenPath,
+		"--strategies", "lexical", "--format", "json", "--write-baseline", baselinePath})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	var report map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+
+	// A golden set the catalog cannot satisfy must fail against the baseline.
+	_, missingGolden := writeSearchEvalFixtures(t, "local:missing")
+	cmd = NewRootCmd()
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetArgs([]string{"search", "eval", "-c", configPath, "-g", missingGolden,
+		"--strategies", "lexical", "--baseline", baselinePath})
+	if err := cmd.Execute(); err == nil {
+		t.Fatalf("Execute() should fail on regression")
+	}
+}
+
+func TestSearchEvalCmd_RequiresGolden(t *testing.T) {
+	cmd := NewRootCmd()
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetArgs([]string{"search", "eval"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatalf("Execute() should fail without --golden")
+	}
+}
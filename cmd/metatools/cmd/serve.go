@@ -3,21 +3,36 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
+	"slices"
 	"strconv"
 	"syscall"
 
 	"github.com/jonwraymond/metatools-mcp/internal/adapters"
+	"github.com/jonwraymond/metatools-mcp/internal/approval"
 	"github.com/jonwraymond/metatools-mcp/internal/bootstrap"
 	"github.com/jonwraymond/metatools-mcp/internal/config"
+	"github.com/jonwraymond/metatools-mcp/internal/content"
+	"github.com/jonwraymond/metatools-mcp/internal/definitions"
 	"github.com/jonwraymond/metatools-mcp/internal/handlers"
+	"github.com/jonwraymond/metatools-mcp/internal/jobs"
 	"github.com/jonwraymond/metatools-mcp/internal/mcpbackend"
+	"github.com/jonwraymond/metatools-mcp/internal/mcplog"
 	"github.com/jonwraymond/metatools-mcp/internal/middleware"
+	"github.com/jonwraymond/metatools-mcp/internal/results"
+	"github.com/jonwraymond/metatools-mcp/internal/roots"
+	"github.com/jonwraymond/metatools-mcp/internal/sampling"
+	"github.com/jonwraymond/metatools-mcp/internal/scope"
 	"github.com/jonwraymond/metatools-mcp/internal/server"
 	"github.com/jonwraymond/metatools-mcp/internal/skills"
+	"github.com/jonwraymond/metatools-mcp/internal/state/runs"
+	"github.com/jonwraymond/metatools-mcp/internal/stepgraph"
+	"github.com/jonwraymond/metatools-mcp/internal/tooldocs"
 	"github.com/jonwraymond/metatools-mcp/internal/toolset"
 	transportpkg "github.com/jonwraymond/metatools-mcp/internal/transport"
+	"github.com/jonwraymond/tooldiscovery/index"
 	"github.com/jonwraymond/tooldiscovery/tooldoc"
 	"github.com/jonwraymond/toolexec/run"
 	bwssecret "github.com/jonwraymond/toolops-integrations/secret/bws"
@@ -125,6 +140,43 @@ func loadServeConfig(configPath string, cli *ServeConfig) (config.AppConfig, err
 	return config.LoadWithOverrides(configPath, overrides)
 }
 
+// mcpBackendConfigs converts configured MCP backends to manager configs.
+func mcpBackendConfigs(appCfg config.AppConfig) []mcpbackend.Config {
+	out := make([]mcpbackend.Config, len(appCfg.Backends.MCP))
+	for i, backend := range appCfg.Backends.MCP {
+		out[i] = mcpbackend.Config{
+			Name:       backend.Name,
+			URL:        backend.URL,
+			Headers:    backend.Headers,
+			MaxRetries: backend.MaxRetries,
+			ShareRoots: appCfg.Roots.Enabled && appCfg.Roots.ShareWithBackends,
+		}
+	}
+	return out
+}
+
+// resolveMCPBackendSecrets resolves secret references in MCP backend configs
+// in place. The returned close function is always non-nil.
+func resolveMCPBackendSecrets(ctx context.Context, appCfg *config.AppConfig) (func() error, error) {
+	noop := func() error { return nil }
+	secretResolver, closeSecrets, err := bootstrap.NewSecretResolver(appCfg.Secrets, bwssecret.Register)
+	if err != nil {
+		return noop, fmt.Errorf("secrets: %w", err)
+	}
+	if closeSecrets == nil {
+		closeSecrets = noop
+	}
+	if secretResolver != nil {
+		resolved, err := bootstrap.ResolveMCPBackendConfigs(ctx, secretResolver, appCfg.Backends.MCP)
+		if err != nil {
+			_ = closeSecrets()
+			return noop, fmt.Errorf("resolve mcp backend secrets: %w", err)
+		}
+		appCfg.Backends.MCP = resolved
+	}
+	return closeSecrets, nil
+}
+
 func buildServerConfig(_ *ServeConfig) (config.Config, error) {
 	appCfg, err := config.Load("")
 	if err != nil {
@@ -140,16 +192,7 @@ func buildServerConfigFromConfig(appCfg config.AppConfig) (config.Config, error)
 	}
 	docs := tooldoc.NewInMemoryStore(tooldoc.StoreOptions{Index: idx})
 
-	mcpBackendCfgs := make([]mcpbackend.Config, len(appCfg.Backends.MCP))
-	for i, backend := range appCfg.Backends.MCP {
-		mcpBackendCfgs[i] = mcpbackend.Config{
-			Name:       backend.Name,
-			URL:        backend.URL,
-			Headers:    backend.Headers,
-			MaxRetries: backend.MaxRetries,
-		}
-	}
-	mcpManager, err := mcpbackend.NewManager(mcpBackendCfgs)
+	mcpManager, err := mcpbackend.NewManager(mcpBackendConfigs(appCfg))
 	if err != nil {
 		return config.Config{}, fmt.Errorf("mcp backends: %w", err)
 	}
@@ -170,6 +213,20 @@ func buildServerConfigFromConfig(appCfg config.AppConfig) (config.Config, error)
 		}
 	}
 
+	var docsManager *tooldocs.Manager
+	if appCfg.Docs.Dir != "" {
+		docsManager = tooldocs.NewManager(tooldocs.Options{
+			Dir:           appCfg.Docs.Dir,
+			Index:         idx,
+			Store:         docs,
+			WatchInterval: appCfg.Docs.WatchInterval,
+		})
+		if err := docsManager.Load(); err != nil {
+			return config.Config{}, fmt.Errorf("load tool docs: %w", err)
+		}
+		docsManager.Listen()
+	}
+
 	runnerOpts := []run.ConfigOption{run.WithIndex(idx)}
 	if localReg != nil {
 		runnerOpts = append(runnerOpts, run.WithLocalRegistry(localReg))
@@ -177,60 +234,76 @@ func buildServerConfigFromConfig(appCfg config.AppConfig) (config.Config, error)
 	if mcpManager.HasBackends() {
 		runnerOpts = append(runnerOpts, run.WithMCPExecutor(mcpManager))
 	}
-	runner := run.NewRunner(runnerOpts...)
+	// Limit runs to the calling session's active toolsets. llm:sample is
+	// answered by the sampling wrapper and is never out of scope.
+	runner := scope.WrapRunner(run.NewRunner(runnerOpts...))
+	if appCfg.Sampling.Enabled {
+		if err := sampling.Register(idx); err != nil {
+			return config.Config{}, err
+		}
+		runner = sampling.WrapRunner(runner, sampling.NewSampler(sampling.Options{
+			MaxTokens:       appCfg.Sampling.MaxTokens,
+			MaxCallsPerRun:  appCfg.Sampling.MaxCallsPerRun,
+			MaxTokensPerRun: appCfg.Sampling.MaxTokensPerRun,
+		}))
+	}
+	// Gate the raw runner so run_tool, chains, skills and code mode all ask
+	// for approval.
+	var gate *approval.Gate
+	if appCfg.Approval.Enabled {
+		gate = approvalGate(appCfg.Approval, idx)
+		runner = approval.WrapRunner(runner, gate)
+	}
+	// Check paths outermost, so calls outside the roots fail before anyone is
+	// asked to approve them.
+	if appCfg.Roots.Enforce {
+		runner = roots.WrapRunner(runner, roots.NewGuard(roots.GuardOptions{
+			Policy: roots.Policy{Tags: appCfg.Roots.Tags},
+			Tools:  adapters.NewIndexAdapter(idx),
+		}))
+	}
 
 	exec, err := maybeCreateExecutor(appCfg.Execution, idx, docs, runner)
 	if err != nil {
 		return config.Config{}, fmt.Errorf("create executor: %w", err)
 	}
 
-	toolsetSpecs := make([]toolset.Spec, len(appCfg.Toolsets))
-	for i, spec := range appCfg.Toolsets {
-		toolsetSpecs[i] = toolset.Spec{
-			Name:             spec.Name,
-			Description:      spec.Description,
-			NamespaceFilters: spec.NamespaceFilters,
-			TagFilters:       spec.TagFilters,
-			AllowIDs:         spec.AllowIDs,
-			DenyIDs:          spec.DenyIDs,
-			Policy:           spec.Policy,
-		}
-	}
-	toolsetsRegistry, err := toolset.BuildRegistry(idx, toolsetSpecs)
-	if err != nil {
-		return config.Config{}, fmt.Errorf("build toolsets: %w", err)
-	}
-	skillSpecs := make([]skills.Spec, len(appCfg.Skills))
-	for i, spec := range appCfg.Skills {
-		steps := make([]skills.StepSpec, len(spec.Steps))
-		for j, step := range spec.Steps {
-			steps[j] = skills.StepSpec{
-				ID:     step.ID,
-				ToolID: step.ToolID,
-				Inputs: step.Inputs,
-			}
-		}
-		skillSpecs[i] = skills.Spec{
-			Name:        spec.Name,
-			Description: spec.Description,
-			ToolsetID:   spec.ToolsetID,
-			Steps:       steps,
-			Guards: skills.GuardSpec{
-				MaxSteps: spec.Guards.MaxSteps,
-				AllowIDs: spec.Guards.AllowIDs,
-			},
-		}
-	}
-	skillsRegistry, err := skills.BuildRegistry(toolsetsRegistry, skillSpecs)
-	if err != nil {
-		return config.Config{}, fmt.Errorf("build skills: %w", err)
+	defs := definitions.NewManager(definitions.Options{
+		Index:  idx,
+		Source: definitionSource(appCfg),
+		Dirs:   definitionDirs(appCfg),
+	})
+	if err := defs.Load(); err != nil {
+		return config.Config{}, err
 	}
+	defs.Listen()
 
 	cfg := adapters.NewConfig(idx, docs, runner, exec)
+	contentOpts := content.Options{
+		MaxBlockBytes:  appCfg.Content.MaxBlockBytes,
+		MaxTotalBytes:  appCfg.Content.MaxTotalBytes,
+		LinkLargerThan: appCfg.Content.LinkLargerThan,
+	}
+	if contentOpts.LinkLargerThan > 0 {
+		contentOpts.Store = content.NewStore(appCfg.Content.LinkTTL, appCfg.Content.LinkQuota)
+	}
+	cfg.Runner = adapters.NewRunnerAdapter(runner,
+		adapters.WithMaxParallel(appCfg.Execution.MaxParallelSteps),
+		adapters.WithContent(content.NewCollector(contentOpts)),
+	)
+	cfg.ContentStore = contentOpts.Store
+	cfg.Approval = gate
+	if appCfg.Roots.Enabled {
+		cfg.Roots = roots.NewTracker()
+	}
 	cfg.Providers = appCfg.Providers
 	cfg.Middleware = appCfg.Middleware
-	cfg.Toolsets = toolsetsRegistry
-	cfg.Skills = skillsRegistry
+	cfg.Toolsets = defs.Toolsets()
+	cfg.Skills = defs.Skills()
+	cfg.Reloaders = append(cfg.Reloaders, defs)
+	if len(definitionDirs(appCfg)) > 0 {
+		cfg.Watchers = append(cfg.Watchers, defs)
+	}
 	if mcpManager.HasBackends() {
 		refreshPolicy := mcpbackend.RefreshPolicy{
 			Interval:   appCfg.Backends.MCPRefresh.Interval,
@@ -240,10 +313,14 @@ func buildServerConfigFromConfig(appCfg config.AppConfig) (config.Config, error)
 		}
 		cfg.Refresher = mcpbackend.NewRefresher(mcpManager, idx, refreshPolicy)
 	}
+	if docsManager != nil && appCfg.Docs.Watch {
+		cfg.Watchers = append(cfg.Watchers, docsManager)
+	}
 	cfg.SkillDefaults = handlers.SkillDefaults{
 		MaxSteps:     appCfg.SkillDefaults.MaxSteps,
 		MaxToolCalls: appCfg.SkillDefaults.MaxToolCalls,
 		Timeout:      appCfg.SkillDefaults.Timeout,
+		MaxParallel:  appCfg.Execution.MaxParallelSteps,
 	}
 
 	wrappedRunner, err := middleware.WrapRunner(cfg.Runner, idx, appCfg.Middleware)
@@ -271,16 +348,153 @@ func buildServerConfigFromConfig(appCfg config.AppConfig) (config.Config, error)
 	if err := envCfg.ValidateEnv(); err != nil {
 		return config.Config{}, fmt.Errorf("invalid config: %w", err)
 	}
+	cfg.Jobs = jobs.Options{
+		MaxConcurrent: appCfg.Execution.MaxConcurrentJobs,
+		TTL:           appCfg.Execution.JobTTL,
+	}
+	cfg.Results = resultsOptions(appCfg.Results)
 	cfg.NotifyToolListChanged = envCfg.NotifyToolListChanged
 	cfg.NotifyToolListChangedDebounceMs = envCfg.NotifyToolListChangedDebounceMs
 
 	return cfg, nil
 }
 
+func resultsOptions(cfg config.ResultsConfig) results.Options {
+	perTool := make(map[string]int, len(cfg.Tools))
+	for _, tool := range cfg.Tools {
+		perTool[tool.ToolID] = tool.MaxBytes
+	}
+	return results.Options{
+		MaxBytes:     cfg.MaxBytes,
+		PerTool:      perTool,
+		PreviewItems: cfg.PreviewItems,
+		TTL:          cfg.TTL,
+		SessionQuota: cfg.SessionQuota,
+	}
+}
+
+func toolsetSpecsFromConfig(appCfg config.AppConfig) []toolset.Spec {
+	toolsetSpecs := make([]toolset.Spec, len(appCfg.Toolsets))
+	for i, spec := range appCfg.Toolsets {
+		toolsetSpecs[i] = toolset.Spec{
+			Name:             spec.Name,
+			Description:      spec.Description,
+			NamespaceFilters: spec.NamespaceFilters,
+			TagFilters:       spec.TagFilters,
+			AllowIDs:         spec.AllowIDs,
+			DenyIDs:          spec.DenyIDs,
+			Policy:           spec.Policy,
+			Exposure:         spec.Exposure,
+		}
+	}
+	return toolsetSpecs
+}
+
+// withDefinitionDirs returns appCfg with toolsets and skills from
+// toolsets_dir and skills_dir appended to the inline ones.
+func withDefinitionDirs(appCfg config.AppConfig) (config.AppConfig, error) {
+	if appCfg.ToolsetsDir != "" {
+		loaded, err := config.LoadToolsetsDir(appCfg.ToolsetsDir)
+		if err != nil {
+			return appCfg, fmt.Errorf("toolsets_dir: %w", err)
+		}
+		appCfg.Toolsets = append(slices.Clone(appCfg.Toolsets), loaded...)
+	}
+	if appCfg.SkillsDir != "" {
+		loaded, err := config.LoadSkillsDir(appCfg.SkillsDir)
+		if err != nil {
+			return appCfg, fmt.Errorf("skills_dir: %w", err)
+		}
+		appCfg.Skills = append(slices.Clone(appCfg.Skills), loaded...)
+	}
+	return appCfg, nil
+}
+
+func approvalGate(cfg config.ApprovalConfig, idx index.Index) *approval.Gate {
+	return approval.NewGate(approval.Options{
+		Policy: approval.Policy{
+			Destructive: cfg.Destructive,
+			Tags:        cfg.Tags,
+			Namespaces:  cfg.Namespaces,
+			ToolIDs:     cfg.ToolIDs,
+		},
+		Tools:      adapters.NewIndexAdapter(idx),
+		RedactKeys: cfg.RedactKeys,
+	})
+}
+
+// definitionSource re-reads the definition directories on every call.
+func definitionSource(appCfg config.AppConfig) definitions.Source {
+	return func() ([]toolset.Spec, []skills.Spec, error) {
+		merged, err := withDefinitionDirs(appCfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		return toolsetSpecsFromConfig(merged), skillSpecsFromConfig(merged), nil
+	}
+}
+
+func definitionDirs(appCfg config.AppConfig) []string {
+	var dirs []string
+	for _, dir := range []string{appCfg.ToolsetsDir, appCfg.SkillsDir} {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+func skillSpecsFromConfig(appCfg config.AppConfig) []skills.Spec {
+	skillSpecs := make([]skills.Spec, len(appCfg.Skills))
+	for i, spec := range appCfg.Skills {
+		steps := make([]skills.StepSpec, len(spec.Steps))
+		for j, step := range spec.Steps {
+			steps[j] = skills.StepSpec{
+				ID:        step.ID,
+				ToolID:    step.ToolID,
+				Inputs:    step.Inputs,
+				DependsOn: step.DependsOn,
+				OnError:   stepPolicyFromConfig(step.OnError),
+			}
+		}
+		skillSpecs[i] = skills.Spec{
+			Name:         spec.Name,
+			Description:  spec.Description,
+			ToolsetID:    spec.ToolsetID,
+			InputSchema:  spec.InputSchema,
+			Output:       spec.Output,
+			OutputSchema: spec.OutputSchema,
+			Steps:        steps,
+			Guards: skills.GuardSpec{
+				MaxSteps: spec.Guards.MaxSteps,
+				AllowIDs: spec.Guards.AllowIDs,
+			},
+		}
+	}
+	return skillSpecs
+}
+
+func stepPolicyFromConfig(cfg *config.SkillOnErrorConfig) *stepgraph.Policy {
+	if cfg == nil {
+		return nil
+	}
+	return &stepgraph.Policy{
+		Action:   cfg.Action,
+		Attempts: cfg.Attempts,
+		Backoff:  cfg.Backoff,
+		ToolID:   cfg.ToolID,
+		Args:     cfg.Args,
+	}
+}
+
 func runServe(ctx context.Context, cfg *ServeConfig) error {
 	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
+	// Keep the process log on stderr and forward request-scoped records to
+	// the calling client as MCP log notifications.
+	slog.SetDefault(slog.New(mcplog.NewHandler(slog.NewTextHandler(os.Stderr, nil))))
+
 	appCfg, err := loadServeConfig(cfg.Config, cfg)
 	if err != nil {
 		return fmt.Errorf("load config: %w", err)
@@ -289,34 +503,38 @@ func runServe(ctx context.Context, cfg *ServeConfig) error {
 		return fmt.Errorf("apply runtime limits: %w", err)
 	}
 
-	secretResolver, closeSecrets, err := bootstrap.NewSecretResolver(appCfg.Secrets, bwssecret.Register)
+	closeSecrets, err := resolveMCPBackendSecrets(ctx, &appCfg)
 	if err != nil {
-		return fmt.Errorf("secrets: %w", err)
-	}
-	if closeSecrets != nil {
-		defer func() { _ = closeSecrets() }()
-	}
-	if secretResolver != nil {
-		resolved, err := bootstrap.ResolveMCPBackendConfigs(ctx, secretResolver, appCfg.Backends.MCP)
-		if err != nil {
-			return fmt.Errorf("resolve mcp backend secrets: %w", err)
-		}
-		appCfg.Backends.MCP = resolved
+		return err
 	}
+	defer func() { _ = closeSecrets() }()
 
 	serverCfg, err := buildServerConfigFromConfig(appCfg)
 	if err != nil {
 		return fmt.Errorf("build server config: %w", err)
 	}
+	if appCfg.State.RunsDB != "" {
+		store, closeRuns, err := runs.OpenSQLite(appCfg.State.RunsDB)
+		if err != nil {
+			return fmt.Errorf("open run store: %w", err)
+		}
+		defer func() { _ = closeRuns() }()
+		serverCfg.Runs = store
+		serverCfg.RunRetention = appCfg.State.RunRetention
+	}
 
 	srv, err := server.New(serverCfg)
 	if err != nil {
 		return fmt.Errorf("create server: %w", err)
 	}
+	defer func() { _ = srv.Close() }()
 
 	if refresher, ok := serverCfg.Refresher.(*mcpbackend.Refresher); ok && refresher != nil {
 		refresher.StartLoop(ctx)
 	}
+	for _, watcher := range serverCfg.Watchers {
+		go watcher.Watch(ctx)
+	}
 
 	var transport transportpkg.Transport
 	switch appCfg.Transport.Type {
@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/jonwraymond/metatools-mcp/internal/config"
 )
 
 func TestServeCmd_Flags(t *testing.T) {
@@ -220,3 +223,44 @@ func clearServeEnv(t *testing.T) {
 		_ = os.Unsetenv(v)
 	}
 }
+
+func TestBuildServerConfig_DocsDir(t *testing.T) {
+	dir := t.TempDir()
+	doc := "tool: local:ping\nsummary: Curated ping\n"
+	if err := os.WriteFile(filepath.Join(dir, "ping.yaml"), []byte(doc), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	appCfg := config.DefaultAppConfig()
+	appCfg.Docs.Dir = dir
+	appCfg.Docs.Watch = true
+
+	serverCfg, err := buildServerConfigFromConfig(appCfg)
+	if err != nil {
+		t.Fatalf("buildServerConfigFromConfig() error = %v", err)
+	}
+	got, err := serverCfg.Docs.DescribeTool(context.Background(), "local:ping", "summary")
+	if err != nil {
+		t.Fatalf("DescribeTool() error = %v", err)
+	}
+	if got.Summary != "Curated ping" {
+		t.Errorf("Summary = %q, want %q", got.Summary, "Curated ping")
+	}
+	if len(serverCfg.Watchers) != 1 {
+		t.Errorf("Watchers = %d, want 1", len(serverCfg.Watchers))
+	}
+}
+
+func TestBuildServerConfig_DocsDirInvalidExample(t *testing.T) {
+	dir := t.TempDir()
+	doc := "tool: local:ping\nexamples:\n  - title: bad\n    args: {unexpected: true}\n"
+	if err := os.WriteFile(filepath.Join(dir, "ping.yaml"), []byte(doc), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	appCfg := config.DefaultAppConfig()
+	appCfg.Docs.Dir = dir
+	if _, err := buildServerConfigFromConfig(appCfg); err == nil {
+		t.Fatal("buildServerConfigFromConfig() should reject examples that violate the input schema")
+	}
+}
@@ -17,7 +17,7 @@ func TestCreateServer_ValidConfig(t *testing.T) {
 	require.NotNil(t, srv)
 
 	tools := srv.ListTools()
-	assert.Equal(t, 13, len(tools))
+	assert.Equal(t, 19, len(tools))
 	assert.True(t, srv.Capabilities().Tools)
 }
 
@@ -15,17 +15,20 @@ import (
 
 // NewConfig adapts the core tool libraries into a metatools server config.
 func NewConfig(idx index.Index, docs tooldoc.Store, runner run.Runner, exec code.Executor) config.Config {
-	defaults := config.DefaultAppConfig().SkillDefaults
+	appDefaults := config.DefaultAppConfig()
+	defaults := appDefaults.SkillDefaults
+	maxParallel := appDefaults.Execution.MaxParallelSteps
 	cfg := config.Config{
 		Index:    NewIndexAdapter(idx),
 		Docs:     NewDocsAdapter(docs),
-		Runner:   NewRunnerAdapter(runner),
+		Runner:   NewRunnerAdapter(runner, WithMaxParallel(maxParallel)),
 		Toolsets: toolset.NewRegistry(nil),
 		Skills:   skills.NewRegistry(nil),
 		SkillDefaults: handlers.SkillDefaults{
 			MaxSteps:     defaults.MaxSteps,
 			MaxToolCalls: defaults.MaxToolCalls,
 			Timeout:      defaults.Timeout,
+			MaxParallel:  maxParallel,
 		},
 		Providers:                       config.DefaultAppConfig().Providers,
 		Middleware:                      config.DefaultAppConfig().Middleware,
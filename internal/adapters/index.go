@@ -77,6 +77,13 @@ func (a *IndexAdapter) GetAllBackends(ctx context.Context, id string) ([]model.T
 	return a.idx.GetAllBackends(id)
 }
 
+// GetTool delegates to index, dropping the default backend.
+func (a *IndexAdapter) GetTool(ctx context.Context, id string) (model.Tool, error) {
+	_ = ctx
+	tool, _, err := a.idx.GetTool(id)
+	return tool, err
+}
+
 // OnChange registers a listener for index mutations when supported.
 // Returns a no-op unsubscribe when change notifications are unavailable.
 func (a *IndexAdapter) OnChange(listener index.ChangeListener) func() {
@@ -2,25 +2,60 @@ package adapters
 
 import (
 	"context"
+	"errors"
+	"log/slog"
+	"time"
 
+	"github.com/jonwraymond/metatools-mcp/internal/content"
+	merrors "github.com/jonwraymond/metatools-mcp/internal/errors"
 	"github.com/jonwraymond/metatools-mcp/internal/handlers"
+	"github.com/jonwraymond/metatools-mcp/internal/stepgraph"
+	"github.com/jonwraymond/metatools-mcp/internal/stepref"
 	"github.com/jonwraymond/toolexec/run"
-	"github.com/jonwraymond/toolfoundation/model"
 )
 
 // RunnerAdapter bridges run.Runner to the handlers.Runner interface.
 type RunnerAdapter struct {
-	runner run.Runner
+	runner      run.Runner
+	maxParallel int
+	content     *content.Collector
+}
+
+// RunnerOption configures a RunnerAdapter.
+type RunnerOption func(*RunnerAdapter)
+
+// WithMaxParallel bounds how many independent chain steps run at once.
+// Zero or less uses stepgraph.DefaultMaxParallel.
+func WithMaxParallel(n int) RunnerOption {
+	return func(a *RunnerAdapter) {
+		a.maxParallel = n
+	}
+}
+
+// WithContent sets the collector that extracts non-text content blocks from
+// MCP results. Without it, blocks are collected with the default size caps
+// and never linked.
+func WithContent(c *content.Collector) RunnerOption {
+	return func(a *RunnerAdapter) {
+		a.content = c
+	}
 }
 
 // NewRunnerAdapter creates a new runner adapter.
-func NewRunnerAdapter(runner run.Runner) *RunnerAdapter {
-	return &RunnerAdapter{runner: runner}
+func NewRunnerAdapter(runner run.Runner, opts ...RunnerOption) *RunnerAdapter {
+	a := &RunnerAdapter{runner: runner}
+	for _, opt := range opts {
+		opt(a)
+	}
+	if a.content == nil {
+		a.content = content.NewCollector(content.Options{})
+	}
+	return a
 }
 
 // Run delegates to run.
 func (a *RunnerAdapter) Run(ctx context.Context, toolID string, args map[string]any) (handlers.RunResult, error) {
-	res, err := a.runner.Run(ctx, toolID, args)
+	res, err := a.run(ctx, toolID, args)
 	if err != nil {
 		return handlers.RunResult{}, err
 	}
@@ -29,49 +64,20 @@ func (a *RunnerAdapter) Run(ctx context.Context, toolID string, args map[string]
 		Backend:    res.Backend,
 		Tool:       res.Tool,
 		MCPResult:  res.MCPResult,
+		Content:    a.collect(res),
 	}, nil
 }
 
-// RunChain delegates to toolrun and maps results.
+// RunChain executes steps in dependency order, resolving step references in
+// each step's args against earlier results before the call.
 func (a *RunnerAdapter) RunChain(ctx context.Context, steps []handlers.ChainStep) (handlers.RunResult, []handlers.StepResult, error) {
-	chainSteps := make([]run.ChainStep, len(steps))
-	for i, s := range steps {
-		chainSteps[i] = run.ChainStep{
-			ToolID:      s.ToolID,
-			Args:        s.Args,
-			UsePrevious: s.UsePrevious,
-		}
-	}
-
-	final, stepResults, err := a.runner.RunChain(ctx, chainSteps)
-
-	mappedSteps := make([]handlers.StepResult, len(stepResults))
-	for i, sr := range stepResults {
-		backend := pickBackend(sr)
-		var backendAny any
-		if backend.Kind != "" {
-			backendAny = backend
-		}
-		mappedSteps[i] = handlers.StepResult{
-			ToolID:     sr.ToolID,
-			Structured: sr.Result.Structured,
-			Backend:    backendAny,
-			Tool:       sr.Result.Tool,
-			Error:      sr.Err,
-		}
-	}
-
-	return handlers.RunResult{
-		Structured: final.Structured,
-		Backend:    final.Backend,
-		Tool:       final.Tool,
-		MCPResult:  final.MCPResult,
-	}, mappedSteps, err
+	return a.runChain(ctx, steps, nil, nil)
 }
 
 // RunWithProgress delegates to toolrun when progress is supported.
 func (a *RunnerAdapter) RunWithProgress(ctx context.Context, toolID string, args map[string]any, onProgress func(handlers.ProgressEvent)) (handlers.RunResult, error) {
 	if pr, ok := a.runner.(run.ProgressRunner); ok {
+		start := time.Now()
 		result, err := pr.RunWithProgress(ctx, toolID, args, func(ev run.ProgressEvent) {
 			if onProgress != nil {
 				onProgress(handlers.ProgressEvent{
@@ -81,6 +87,8 @@ func (a *RunnerAdapter) RunWithProgress(ctx context.Context, toolID string, args
 				})
 			}
 		})
+		err = merrors.WrapCanceled(ctx, err)
+		logCall(ctx, toolID, start, err)
 		if err != nil {
 			return handlers.RunResult{}, err
 		}
@@ -89,11 +97,12 @@ func (a *RunnerAdapter) RunWithProgress(ctx context.Context, toolID string, args
 			Backend:    result.Backend,
 			Tool:       result.Tool,
 			MCPResult:  result.MCPResult,
+			Content:    a.collect(result),
 		}, nil
 	}
 
 	// Fallback to non-progress execution.
-	res, err := a.runner.Run(ctx, toolID, args)
+	res, err := a.run(ctx, toolID, args)
 	if err != nil {
 		return handlers.RunResult{}, err
 	}
@@ -102,62 +111,192 @@ func (a *RunnerAdapter) RunWithProgress(ctx context.Context, toolID string, args
 		Backend:    res.Backend,
 		Tool:       res.Tool,
 		MCPResult:  res.MCPResult,
+		Content:    a.collect(res),
 	}, nil
 }
 
-// RunChainWithProgress delegates to toolrun when progress is supported.
+// RunChainWithProgress executes a chain and emits a progress update per step.
 func (a *RunnerAdapter) RunChainWithProgress(ctx context.Context, steps []handlers.ChainStep, onProgress func(handlers.ProgressEvent)) (handlers.RunResult, []handlers.StepResult, error) {
-	if pr, ok := a.runner.(run.ProgressRunner); ok {
-		chainSteps := make([]run.ChainStep, len(steps))
-		for i, s := range steps {
-			chainSteps[i] = run.ChainStep{
-				ToolID:      s.ToolID,
-				Args:        s.Args,
-				UsePrevious: s.UsePrevious,
-			}
+	return a.runChain(ctx, steps, onProgress, nil)
+}
+
+// RunChainWithCheckpoint executes a chain, reporting each finished step to
+// onStep. Steps with a Recorded result are replayed without a call.
+func (a *RunnerAdapter) RunChainWithCheckpoint(ctx context.Context, steps []handlers.ChainStep, onProgress func(handlers.ProgressEvent), onStep func(int, handlers.StepResult)) (handlers.RunResult, []handlers.StepResult, error) {
+	return a.runChain(ctx, steps, onProgress, onStep)
+}
+
+// runChain mirrors the toolrun chain policy (stop on first error, previous
+// result injected at args["previous"]) and adds step reference resolution and
+// depends_on scheduling. Step results and progress follow topological order.
+func (a *RunnerAdapter) runChain(ctx context.Context, steps []handlers.ChainStep, onProgress func(handlers.ProgressEvent), onStep func(int, handlers.StepResult)) (handlers.RunResult, []handlers.StepResult, error) {
+	if len(steps) == 0 {
+		return handlers.RunResult{}, nil, nil
+	}
+	specs := make([]stepgraph.Spec, len(steps))
+	for i, step := range steps {
+		specs[i] = stepgraph.Spec{
+			ID:          step.ID,
+			DependsOn:   step.DependsOn,
+			Args:        step.Args,
+			UsePrevious: step.UsePrevious,
+			OnError:     step.OnError,
 		}
+	}
+	graph, err := stepgraph.Build(specs)
+	if err != nil {
+		return handlers.RunResult{}, nil, err
+	}
 
-		final, stepResults, err := pr.RunChainWithProgress(ctx, chainSteps, func(ev run.ProgressEvent) {
-			if onProgress != nil {
-				onProgress(handlers.ProgressEvent{
-					Progress: ev.Progress,
-					Total:    ev.Total,
-					Message:  ev.Message,
-				})
-			}
-		})
+	progress := func(done int, msg string) {
+		if onProgress != nil {
+			onProgress(handlers.ProgressEvent{Progress: float64(done), Total: float64(len(steps)), Message: msg})
+		}
+	}
+	progress(0, "started")
 
-		mappedSteps := make([]handlers.StepResult, len(stepResults))
-		for i, sr := range stepResults {
-			backend := pickBackend(sr)
-			var backendAny any
-			if backend.Kind != "" {
-				backendAny = backend
+	refs := stepref.NewResults()
+	results := make([]run.RunResult, len(steps))
+	reports := make([]*stepgraph.Report, len(steps))
+	mapped := make([]handlers.StepResult, 0, len(steps))
+	ran, err := graph.Run(ctx, stepgraph.Options{
+		MaxParallel: a.maxParallel,
+		OnStep: func(i int, err error) {
+			sr := stepResult(i, steps[i], results[i], err, reports[i])
+			sr.Content = a.collect(results[i])
+			mapped = append(mapped, sr)
+			logStep(ctx, "chain step finished", i, steps[i], sr.Error)
+			if onStep != nil {
+				onStep(i, sr)
+			}
+			if sr.Error != nil {
+				progress(len(mapped), "step_error")
+				return
 			}
-			mappedSteps[i] = handlers.StepResult{
-				ToolID:     sr.ToolID,
-				Structured: sr.Result.Structured,
-				Backend:    backendAny,
-				Tool:       sr.Result.Tool,
-				Error:      sr.Err,
+			progress(len(mapped), "step_completed")
+		},
+	}, func(ctx context.Context, i int) error {
+		step := steps[i]
+		logStep(ctx, "chain step started", i, step, nil)
+		if step.Recorded != nil {
+			results[i] = run.RunResult{Structured: step.Recorded.Structured}
+			refs.Set(i, step.ID, results[i].Structured)
+			return nil
+		}
+		call := func(ctx context.Context, toolID string, stepArgs map[string]any) error {
+			args, err := stepref.Resolve(stepArgs, refs)
+			if err != nil {
+				return err
 			}
+			if step.UsePrevious {
+				if args == nil {
+					args = make(map[string]any, 1)
+				}
+				var previous any
+				if i > 0 {
+					previous = results[i-1].Structured
+				}
+				args["previous"] = previous
+			}
+			results[i], err = a.run(ctx, toolID, args)
+			return err
+		}
+		report, err := step.OnError.Apply(ctx, nil, step.ToolID, step.Args, call)
+		reports[i] = report
+		if err != nil || report.Continued() {
+			return err
 		}
+		refs.Set(i, step.ID, results[i].Structured)
+		return nil
+	})
+	if err != nil {
+		return handlers.RunResult{}, mapped, err
+	}
 
-		return handlers.RunResult{
-			Structured: final.Structured,
-			Backend:    final.Backend,
-			Tool:       final.Tool,
-			MCPResult:  final.MCPResult,
-		}, mappedSteps, err
+	// The final result is the last step that produced one.
+	var final run.RunResult
+	for j := len(ran) - 1; j >= 0; j-- {
+		if !reports[ran[j]].Continued() {
+			final = results[ran[j]]
+			break
+		}
+	}
+	return handlers.RunResult{
+		Structured: final.Structured,
+		Backend:    final.Backend,
+		Tool:       final.Tool,
+		MCPResult:  final.MCPResult,
+	}, mapped, nil
+}
+
+// stepResult maps a finished step, taking the backend from a tool error when
+// the call failed. Errors kept by on_error continue are reported on the step.
+func stepResult(i int, step handlers.ChainStep, res run.RunResult, err error, report *stepgraph.Report) handlers.StepResult {
+	if err == nil && report.Continued() {
+		err = report.Cause
+	}
+	backend := res.Backend
+	var toolErr *run.ToolError
+	if err != nil && errors.As(err, &toolErr) && toolErr.Backend != nil {
+		backend = *toolErr.Backend
+	}
+	var backendAny any
+	if backend.Kind != "" {
+		backendAny = backend
 	}
+	return handlers.StepResult{
+		Index:      i,
+		StepID:     step.ID,
+		ToolID:     step.ToolID,
+		Structured: res.Structured,
+		Backend:    backendAny,
+		Tool:       res.Tool,
+		Error:      err,
+		Policy:     report,
+	}
+}
 
-	// Fallback to non-progress chain execution.
-	return a.RunChain(ctx, steps)
+// collect returns the non-text content blocks of an MCP result.
+func (a *RunnerAdapter) collect(res run.RunResult) []any {
+	blocks := a.content.Collect(res.MCPResult)
+	if len(blocks) == 0 {
+		return nil
+	}
+	out := make([]any, len(blocks))
+	for i, b := range blocks {
+		out[i] = b
+	}
+	return out
 }
 
-func pickBackend(sr run.StepResult) model.ToolBackend {
-	if sr.Backend.Kind != "" {
-		return sr.Backend
+// run calls the wrapped runner and logs the call. Calls stopped by ctx
+// carry its cancellation cause.
+func (a *RunnerAdapter) run(ctx context.Context, toolID string, args map[string]any) (run.RunResult, error) {
+	start := time.Now()
+	res, err := a.runner.Run(ctx, toolID, args)
+	err = merrors.WrapCanceled(ctx, err)
+	logCall(ctx, toolID, start, err)
+	return res, err
+}
+
+// logCall logs a finished tool call. Records carry ctx, so they also reach
+// the calling client as MCP log notifications.
+func logCall(ctx context.Context, toolID string, start time.Time, err error) {
+	ms := time.Since(start).Milliseconds()
+	if err != nil {
+		slog.WarnContext(ctx, "tool call failed", "tool_id", toolID, "duration_ms", ms, "error", err)
+		return
+	}
+	slog.DebugContext(ctx, "tool call", "tool_id", toolID, "duration_ms", ms)
+}
+
+func logStep(ctx context.Context, msg string, i int, step handlers.ChainStep, stepErr error) {
+	attrs := []any{"step", i, "tool_id", step.ToolID}
+	if step.ID != "" {
+		attrs = append(attrs, "step_id", step.ID)
+	}
+	if stepErr != nil {
+		attrs = append(attrs, "error", stepErr)
 	}
-	return sr.Result.Backend
+	slog.DebugContext(ctx, msg, attrs...)
 }
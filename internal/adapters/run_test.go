@@ -0,0 +1,211 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	merrors "github.com/jonwraymond/metatools-mcp/internal/errors"
+	"github.com/jonwraymond/metatools-mcp/internal/handlers"
+	"github.com/jonwraymond/metatools-mcp/internal/stepgraph"
+	"github.com/jonwraymond/toolexec/run"
+	"github.com/jonwraymond/toolfoundation/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRunner implements run.Runner.Run and records the args of each call.
+type fakeRunner struct {
+	run.Runner
+	results map[string]any
+	mu      sync.Mutex
+	calls   []map[string]any
+}
+
+func (f *fakeRunner) Run(_ context.Context, toolID string, args map[string]any) (run.RunResult, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, args)
+	f.mu.Unlock()
+	value, ok := f.results[toolID]
+	if !ok {
+		return run.RunResult{}, run.ErrToolNotFound
+	}
+	return run.RunResult{
+		Structured: value,
+		Backend:    model.ToolBackend{Kind: model.BackendKindLocal, Local: &model.LocalBackend{Name: toolID}},
+	}, nil
+}
+
+func TestRunnerAdapter_RunChainResolvesReferences(t *testing.T) {
+	runner := &fakeRunner{results: map[string]any{
+		"gh:repo":   map[string]any{"owner": "octo"},
+		"gh:search": map[string]any{"items": []any{map[string]any{"id": 7.0}}},
+		"gh:get":    map[string]any{"url": "https://example.com/7"},
+	}}
+	adapter := NewRunnerAdapter(runner)
+
+	final, steps, err := adapter.RunChain(context.Background(), []handlers.ChainStep{
+		{ID: "repo", ToolID: "gh:repo"},
+		{ID: "search", ToolID: "gh:search"},
+		{ToolID: "gh:get", UsePrevious: true, Args: map[string]any{
+			"issue_id": map[string]any{"$ref": "steps.search.structured.items[0].id"},
+			"owner":    "${steps[0].result.owner}",
+		}},
+	})
+	require.NoError(t, err)
+	require.Len(t, steps, 3)
+	assert.Equal(t, "search", steps[1].StepID)
+	assert.Equal(t, map[string]any{"url": "https://example.com/7"}, final.Structured)
+	assert.Equal(t, map[string]any{
+		"issue_id": 7.0,
+		"owner":    "octo",
+		"previous": map[string]any{"items": []any{map[string]any{"id": 7.0}}},
+	}, runner.calls[2])
+}
+
+func TestRunnerAdapter_RunChainUnresolvedReference(t *testing.T) {
+	runner := &fakeRunner{results: map[string]any{"gh:search": map[string]any{"items": []any{}}}}
+	adapter := NewRunnerAdapter(runner)
+
+	var events []handlers.ProgressEvent
+	_, steps, err := adapter.RunChainWithProgress(context.Background(), []handlers.ChainStep{
+		{ID: "search", ToolID: "gh:search"},
+		{ToolID: "gh:get", Args: map[string]any{"id": "${steps.search.structured.items[0].id}"}},
+	}, func(ev handlers.ProgressEvent) { events = append(events, ev) })
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, merrors.ErrValidationInput))
+	assert.Contains(t, err.Error(), "args.id")
+	assert.Contains(t, err.Error(), "index 0 out of range")
+	require.Len(t, steps, 2)
+	assert.Equal(t, err, steps[1].Error)
+	assert.Len(t, runner.calls, 1, "the failing step must not run")
+	require.Len(t, events, 3)
+	assert.Equal(t, "step_error", events[2].Message)
+
+	obj := merrors.MapToolError(steps[1].Error, steps[1].ToolID, nil, -1)
+	assert.Equal(t, merrors.CodeValidationInput, obj.Code)
+}
+
+func TestRunnerAdapter_RunChainDependsOn(t *testing.T) {
+	runner := &fakeRunner{results: map[string]any{
+		"gh:issues": map[string]any{"count": 3.0},
+		"gh:pulls":  map[string]any{"count": 2.0},
+		"gh:report": map[string]any{"ok": true},
+	}}
+	adapter := NewRunnerAdapter(runner, WithMaxParallel(2))
+
+	var events []handlers.ProgressEvent
+	final, steps, err := adapter.RunChainWithProgress(context.Background(), []handlers.ChainStep{
+		{ID: "report", ToolID: "gh:report", DependsOn: []string{"issues"}, Args: map[string]any{
+			"pulls": "${steps.pulls.structured.count}",
+		}},
+		{ID: "issues", ToolID: "gh:issues", DependsOn: []string{}},
+		{ID: "pulls", ToolID: "gh:pulls"},
+	}, func(ev handlers.ProgressEvent) { events = append(events, ev) })
+	require.NoError(t, err)
+
+	require.Len(t, steps, 3)
+	assert.Equal(t, []string{"issues", "pulls", "report"}, []string{steps[0].StepID, steps[1].StepID, steps[2].StepID})
+	// Results keep the declared index of their step.
+	assert.Equal(t, []int{1, 2, 0}, []int{steps[0].Index, steps[1].Index, steps[2].Index})
+	assert.Equal(t, map[string]any{"ok": true}, final.Structured)
+	require.Len(t, events, 4)
+	assert.Equal(t, float64(3), events[3].Progress)
+	assert.Equal(t, map[string]any{"pulls": 2.0}, runner.calls[2])
+}
+
+func TestRunnerAdapter_RunChainCycle(t *testing.T) {
+	runner := &fakeRunner{}
+	adapter := NewRunnerAdapter(runner)
+
+	_, _, err := adapter.RunChain(context.Background(), []handlers.ChainStep{
+		{ID: "a", ToolID: "x", DependsOn: []string{"b"}},
+		{ID: "b", ToolID: "y", DependsOn: []string{"a"}},
+	})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, merrors.ErrValidationInput))
+	assert.Contains(t, err.Error(), "dependency cycle")
+	assert.Empty(t, runner.calls)
+}
+
+func TestRunnerAdapter_RunChainErrorPolicies(t *testing.T) {
+	runner := &fakeRunner{results: map[string]any{
+		"cache:get": map[string]any{"hit": true},
+		"gh:list":   map[string]any{"n": 1.0},
+	}}
+	adapter := NewRunnerAdapter(runner)
+
+	final, steps, err := adapter.RunChain(context.Background(), []handlers.ChainStep{
+		{ID: "fetch", ToolID: "gh:get", OnError: &stepgraph.Policy{
+			Action: stepgraph.ActionFallback,
+			ToolID: "cache:get",
+		}},
+		{ID: "optional", ToolID: "gh:missing", OnError: &stepgraph.Policy{Action: stepgraph.ActionContinue}},
+		{ID: "list", ToolID: "gh:list"},
+		{ID: "last", ToolID: "gh:missing", OnError: &stepgraph.Policy{Action: stepgraph.ActionContinue}},
+	})
+	require.NoError(t, err)
+	require.Len(t, steps, 4)
+
+	assert.Equal(t, map[string]any{"hit": true}, steps[0].Structured)
+	assert.NoError(t, steps[0].Error)
+	require.NotNil(t, steps[0].Policy)
+	assert.Equal(t, "cache:get", steps[0].Policy.FallbackToolID)
+	assert.ErrorIs(t, steps[0].Policy.Cause, run.ErrToolNotFound)
+
+	assert.ErrorIs(t, steps[1].Error, run.ErrToolNotFound)
+	assert.True(t, steps[1].Policy.Continued())
+
+	// The final result skips trailing steps that only recorded an error.
+	assert.Equal(t, map[string]any{"n": 1.0}, final.Structured)
+}
+
+// cancelingRunner cancels the call's context and then fails the way
+// toolexec reports backend errors, without the context error.
+type cancelingRunner struct {
+	run.Runner
+	cancel context.CancelCauseFunc
+}
+
+func (r *cancelingRunner) Run(context.Context, string, map[string]any) (run.RunResult, error) {
+	r.cancel(errors.New("canceled by cancel_run"))
+	return run.RunResult{}, fmt.Errorf("%w: context canceled", run.ErrExecution)
+}
+
+func TestRunnerAdapter_RecordsCancelReason(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	adapter := NewRunnerAdapter(&cancelingRunner{cancel: cancel})
+
+	_, err := adapter.Run(ctx, "mcp.github:search", nil)
+	errObj := merrors.MapToolError(err, "mcp.github:search", nil, -1)
+	assert.Equal(t, merrors.CodeCancelled, errObj.Code)
+	assert.Equal(t, "canceled by cancel_run", errObj.Details["reason"])
+
+	ctx, cancel = context.WithCancelCause(context.Background())
+	adapter = NewRunnerAdapter(&cancelingRunner{cancel: cancel})
+	_, _, err = adapter.RunChain(ctx, []handlers.ChainStep{{ToolID: "mcp.github:search"}})
+	assert.Equal(t, "canceled by cancel_run", merrors.MapToolError(err, "", nil, 0).Details["reason"])
+}
+
+func TestRunnerAdapter_RunChainWithCheckpointReplaysRecordedSteps(t *testing.T) {
+	runner := &fakeRunner{results: map[string]any{
+		"gh:get": map[string]any{"url": "https://example.com/7"},
+	}}
+	adapter := NewRunnerAdapter(runner)
+
+	recorded := map[string]any{"items": []any{map[string]any{"id": 7.0}}}
+	var checkpoints []int
+	final, steps, err := adapter.RunChainWithCheckpoint(context.Background(), []handlers.ChainStep{
+		{ID: "search", ToolID: "gh:search", Recorded: &handlers.StepResult{StepID: "search", ToolID: "gh:search", Structured: recorded}},
+		{ToolID: "gh:get", Args: map[string]any{"id": "${steps.search.result.items[0].id}"}},
+	}, nil, func(i int, _ handlers.StepResult) { checkpoints = append(checkpoints, i) })
+	require.NoError(t, err)
+	require.Len(t, steps, 2)
+	assert.Equal(t, recorded, steps[0].Structured)
+	assert.Equal(t, map[string]any{"url": "https://example.com/7"}, final.Structured)
+	assert.Equal(t, []map[string]any{{"id": 7.0}}, runner.calls, "recorded steps must not run")
+	assert.Equal(t, []int{0, 1}, checkpoints)
+}
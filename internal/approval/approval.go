@@ -0,0 +1,280 @@
+// Package approval gates tool calls behind human approval. A Gate matches
+// tools against a Policy and, for matching tools, asks the calling client to
+// confirm through MCP elicitation before the call runs.
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	merrors "github.com/jonwraymond/metatools-mcp/internal/errors"
+	"github.com/jonwraymond/metatools-mcp/internal/runcheck"
+	"github.com/jonwraymond/toolexec/run"
+	"github.com/jonwraymond/toolfoundation/model"
+	"github.com/jonwraymond/toolops/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Decisions recorded in the audit log.
+const (
+	DecisionApproved        = "approved"
+	DecisionApprovedSession = "approved_for_session"
+	DecisionRemembered      = "remembered"
+	DecisionDeclined        = "declined"
+	DecisionCanceled        = "canceled"
+	DecisionUnavailable     = "unavailable"
+)
+
+// DefaultRedactKeys are argument keys whose values are never shown to the
+// approver.
+var DefaultRedactKeys = []string{"password", "secret", "token", "api_key", "apikey", "authorization", "credential"}
+
+const redacted = "[REDACTED]"
+
+// Policy selects the tools that need approval.
+type Policy struct {
+	// Destructive gates tools annotated with destructiveHint: true.
+	Destructive bool
+	// Tags gates tools carrying any of these tags.
+	Tags []string
+	// Namespaces gates tools in any of these namespaces.
+	Namespaces []string
+	// ToolIDs gates these tools.
+	ToolIDs []string
+}
+
+// Reason returns why tool needs approval, or "" when it does not.
+func (p Policy) Reason(tool model.Tool) string {
+	id := tool.ToolID()
+	switch {
+	case slices.Contains(p.ToolIDs, id):
+		return "tool " + id + " requires approval"
+	case tool.Namespace != "" && slices.Contains(p.Namespaces, tool.Namespace):
+		return "namespace " + tool.Namespace + " requires approval"
+	}
+	for _, tag := range tool.Tags {
+		if slices.Contains(p.Tags, tag) {
+			return "tag " + tag + " requires approval"
+		}
+	}
+	if p.Destructive && tool.Annotations != nil && tool.Annotations.DestructiveHint != nil && *tool.Annotations.DestructiveHint {
+		return "tool is marked destructive"
+	}
+	return ""
+}
+
+// Session is the client session asked for approval.
+type Session interface {
+	ID() string
+	Elicit(ctx context.Context, params *mcp.ElicitParams) (*mcp.ElicitResult, error)
+}
+
+type sessionKey struct{}
+
+// WithSession returns a context whose tool calls ask session for approval.
+func WithSession(ctx context.Context, session Session) context.Context {
+	return context.WithValue(ctx, sessionKey{}, session)
+}
+
+// SessionFromContext returns the session set by WithSession, or nil.
+func SessionFromContext(ctx context.Context) Session {
+	s, _ := ctx.Value(sessionKey{}).(Session)
+	return s
+}
+
+// ToolLookup resolves tool IDs to tool definitions.
+type ToolLookup interface {
+	GetTool(ctx context.Context, id string) (model.Tool, error)
+}
+
+// Options configures a Gate.
+type Options struct {
+	Policy Policy
+	// Tools resolves the tools being called. Tools it cannot find are not
+	// gated; the runner reports them as not found.
+	Tools ToolLookup
+	// RedactKeys are argument keys whose values are hidden from the approver,
+	// matched case-insensitively at any depth. Empty uses DefaultRedactKeys.
+	RedactKeys []string
+	// Logger receives the audit entry of every decision; defaults to
+	// slog.Default.
+	Logger *slog.Logger
+}
+
+// Gate asks for approval before gated tool calls run.
+type Gate struct {
+	policy     Policy
+	tools      ToolLookup
+	redactKeys []string
+	logger     *slog.Logger
+
+	mu       sync.Mutex
+	approved map[string]map[string]struct{}
+}
+
+// NewGate creates an approval gate.
+func NewGate(opts Options) *Gate {
+	if len(opts.RedactKeys) == 0 {
+		opts.RedactKeys = DefaultRedactKeys
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	keys := make([]string, len(opts.RedactKeys))
+	for i, k := range opts.RedactKeys {
+		keys[i] = strings.ToLower(k)
+	}
+	return &Gate{
+		policy:     opts.Policy,
+		tools:      opts.Tools,
+		redactKeys: keys,
+		logger:     opts.Logger,
+		approved:   make(map[string]map[string]struct{}),
+	}
+}
+
+// Check returns nil when the call of toolID with args may run. Gated calls
+// without an elicitation-capable session fail with ErrApprovalRequired;
+// calls the user does not accept fail with ErrApprovalDenied.
+func (g *Gate) Check(ctx context.Context, toolID string, args map[string]any) error {
+	if g.tools == nil {
+		return nil
+	}
+	tool, err := g.tools.GetTool(ctx, toolID)
+	if err != nil {
+		return nil
+	}
+	reason := g.policy.Reason(tool)
+	if reason == "" {
+		return nil
+	}
+
+	session := SessionFromContext(ctx)
+	if session == nil {
+		g.audit(ctx, toolID, reason, DecisionUnavailable, "")
+		return fmt.Errorf("%w: %s: %s; the client does not support elicitation", merrors.ErrApprovalRequired, toolID, reason)
+	}
+	sessionID := session.ID()
+	if g.remembered(sessionID, toolID) {
+		g.audit(ctx, toolID, reason, DecisionRemembered, sessionID)
+		return nil
+	}
+
+	res, err := session.Elicit(ctx, &mcp.ElicitParams{
+		Message:         g.message(toolID, reason, args),
+		RequestedSchema: rememberSchema,
+	})
+	if err != nil {
+		g.audit(ctx, toolID, reason, DecisionUnavailable, sessionID)
+		return fmt.Errorf("%w: %s: %s: %v", merrors.ErrApprovalRequired, toolID, reason, err)
+	}
+	switch res.Action {
+	case "accept":
+		decision := DecisionApproved
+		if remember, _ := res.Content["remember"].(bool); remember {
+			g.remember(sessionID, toolID)
+			decision = DecisionApprovedSession
+		}
+		g.audit(ctx, toolID, reason, decision, sessionID)
+		return nil
+	case "decline":
+		g.audit(ctx, toolID, reason, DecisionDeclined, sessionID)
+	default:
+		g.audit(ctx, toolID, reason, DecisionCanceled, sessionID)
+	}
+	return fmt.Errorf("%w: %s: %s", merrors.ErrApprovalDenied, toolID, res.Action)
+}
+
+// WrapRunner returns a runner that checks every tool call with gate before
+// delegating to base, see runcheck.Wrap.
+func WrapRunner(base run.Runner, gate *Gate) run.Runner {
+	return runcheck.Wrap(base, gate.Check)
+}
+
+// Forget drops the approvals remembered for sessionID.
+func (g *Gate) Forget(sessionID string) {
+	g.mu.Lock()
+	delete(g.approved, sessionID)
+	g.mu.Unlock()
+}
+
+// rememberSchema asks whether to approve the tool for the rest of the
+// session. It has no default: the SDK applies defaults to the returned
+// content, which clients may omit on accept.
+var rememberSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"remember": map[string]any{
+			"type":        "boolean",
+			"title":       "Approve for this session",
+			"description": "Skip this prompt for further calls of the tool in this session.",
+		},
+	},
+}
+
+func (g *Gate) message(toolID, reason string, args map[string]any) string {
+	msg := fmt.Sprintf("Approve call to %s? Reason: %s.", toolID, reason)
+	if len(args) == 0 {
+		return msg
+	}
+	data, err := json.Marshal(g.redact(args))
+	if err != nil {
+		return msg
+	}
+	return msg + "\nArguments: " + string(data)
+}
+
+// redact returns a copy of v with the values of redacted keys replaced.
+func (g *Gate) redact(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, val := range v {
+			if slices.Contains(g.redactKeys, strings.ToLower(k)) {
+				out[k] = redacted
+				continue
+			}
+			out[k] = g.redact(val)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, val := range v {
+			out[i] = g.redact(val)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func (g *Gate) remembered(sessionID, toolID string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	_, ok := g.approved[sessionID][toolID]
+	return ok
+}
+
+func (g *Gate) remember(sessionID, toolID string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.approved[sessionID] == nil {
+		g.approved[sessionID] = make(map[string]struct{})
+	}
+	g.approved[sessionID][toolID] = struct{}{}
+}
+
+func (g *Gate) audit(ctx context.Context, toolID, reason, decision, sessionID string) {
+	g.logger.InfoContext(ctx, "tool approval",
+		"tool_id", toolID,
+		"reason", reason,
+		"decision", decision,
+		"session_id", sessionID,
+		"principal", auth.PrincipalFromContext(ctx),
+	)
+}
@@ -0,0 +1,188 @@
+package approval
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	merrors "github.com/jonwraymond/metatools-mcp/internal/errors"
+	"github.com/jonwraymond/toolexec/run"
+	"github.com/jonwraymond/toolfoundation/model"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTools map[string]model.Tool
+
+func (f fakeTools) GetTool(_ context.Context, id string) (model.Tool, error) {
+	tool, ok := f[id]
+	if !ok {
+		return model.Tool{}, errors.New("not found")
+	}
+	return tool, nil
+}
+
+type fakeSession struct {
+	id      string
+	results []*mcp.ElicitResult
+	prompts []*mcp.ElicitParams
+}
+
+func (s *fakeSession) ID() string { return s.id }
+
+func (s *fakeSession) Elicit(_ context.Context, params *mcp.ElicitParams) (*mcp.ElicitResult, error) {
+	s.prompts = append(s.prompts, params)
+	if len(s.results) == 0 {
+		return nil, errors.New("client does not support elicitation")
+	}
+	res := s.results[0]
+	s.results = s.results[1:]
+	return res, nil
+}
+
+func newTool(namespace, name string, tags []string, destructive bool) model.Tool {
+	tool := model.Tool{Tool: mcp.Tool{Name: name}, Namespace: namespace, Tags: tags}
+	if destructive {
+		tool.Annotations = &mcp.ToolAnnotations{DestructiveHint: &destructive}
+	}
+	return tool
+}
+
+func testTools() fakeTools {
+	return fakeTools{
+		"fs:delete": newTool("fs", "delete", nil, true),
+		"fs:read":   newTool("fs", "read", nil, false),
+		"db:drop":   newTool("db", "drop", []string{"dangerous"}, false),
+		"ops:exec":  newTool("ops", "exec", nil, false),
+	}
+}
+
+func TestPolicy_Reason(t *testing.T) {
+	tools := testTools()
+	policy := Policy{Destructive: true, Tags: []string{"dangerous"}, Namespaces: []string{"ops"}, ToolIDs: []string{"fs:read"}}
+
+	require.Equal(t, "tool is marked destructive", policy.Reason(tools["fs:delete"]))
+	require.Equal(t, "tool fs:read requires approval", policy.Reason(tools["fs:read"]))
+	require.Equal(t, "tag dangerous requires approval", policy.Reason(tools["db:drop"]))
+	require.Equal(t, "namespace ops requires approval", policy.Reason(tools["ops:exec"]))
+	require.Empty(t, Policy{}.Reason(tools["fs:delete"]))
+}
+
+func TestGate_RequiresSession(t *testing.T) {
+	var logs bytes.Buffer
+	gate := NewGate(Options{
+		Policy: Policy{Destructive: true},
+		Tools:  testTools(),
+		Logger: slog.New(slog.NewTextHandler(&logs, nil)),
+	})
+
+	require.NoError(t, gate.Check(context.Background(), "fs:read", nil))
+	require.NoError(t, gate.Check(context.Background(), "unknown:tool", nil))
+
+	err := gate.Check(context.Background(), "fs:delete", nil)
+	require.ErrorIs(t, err, merrors.ErrApprovalRequired)
+	require.Contains(t, logs.String(), "decision=unavailable")
+
+	// A session whose client cannot elicit also requires approval.
+	ctx := WithSession(context.Background(), &fakeSession{id: "s1"})
+	require.ErrorIs(t, gate.Check(ctx, "fs:delete", nil), merrors.ErrApprovalRequired)
+}
+
+func TestGate_AcceptDeclineAndRemember(t *testing.T) {
+	var logs bytes.Buffer
+	gate := NewGate(Options{
+		Policy: Policy{Destructive: true},
+		Tools:  testTools(),
+		Logger: slog.New(slog.NewTextHandler(&logs, nil)),
+	})
+	session := &fakeSession{id: "s1", results: []*mcp.ElicitResult{
+		{Action: "accept"},
+		{Action: "decline"},
+		{Action: "cancel"},
+		{Action: "accept", Content: map[string]any{"remember": true}},
+	}}
+	ctx := WithSession(context.Background(), session)
+
+	require.NoError(t, gate.Check(ctx, "fs:delete", nil))
+	require.ErrorIs(t, gate.Check(ctx, "fs:delete", nil), merrors.ErrApprovalDenied)
+	require.ErrorIs(t, gate.Check(ctx, "fs:delete", nil), merrors.ErrApprovalDenied)
+	require.NoError(t, gate.Check(ctx, "fs:delete", nil))
+	require.Len(t, session.prompts, 4)
+
+	// Remembered approvals skip the prompt for the same session only.
+	require.NoError(t, gate.Check(ctx, "fs:delete", nil))
+	require.Len(t, session.prompts, 4)
+	other := WithSession(context.Background(), &fakeSession{id: "s2"})
+	require.ErrorIs(t, gate.Check(other, "fs:delete", nil), merrors.ErrApprovalRequired)
+
+	gate.Forget("s1")
+	require.ErrorIs(t, gate.Check(ctx, "fs:delete", nil), merrors.ErrApprovalRequired)
+
+	for _, decision := range []string{"approved", "declined", "canceled", "approved_for_session", "remembered"} {
+		require.Contains(t, logs.String(), "decision="+decision)
+	}
+}
+
+func TestGate_RedactsArgs(t *testing.T) {
+	gate := NewGate(Options{Policy: Policy{Destructive: true}, Tools: testTools()})
+	session := &fakeSession{id: "s1", results: []*mcp.ElicitResult{{Action: "accept"}}}
+	args := map[string]any{
+		"path":    "/tmp/x",
+		"API_KEY": "k",
+		"nested":  []any{map[string]any{"password": "p", "user": "u"}},
+	}
+
+	require.NoError(t, gate.Check(WithSession(context.Background(), session), "fs:delete", args))
+	require.Len(t, session.prompts, 1)
+	msg := session.prompts[0].Message
+	require.Contains(t, msg, "fs:delete")
+	require.Contains(t, msg, "tool is marked destructive")
+	require.Contains(t, msg, `"path":"/tmp/x"`)
+	require.Contains(t, msg, `"API_KEY":"[REDACTED]"`)
+	require.Contains(t, msg, `"password":"[REDACTED]"`)
+	require.NotContains(t, msg, `"k"`)
+	require.Equal(t, "k", args["API_KEY"])
+}
+
+type stubRunner struct {
+	calls []string
+}
+
+func (r *stubRunner) Run(_ context.Context, toolID string, _ map[string]any) (run.RunResult, error) {
+	r.calls = append(r.calls, toolID)
+	return run.RunResult{Structured: toolID}, nil
+}
+
+func (r *stubRunner) RunStream(context.Context, string, map[string]any) (<-chan run.StreamEvent, error) {
+	return nil, run.ErrStreamNotSupported
+}
+
+func (r *stubRunner) RunChain(ctx context.Context, steps []run.ChainStep) (run.RunResult, []run.StepResult, error) {
+	var last run.RunResult
+	for _, step := range steps {
+		last, _ = r.Run(ctx, step.ToolID, step.Args)
+	}
+	return last, nil, nil
+}
+
+func TestWrapRunner(t *testing.T) {
+	base := &stubRunner{}
+	runner := WrapRunner(base, NewGate(Options{Policy: Policy{Destructive: true}, Tools: testTools()}))
+	_, isProgress := runner.(run.ProgressRunner)
+	require.False(t, isProgress)
+	ctx := context.Background()
+
+	_, err := runner.Run(ctx, "fs:read", nil)
+	require.NoError(t, err)
+	_, err = runner.Run(ctx, "fs:delete", nil)
+	require.ErrorIs(t, err, merrors.ErrApprovalRequired)
+	_, err = runner.RunStream(ctx, "fs:delete", nil)
+	require.ErrorIs(t, err, merrors.ErrApprovalRequired)
+
+	// A gated step stops the chain before any step runs.
+	_, _, err = runner.RunChain(ctx, []run.ChainStep{{ToolID: "fs:read"}, {ToolID: "fs:delete"}})
+	require.ErrorIs(t, err, merrors.ErrApprovalRequired)
+	require.Equal(t, []string{"fs:read"}, base.calls)
+}
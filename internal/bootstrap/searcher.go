@@ -6,12 +6,37 @@ import (
 	"strings"
 
 	"github.com/jonwraymond/metatools-mcp/internal/config"
+	"github.com/jonwraymond/metatools-mcp/internal/query"
 	"github.com/jonwraymond/tooldiscovery/index"
 )
 
 // SearcherFromConfig selects a searcher based on configuration.
-// Returns nil to use the default lexical search.
+// Returns nil to use the default lexical search. When query processing is
+// enabled, the selected strategy is wrapped with a query.Searcher; semantic
+// and hybrid searchers still embed the query and documents as written.
 func SearcherFromConfig(cfg config.SearchConfig) (index.Searcher, error) {
+	searcher, err := strategySearcherFromConfig(cfg)
+	if err != nil || !cfg.QueryEnabled {
+		return searcher, err
+	}
+	return query.NewSearcher(searcher, QueryProcessorFromConfig(cfg)), nil
+}
+
+// QueryProcessorFromConfig builds the query processor for search settings.
+func QueryProcessorFromConfig(cfg config.SearchConfig) *query.Processor {
+	return query.NewProcessor(query.Options{
+		SplitIdentifiers: cfg.QuerySplitIdents,
+		Stemming:         cfg.QueryStemming,
+		Synonyms:         cfg.Synonyms,
+		Fuzzy: query.FuzzyOptions{
+			Enabled:       cfg.FuzzyEnabled,
+			MaxDistance:   cfg.FuzzyMaxDistance,
+			MinTermLength: cfg.FuzzyMinTermLength,
+		},
+	})
+}
+
+func strategySearcherFromConfig(cfg config.SearchConfig) (index.Searcher, error) {
 	switch strings.ToLower(strings.TrimSpace(cfg.Strategy)) {
 	case "", "lexical":
 		return nil, nil
@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"github.com/jonwraymond/metatools-mcp/internal/config"
+	"github.com/jonwraymond/metatools-mcp/internal/query"
 	semanticregistry "github.com/jonwraymond/metatools-mcp/internal/semantic"
 	"github.com/jonwraymond/tooldiscovery/index"
 	"github.com/jonwraymond/tooldiscovery/semantic"
@@ -21,32 +22,57 @@ func semanticSearcherFromConfig(cfg config.SearchConfig) (index.Searcher, error)
 	embedding := semantic.NewEmbeddingStrategy(embedder)
 	switch cfg.Strategy {
 	case "semantic":
-		return newSemanticIndexSearcher(embedding), nil
+		return &semanticIndexSearcher{strategy: embedding}, nil
 	case "hybrid":
 		bm25 := semantic.NewBM25Strategy(nil)
 		hybrid, err := semantic.NewHybridStrategy(bm25, embedding, cfg.SemanticWeight)
 		if err != nil {
 			return nil, err
 		}
-		return newSemanticIndexSearcher(hybrid), nil
+		return &semanticIndexSearcher{strategy: hybrid, bm25: bm25, embedding: embedding, weight: cfg.SemanticWeight}, nil
 	default:
 		return nil, fmt.Errorf("semantic searcher does not support strategy %q", cfg.Strategy)
 	}
 }
 
+// semanticIndexSearcher ranks documents with a semantic strategy. Behind
+// query processing, embeddings are taken of the query and documents as
+// written, while the bm25 leg of a hybrid strategy scores the processed
+// terms.
 type semanticIndexSearcher struct {
 	strategy semantic.Strategy
+	// bm25 and embedding are the legs of a hybrid strategy, weighted by
+	// weight; they are nil for the embedding-only strategy.
+	bm25      semantic.Strategy
+	embedding semantic.Strategy
+	weight    float64
 }
 
-func newSemanticIndexSearcher(strategy semantic.Strategy) index.Searcher {
-	return &semanticIndexSearcher{strategy: strategy}
+func (s *semanticIndexSearcher) Search(query string, limit int, docs []index.SearchDoc) ([]index.Summary, error) {
+	return s.search(s.strategy, query, limit, docs)
 }
 
-func (s *semanticIndexSearcher) Search(query string, limit int, docs []index.SearchDoc) ([]index.Summary, error) {
+// SearchProcessed implements query.ProcessedSearcher.
+func (s *semanticIndexSearcher) SearchProcessed(p query.Processed, limit int) ([]index.Summary, error) {
+	if s.bm25 == nil {
+		return s.search(s.strategy, p.Query, limit, p.Docs)
+	}
+	terms := &processedBM25{bm25: s.bm25, terms: p.Terms, docs: make(map[string]semantic.Document, len(p.TermDocs))}
+	for _, doc := range semantic.DocumentsFromSearchDocs(p.TermDocs) {
+		terms.docs[doc.ID] = doc
+	}
+	hybrid, err := semantic.NewHybridStrategy(terms, s.embedding, s.weight)
+	if err != nil {
+		return nil, err
+	}
+	return s.search(hybrid, p.Query, limit, p.Docs)
+}
+
+func (s *semanticIndexSearcher) search(strategy semantic.Strategy, query string, limit int, docs []index.SearchDoc) ([]index.Summary, error) {
 	if limit <= 0 {
 		return []index.Summary{}, nil
 	}
-	if s.strategy == nil {
+	if strategy == nil {
 		return nil, semantic.ErrInvalidSearcher
 	}
 
@@ -58,7 +84,7 @@ func (s *semanticIndexSearcher) Search(query string, limit int, docs []index.Sea
 		}
 	}
 
-	searcher := semantic.NewSearcher(idx, s.strategy)
+	searcher := semantic.NewSearcher(idx, strategy)
 	results, err := searcher.Search(context.Background(), query)
 	if err != nil {
 		return nil, err
@@ -85,7 +111,25 @@ func (s *semanticIndexSearcher) Search(query string, limit int, docs []index.Sea
 
 func (s *semanticIndexSearcher) Deterministic() bool { return true }
 
-var _ index.DeterministicSearcher = (*semanticIndexSearcher)(nil)
+var (
+	_ index.DeterministicSearcher = (*semanticIndexSearcher)(nil)
+	_ query.ProcessedSearcher     = (*semanticIndexSearcher)(nil)
+)
+
+// processedBM25 scores the processed terms against the documents augmented
+// with their normalized terms, whatever query and document it is given.
+type processedBM25 struct {
+	bm25  semantic.Strategy
+	terms string
+	docs  map[string]semantic.Document
+}
+
+func (s *processedBM25) Score(ctx context.Context, _ string, doc semantic.Document) (float64, error) {
+	if termDoc, ok := s.docs[doc.ID]; ok {
+		doc = termDoc
+	}
+	return s.bm25.Score(ctx, s.terms, doc)
+}
 
 func minInt(a, b int) int {
 	if a < b {
@@ -4,9 +4,11 @@ package bootstrap
 
 import (
 	"context"
+	"sync"
 	"testing"
 
 	"github.com/jonwraymond/metatools-mcp/internal/config"
+	"github.com/jonwraymond/metatools-mcp/internal/query"
 	semanticregistry "github.com/jonwraymond/metatools-mcp/internal/semantic"
 	"github.com/jonwraymond/tooldiscovery/index"
 	"github.com/jonwraymond/tooldiscovery/semantic"
@@ -19,6 +21,19 @@ func (stubEmbedder) Embed(_ context.Context, _ string) ([]float32, error) {
 	return []float32{0.1, 0.2, 0.3}, nil
 }
 
+// recordingEmbedder records every text it embeds.
+type recordingEmbedder struct {
+	mu    sync.Mutex
+	texts []string
+}
+
+func (e *recordingEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	e.mu.Lock()
+	e.texts = append(e.texts, text)
+	e.mu.Unlock()
+	return []float32{0.1, 0.2, 0.3}, nil
+}
+
 func TestSearcherFromConfig_Semantic(t *testing.T) {
 	require.NoError(t, semanticregistry.RegisterEmbedder("stub", func(_ map[string]any) (semantic.Embedder, error) {
 		return stubEmbedder{}, nil
@@ -53,3 +68,73 @@ func TestSearcherFromConfig_Hybrid(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, searcher)
 }
+
+func TestSearcherFromConfig_SemanticEmbedsTextAsWritten(t *testing.T) {
+	embedder := &recordingEmbedder{}
+	require.NoError(t, semanticregistry.RegisterEmbedder("recording", func(_ map[string]any) (semantic.Embedder, error) {
+		return embedder, nil
+	}))
+
+	docs := []index.SearchDoc{{
+		ID:      "github:create_pr",
+		DocText: "github:create_pr opens pull requests",
+		Summary: index.Summary{ID: "github:create_pr", Name: "create_pr", Namespace: "github"},
+	}}
+	embedded := func(cfg config.SearchConfig) []string {
+		searcher, err := SearcherFromConfig(cfg)
+		require.NoError(t, err)
+		embedder.texts = nil
+		_, err = searcher.Search("creating PRs", 5, docs)
+		require.NoError(t, err)
+		return embedder.texts
+	}
+
+	for _, strategy := range []string{"semantic", "hybrid"} {
+		cfg := config.SearchConfig{
+			Strategy:         strategy,
+			SemanticEmbedder: "recording",
+			SemanticWeight:   0.5,
+		}
+		plain := embedded(cfg)
+		require.Contains(t, plain, "creating PRs", strategy)
+
+		cfg.QueryEnabled = true
+		cfg.QuerySplitIdents = true
+		cfg.QueryStemming = true
+		cfg.Synonyms = map[string][]string{"pr": {"pull request"}}
+		searcher, err := SearcherFromConfig(cfg)
+		require.NoError(t, err)
+		require.IsType(t, &query.Searcher{}, searcher, strategy)
+		// The embedder sees the same text with query processing as without.
+		require.ElementsMatch(t, plain, embedded(cfg), strategy)
+	}
+}
+
+func TestSearcherFromConfig_HybridScoresProcessedTerms(t *testing.T) {
+	require.NoError(t, semanticregistry.RegisterEmbedder("stub-processed", func(_ map[string]any) (semantic.Embedder, error) {
+		return stubEmbedder{}, nil
+	}))
+	cfg := config.SearchConfig{
+		Strategy:         "hybrid",
+		SemanticEmbedder: "stub-processed",
+		SemanticWeight:   0.5,
+		QueryEnabled:     true,
+		Synonyms:         map[string][]string{"k8s": {"kubernetes"}},
+	}
+	searcher, err := SearcherFromConfig(cfg)
+	require.NoError(t, err)
+
+	// The stub embeds every text alike, so only the bm25 leg ranks; it
+	// finds kubernetes through the synonym.
+	docs := []index.SearchDoc{
+		{ID: "git:status", DocText: "git:status show the working tree status", Summary: index.Summary{ID: "git:status", Name: "status", Namespace: "git"}},
+		{ID: "kubernetes:get_pods", DocText: "kubernetes:get_pods list pods", Summary: index.Summary{ID: "kubernetes:get_pods", Name: "get_pods", Namespace: "kubernetes"}},
+	}
+	got, err := searcher.Search("k8s pods", 2, docs)
+	require.NoError(t, err)
+	require.Equal(t, "kubernetes:get_pods", got[0].ID)
+
+	got, err = searcher.Search("k8s", 2, docs)
+	require.NoError(t, err)
+	require.Equal(t, "kubernetes:get_pods", got[0].ID)
+}
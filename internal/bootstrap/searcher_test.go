@@ -0,0 +1,39 @@
+package bootstrap
+
+import (
+	"testing"
+
+	"github.com/jonwraymond/metatools-mcp/internal/config"
+	"github.com/jonwraymond/metatools-mcp/internal/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearcherFromConfig_QueryEnabledWrapsLexical(t *testing.T) {
+	cfg := config.SearchConfig{
+		Strategy:           "lexical",
+		QueryEnabled:       true,
+		QuerySplitIdents:   true,
+		QueryStemming:      true,
+		FuzzyEnabled:       true,
+		FuzzyMaxDistance:   1,
+		FuzzyMinTermLength: 4,
+		Synonyms:           map[string][]string{"k8s": {"kubernetes"}},
+	}
+	searcher, err := SearcherFromConfig(cfg)
+	require.NoError(t, err)
+
+	qs, ok := searcher.(*query.Searcher)
+	require.True(t, ok, "expected *query.Searcher, got %T", searcher)
+	assert.Nil(t, qs.Inner())
+	opts := qs.Processor().Options()
+	assert.True(t, opts.SplitIdentifiers)
+	assert.True(t, opts.Stemming)
+	assert.Equal(t, 1, opts.Fuzzy.MaxDistance)
+	assert.Equal(t, []string{"kubernetes"}, opts.Synonyms["k8s"])
+}
+
+func TestSearcherFromConfig_QueryEnabledPropagatesErrors(t *testing.T) {
+	_, err := SearcherFromConfig(config.SearchConfig{Strategy: "nope", QueryEnabled: true})
+	assert.Error(t, err)
+}
@@ -0,0 +1,198 @@
+// Package catalog captures the tools, toolsets and skills a server exposes
+// as a portable document and compares two such documents for breaking
+// changes.
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jonwraymond/metatools-mcp/internal/jsonshape"
+	"github.com/jonwraymond/metatools-mcp/internal/skills"
+	"github.com/jonwraymond/metatools-mcp/internal/toolset"
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolfoundation/model"
+	"gopkg.in/yaml.v3"
+)
+
+// Catalog is an exported view of everything a server exposes.
+type Catalog struct {
+	Tools    []Tool    `json:"tools"`
+	Toolsets []Toolset `json:"toolsets,omitempty"`
+	Skills   []Skill   `json:"skills,omitempty"`
+}
+
+// Tool is a tool with its schemas, backends and toolset membership.
+type Tool struct {
+	ID           string         `json:"id"`
+	Name         string         `json:"name"`
+	Namespace    string         `json:"namespace,omitempty"`
+	Version      string         `json:"version,omitempty"`
+	Description  string         `json:"description,omitempty"`
+	Tags         []string       `json:"tags,omitempty"`
+	InputSchema  map[string]any `json:"inputSchema,omitempty"`
+	OutputSchema map[string]any `json:"outputSchema,omitempty"`
+	// Backends lists backends as "kind:name", e.g. "mcp:deepwiki".
+	Backends []string `json:"backends,omitempty"`
+	Toolsets []string `json:"toolsets,omitempty"`
+}
+
+// Toolset is a toolset and the IDs of its member tools.
+type Toolset struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Tools       []string `json:"tools"`
+}
+
+// Skill is a skill definition.
+type Skill struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	ToolsetID   string         `json:"toolsetId,omitempty"`
+	InputSchema map[string]any `json:"inputSchema,omitempty"`
+	Steps       []Step         `json:"steps"`
+}
+
+// Step is a single skill step.
+type Step struct {
+	ID        string         `json:"id"`
+	ToolID    string         `json:"toolId"`
+	Inputs    map[string]any `json:"inputs,omitempty"`
+	DependsOn []string       `json:"dependsOn,omitempty"`
+}
+
+// Build records every tool in idx along with toolset membership and skill
+// definitions. The index must list all tools for an empty query, which holds
+// for the default lexical searcher.
+func Build(idx index.Index, toolsets []*toolset.Toolset, skillList []*skills.Skill) (Catalog, error) {
+	toolIDs, err := toolset.ListToolIDs(idx)
+	if err != nil {
+		return Catalog{}, fmt.Errorf("list tools: %w", err)
+	}
+
+	membership := make(map[string][]string)
+	cat := Catalog{Tools: make([]Tool, 0, len(toolIDs))}
+	for _, ts := range toolsets {
+		if ts == nil {
+			continue
+		}
+		ids := ts.ToolIDs()
+		for _, id := range ids {
+			membership[id] = append(membership[id], ts.ID)
+		}
+		cat.Toolsets = append(cat.Toolsets, Toolset{
+			ID:          ts.ID,
+			Name:        ts.Name,
+			Description: ts.Description,
+			Tools:       ids,
+		})
+	}
+
+	for _, id := range toolIDs {
+		tool, _, err := idx.GetTool(id)
+		if err != nil {
+			return Catalog{}, fmt.Errorf("get tool %q: %w", id, err)
+		}
+		backends, err := idx.GetAllBackends(id)
+		if err != nil {
+			return Catalog{}, fmt.Errorf("get backends for %q: %w", id, err)
+		}
+		entry, err := toolEntry(tool, backends)
+		if err != nil {
+			return Catalog{}, err
+		}
+		entry.Toolsets = membership[entry.ID]
+		sort.Strings(entry.Toolsets)
+		cat.Tools = append(cat.Tools, entry)
+	}
+	sort.Slice(cat.Tools, func(i, j int) bool { return cat.Tools[i].ID < cat.Tools[j].ID })
+
+	for _, s := range skillList {
+		if s == nil {
+			continue
+		}
+		entry := Skill{
+			ID:          s.ID,
+			Name:        s.Name,
+			Description: s.Description,
+			ToolsetID:   s.ToolsetID,
+			InputSchema: s.InputSchema,
+			Steps:       make([]Step, len(s.Steps)),
+		}
+		for i, step := range s.Steps {
+			entry.Steps[i] = Step{ID: step.ID, ToolID: step.ToolID, Inputs: step.Inputs, DependsOn: s.DependsOn[step.ID]}
+		}
+		cat.Skills = append(cat.Skills, entry)
+	}
+	return cat, nil
+}
+
+func toolEntry(tool model.Tool, backends []model.ToolBackend) (Tool, error) {
+	id := tool.ToolID()
+	input, err := jsonshape.Map(tool.InputSchema)
+	if err != nil {
+		return Tool{}, fmt.Errorf("tool %q input schema: %w", id, err)
+	}
+	output, err := jsonshape.Map(tool.OutputSchema)
+	if err != nil {
+		return Tool{}, fmt.Errorf("tool %q output schema: %w", id, err)
+	}
+	entry := Tool{
+		ID:           id,
+		Name:         tool.Name,
+		Namespace:    tool.Namespace,
+		Version:      tool.Version,
+		Description:  tool.Description,
+		Tags:         tool.Tags,
+		InputSchema:  input,
+		OutputSchema: output,
+	}
+	for _, backend := range backends {
+		entry.Backends = append(entry.Backends, backendName(backend))
+	}
+	sort.Strings(entry.Backends)
+	return entry, nil
+}
+
+func backendName(b model.ToolBackend) string {
+	switch {
+	case b.MCP != nil:
+		return string(b.Kind) + ":" + b.MCP.ServerName
+	case b.Local != nil:
+		return string(b.Kind) + ":" + b.Local.Name
+	case b.Provider != nil:
+		return string(b.Kind) + ":" + b.Provider.ProviderID + "/" + b.Provider.ToolID
+	default:
+		return string(b.Kind)
+	}
+}
+
+// Load reads a catalog written by Write in JSON or YAML format.
+func Load(path string) (Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Catalog{}, fmt.Errorf("read catalog: %w", err)
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".yaml" || ext == ".yml" {
+		// Route YAML through JSON so both formats share the json field names.
+		var raw any
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return Catalog{}, fmt.Errorf("parse catalog %q: %w", path, err)
+		}
+		if data, err = json.Marshal(raw); err != nil {
+			return Catalog{}, fmt.Errorf("parse catalog %q: %w", path, err)
+		}
+	}
+	var cat Catalog
+	if err := json.Unmarshal(data, &cat); err != nil {
+		return Catalog{}, fmt.Errorf("parse catalog %q: %w", path, err)
+	}
+	return cat, nil
+}
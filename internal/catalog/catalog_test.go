@@ -0,0 +1,109 @@
+package catalog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jonwraymond/metatools-mcp/internal/skills"
+	"github.com/jonwraymond/metatools-mcp/internal/toolset"
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolfoundation/model"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCatalog(t *testing.T) Catalog {
+	t.Helper()
+	idx := index.NewInMemoryIndex()
+	err := idx.RegisterTool(model.Tool{
+		Tool: mcp.Tool{
+			Name:        "get_pods",
+			Description: "Get pods in a cluster",
+			InputSchema: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"namespace": map[string]any{"type": "string"}},
+				"required":   []string{"namespace"},
+			},
+		},
+		Namespace: "kubernetes",
+		Tags:      []string{"k8s"},
+	}, model.ToolBackend{Kind: model.BackendKindMCP, MCP: &model.MCPBackend{ServerName: "kube"}})
+	require.NoError(t, err)
+	err = idx.RegisterTool(model.Tool{
+		Tool: mcp.Tool{
+			Name:        "ping",
+			Description: "Ping",
+			InputSchema: map[string]any{"type": "object"},
+		},
+		Namespace: "local",
+	}, model.ToolBackend{Kind: model.BackendKindLocal, Local: &model.LocalBackend{Name: "ping"}})
+	require.NoError(t, err)
+
+	sets, err := toolset.BuildRegistry(idx, []toolset.Spec{{Name: "kube", NamespaceFilters: []string{"kubernetes"}}})
+	require.NoError(t, err)
+	skillRegistry, err := skills.BuildRegistry(sets, []skills.Spec{{
+		Name:      "list pods",
+		ToolsetID: "toolset:kube",
+		Steps:     []skills.StepSpec{{ID: "pods", ToolID: "kubernetes:get_pods", Inputs: map[string]any{"namespace": "default"}}},
+	}})
+	require.NoError(t, err)
+
+	cat, err := Build(idx, sets.List(), skillRegistry.List())
+	require.NoError(t, err)
+	return cat
+}
+
+func TestBuild(t *testing.T) {
+	cat := testCatalog(t)
+
+	require.Len(t, cat.Tools, 2)
+	pods := cat.Tools[0]
+	assert.Equal(t, "kubernetes:get_pods", pods.ID)
+	assert.Equal(t, []string{"mcp:kube"}, pods.Backends)
+	assert.Equal(t, []string{"toolset:kube"}, pods.Toolsets)
+	assert.Equal(t, []any{"namespace"}, pods.InputSchema["required"])
+	assert.Equal(t, []string{"local:ping"}, cat.Tools[1].Backends)
+	assert.Empty(t, cat.Tools[1].Toolsets)
+
+	require.Len(t, cat.Toolsets, 1)
+	assert.Equal(t, []string{"kubernetes:get_pods"}, cat.Toolsets[0].Tools)
+	require.Len(t, cat.Skills, 1)
+	assert.Equal(t, "toolset:kube", cat.Skills[0].ToolsetID)
+	assert.Equal(t, "kubernetes:get_pods", cat.Skills[0].Steps[0].ToolID)
+}
+
+func TestWriteLoadRoundTrip(t *testing.T) {
+	cat := testCatalog(t)
+	for _, format := range []string{FormatJSON, FormatYAML} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, Write(&buf, cat, format))
+			path := filepath.Join(t.TempDir(), "catalog."+format)
+			require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+
+			loaded, err := Load(path)
+			require.NoError(t, err)
+			assert.Equal(t, cat, loaded)
+			assert.True(t, Compare(cat, loaded).Empty())
+		})
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, testCatalog(t), FormatMarkdown))
+
+	out := buf.String()
+	assert.Contains(t, out, "### `kubernetes:get_pods`")
+	assert.Contains(t, out, "- **Backends:** `mcp:kube`")
+	assert.Contains(t, out, "| `toolset:kube` |  | `kubernetes:get_pods` |")
+	assert.Contains(t, out, "1. `pods` → `kubernetes:get_pods`")
+}
+
+func TestWriteUnsupportedFormat(t *testing.T) {
+	err := Write(&bytes.Buffer{}, Catalog{}, "xml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,385 @@
+package catalog
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ChangeKind classifies a schema change.
+type ChangeKind string
+
+// Schema change kinds.
+const (
+	FieldAdded      ChangeKind = "field_added"
+	FieldRemoved    ChangeKind = "field_removed"
+	TypeChanged     ChangeKind = "type_changed"
+	RequiredAdded   ChangeKind = "required_added"
+	RequiredRemoved ChangeKind = "required_removed"
+	EnumChanged     ChangeKind = "enum_changed"
+	SchemaChanged   ChangeKind = "schema_changed"
+)
+
+// Change is a single difference within a tool's schemas.
+type Change struct {
+	Kind ChangeKind `json:"kind"`
+	// Path locates the field, e.g. "input.options.depth" or "output.items[]".
+	Path     string `json:"path"`
+	Breaking bool   `json:"breaking"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+// ToolChange lists the schema changes of a tool present in both catalogs.
+type ToolChange struct {
+	ID       string   `json:"id"`
+	Breaking bool     `json:"breaking"`
+	Changes  []Change `json:"changes"`
+}
+
+// Diff is the difference between two catalogs.
+type Diff struct {
+	Added           []string     `json:"added"`
+	Removed         []string     `json:"removed"`
+	Changed         []ToolChange `json:"changed"`
+	ToolsetsAdded   []string     `json:"toolsetsAdded,omitempty"`
+	ToolsetsRemoved []string     `json:"toolsetsRemoved,omitempty"`
+	SkillsAdded     []string     `json:"skillsAdded,omitempty"`
+	SkillsRemoved   []string     `json:"skillsRemoved,omitempty"`
+}
+
+// Breaking reports whether any tool was removed or changed incompatibly.
+func (d Diff) Breaking() bool {
+	if len(d.Removed) > 0 {
+		return true
+	}
+	for _, c := range d.Changed {
+		if c.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// Empty reports whether the catalogs are equivalent.
+func (d Diff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0 &&
+		len(d.ToolsetsAdded) == 0 && len(d.ToolsetsRemoved) == 0 &&
+		len(d.SkillsAdded) == 0 && len(d.SkillsRemoved) == 0
+}
+
+// Compare reports added, removed and schema-changed tools between old and new.
+//
+// A change is breaking when existing callers may fail against the new
+// catalog: a removed tool, a removed required input or output field, a new
+// required input field, a type change, or a narrowed input enum. Added
+// optional fields and relaxed requirements are not breaking.
+func Compare(old, cur Catalog) Diff {
+	oldTools := make(map[string]Tool, len(old.Tools))
+	for _, t := range old.Tools {
+		oldTools[t.ID] = t
+	}
+	newTools := make(map[string]Tool, len(cur.Tools))
+	for _, t := range cur.Tools {
+		newTools[t.ID] = t
+	}
+
+	d := Diff{Added: []string{}, Removed: []string{}, Changed: []ToolChange{}}
+	for id := range newTools {
+		if _, ok := oldTools[id]; !ok {
+			d.Added = append(d.Added, id)
+		}
+	}
+	for id, oldTool := range oldTools {
+		newTool, ok := newTools[id]
+		if !ok {
+			d.Removed = append(d.Removed, id)
+			continue
+		}
+		var changes []Change
+		changes = compareSchema(changes, "input", oldTool.InputSchema, newTool.InputSchema, true)
+		changes = compareSchema(changes, "output", oldTool.OutputSchema, newTool.OutputSchema, false)
+		if len(changes) == 0 {
+			continue
+		}
+		tc := ToolChange{ID: id, Changes: changes}
+		for _, c := range changes {
+			tc.Breaking = tc.Breaking || c.Breaking
+		}
+		d.Changed = append(d.Changed, tc)
+	}
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	sort.Slice(d.Changed, func(i, j int) bool { return d.Changed[i].ID < d.Changed[j].ID })
+
+	d.ToolsetsAdded, d.ToolsetsRemoved = compareIDs(toolsetIDs(old.Toolsets), toolsetIDs(cur.Toolsets))
+	d.SkillsAdded, d.SkillsRemoved = compareIDs(skillIDs(old.Skills), skillIDs(cur.Skills))
+	return d
+}
+
+// compareSchema appends the differences between two schemas. input selects
+// the compatibility rules for arguments (callers write) versus results
+// (callers read).
+func compareSchema(changes []Change, path string, old, cur map[string]any, input bool) []Change {
+	if reflect.DeepEqual(old, cur) {
+		return changes
+	}
+	before := len(changes)
+
+	oldType, newType := schemaType(old), schemaType(cur)
+	if oldType != "" && newType != "" && oldType != newType {
+		return append(changes, Change{
+			Kind:     TypeChanged,
+			Path:     path,
+			Breaking: true,
+			Detail:   oldType + " -> " + newType,
+		})
+	}
+
+	if input {
+		changes = compareEnum(changes, path, old["enum"], cur["enum"])
+	}
+
+	oldProps, newProps := properties(old), properties(cur)
+	oldReq, newReq := required(old), required(cur)
+	for _, name := range sortedKeys(oldProps) {
+		fieldPath := path + "." + name
+		newProp, ok := newProps[name]
+		if !ok {
+			_, wasRequired := oldReq[name]
+			changes = append(changes, Change{
+				Kind:     FieldRemoved,
+				Path:     fieldPath,
+				Breaking: wasRequired,
+				Detail:   requiredLabel(wasRequired),
+			})
+			continue
+		}
+		_, wasRequired := oldReq[name]
+		_, isRequired := newReq[name]
+		switch {
+		case !wasRequired && isRequired && input:
+			changes = append(changes, Change{Kind: RequiredAdded, Path: fieldPath, Breaking: true})
+		case wasRequired && !isRequired:
+			// Output fields that may now be absent can break readers.
+			changes = append(changes, Change{Kind: RequiredRemoved, Path: fieldPath, Breaking: !input})
+		}
+		changes = compareSchema(changes, fieldPath, asMap(oldProps[name]), asMap(newProp), input)
+	}
+	for _, name := range sortedKeys(newProps) {
+		if _, ok := oldProps[name]; ok {
+			continue
+		}
+		_, isRequired := newReq[name]
+		changes = append(changes, Change{
+			Kind:     FieldAdded,
+			Path:     path + "." + name,
+			Breaking: input && isRequired,
+			Detail:   requiredLabel(isRequired),
+		})
+	}
+
+	changes = compareSchema(changes, path+"[]", asMap(old["items"]), asMap(cur["items"]), input)
+
+	if len(changes) == before {
+		changes = append(changes, Change{Kind: SchemaChanged, Path: path})
+	}
+	return changes
+}
+
+func compareEnum(changes []Change, path string, old, cur any) []Change {
+	oldValues, newValues := enumValues(old), enumValues(cur)
+	if oldValues == nil && newValues == nil {
+		return changes
+	}
+	var removed, added []string
+	for v := range oldValues {
+		if _, ok := newValues[v]; !ok {
+			removed = append(removed, v)
+		}
+	}
+	for v := range newValues {
+		if _, ok := oldValues[v]; !ok {
+			added = append(added, v)
+		}
+	}
+	if len(removed) == 0 && len(added) == 0 {
+		return changes
+	}
+	sort.Strings(removed)
+	sort.Strings(added)
+	var detail []string
+	if len(removed) > 0 {
+		detail = append(detail, "removed "+strings.Join(removed, ", "))
+	}
+	if len(added) > 0 {
+		detail = append(detail, "added "+strings.Join(added, ", "))
+	}
+	// An enum introduced where none existed restricts callers too.
+	narrowed := len(removed) > 0 || (oldValues == nil && newValues != nil)
+	return append(changes, Change{
+		Kind:     EnumChanged,
+		Path:     path,
+		Breaking: narrowed,
+		Detail:   strings.Join(detail, "; "),
+	})
+}
+
+func enumValues(v any) map[string]struct{} {
+	list, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	out := make(map[string]struct{}, len(list))
+	for _, item := range list {
+		out[fmt.Sprint(item)] = struct{}{}
+	}
+	return out
+}
+
+// schemaType returns the declared type, with unions sorted and joined by "|".
+func schemaType(schema map[string]any) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []any:
+		parts := make([]string, 0, len(t))
+		for _, p := range t {
+			parts = append(parts, fmt.Sprint(p))
+		}
+		sort.Strings(parts)
+		return strings.Join(parts, "|")
+	default:
+		return ""
+	}
+}
+
+func properties(schema map[string]any) map[string]any {
+	props, _ := schema["properties"].(map[string]any)
+	return props
+}
+
+func required(schema map[string]any) map[string]struct{} {
+	list, _ := schema["required"].([]any)
+	out := make(map[string]struct{}, len(list))
+	for _, item := range list {
+		if name, ok := item.(string); ok {
+			out[name] = struct{}{}
+		}
+	}
+	return out
+}
+
+func asMap(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	return m
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func requiredLabel(required bool) string {
+	if required {
+		return "required"
+	}
+	return "optional"
+}
+
+func compareIDs(old, cur []string) (added, removed []string) {
+	oldSet := make(map[string]struct{}, len(old))
+	for _, id := range old {
+		oldSet[id] = struct{}{}
+	}
+	newSet := make(map[string]struct{}, len(cur))
+	for _, id := range cur {
+		newSet[id] = struct{}{}
+		if _, ok := oldSet[id]; !ok {
+			added = append(added, id)
+		}
+	}
+	for _, id := range old {
+		if _, ok := newSet[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+func toolsetIDs(sets []Toolset) []string {
+	out := make([]string, len(sets))
+	for i, ts := range sets {
+		out[i] = ts.ID
+	}
+	return out
+}
+
+func skillIDs(list []Skill) []string {
+	out := make([]string, len(list))
+	for i, s := range list {
+		out[i] = s.ID
+	}
+	return out
+}
+
+// WriteDiffText renders a diff for terminals.
+func WriteDiffText(w io.Writer, d Diff) error {
+	var b strings.Builder
+	if d.Empty() {
+		b.WriteString("No changes.\n")
+	}
+	writeIDSection(&b, "Added tools", "+", d.Added, "")
+	writeIDSection(&b, "Removed tools", "-", d.Removed, " [breaking]")
+	if len(d.Changed) > 0 {
+		fmt.Fprintf(&b, "Changed tools (%d):\n", len(d.Changed))
+		for _, tc := range d.Changed {
+			fmt.Fprintf(&b, "  ~ %s%s\n", tc.ID, breakingLabel(tc.Breaking))
+			for _, c := range tc.Changes {
+				line := fmt.Sprintf("      %s %s", c.Kind, c.Path)
+				if c.Detail != "" {
+					line += " (" + c.Detail + ")"
+				}
+				b.WriteString(line + breakingLabel(c.Breaking) + "\n")
+			}
+		}
+	}
+	writeIDSection(&b, "Added toolsets", "+", d.ToolsetsAdded, "")
+	writeIDSection(&b, "Removed toolsets", "-", d.ToolsetsRemoved, "")
+	writeIDSection(&b, "Added skills", "+", d.SkillsAdded, "")
+	writeIDSection(&b, "Removed skills", "-", d.SkillsRemoved, "")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteDiffJSON renders a diff as indented JSON.
+func WriteDiffJSON(w io.Writer, d Diff) error {
+	return writeJSON(w, struct {
+		Diff
+		Breaking bool `json:"breaking"`
+	}{Diff: d, Breaking: d.Breaking()})
+}
+
+func writeIDSection(b *strings.Builder, title, marker string, ids []string, suffix string) {
+	if len(ids) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "%s (%d):\n", title, len(ids))
+	for _, id := range ids {
+		fmt.Fprintf(b, "  %s %s%s\n", marker, id, suffix)
+	}
+}
+
+func breakingLabel(breaking bool) string {
+	if breaking {
+		return " [breaking]"
+	}
+	return ""
+}
@@ -0,0 +1,155 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func schema(t *testing.T, raw string) map[string]any {
+	t.Helper()
+	var out map[string]any
+	require.NoError(t, json.Unmarshal([]byte(raw), &out))
+	return out
+}
+
+func diffInput(t *testing.T, oldSchema, newSchema string) []Change {
+	t.Helper()
+	d := Compare(
+		Catalog{Tools: []Tool{{ID: "ns:tool", InputSchema: schema(t, oldSchema)}}},
+		Catalog{Tools: []Tool{{ID: "ns:tool", InputSchema: schema(t, newSchema)}}},
+	)
+	if len(d.Changed) == 0 {
+		return nil
+	}
+	require.Len(t, d.Changed, 1)
+	return d.Changed[0].Changes
+}
+
+func TestCompare_AddedRemoved(t *testing.T) {
+	d := Compare(
+		Catalog{
+			Tools:    []Tool{{ID: "a:keep"}, {ID: "a:gone"}},
+			Toolsets: []Toolset{{ID: "toolset:old"}},
+		},
+		Catalog{
+			Tools:  []Tool{{ID: "a:keep"}, {ID: "a:new"}},
+			Skills: []Skill{{ID: "skill:new"}},
+		},
+	)
+
+	assert.Equal(t, []string{"a:new"}, d.Added)
+	assert.Equal(t, []string{"a:gone"}, d.Removed)
+	assert.Empty(t, d.Changed)
+	assert.Equal(t, []string{"toolset:old"}, d.ToolsetsRemoved)
+	assert.Equal(t, []string{"skill:new"}, d.SkillsAdded)
+	assert.True(t, d.Breaking())
+}
+
+func TestCompare_InputChanges(t *testing.T) {
+	base := `{"type":"object","properties":{"repo":{"type":"string"},"limit":{"type":"integer"}},"required":["repo"]}`
+
+	tests := []struct {
+		name    string
+		newSpec string
+		want    Change
+	}{
+		{
+			name:    "removed required field",
+			newSpec: `{"type":"object","properties":{"limit":{"type":"integer"}}}`,
+			want:    Change{Kind: FieldRemoved, Path: "input.repo", Breaking: true, Detail: "required"},
+		},
+		{
+			name:    "removed optional field",
+			newSpec: `{"type":"object","properties":{"repo":{"type":"string"}},"required":["repo"]}`,
+			want:    Change{Kind: FieldRemoved, Path: "input.limit", Detail: "optional"},
+		},
+		{
+			name:    "type change",
+			newSpec: `{"type":"object","properties":{"repo":{"type":"string"},"limit":{"type":"string"}},"required":["repo"]}`,
+			want:    Change{Kind: TypeChanged, Path: "input.limit", Breaking: true, Detail: "integer -> string"},
+		},
+		{
+			name:    "field becomes required",
+			newSpec: `{"type":"object","properties":{"repo":{"type":"string"},"limit":{"type":"integer"}},"required":["repo","limit"]}`,
+			want:    Change{Kind: RequiredAdded, Path: "input.limit", Breaking: true},
+		},
+		{
+			name:    "added optional field",
+			newSpec: `{"type":"object","properties":{"repo":{"type":"string"},"limit":{"type":"integer"},"page":{"type":"integer"}},"required":["repo"]}`,
+			want:    Change{Kind: FieldAdded, Path: "input.page", Detail: "optional"},
+		},
+		{
+			name:    "description only",
+			newSpec: `{"type":"object","properties":{"repo":{"type":"string","description":"owner/name"},"limit":{"type":"integer"}},"required":["repo"]}`,
+			want:    Change{Kind: SchemaChanged, Path: "input.repo"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := diffInput(t, base, tt.newSpec)
+			assert.Equal(t, []Change{tt.want}, changes)
+		})
+	}
+}
+
+func TestCompare_NestedAndEnum(t *testing.T) {
+	changes := diffInput(t,
+		`{"type":"object","properties":{"items":{"type":"array","items":{"type":"object","properties":{"mode":{"type":"string","enum":["a","b"]}}}}}}`,
+		`{"type":"object","properties":{"items":{"type":"array","items":{"type":"object","properties":{"mode":{"type":"string","enum":["a","c"]}}}}}}`,
+	)
+	assert.Equal(t, []Change{{
+		Kind:     EnumChanged,
+		Path:     "input.items[].mode",
+		Breaking: true,
+		Detail:   "removed b; added c",
+	}}, changes)
+
+	widened := diffInput(t,
+		`{"type":"string","enum":["a"]}`,
+		`{"type":"string","enum":["a","b"]}`,
+	)
+	assert.Equal(t, []Change{{Kind: EnumChanged, Path: "input", Detail: "added b"}}, widened)
+}
+
+func TestCompare_OutputRules(t *testing.T) {
+	d := Compare(
+		Catalog{Tools: []Tool{{ID: "ns:tool", OutputSchema: schema(t,
+			`{"type":"object","properties":{"id":{"type":"string"},"url":{"type":"string"}},"required":["id","url"]}`)}}},
+		Catalog{Tools: []Tool{{ID: "ns:tool", OutputSchema: schema(t,
+			`{"type":"object","properties":{"url":{"type":"string"},"extra":{"type":"string"}},"required":["url","extra"]}`)}}},
+	)
+	require.Len(t, d.Changed, 1)
+	assert.True(t, d.Changed[0].Breaking)
+	assert.Equal(t, []Change{
+		{Kind: FieldRemoved, Path: "output.id", Breaking: true, Detail: "required"},
+		{Kind: FieldAdded, Path: "output.extra", Detail: "required"},
+	}, d.Changed[0].Changes)
+}
+
+func TestWriteDiff(t *testing.T) {
+	d := Compare(
+		Catalog{Tools: []Tool{{ID: "a:gone"}, {ID: "a:tool", InputSchema: schema(t, `{"type":"string"}`)}}},
+		Catalog{Tools: []Tool{{ID: "a:new"}, {ID: "a:tool", InputSchema: schema(t, `{"type":"integer"}`)}}},
+	)
+
+	var text bytes.Buffer
+	require.NoError(t, WriteDiffText(&text, d))
+	assert.Contains(t, text.String(), "  + a:new\n")
+	assert.Contains(t, text.String(), "  - a:gone [breaking]\n")
+	assert.Contains(t, text.String(), "type_changed input (string -> integer) [breaking]")
+
+	var js bytes.Buffer
+	require.NoError(t, WriteDiffJSON(&js, d))
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(js.Bytes(), &decoded))
+	assert.Equal(t, true, decoded["breaking"])
+	assert.Equal(t, []any{"a:new"}, decoded["added"])
+
+	var empty bytes.Buffer
+	require.NoError(t, WriteDiffText(&empty, Compare(Catalog{}, Catalog{})))
+	assert.Equal(t, "No changes.\n", empty.String())
+}
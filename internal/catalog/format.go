@@ -0,0 +1,168 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jonwraymond/metatools-mcp/internal/skillinput"
+	"gopkg.in/yaml.v3"
+)
+
+// Supported export formats.
+const (
+	FormatJSON     = "json"
+	FormatYAML     = "yaml"
+	FormatMarkdown = "markdown"
+)
+
+// Formats lists the supported export formats.
+var Formats = []string{FormatJSON, FormatYAML, FormatMarkdown}
+
+// Write renders the catalog in the given format.
+func Write(w io.Writer, cat Catalog, format string) error {
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, cat)
+	case FormatYAML:
+		return writeYAML(w, cat)
+	case FormatMarkdown:
+		return writeMarkdown(w, cat)
+	default:
+		return fmt.Errorf("unsupported catalog format %q", format)
+	}
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeYAML reuses the JSON field names and order by decoding the JSON
+// encoding into a YAML node tree.
+func writeYAML(w io.Writer, cat Catalog) error {
+	data, err := json.Marshal(cat)
+	if err != nil {
+		return err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	clearStyle(&node)
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// clearStyle switches JSON flow style to block style. The encoder still quotes
+// strings that would otherwise read as other scalars.
+func clearStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearStyle(child)
+	}
+}
+
+func writeMarkdown(w io.Writer, cat Catalog) error {
+	var b bytes.Buffer
+	b.WriteString("# Tool Catalog\n\n")
+	fmt.Fprintf(&b, "%d tools, %d toolsets, %d skills.\n", len(cat.Tools), len(cat.Toolsets), len(cat.Skills))
+
+	if len(cat.Tools) > 0 {
+		b.WriteString("\n## Tools\n")
+	}
+	for _, tool := range cat.Tools {
+		fmt.Fprintf(&b, "\n### `%s`\n\n", tool.ID)
+		if tool.Description != "" {
+			b.WriteString(tool.Description + "\n\n")
+		}
+		writeField(&b, "Version", tool.Version)
+		writeField(&b, "Tags", codeList(tool.Tags))
+		writeField(&b, "Backends", codeList(tool.Backends))
+		writeField(&b, "Toolsets", codeList(tool.Toolsets))
+		if err := writeSchema(&b, "Input schema", tool.InputSchema); err != nil {
+			return err
+		}
+		if err := writeSchema(&b, "Output schema", tool.OutputSchema); err != nil {
+			return err
+		}
+	}
+
+	if len(cat.Toolsets) > 0 {
+		b.WriteString("\n## Toolsets\n\n")
+		b.WriteString("| ID | Description | Tools |\n|---|---|---|\n")
+		for _, ts := range cat.Toolsets {
+			fmt.Fprintf(&b, "| `%s` | %s | %s |\n", ts.ID, tableCell(ts.Description), codeList(ts.Tools))
+		}
+	}
+
+	if len(cat.Skills) > 0 {
+		b.WriteString("\n## Skills\n")
+	}
+	for _, s := range cat.Skills {
+		fmt.Fprintf(&b, "\n### `%s`\n\n", s.ID)
+		if s.Description != "" {
+			b.WriteString(s.Description + "\n\n")
+		}
+		if s.ToolsetID != "" || len(s.InputSchema) > 0 {
+			if s.ToolsetID != "" {
+				writeField(&b, "Toolset", "`"+s.ToolsetID+"`")
+			}
+			writeField(&b, "Inputs", codeList(skillinput.Declared(s.InputSchema)))
+			b.WriteString("\n")
+		}
+		b.WriteString("Steps:\n\n")
+		for i, step := range s.Steps {
+			fmt.Fprintf(&b, "%d. `%s` → `%s`", i+1, step.ID, step.ToolID)
+			if len(step.DependsOn) > 0 {
+				fmt.Fprintf(&b, " (after `%s`)", strings.Join(step.DependsOn, "`, `"))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+func writeField(b *bytes.Buffer, label, value string) {
+	if value == "" {
+		return
+	}
+	fmt.Fprintf(b, "- **%s:** %s\n", label, value)
+}
+
+func writeSchema(b *bytes.Buffer, label string, schema map[string]any) error {
+	if len(schema) == 0 {
+		return nil
+	}
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(b, "\n%s:\n\n```json\n%s\n```\n", label, data)
+	return nil
+}
+
+func codeList(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = "`" + v + "`"
+	}
+	return strings.Join(out, ", ")
+}
+
+func tableCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
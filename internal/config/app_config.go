@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/jonwraymond/metatools-mcp/internal/middleware"
+	"github.com/jonwraymond/metatools-mcp/internal/skillinput"
 )
 
 // AppConfig holds all metatools-mcp configuration loaded from files/env/flags.
@@ -20,11 +21,19 @@ type AppConfig struct {
 	Backends      BackendsConfig      `koanf:"backends"`
 	Secrets       SecretsConfig       `koanf:"secrets"`
 	State         StateConfig         `koanf:"state"`
+	Approval      ApprovalConfig      `koanf:"approval"`
+	Sampling      SamplingConfig      `koanf:"sampling"`
+	Content       ContentConfig       `koanf:"content"`
+	Results       ResultsConfig       `koanf:"results"`
+	Roots         RootsConfig         `koanf:"roots"`
 	Middleware    middleware.Config   `koanf:"middleware"`
 	Toolsets      []ToolsetConfig     `koanf:"toolsets"`
 	Skills        []SkillConfig       `koanf:"skills"`
+	ToolsetsDir   string              `koanf:"toolsets_dir"`
+	SkillsDir     string              `koanf:"skills_dir"`
 	SkillDefaults SkillDefaultsConfig `koanf:"skill_defaults"`
 	Health        HealthConfig        `koanf:"health"`
+	Docs          DocsConfig          `koanf:"docs"`
 }
 
 // ServerConfig holds server identity settings.
@@ -66,6 +75,10 @@ type AppSearchConfig struct {
 	Strategy string               `koanf:"strategy"`
 	BM25     BM25Config           `koanf:"bm25"`
 	Semantic SemanticSearchConfig `koanf:"semantic"`
+	Query    QueryConfig          `koanf:"query"`
+	// Synonyms maps a term or phrase to equivalents (e.g. "k8s": ["kubernetes"]).
+	// Keys must not contain dots.
+	Synonyms map[string][]string `koanf:"synonyms"`
 }
 
 // BM25Config holds BM25 search settings.
@@ -84,16 +97,119 @@ type SemanticSearchConfig struct {
 	Weight   float64        `koanf:"weight"`
 }
 
+// QueryConfig configures the query-processing stage shared by all search strategies.
+type QueryConfig struct {
+	Enabled          bool        `koanf:"enabled"`
+	SplitIdentifiers bool        `koanf:"split_identifiers"`
+	Stemming         bool        `koanf:"stemming"`
+	Fuzzy            FuzzyConfig `koanf:"fuzzy"`
+}
+
+// FuzzyConfig configures edit-distance typo correction on tool names.
+type FuzzyConfig struct {
+	Enabled       bool `koanf:"enabled"`
+	MaxDistance   int  `koanf:"max_distance"`
+	MinTermLength int  `koanf:"min_term_length"`
+}
+
 // ExecutionConfig holds tool execution settings.
 type ExecutionConfig struct {
 	Timeout       time.Duration `koanf:"timeout"`
 	MaxToolCalls  int           `koanf:"max_tool_calls"`
 	MaxChainSteps int           `koanf:"max_chain_steps"`
+	// MaxParallelSteps bounds how many independent chain or skill steps run
+	// at once when steps declare depends_on.
+	MaxParallelSteps int `koanf:"max_parallel_steps"`
+	// MaxConcurrentJobs bounds how many async run_tool, run_chain and
+	// execute_code calls run at once; further jobs queue.
+	MaxConcurrentJobs int `koanf:"max_concurrent_jobs"`
+	// JobTTL is how long finished async jobs and their results are kept.
+	JobTTL time.Duration `koanf:"job_ttl"`
 }
 
 // StateConfig holds persistent runtime configuration.
 type StateConfig struct {
 	RuntimeLimitsDB string `koanf:"runtime_limits_db"`
+	// RunsDB is the SQLite database for run_chain and run_skill run records.
+	// Empty disables run records.
+	RunsDB string `koanf:"runs_db"`
+	// RunRetention is how long finished run records are kept; zero keeps
+	// them forever.
+	RunRetention time.Duration `koanf:"run_retention"`
+}
+
+// ApprovalConfig gates tool calls behind human approval through MCP
+// elicitation. A tool needs approval when any of the rules match it.
+type ApprovalConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// Destructive gates tools annotated with destructiveHint: true.
+	Destructive bool     `koanf:"destructive"`
+	Tags        []string `koanf:"tags"`
+	Namespaces  []string `koanf:"namespaces"`
+	ToolIDs     []string `koanf:"tool_ids"`
+	// RedactKeys are argument keys hidden from the approval prompt; empty
+	// uses the built-in list.
+	RedactKeys []string `koanf:"redact_keys"`
+}
+
+// SamplingConfig configures the llm:sample tool, which calls the client's
+// model through MCP sampling. Budgets apply per metatool call.
+type SamplingConfig struct {
+	Enabled         bool `koanf:"enabled"`
+	MaxTokens       int  `koanf:"max_tokens"`
+	MaxCallsPerRun  int  `koanf:"max_calls_per_run"`
+	MaxTokensPerRun int  `koanf:"max_tokens_per_run"`
+}
+
+// ContentConfig bounds the image, audio and resource content passed through
+// from tool results, and optionally links large binaries instead of
+// inlining them.
+type ContentConfig struct {
+	MaxBlockBytes int `koanf:"max_block_bytes"`
+	MaxTotalBytes int `koanf:"max_total_bytes"`
+	// LinkLargerThan serves binaries larger than this many bytes as
+	// metatools://content resources; zero keeps them inline.
+	LinkLargerThan int           `koanf:"link_larger_than"`
+	LinkTTL        time.Duration `koanf:"link_ttl"`
+	// LinkQuota caps the bytes of all linked binaries kept at once; binaries
+	// past it are replaced by a note.
+	LinkQuota int `koanf:"link_quota"`
+}
+
+// ResultsConfig limits the size of run_tool results. Results over the limit
+// are cut to a structural preview, and the full result is kept for
+// read_result.
+type ResultsConfig struct {
+	// MaxBytes is the default limit in bytes of JSON; zero disables it.
+	MaxBytes int                `koanf:"max_bytes"`
+	Tools    []ResultToolConfig `koanf:"tools"`
+	// PreviewItems is the most array items a preview keeps.
+	PreviewItems int           `koanf:"preview_items"`
+	TTL          time.Duration `koanf:"ttl"`
+	// SessionQuota caps the bytes of full results kept per session; the
+	// oldest are dropped first.
+	SessionQuota int `koanf:"session_quota"`
+}
+
+// ResultToolConfig sets the result size limit of one tool.
+type ResultToolConfig struct {
+	ToolID   string `koanf:"tool_id"`
+	MaxBytes int    `koanf:"max_bytes"`
+}
+
+// RootsConfig controls the filesystem roots clients share. Fetched roots
+// reach tools on the request context.
+type RootsConfig struct {
+	// Enabled fetches each client's roots.
+	Enabled bool `koanf:"enabled"`
+	// Enforce rejects calls of tools carrying any of Tags whose arguments
+	// name paths outside the caller's roots.
+	Enforce bool     `koanf:"enforce"`
+	Tags    []string `koanf:"tags"`
+	// ShareWithBackends offers each caller's roots to MCP backends. Every
+	// session shares a backend's connection, so a backend can see another
+	// session's roots.
+	ShareWithBackends bool `koanf:"share_with_backends"`
 }
 
 // SecretsConfig configures secret providers and resolution behavior.
@@ -124,6 +240,22 @@ type ProvidersConfig struct {
 	DescribeSkill    ProviderEnabled   `koanf:"describe_skill"`
 	PlanSkill        ProviderEnabled   `koanf:"plan_skill"`
 	RunSkill         ProviderEnabled   `koanf:"run_skill"`
+	// SkillTools publishes each skill as its own MCP tool.
+	SkillTools ProviderEnabled `koanf:"skill_tools"`
+	// GetRun, ResumeRun and CancelRun manage run records; they require
+	// state.runs_db.
+	GetRun    ProviderEnabled `koanf:"get_run"`
+	ResumeRun ProviderEnabled `koanf:"resume_run"`
+	CancelRun ProviderEnabled `koanf:"cancel_run"`
+	// GetJob, ListJobs and CancelJob manage async jobs.
+	GetJob    ProviderEnabled `koanf:"get_job"`
+	ListJobs  ProviderEnabled `koanf:"list_jobs"`
+	CancelJob ProviderEnabled `koanf:"cancel_job"`
+	// ActivateToolset and DeactivateToolset narrow a session to chosen
+	// toolsets.
+	ActivateToolset   ProviderEnabled `koanf:"activate_toolset"`
+	DeactivateToolset ProviderEnabled `koanf:"deactivate_toolset"`
+	ReadResult        ProviderEnabled `koanf:"read_result"`
 }
 
 // ProviderEnabled is a simple on/off provider config.
@@ -151,9 +283,21 @@ type ToolsetConfig struct {
 
 // SkillStepConfig defines a skill step.
 type SkillStepConfig struct {
-	ID     string         `koanf:"id"`
-	ToolID string         `koanf:"tool_id"`
-	Inputs map[string]any `koanf:"inputs"`
+	ID        string              `koanf:"id"`
+	ToolID    string              `koanf:"tool_id"`
+	Inputs    map[string]any      `koanf:"inputs"`
+	DependsOn []string            `koanf:"depends_on"`
+	OnError   *SkillOnErrorConfig `koanf:"on_error"`
+}
+
+// SkillOnErrorConfig defines how a failing skill step is handled.
+type SkillOnErrorConfig struct {
+	// Action is one of fail (default), continue, retry or fallback.
+	Action   string         `koanf:"action"`
+	Attempts int            `koanf:"attempts"`
+	Backoff  time.Duration  `koanf:"backoff"`
+	ToolID   string         `koanf:"tool_id"`
+	Args     map[string]any `koanf:"args"`
 }
 
 // SkillGuardsConfig defines skill guard settings.
@@ -164,11 +308,33 @@ type SkillGuardsConfig struct {
 
 // SkillConfig defines a skill.
 type SkillConfig struct {
-	Name        string            `koanf:"name"`
-	Description string            `koanf:"description"`
-	ToolsetID   string            `koanf:"toolset_id"`
+	Name        string `koanf:"name"`
+	Description string `koanf:"description"`
+	ToolsetID   string `koanf:"toolset_id"`
+	// InputSchema is a JSON Schema object whose properties may be referenced
+	// from step inputs as {{ inputs.<name> }}.
+	InputSchema map[string]any    `koanf:"input_schema"`
 	Steps       []SkillStepConfig `koanf:"steps"`
-	Guards      SkillGuardsConfig `koanf:"guards"`
+	// Output builds the run's final object from step results with step
+	// references; OutputSchema validates it after execution.
+	Output       map[string]any    `koanf:"output"`
+	OutputSchema map[string]any    `koanf:"output_schema"`
+	Guards       SkillGuardsConfig `koanf:"guards"`
+}
+
+// validate checks that every {{ inputs.<name> }} template is declared.
+func (s SkillConfig) validate() error {
+	templated := []any{s.Output}
+	for _, step := range s.Steps {
+		templated = append(templated, step.Inputs)
+		if step.OnError != nil {
+			templated = append(templated, step.OnError.Args)
+		}
+	}
+	if err := skillinput.CheckSchema(s.InputSchema, templated...); err != nil {
+		return fmt.Errorf("skill %q: %w", s.Name, err)
+	}
+	return nil
 }
 
 // SkillDefaultsConfig defines default skill limits.
@@ -184,6 +350,14 @@ type HealthConfig struct {
 	Path    string `koanf:"http_path"`
 }
 
+// DocsConfig configures authored tool documentation loaded from files.
+type DocsConfig struct {
+	// Dir holds per-tool YAML/Markdown docs keyed by tool ID or glob.
+	Dir           string        `koanf:"dir"`
+	Watch         bool          `koanf:"watch"`
+	WatchInterval time.Duration `koanf:"watch_interval"`
+}
+
 // BackendsConfig holds backend source settings.
 type BackendsConfig struct {
 	Local LocalBackendConfig `koanf:"local"`
@@ -261,27 +435,51 @@ func DefaultAppConfig() AppConfig {
 				Config:   map[string]any{},
 				Weight:   0.5,
 			},
+			Query: QueryConfig{
+				Enabled:          false,
+				SplitIdentifiers: true,
+				Stemming:         true,
+				Fuzzy: FuzzyConfig{
+					Enabled:       true,
+					MaxDistance:   1,
+					MinTermLength: 4,
+				},
+			},
+			Synonyms: nil,
 		},
 		Execution: ExecutionConfig{
-			Timeout:       30 * time.Second,
-			MaxToolCalls:  64,
-			MaxChainSteps: 8,
+			Timeout:           30 * time.Second,
+			MaxToolCalls:      64,
+			MaxChainSteps:     8,
+			MaxParallelSteps:  4,
+			MaxConcurrentJobs: 4,
+			JobTTL:            time.Hour,
 		},
 		Providers: ProvidersConfig{
-			SearchTools:      ProviderEnabled{Enabled: true},
-			ListTools:        ProviderEnabled{Enabled: true},
-			ListNamespaces:   ProviderEnabled{Enabled: true},
-			DescribeTool:     ProviderEnabled{Enabled: true},
-			ListToolExamples: ProviderEnabled{Enabled: true},
-			RunTool:          ProviderEnabled{Enabled: true},
-			RunChain:         ProviderEnabled{Enabled: true},
-			ExecuteCode:      ExecuteCodeConfig{Enabled: false, Sandbox: "dev"},
-			ListToolsets:     ProviderEnabled{Enabled: true},
-			DescribeToolset:  ProviderEnabled{Enabled: true},
-			ListSkills:       ProviderEnabled{Enabled: true},
-			DescribeSkill:    ProviderEnabled{Enabled: true},
-			PlanSkill:        ProviderEnabled{Enabled: true},
-			RunSkill:         ProviderEnabled{Enabled: true},
+			SearchTools:       ProviderEnabled{Enabled: true},
+			ListTools:         ProviderEnabled{Enabled: true},
+			ListNamespaces:    ProviderEnabled{Enabled: true},
+			DescribeTool:      ProviderEnabled{Enabled: true},
+			ListToolExamples:  ProviderEnabled{Enabled: true},
+			RunTool:           ProviderEnabled{Enabled: true},
+			RunChain:          ProviderEnabled{Enabled: true},
+			ExecuteCode:       ExecuteCodeConfig{Enabled: false, Sandbox: "dev"},
+			ListToolsets:      ProviderEnabled{Enabled: true},
+			DescribeToolset:   ProviderEnabled{Enabled: true},
+			ListSkills:        ProviderEnabled{Enabled: true},
+			DescribeSkill:     ProviderEnabled{Enabled: true},
+			PlanSkill:         ProviderEnabled{Enabled: true},
+			RunSkill:          ProviderEnabled{Enabled: true},
+			SkillTools:        ProviderEnabled{Enabled: false},
+			GetRun:            ProviderEnabled{Enabled: false},
+			ResumeRun:         ProviderEnabled{Enabled: false},
+			CancelRun:         ProviderEnabled{Enabled: false},
+			GetJob:            ProviderEnabled{Enabled: true},
+			ListJobs:          ProviderEnabled{Enabled: true},
+			CancelJob:         ProviderEnabled{Enabled: true},
+			ActivateToolset:   ProviderEnabled{Enabled: true},
+			DeactivateToolset: ProviderEnabled{Enabled: true},
+			ReadResult:        ProviderEnabled{Enabled: true},
 		},
 		Backends: BackendsConfig{
 			Local: LocalBackendConfig{
@@ -303,6 +501,35 @@ func DefaultAppConfig() AppConfig {
 		},
 		State: StateConfig{
 			RuntimeLimitsDB: "",
+			RunsDB:          "",
+			RunRetention:    7 * 24 * time.Hour,
+		},
+		Approval: ApprovalConfig{
+			Enabled:     false,
+			Destructive: true,
+		},
+		Sampling: SamplingConfig{
+			Enabled:         true,
+			MaxTokens:       1024,
+			MaxCallsPerRun:  10,
+			MaxTokensPerRun: 8192,
+		},
+		Content: ContentConfig{
+			MaxBlockBytes: 1 << 20,
+			MaxTotalBytes: 4 << 20,
+			LinkTTL:       time.Hour,
+			LinkQuota:     64 << 20,
+		},
+		Results: ResultsConfig{
+			MaxBytes:     64 << 10,
+			PreviewItems: 10,
+			TTL:          time.Hour,
+			SessionQuota: 64 << 20,
+		},
+		Roots: RootsConfig{
+			Enabled: true,
+			Enforce: false,
+			Tags:    []string{"fs"},
 		},
 		Middleware: middleware.Config{},
 		SkillDefaults: SkillDefaultsConfig{
@@ -314,6 +541,10 @@ func DefaultAppConfig() AppConfig {
 			Enabled: false,
 			Path:    "/healthz",
 		},
+		Docs: DocsConfig{
+			Watch:         false,
+			WatchInterval: 2 * time.Second,
+		},
 	}
 }
 
@@ -333,6 +564,17 @@ func (c *AppConfig) Validate() error {
 		return fmt.Errorf("invalid search strategy %q, must be one of: bm25, lexical, semantic, hybrid", c.Search.Strategy)
 	}
 
+	if c.Search.Query.Fuzzy.MaxDistance < 0 || c.Search.Query.Fuzzy.MaxDistance > 3 {
+		return fmt.Errorf("invalid search fuzzy max_distance %d, must be 0-3", c.Search.Query.Fuzzy.MaxDistance)
+	}
+	if c.Search.Query.Fuzzy.MinTermLength < 0 {
+		return errors.New("search fuzzy min_term_length cannot be negative")
+	}
+
+	if c.Docs.WatchInterval < 0 {
+		return errors.New("docs watch_interval cannot be negative")
+	}
+
 	if c.Execution.Timeout < 0 {
 		return errors.New("execution timeout cannot be negative")
 	}
@@ -342,6 +584,49 @@ func (c *AppConfig) Validate() error {
 	if c.Execution.MaxChainSteps < 0 {
 		return errors.New("execution max chain steps cannot be negative")
 	}
+	if c.Execution.MaxParallelSteps < 0 {
+		return errors.New("execution max parallel steps cannot be negative")
+	}
+	if c.Execution.MaxConcurrentJobs < 0 {
+		return errors.New("execution max concurrent jobs cannot be negative")
+	}
+	if c.Execution.JobTTL < 0 {
+		return errors.New("execution job ttl cannot be negative")
+	}
+
+	if c.State.RunRetention < 0 {
+		return errors.New("state run_retention cannot be negative")
+	}
+	if c.State.RunsDB == "" && (c.Providers.GetRun.Enabled || c.Providers.ResumeRun.Enabled || c.Providers.CancelRun.Enabled) {
+		return errors.New("providers get_run, resume_run and cancel_run require state.runs_db")
+	}
+
+	if c.Sampling.MaxTokens < 0 || c.Sampling.MaxCallsPerRun < 0 || c.Sampling.MaxTokensPerRun < 0 {
+		return errors.New("sampling limits cannot be negative")
+	}
+
+	if c.Content.MaxBlockBytes < 0 || c.Content.MaxTotalBytes < 0 || c.Content.LinkLargerThan < 0 || c.Content.LinkTTL < 0 || c.Content.LinkQuota < 0 {
+		return errors.New("content limits cannot be negative")
+	}
+
+	if c.Results.MaxBytes < 0 || c.Results.PreviewItems < 0 || c.Results.TTL < 0 || c.Results.SessionQuota < 0 {
+		return errors.New("results limits cannot be negative")
+	}
+	for _, tool := range c.Results.Tools {
+		if tool.ToolID == "" || tool.MaxBytes <= 0 {
+			return errors.New("results tools need a tool_id and a positive max_bytes")
+		}
+	}
+
+	if c.Roots.Enforce && !c.Roots.Enabled {
+		return errors.New("roots enforce requires roots enabled")
+	}
+	if c.Roots.Enforce && len(c.Roots.Tags) == 0 {
+		return errors.New("roots enforce requires at least one tag")
+	}
+	if c.Roots.ShareWithBackends && !c.Roots.Enabled {
+		return errors.New("roots share_with_backends requires roots enabled")
+	}
 
 	if c.SkillDefaults.MaxSteps < 0 {
 		return errors.New("skill defaults max steps cannot be negative")
@@ -353,6 +638,12 @@ func (c *AppConfig) Validate() error {
 		return errors.New("skill defaults timeout cannot be negative")
 	}
 
+	for _, sk := range c.Skills {
+		if err := sk.validate(); err != nil {
+			return err
+		}
+	}
+
 	if c.Backends.MCPRefresh.Interval < 0 {
 		return errors.New("mcp refresh interval cannot be negative")
 	}
@@ -393,5 +684,12 @@ func (c AppSearchConfig) ToSearchConfig() SearchConfig {
 		SemanticEmbedder:   c.Semantic.Embedder,
 		SemanticConfig:     c.Semantic.Config,
 		SemanticWeight:     c.Semantic.Weight,
+		QueryEnabled:       c.Query.Enabled,
+		QuerySplitIdents:   c.Query.SplitIdentifiers,
+		QueryStemming:      c.Query.Stemming,
+		FuzzyEnabled:       c.Query.Fuzzy.Enabled,
+		FuzzyMaxDistance:   c.Query.Fuzzy.MaxDistance,
+		FuzzyMinTermLength: c.Query.Fuzzy.MinTermLength,
+		Synonyms:           c.Synonyms,
 	}
 }
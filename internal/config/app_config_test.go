@@ -40,6 +40,95 @@ func TestAppConfig_ValidateTransport(t *testing.T) {
 	}
 }
 
+func TestAppConfig_ValidateRunState(t *testing.T) {
+	cfg := DefaultAppConfig()
+	cfg.Providers.ResumeRun.Enabled = true
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("Validate() should fail for resume_run without state.runs_db")
+	}
+	cfg.State.RunsDB = "runs.db"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	cfg.State.RunRetention = -time.Hour
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("Validate() should fail for negative run_retention")
+	}
+}
+
+func TestAppConfig_ValidateSampling(t *testing.T) {
+	cfg := DefaultAppConfig()
+	if !cfg.Sampling.Enabled {
+		t.Errorf("Sampling.Enabled = false, want true")
+	}
+	cfg.Sampling.MaxCallsPerRun = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("Validate() should fail for negative sampling max_calls_per_run")
+	}
+}
+
+func TestAppConfig_ValidateContent(t *testing.T) {
+	cfg := DefaultAppConfig()
+	if cfg.Content.LinkLargerThan != 0 {
+		t.Errorf("Content.LinkLargerThan = %d, want 0", cfg.Content.LinkLargerThan)
+	}
+	cfg.Content.MaxTotalBytes = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("Validate() should fail for negative content max_total_bytes")
+	}
+}
+
+func TestAppConfig_ValidateResults(t *testing.T) {
+	cfg := DefaultAppConfig()
+	if cfg.Results.MaxBytes != 64<<10 {
+		t.Errorf("Results.MaxBytes = %d, want %d", cfg.Results.MaxBytes, 64<<10)
+	}
+	cfg.Results.SessionQuota = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("Validate() should fail for negative results session_quota")
+	}
+
+	cfg = DefaultAppConfig()
+	cfg.Results.Tools = []ResultToolConfig{{ToolID: "db:query"}}
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("Validate() should fail for a results tool without max_bytes")
+	}
+}
+
+func TestAppConfig_ValidateRoots(t *testing.T) {
+	cfg := DefaultAppConfig()
+	if !cfg.Roots.Enabled || cfg.Roots.Enforce {
+		t.Errorf("Roots = %+v, want fetched and not enforced by default", cfg.Roots)
+	}
+	cfg.Roots.Enforce = true
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	cfg.Roots.Enabled = false
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("Validate() should fail for enforced roots that are not fetched")
+	}
+
+	cfg = DefaultAppConfig()
+	cfg.Roots.Enforce = true
+	cfg.Roots.Tags = nil
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("Validate() should fail for enforced roots without tags")
+	}
+
+	cfg = DefaultAppConfig()
+	if cfg.Roots.ShareWithBackends {
+		t.Errorf("Roots.ShareWithBackends = true, want off by default")
+	}
+	cfg.Roots.ShareWithBackends = true
+	cfg.Roots.Enabled = false
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("Validate() should fail for shared roots that are not fetched")
+	}
+}
+
 func TestAppConfig_ValidateSearchStrategy(t *testing.T) {
 	cfg := DefaultAppConfig()
 	cfg.Search.Strategy = "invalid"
@@ -48,6 +137,37 @@ func TestAppConfig_ValidateSearchStrategy(t *testing.T) {
 	}
 }
 
+func TestAppConfig_ValidateSearchQuery(t *testing.T) {
+	cfg := DefaultAppConfig()
+	cfg.Search.Query.Fuzzy.MaxDistance = 4
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("Validate() should fail for fuzzy max_distance above 3")
+	}
+
+	cfg = DefaultAppConfig()
+	cfg.Search.Query.Fuzzy.MinTermLength = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("Validate() should fail for negative fuzzy min_term_length")
+	}
+}
+
+func TestAppSearchConfig_ToSearchConfigQuery(t *testing.T) {
+	cfg := DefaultAppConfig()
+	cfg.Search.Query.Enabled = true
+	cfg.Search.Synonyms = map[string][]string{"k8s": {"kubernetes"}}
+
+	sc := cfg.Search.ToSearchConfig()
+	if !sc.QueryEnabled || !sc.QuerySplitIdents || !sc.QueryStemming || !sc.FuzzyEnabled {
+		t.Errorf("query flags not propagated: %+v", sc)
+	}
+	if sc.FuzzyMaxDistance != 1 || sc.FuzzyMinTermLength != 4 {
+		t.Errorf("fuzzy settings = (%d, %d), want (1, 4)", sc.FuzzyMaxDistance, sc.FuzzyMinTermLength)
+	}
+	if got := sc.Synonyms["k8s"]; len(got) != 1 || got[0] != "kubernetes" {
+		t.Errorf("Synonyms[k8s] = %v, want [kubernetes]", got)
+	}
+}
+
 func TestAppConfig_ValidateExecutionLimits(t *testing.T) {
 	cfg := DefaultAppConfig()
 	cfg.Execution.MaxToolCalls = -1
@@ -60,6 +180,12 @@ func TestAppConfig_ValidateExecutionLimits(t *testing.T) {
 	if err := cfg.Validate(); err == nil {
 		t.Fatalf("Validate() should fail for negative max chain steps")
 	}
+
+	cfg = DefaultAppConfig()
+	cfg.Execution.MaxParallelSteps = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("Validate() should fail for negative max parallel steps")
+	}
 }
 
 func TestAppConfig_ValidateMCPBackends(t *testing.T) {
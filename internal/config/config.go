@@ -1,11 +1,19 @@
 package config
 
 import (
+	"context"
 	"errors"
+	"time"
 
+	"github.com/jonwraymond/metatools-mcp/internal/approval"
+	"github.com/jonwraymond/metatools-mcp/internal/content"
 	"github.com/jonwraymond/metatools-mcp/internal/handlers"
+	"github.com/jonwraymond/metatools-mcp/internal/jobs"
 	"github.com/jonwraymond/metatools-mcp/internal/middleware"
 	"github.com/jonwraymond/metatools-mcp/internal/provider"
+	"github.com/jonwraymond/metatools-mcp/internal/results"
+	"github.com/jonwraymond/metatools-mcp/internal/roots"
+	"github.com/jonwraymond/metatools-mcp/internal/state/runs"
 )
 
 // Config holds the server configuration with injected dependencies
@@ -21,6 +29,40 @@ type Config struct {
 
 	Refresher handlers.Refresher // optional backend refresher
 
+	// Runs stores run_chain and run_skill run records. When nil, runs are
+	// not recorded and get_run, resume_run and cancel_run are unavailable.
+	Runs         runs.Store
+	RunRetention time.Duration
+
+	// Jobs configures the worker pool for async run_tool, run_chain and
+	// execute_code calls.
+	Jobs jobs.Options
+
+	// Results limits the size of run_tool results and keeps oversized
+	// results for read_result.
+	Results results.Options
+
+	// ContentStore, when set, serves the binaries the runner's content
+	// collector moved out of tool results as MCP resources.
+	ContentStore *content.Store
+
+	// Approval, when set, is the gate wrapped around Runner. The server
+	// gives it the calling session so it can ask the client for approval.
+	Approval *approval.Gate
+
+	// Roots, when set, tracks the roots of each client session. Tool calls
+	// carry the caller's roots on their context.
+	Roots *roots.Tracker
+
+	// Watchers run in the background for the lifetime of the server,
+	// e.g. to reload file-based configuration.
+	Watchers []Watcher
+
+	// Reloaders report changes outside the index that alter what clients
+	// see, e.g. rebuilt toolsets and skills. Each reload triggers a
+	// tools/list_changed notification when notifications are enabled.
+	Reloaders []ReloadNotifier
+
 	Providers        ProvidersConfig
 	ProviderRegistry *provider.Registry // optional override
 	Middleware       middleware.Config
@@ -29,6 +71,16 @@ type Config struct {
 	NotifyToolListChangedDebounceMs int
 }
 
+// Watcher is a background task that runs until its context is cancelled.
+type Watcher interface {
+	Watch(ctx context.Context)
+}
+
+// ReloadNotifier calls registered functions after a reload.
+type ReloadNotifier interface {
+	OnReload(fn func()) (unsubscribe func())
+}
+
 // Validate checks that required dependencies are provided
 func (c *Config) Validate() error {
 	if c.Index == nil {
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/rawbytes"
+	"github.com/knadh/koanf/v2"
+)
+
+// LoadToolsetsDir reads one toolset definition per YAML file in dir, in file
+// name order. Each file has the shape of a toolsets entry.
+func LoadToolsetsDir(dir string) ([]ToolsetConfig, error) {
+	files, err := DefinitionFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ToolsetConfig, 0, len(files))
+	for _, path := range files {
+		var ts ToolsetConfig
+		if err := decodeFile(path, &ts); err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(ts.Name) == "" {
+			return nil, fmt.Errorf("toolset file %q: name is required", path)
+		}
+		out = append(out, ts)
+	}
+	return out, nil
+}
+
+// LoadSkillsDir reads one skill definition per YAML file in dir, in file name
+// order. Each file has the shape of a skills entry.
+func LoadSkillsDir(dir string) ([]SkillConfig, error) {
+	files, err := DefinitionFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SkillConfig, 0, len(files))
+	for _, path := range files {
+		var sk SkillConfig
+		if err := decodeFile(path, &sk); err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(sk.Name) == "" {
+			return nil, fmt.Errorf("skill file %q: name is required", path)
+		}
+		if err := sk.validate(); err != nil {
+			return nil, fmt.Errorf("skill file %q: %w", path, err)
+		}
+		out = append(out, sk)
+	}
+	return out, nil
+}
+
+// DefinitionFiles lists the .yaml and .yml files directly inside dir, sorted.
+func DefinitionFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read definitions dir: %w", err)
+	}
+	var out []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yaml", ".yml":
+			out = append(out, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func decodeFile(path string, out any) error {
+	// #nosec G304 -- definition dirs are configured by the operator.
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read file %q: %w", path, err)
+	}
+	expanded, err := expandEnv(b)
+	if err != nil {
+		return fmt.Errorf("expand env in file %q: %w", path, err)
+	}
+	k := koanf.New(".")
+	if err := k.Load(rawbytes.Provider(expanded), yaml.Parser()); err != nil {
+		return fmt.Errorf("load file %q: %w", path, err)
+	}
+	if err := k.UnmarshalWithConf("", out, unmarshalConf(out)); err != nil {
+		return fmt.Errorf("unmarshal file %q: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+}
+
+func TestLoadSkillsDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"b-triage.yaml": `
+name: triage
+input_schema:
+  type: object
+  properties:
+    repo: {type: string}
+steps:
+  - id: issues
+    tool_id: gh:issues
+    inputs:
+      repo: "{{ inputs.repo }}"
+    on_error:
+      action: retry
+      backoff: 50ms
+`,
+		"a-ping.yml": `
+name: ping
+steps:
+  - id: ping
+    tool_id: local:ping
+`,
+		"README.md": "not a skill",
+	})
+
+	got, err := LoadSkillsDir(dir)
+	if err != nil {
+		t.Fatalf("LoadSkillsDir() error = %v", err)
+	}
+	if len(got) != 2 || got[0].Name != "ping" || got[1].Name != "triage" {
+		t.Fatalf("LoadSkillsDir() = %+v, want ping then triage", got)
+	}
+	if got[1].Steps[0].OnError.Backoff != 50*time.Millisecond {
+		t.Errorf("Backoff = %v, want 50ms", got[1].Steps[0].OnError.Backoff)
+	}
+}
+
+func TestLoadSkillsDir_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"no name":    "steps: []\n",
+		"undeclared": "name: x\nsteps:\n  - id: a\n    tool_id: t\n    inputs:\n      q: \"{{ inputs.q }}\"\n",
+		"bad yaml":   "name: [\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{"skill.yaml": content})
+			_, err := LoadSkillsDir(dir)
+			if err == nil || !strings.Contains(err.Error(), "skill.yaml") {
+				t.Fatalf("LoadSkillsDir() error = %v, want error naming the file", err)
+			}
+		})
+	}
+}
+
+func TestLoadToolsetsDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"core.yaml": "name: core\nnamespace_filters: [local]\npolicy: allow_all\n",
+	})
+	got, err := LoadToolsetsDir(dir)
+	if err != nil {
+		t.Fatalf("LoadToolsetsDir() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Name != "core" || got[0].NamespaceFilters[0] != "local" {
+		t.Errorf("LoadToolsetsDir() = %+v", got)
+	}
+
+	if _, err := LoadToolsetsDir(filepath.Join(dir, "missing")); err == nil {
+		t.Error("LoadToolsetsDir() on a missing dir should fail")
+	}
+}
@@ -26,6 +26,13 @@ type SearchConfig struct {
 	SemanticEmbedder   string  `env:"SEMANTIC_EMBEDDER" envDefault:""`
 	SemanticWeight     float64 `env:"SEMANTIC_WEIGHT" envDefault:"0.5"`
 	SemanticConfig     map[string]any
+	QueryEnabled       bool `env:"QUERY_ENABLED" envDefault:"false"`
+	QuerySplitIdents   bool `env:"QUERY_SPLIT_IDENTIFIERS" envDefault:"true"`
+	QueryStemming      bool `env:"QUERY_STEMMING" envDefault:"true"`
+	FuzzyEnabled       bool `env:"QUERY_FUZZY_ENABLED" envDefault:"true"`
+	FuzzyMaxDistance   int  `env:"QUERY_FUZZY_MAX_DISTANCE" envDefault:"1"`
+	FuzzyMinTermLength int  `env:"QUERY_FUZZY_MIN_TERM_LENGTH" envDefault:"4"`
+	Synonyms           map[string][]string
 }
 
 // validStrategies defines the allowed search strategies
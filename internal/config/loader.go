@@ -15,7 +15,10 @@ import (
 	"github.com/knadh/koanf/v2"
 )
 
-var envVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+var (
+	envVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+	envVarName    = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
 
 // Load reads configuration from defaults, optional file, and environment variables.
 // Precedence: defaults < file < env.
@@ -67,16 +70,7 @@ func loadConfig(path string, overrides map[string]any) (AppConfig, error) {
 	}
 
 	var cfg AppConfig
-	if err := k.UnmarshalWithConf("", &cfg, koanf.UnmarshalConf{
-		Tag: "koanf",
-		DecoderConfig: &mapstructure.DecoderConfig{
-			DecodeHook: mapstructure.ComposeDecodeHookFunc(
-				mapstructure.StringToTimeDurationHookFunc(),
-			),
-			WeaklyTypedInput: true,
-			Result:           &cfg,
-		},
-	}); err != nil {
+	if err := k.UnmarshalWithConf("", &cfg, unmarshalConf(&cfg)); err != nil {
 		return AppConfig{}, fmt.Errorf("unmarshal config: %w", err)
 	}
 
@@ -87,6 +81,21 @@ func loadConfig(path string, overrides map[string]any) (AppConfig, error) {
 	return cfg, nil
 }
 
+// unmarshalConf decodes koanf tags, durations from strings and weakly typed
+// scalars into out.
+func unmarshalConf(out any) koanf.UnmarshalConf {
+	return koanf.UnmarshalConf{
+		Tag: "koanf",
+		DecoderConfig: &mapstructure.DecoderConfig{
+			DecodeHook: mapstructure.ComposeDecodeHookFunc(
+				mapstructure.StringToTimeDurationHookFunc(),
+			),
+			WeaklyTypedInput: true,
+			Result:           out,
+		},
+	}
+}
+
 func expandEnv(b []byte) ([]byte, error) {
 	// Allow writing literal "$" without triggering substitution.
 	const dollarSentinel = "\x00METATOOLS_DOLLAR\x00"
@@ -110,7 +119,14 @@ func expandEnv(b []byte) ([]byte, error) {
 		return nil, fmt.Errorf("missing required environment variables: %s", strings.Join(keys, ", "))
 	}
 
-	s = os.ExpandEnv(s)
+	// Only ${NAME} with a valid variable name is substituted, so skill step
+	// references such as ${steps.search.result} pass through.
+	s = os.Expand(s, func(key string) string {
+		if !envVarName.MatchString(key) {
+			return "${" + key + "}"
+		}
+		return os.Getenv(key)
+	})
 	s = strings.ReplaceAll(s, dollarSentinel, "$")
 	return []byte(s), nil
 }
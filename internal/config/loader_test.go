@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -33,6 +34,10 @@ search:
   strategy: bm25
   bm25:
     name_boost: 5
+  query:
+    enabled: true
+  synonyms:
+    pr: ["pull request", "merge request"]
 execution:
   timeout: 60s
 `
@@ -57,11 +62,291 @@ execution:
 	if cfg.Search.BM25.NameBoost != 5 {
 		t.Errorf("Search.BM25.NameBoost = %d, want %d", cfg.Search.BM25.NameBoost, 5)
 	}
+	if !cfg.Search.Query.Enabled {
+		t.Errorf("Search.Query.Enabled = false, want true")
+	}
+	if got := cfg.Search.Synonyms["pr"]; len(got) != 2 || got[1] != "merge request" {
+		t.Errorf("Search.Synonyms[pr] = %v, want [pull request merge request]", got)
+	}
 	if cfg.Execution.Timeout != 60*time.Second {
 		t.Errorf("Execution.Timeout = %v, want %v", cfg.Execution.Timeout, 60*time.Second)
 	}
 }
 
+func TestLoad_Approval(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "metatools.yaml")
+
+	yaml := `
+approval:
+  enabled: true
+  tags: [dangerous]
+  tool_ids: ["fs:delete"]
+`
+	if err := os.WriteFile(configPath, []byte(yaml), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if !cfg.Approval.Enabled {
+		t.Errorf("Approval.Enabled = false, want true")
+	}
+	if !cfg.Approval.Destructive {
+		t.Errorf("Approval.Destructive = false, want default true")
+	}
+	if len(cfg.Approval.Tags) != 1 || cfg.Approval.Tags[0] != "dangerous" {
+		t.Errorf("Approval.Tags = %v, want [dangerous]", cfg.Approval.Tags)
+	}
+	if len(cfg.Approval.ToolIDs) != 1 || cfg.Approval.ToolIDs[0] != "fs:delete" {
+		t.Errorf("Approval.ToolIDs = %v, want [fs:delete]", cfg.Approval.ToolIDs)
+	}
+}
+
+func TestLoad_Content(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "metatools.yaml")
+
+	yaml := `
+content:
+  link_larger_than: 65536
+  link_ttl: 10m
+`
+	if err := os.WriteFile(configPath, []byte(yaml), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.Content.LinkLargerThan != 65536 {
+		t.Errorf("Content.LinkLargerThan = %d, want 65536", cfg.Content.LinkLargerThan)
+	}
+	if cfg.Content.LinkTTL != 10*time.Minute {
+		t.Errorf("Content.LinkTTL = %v, want %v", cfg.Content.LinkTTL, 10*time.Minute)
+	}
+	if cfg.Content.MaxBlockBytes != 1<<20 {
+		t.Errorf("Content.MaxBlockBytes = %d, want default %d", cfg.Content.MaxBlockBytes, 1<<20)
+	}
+}
+
+func TestLoad_Results(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "metatools.yaml")
+
+	yaml := `
+results:
+  max_bytes: 32768
+  tools:
+    - tool_id: db:query
+      max_bytes: 262144
+`
+	if err := os.WriteFile(configPath, []byte(yaml), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.Results.MaxBytes != 32768 {
+		t.Errorf("Results.MaxBytes = %d, want 32768", cfg.Results.MaxBytes)
+	}
+	if len(cfg.Results.Tools) != 1 || cfg.Results.Tools[0].ToolID != "db:query" || cfg.Results.Tools[0].MaxBytes != 262144 {
+		t.Errorf("Results.Tools = %+v, want db:query at 262144", cfg.Results.Tools)
+	}
+	if cfg.Results.TTL != time.Hour {
+		t.Errorf("Results.TTL = %v, want default %v", cfg.Results.TTL, time.Hour)
+	}
+}
+
+func TestLoad_Roots(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "metatools.yaml")
+
+	yaml := `
+roots:
+  enforce: true
+  tags: [fs, files]
+`
+	if err := os.WriteFile(configPath, []byte(yaml), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if !cfg.Roots.Enabled || !cfg.Roots.Enforce {
+		t.Errorf("Roots = %+v, want enabled and enforced", cfg.Roots)
+	}
+	if len(cfg.Roots.Tags) != 2 || cfg.Roots.Tags[1] != "files" {
+		t.Errorf("Roots.Tags = %v, want [fs files]", cfg.Roots.Tags)
+	}
+}
+
+func TestLoad_SkillStepDependsOn(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "metatools.yaml")
+
+	yaml := `
+execution:
+  max_parallel_steps: 2
+skills:
+  - name: triage
+    steps:
+      - id: issues
+        tool_id: gh:issues
+        depends_on: []
+      - id: report
+        tool_id: gh:report
+        depends_on: [issues]
+      - id: pulls
+        tool_id: gh:pulls
+`
+	if err := os.WriteFile(configPath, []byte(yaml), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Execution.MaxParallelSteps != 2 {
+		t.Errorf("Execution.MaxParallelSteps = %d, want 2", cfg.Execution.MaxParallelSteps)
+	}
+	steps := cfg.Skills[0].Steps
+	if steps[0].DependsOn == nil || len(steps[0].DependsOn) != 0 {
+		t.Errorf("steps[0].DependsOn = %#v, want empty non-nil list", steps[0].DependsOn)
+	}
+	if got := steps[1].DependsOn; len(got) != 1 || got[0] != "issues" {
+		t.Errorf("steps[1].DependsOn = %v, want [issues]", got)
+	}
+	if steps[2].DependsOn != nil {
+		t.Errorf("steps[2].DependsOn = %#v, want nil", steps[2].DependsOn)
+	}
+}
+
+func TestLoad_SkillStepOnError(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "metatools.yaml")
+
+	yaml := `
+skills:
+  - name: resilient
+    steps:
+      - id: fetch
+        tool_id: gh:get
+        on_error:
+          action: retry
+          attempts: 2
+          backoff: 250ms
+      - id: lookup
+        tool_id: gh:lookup
+        on_error:
+          action: fallback
+          tool_id: cache:lookup
+          args:
+            mode: cached
+`
+	if err := os.WriteFile(configPath, []byte(yaml), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	steps := cfg.Skills[0].Steps
+	retry := steps[0].OnError
+	if retry == nil || retry.Action != "retry" || retry.Attempts != 2 || retry.Backoff != 250*time.Millisecond {
+		t.Errorf("steps[0].OnError = %+v, want retry x2 after 250ms", retry)
+	}
+	fallback := steps[1].OnError
+	if fallback == nil || fallback.ToolID != "cache:lookup" || fallback.Args["mode"] != "cached" {
+		t.Errorf("steps[1].OnError = %+v, want fallback to cache:lookup", fallback)
+	}
+}
+
+func TestLoad_SkillInputSchema(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "metatools.yaml")
+
+	yaml := `
+skills:
+  - name: triage
+    input_schema:
+      type: object
+      properties:
+        repo:
+          type: string
+      required: [repo]
+    steps:
+      - id: issues
+        tool_id: gh:issues
+        inputs:
+          repo: "{{ inputs.repo }}"
+    output:
+      open: "${steps.issues.result.total}"
+    output_schema:
+      type: object
+      required: [open]
+`
+	if err := os.WriteFile(configPath, []byte(yaml), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	props, _ := cfg.Skills[0].InputSchema["properties"].(map[string]any)
+	if _, ok := props["repo"]; !ok {
+		t.Errorf("InputSchema = %v, want repo property", cfg.Skills[0].InputSchema)
+	}
+	if cfg.Skills[0].Output["open"] != "${steps.issues.result.total}" {
+		t.Errorf("Output = %v, want open mapping", cfg.Skills[0].Output)
+	}
+	if cfg.Skills[0].OutputSchema["type"] != "object" {
+		t.Errorf("OutputSchema = %v, want object schema", cfg.Skills[0].OutputSchema)
+	}
+}
+
+func TestLoad_SkillUndeclaredInput(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "metatools.yaml")
+
+	yaml := `
+skills:
+  - name: triage
+    input_schema:
+      type: object
+      properties:
+        repo:
+          type: string
+    steps:
+      - id: issues
+        tool_id: gh:issues
+        inputs:
+          query: "label:{{ inputs.label }}"
+`
+	if err := os.WriteFile(configPath, []byte(yaml), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	_, err := Load(configPath)
+	if err == nil || !strings.Contains(err.Error(), `"label"`) {
+		t.Fatalf("Load() error = %v, want undeclared input \"label\"", err)
+	}
+}
+
 func TestLoad_EnvOverrides(t *testing.T) {
 	t.Setenv("METATOOLS_TRANSPORT_TYPE", "streamable")
 	t.Setenv("METATOOLS_TRANSPORT_HTTP_PORT", "3000")
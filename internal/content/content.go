@@ -0,0 +1,127 @@
+// Package content carries the non-text content blocks of upstream tool
+// results (images, audio, embedded resources and resource links) through
+// metatools. A Collector applies size caps and can move large binaries into
+// a Store, replacing them with resource links served by metatools.
+package content
+
+import (
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Defaults applied to zero Options fields.
+const (
+	DefaultMaxBlockBytes = 1 << 20
+	DefaultMaxTotalBytes = 4 << 20
+)
+
+// Options configures a Collector.
+type Options struct {
+	// MaxBlockBytes caps the decoded size of a single block, inline or
+	// linked. Larger blocks are replaced by a text note.
+	MaxBlockBytes int
+	// MaxTotalBytes caps the inline bytes kept from one result. Blocks past
+	// the cap are replaced by a text note.
+	MaxTotalBytes int
+	// LinkLargerThan moves binaries larger than this many bytes into the
+	// Store and returns resource links in their place. Zero keeps all
+	// binaries inline.
+	LinkLargerThan int
+	// Store holds linked binaries; it is required when LinkLargerThan is set.
+	// Binaries that do not fit its quota are replaced by a text note.
+	Store *Store
+}
+
+// Collector extracts content blocks from tool results. It is safe for
+// concurrent use.
+type Collector struct {
+	opts Options
+}
+
+// NewCollector builds a Collector, applying defaults to zero Options fields.
+func NewCollector(opts Options) *Collector {
+	if opts.MaxBlockBytes <= 0 {
+		opts.MaxBlockBytes = DefaultMaxBlockBytes
+	}
+	if opts.MaxTotalBytes <= 0 {
+		opts.MaxTotalBytes = DefaultMaxTotalBytes
+	}
+	if opts.Store == nil {
+		opts.LinkLargerThan = 0
+	}
+	return &Collector{opts: opts}
+}
+
+// Collect returns the image, audio, resource and resource link blocks of
+// res. Text blocks are left out: they already make up the structured result.
+func (c *Collector) Collect(res *mcp.CallToolResult) []mcp.Content {
+	if res == nil {
+		return nil
+	}
+	var out []mcp.Content
+	total := 0
+	for _, block := range res.Content {
+		switch b := block.(type) {
+		case *mcp.ImageContent:
+			out = append(out, c.binary(b, "image", b.MIMEType, b.Data, &total))
+		case *mcp.AudioContent:
+			out = append(out, c.binary(b, "audio", b.MIMEType, b.Data, &total))
+		case *mcp.EmbeddedResource:
+			if b.Resource == nil {
+				continue
+			}
+			if b.Resource.Blob != nil {
+				out = append(out, c.binary(b, b.Resource.URI, b.Resource.MIMEType, b.Resource.Blob, &total))
+				continue
+			}
+			out = append(out, c.inline(b, b.Resource.MIMEType, len(b.Resource.Text), &total))
+		case *mcp.ResourceLink:
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// binary keeps a binary block inline, links it, or replaces it with a note.
+func (c *Collector) binary(block mcp.Content, name, mimeType string, data []byte, total *int) mcp.Content {
+	if len(data) > c.opts.MaxBlockBytes {
+		return omitted(mimeType, len(data), sizeLimit)
+	}
+	if c.opts.LinkLargerThan > 0 && len(data) > c.opts.LinkLargerThan {
+		uri, ok := c.opts.Store.Put(data, mimeType)
+		if !ok {
+			return omitted(mimeType, len(data), quotaFull)
+		}
+		size := int64(len(data))
+		return &mcp.ResourceLink{
+			URI:      uri,
+			Name:     name,
+			MIMEType: mimeType,
+			Size:     &size,
+		}
+	}
+	return c.inline(block, mimeType, len(data), total)
+}
+
+// inline keeps a block while the result stays under MaxTotalBytes.
+func (c *Collector) inline(block mcp.Content, mimeType string, size int, total *int) mcp.Content {
+	if size > c.opts.MaxBlockBytes || *total+size > c.opts.MaxTotalBytes {
+		return omitted(mimeType, size, sizeLimit)
+	}
+	*total += size
+	return block
+}
+
+// Reasons given in omitted notes.
+const (
+	sizeLimit = "exceeds content size limit"
+	quotaFull = "linked content quota is full"
+)
+
+func omitted(mimeType string, size int, reason string) mcp.Content {
+	if mimeType == "" {
+		mimeType = "binary"
+	}
+	return &mcp.TextContent{Text: fmt.Sprintf("[omitted %s content of %d bytes: %s]", mimeType, size, reason)}
+}
@@ -0,0 +1,102 @@
+package content
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollector_PassesRichContent(t *testing.T) {
+	link := &mcp.ResourceLink{URI: "file:///report.pdf", Name: "report"}
+	image := &mcp.ImageContent{Data: []byte("png"), MIMEType: "image/png"}
+	audio := &mcp.AudioContent{Data: []byte("wav"), MIMEType: "audio/wav"}
+	embedded := &mcp.EmbeddedResource{Resource: &mcp.ResourceContents{URI: "file:///a.txt", Text: "hello"}}
+
+	got := NewCollector(Options{}).Collect(&mcp.CallToolResult{Content: []mcp.Content{
+		&mcp.TextContent{Text: `{"ok":true}`}, image, audio, embedded, link,
+	}})
+	require.Equal(t, []mcp.Content{image, audio, embedded, link}, got)
+	require.Empty(t, NewCollector(Options{}).Collect(nil))
+}
+
+func TestCollector_SizeCaps(t *testing.T) {
+	c := NewCollector(Options{MaxBlockBytes: 8, MaxTotalBytes: 10})
+	got := c.Collect(&mcp.CallToolResult{Content: []mcp.Content{
+		&mcp.ImageContent{Data: bytes.Repeat([]byte("a"), 9), MIMEType: "image/png"},
+		&mcp.ImageContent{Data: bytes.Repeat([]byte("b"), 6), MIMEType: "image/png"},
+		&mcp.AudioContent{Data: bytes.Repeat([]byte("c"), 6), MIMEType: "audio/wav"},
+	}})
+	require.Len(t, got, 3)
+
+	// The first block is over the per-block cap, the third over the total.
+	require.Equal(t, "[omitted image/png content of 9 bytes: exceeds content size limit]", got[0].(*mcp.TextContent).Text)
+	require.Equal(t, bytes.Repeat([]byte("b"), 6), got[1].(*mcp.ImageContent).Data)
+	require.Equal(t, "[omitted audio/wav content of 6 bytes: exceeds content size limit]", got[2].(*mcp.TextContent).Text)
+}
+
+func TestCollector_LinksLargeBinaries(t *testing.T) {
+	store := NewStore(time.Minute, 0)
+	c := NewCollector(Options{LinkLargerThan: 4, Store: store})
+	data := []byte("large image")
+	got := c.Collect(&mcp.CallToolResult{Content: []mcp.Content{
+		&mcp.ImageContent{Data: data, MIMEType: "image/png"},
+		&mcp.ImageContent{Data: []byte("tiny"), MIMEType: "image/png"},
+	}})
+	require.Len(t, got, 2)
+	require.IsType(t, &mcp.ImageContent{}, got[1])
+
+	link, ok := got[0].(*mcp.ResourceLink)
+	require.True(t, ok)
+	require.True(t, strings.HasPrefix(link.URI, URIPrefix))
+	require.Equal(t, "image/png", link.MIMEType)
+	require.Equal(t, int64(len(data)), *link.Size)
+
+	res, err := store.Read(context.Background(), &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: link.URI}})
+	require.NoError(t, err)
+	require.Equal(t, data, res.Contents[0].Blob)
+	require.Equal(t, "image/png", res.Contents[0].MIMEType)
+}
+
+func TestStore_Expires(t *testing.T) {
+	store := NewStore(time.Minute, 0)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	uri, ok := store.Put([]byte("data"), "application/octet-stream")
+	require.True(t, ok)
+
+	read := func(uri string) error {
+		_, err := store.Read(context.Background(), &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: uri}})
+		return err
+	}
+	require.NoError(t, read(uri))
+	require.Error(t, read(URIPrefix+"missing"))
+	require.Error(t, read("file:///elsewhere"))
+
+	now = now.Add(2 * time.Minute)
+	require.Error(t, read(uri))
+}
+
+func TestCollector_LinkQuota(t *testing.T) {
+	store := NewStore(time.Minute, 10)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	c := NewCollector(Options{LinkLargerThan: 4, Store: store})
+	collect := func(data string) mcp.Content {
+		return c.Collect(&mcp.CallToolResult{Content: []mcp.Content{
+			&mcp.ImageContent{Data: []byte(data), MIMEType: "image/png"},
+		}})[0]
+	}
+
+	require.IsType(t, &mcp.ResourceLink{}, collect("first!"))
+	// The second binary does not fit next to the first.
+	require.Equal(t, "[omitted image/png content of 6 bytes: linked content quota is full]", collect("second").(*mcp.TextContent).Text)
+
+	// Expired links free their bytes.
+	now = now.Add(2 * time.Minute)
+	require.IsType(t, &mcp.ResourceLink{}, collect("second"))
+}
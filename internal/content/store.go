@@ -0,0 +1,116 @@
+package content
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// URIPrefix starts the URI of every stored binary.
+const URIPrefix = "metatools://content/"
+
+// Defaults applied to zero NewStore arguments.
+const (
+	// DefaultTTL is how long stored binaries are kept.
+	DefaultTTL = time.Hour
+	// DefaultQuota caps the bytes of all stored binaries.
+	DefaultQuota = 64 << 20
+)
+
+type entry struct {
+	data     []byte
+	mimeType string
+	expires  time.Time
+}
+
+// Store keeps linked binaries in memory until their TTL expires and serves
+// them as MCP resources. It is safe for concurrent use.
+type Store struct {
+	ttl   time.Duration
+	quota int
+	now   func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]entry
+	used    int
+}
+
+// NewStore creates a Store keeping binaries for ttl, up to quota bytes in
+// total. Zero or less uses DefaultTTL and DefaultQuota.
+func NewStore(ttl time.Duration, quota int) *Store {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	if quota <= 0 {
+		quota = DefaultQuota
+	}
+	return &Store{ttl: ttl, quota: quota, now: time.Now, entries: make(map[string]entry)}
+}
+
+// Put stores data and returns the URI it is served at. It reports false,
+// storing nothing, when data does not fit the quota next to the binaries
+// that have not expired yet. Stored binaries are never evicted early, so
+// links already handed out stay valid for their TTL.
+func (s *Store) Put(data []byte, mimeType string) (string, bool) {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	id := hex.EncodeToString(b[:])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep()
+	if s.used+len(data) > s.quota {
+		return "", false
+	}
+	s.entries[id] = entry{data: data, mimeType: mimeType, expires: s.now().Add(s.ttl)}
+	s.used += len(data)
+	return URIPrefix + id, true
+}
+
+// Template returns the resource template stored binaries are served under.
+func (s *Store) Template() *mcp.ResourceTemplate {
+	return &mcp.ResourceTemplate{
+		Name:        "content",
+		Title:       "Tool result content",
+		Description: "Binary content from tool results, linked instead of inlined because of its size.",
+		URITemplate: URIPrefix + "{id}",
+	}
+}
+
+// Read serves resources/read for stored binaries.
+func (s *Store) Read(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	id, ok := strings.CutPrefix(uri, URIPrefix)
+	if !ok {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	s.mu.Lock()
+	s.sweep()
+	e, ok := s.entries[id]
+	s.mu.Unlock()
+	if !ok {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{{
+		URI:      uri,
+		MIMEType: e.mimeType,
+		Blob:     e.data,
+	}}}, nil
+}
+
+// sweep drops expired entries; the caller holds s.mu.
+func (s *Store) sweep() {
+	now := s.now()
+	for id, e := range s.entries {
+		if now.After(e.expires) {
+			delete(s.entries, id)
+			s.used -= len(e.data)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+// Package definitions keeps the toolset and skill registries current. It
+// rebuilds both from their source when definition files change or the tool
+// index changes, and swaps them in atomically.
+package definitions
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jonwraymond/metatools-mcp/internal/config"
+	"github.com/jonwraymond/metatools-mcp/internal/skills"
+	"github.com/jonwraymond/metatools-mcp/internal/toolset"
+	"github.com/jonwraymond/tooldiscovery/index"
+)
+
+// Defaults for Options.
+const (
+	DefaultWatchInterval = 2 * time.Second
+	DefaultDebounce      = 200 * time.Millisecond
+)
+
+// Source returns the current toolset and skill specs, e.g. inline config
+// merged with definition directories.
+type Source func() ([]toolset.Spec, []skills.Spec, error)
+
+// Options configures a Manager.
+type Options struct {
+	// Index resolves toolset membership.
+	Index index.Index
+	// Source is called on every rebuild.
+	Source Source
+	// Dirs are polled for changes by Watch.
+	Dirs []string
+	// WatchInterval is the polling interval for Watch.
+	WatchInterval time.Duration
+	// Debounce delays rebuilds after index changes so bulk registrations
+	// rebuild once.
+	Debounce time.Duration
+	// Logger receives reload results; defaults to slog.Default.
+	Logger *slog.Logger
+}
+
+type registries struct {
+	toolsets *toolset.Registry
+	skills   *skills.Registry
+}
+
+// Manager owns the live toolset and skill registries.
+//
+// Contract:
+//   - Concurrency: safe for concurrent use; readers never block on rebuilds.
+//   - Errors: Load fails on invalid definitions; later rebuilds log errors and
+//     keep the previous registries.
+type Manager struct {
+	opts    Options
+	current atomic.Pointer[registries]
+
+	// reloadMu serializes rebuilds. It is separate from mu so index change
+	// callbacks never wait on a rebuild that is reading the index.
+	reloadMu    sync.Mutex
+	mu          sync.Mutex
+	fingerprint uint64
+	timer       *time.Timer
+	listeners   map[int]func()
+	nextID      int
+}
+
+// NewManager creates a manager. Call Load before serving.
+func NewManager(opts Options) *Manager {
+	if opts.WatchInterval <= 0 {
+		opts.WatchInterval = DefaultWatchInterval
+	}
+	if opts.Debounce <= 0 {
+		opts.Debounce = DefaultDebounce
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	m := &Manager{opts: opts, listeners: make(map[int]func())}
+	m.current.Store(&registries{toolsets: toolset.NewRegistry(nil), skills: skills.NewRegistry(nil)})
+	return m
+}
+
+// Load builds the registries for the first time.
+func (m *Manager) Load() error {
+	fp, err := m.dirFingerprint()
+	if err != nil {
+		return err
+	}
+	m.reloadMu.Lock()
+	err = m.rebuildLocked()
+	m.reloadMu.Unlock()
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.fingerprint = fp
+	m.mu.Unlock()
+	return nil
+}
+
+// Reload rebuilds both registries and swaps them in. On error the previous
+// registries stay in effect. Listeners are notified after a successful swap.
+func (m *Manager) Reload() error {
+	m.reloadMu.Lock()
+	err := m.rebuildLocked()
+	m.reloadMu.Unlock()
+	if err != nil {
+		return err
+	}
+	m.notify()
+	return nil
+}
+
+func (m *Manager) rebuildLocked() error {
+	toolsetSpecs, skillSpecs, err := m.opts.Source()
+	if err != nil {
+		return err
+	}
+	toolsets, err := toolset.BuildRegistry(m.opts.Index, toolsetSpecs)
+	if err != nil {
+		return fmt.Errorf("build toolsets: %w", err)
+	}
+	skillReg, err := skills.BuildRegistry(toolsets, skillSpecs)
+	if err != nil {
+		return fmt.Errorf("build skills: %w", err)
+	}
+	m.current.Store(&registries{toolsets: toolsets, skills: skillReg})
+	return nil
+}
+
+// Toolsets returns a view that always reads the current toolset registry.
+func (m *Manager) Toolsets() Toolsets {
+	return Toolsets{m: m}
+}
+
+// Skills returns a view that always reads the current skill registry.
+func (m *Manager) Skills() Skills {
+	return Skills{m: m}
+}
+
+// OnReload registers fn to run after each successful rebuild. The returned
+// function unsubscribes.
+func (m *Manager) OnReload(fn func()) func() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := m.nextID
+	m.nextID++
+	m.listeners[id] = fn
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.listeners, id)
+	}
+}
+
+func (m *Manager) notify() {
+	m.mu.Lock()
+	fns := make([]func(), 0, len(m.listeners))
+	for _, fn := range m.listeners {
+		fns = append(fns, fn)
+	}
+	m.mu.Unlock()
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// Listen rebuilds the registries, debounced, whenever the index changes so
+// toolsets pick up new and removed tools. The returned function unsubscribes.
+func (m *Manager) Listen() func() {
+	notifier, ok := m.opts.Index.(index.ChangeNotifier)
+	if !ok {
+		return func() {}
+	}
+	unsub := notifier.OnChange(func(index.ChangeEvent) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if m.timer == nil {
+			m.timer = time.AfterFunc(m.opts.Debounce, m.reloadAndLog)
+			return
+		}
+		m.timer.Reset(m.opts.Debounce)
+	})
+	return func() {
+		unsub()
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if m.timer != nil {
+			m.timer.Stop()
+		}
+	}
+}
+
+// Watch polls the definition directories and rebuilds on change until ctx is
+// done.
+func (m *Manager) Watch(ctx context.Context) {
+	ticker := time.NewTicker(m.opts.WatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.reloadIfChanged()
+		}
+	}
+}
+
+func (m *Manager) reloadIfChanged() {
+	fp, err := m.dirFingerprint()
+	if err != nil {
+		m.opts.Logger.Warn("definitions watch failed", "error", err)
+		return
+	}
+	m.mu.Lock()
+	unchanged := fp == m.fingerprint
+	m.fingerprint = fp
+	m.mu.Unlock()
+	if unchanged {
+		return
+	}
+	m.reloadAndLog()
+}
+
+func (m *Manager) reloadAndLog() {
+	if err := m.Reload(); err != nil {
+		m.opts.Logger.Warn("definitions reload failed; keeping previous toolsets and skills", "error", err)
+		return
+	}
+	cur := m.current.Load()
+	m.opts.Logger.Info("definitions reloaded",
+		"toolsets", len(cur.toolsets.List()),
+		"skills", len(cur.skills.List()))
+}
+
+// dirFingerprint hashes definition file paths, sizes and modification times.
+func (m *Manager) dirFingerprint() (uint64, error) {
+	h := fnv.New64a()
+	for _, dir := range m.opts.Dirs {
+		files, err := config.DefinitionFiles(dir)
+		if err != nil {
+			return 0, err
+		}
+		for _, path := range files {
+			info, err := os.Stat(path)
+			if err != nil {
+				return 0, err
+			}
+			_, _ = fmt.Fprintf(h, "%s\x00%d\x00%d\x01", path, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	return h.Sum64(), nil
+}
+
+// Toolsets is a live view of the current toolset registry.
+type Toolsets struct{ m *Manager }
+
+// List returns toolsets in deterministic order.
+func (v Toolsets) List() []*toolset.Toolset { return v.m.current.Load().toolsets.List() }
+
+// Get returns a toolset by ID.
+func (v Toolsets) Get(id string) (*toolset.Toolset, bool) {
+	return v.m.current.Load().toolsets.Get(id)
+}
+
+// Skills is a live view of the current skill registry.
+type Skills struct{ m *Manager }
+
+// List returns skills in deterministic order.
+func (v Skills) List() []*skills.Skill { return v.m.current.Load().skills.List() }
+
+// Get returns a skill by ID.
+func (v Skills) Get(id string) (*skills.Skill, bool) {
+	return v.m.current.Load().skills.Get(id)
+}
@@ -0,0 +1,138 @@
+package definitions
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jonwraymond/metatools-mcp/internal/skills"
+	"github.com/jonwraymond/metatools-mcp/internal/toolset"
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolfoundation/model"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func registerTool(t *testing.T, idx index.Index, name string) {
+	t.Helper()
+	tool := model.Tool{
+		Namespace: "test",
+		Tool:      mcp.Tool{Name: name, InputSchema: map[string]any{"type": "object"}},
+	}
+	backend := model.ToolBackend{Kind: model.BackendKindLocal, Local: &model.LocalBackend{Name: name}}
+	require.NoError(t, idx.RegisterTool(tool, backend))
+}
+
+// specSource returns a Source serving whatever set last stored.
+func specSource() (Source, func([]toolset.Spec, []skills.Spec, error)) {
+	var mu sync.Mutex
+	var ts []toolset.Spec
+	var sk []skills.Spec
+	var err error
+	source := func() ([]toolset.Spec, []skills.Spec, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return ts, sk, err
+	}
+	set := func(t []toolset.Spec, s []skills.Spec, e error) {
+		mu.Lock()
+		defer mu.Unlock()
+		ts, sk, err = t, s, e
+	}
+	return source, set
+}
+
+func TestManager_LoadAndReload(t *testing.T) {
+	idx := index.NewInMemoryIndex()
+	registerTool(t, idx, "ping")
+	source, set := specSource()
+	set([]toolset.Spec{{Name: "core", NamespaceFilters: []string{"test"}}},
+		[]skills.Spec{{Name: "check", ToolsetID: "toolset:core", Steps: []skills.StepSpec{{ID: "a", ToolID: "test:ping"}}}}, nil)
+
+	m := NewManager(Options{Index: idx, Source: source})
+	require.NoError(t, m.Load())
+
+	toolsets, skillViews := m.Toolsets(), m.Skills()
+	require.Len(t, toolsets.List(), 1)
+	_, ok := skillViews.Get("skill:check")
+	require.True(t, ok)
+
+	reloads := 0
+	unsub := m.OnReload(func() { reloads++ })
+
+	// A broken definition keeps the previous registries.
+	set(nil, []skills.Spec{{Name: "check", ToolsetID: "toolset:missing", Steps: []skills.StepSpec{{ID: "a", ToolID: "test:ping"}}}}, nil)
+	require.ErrorContains(t, m.Reload(), "unknown toolset")
+	assert.Len(t, toolsets.List(), 1)
+	assert.Equal(t, 0, reloads)
+
+	set(nil, nil, errors.New("read failed"))
+	require.Error(t, m.Reload())
+
+	set([]toolset.Spec{{Name: "core"}, {Name: "extra"}}, nil, nil)
+	require.NoError(t, m.Reload())
+	assert.Len(t, toolsets.List(), 2, "views see the new registry")
+	assert.Empty(t, skillViews.List())
+	assert.Equal(t, 1, reloads)
+
+	unsub()
+	require.NoError(t, m.Reload())
+	assert.Equal(t, 1, reloads)
+}
+
+func TestManager_ListenRebuildsOnIndexChange(t *testing.T) {
+	idx := index.NewInMemoryIndex()
+	registerTool(t, idx, "ping")
+	source, set := specSource()
+	set([]toolset.Spec{{Name: "core", NamespaceFilters: []string{"test"}}}, nil, nil)
+
+	m := NewManager(Options{Index: idx, Source: source, Debounce: 10 * time.Millisecond})
+	require.NoError(t, m.Load())
+	reloaded := make(chan struct{}, 4)
+	m.OnReload(func() { reloaded <- struct{}{} })
+	stop := m.Listen()
+	defer stop()
+
+	ts, _ := m.Toolsets().Get("toolset:core")
+	require.Equal(t, []string{"test:ping"}, ts.ToolIDs())
+
+	registerTool(t, idx, "pong")
+	registerTool(t, idx, "pang")
+	select {
+	case <-reloaded:
+	case <-time.After(time.Second):
+		t.Fatal("expected a rebuild after index change")
+	}
+	ts, _ = m.Toolsets().Get("toolset:core")
+	assert.Equal(t, []string{"test:pang", "test:ping", "test:pong"}, ts.ToolIDs())
+}
+
+func TestManager_WatchReloadsOnFileChange(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "core.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("name: core\n"), 0o600))
+
+	loads := 0
+	source := func() ([]toolset.Spec, []skills.Spec, error) {
+		loads++
+		return nil, nil, nil
+	}
+	m := NewManager(Options{Index: index.NewInMemoryIndex(), Source: source, Dirs: []string{dir}})
+	require.NoError(t, m.Load())
+
+	m.reloadIfChanged()
+	assert.Equal(t, 1, loads, "unchanged files do not rebuild")
+
+	require.NoError(t, os.WriteFile(file, []byte("name: core\ndescription: changed\n"), 0o600))
+	require.NoError(t, os.Chtimes(file, time.Now().Add(time.Second), time.Now().Add(time.Second)))
+	m.reloadIfChanged()
+	assert.Equal(t, 2, loads)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0o600))
+	m.reloadIfChanged()
+	assert.Equal(t, 2, loads, "non-YAML files are ignored")
+}
@@ -0,0 +1,199 @@
+// Package dryrun walks chain and skill steps without executing any tool. It
+// resolves each tool against the index, validates step arguments against the
+// tool's input schema, and feeds step references from mocks or from values
+// derived from the tools' output schemas.
+package dryrun
+
+import (
+	"context"
+	"fmt"
+
+	merrors "github.com/jonwraymond/metatools-mcp/internal/errors"
+	"github.com/jonwraymond/metatools-mcp/internal/jsonshape"
+	"github.com/jonwraymond/metatools-mcp/internal/stepgraph"
+	"github.com/jonwraymond/metatools-mcp/internal/stepref"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// Output sources reported on a StepTrace.
+const (
+	// SourceMock means the output came from Options.Mocks.
+	SourceMock = "mock"
+	// SourceSchema means the output was derived from the tool's output schema.
+	SourceSchema = "schema"
+	// SourceUnknown means the step has no mock and no usable output schema;
+	// later steps referencing it are not validated.
+	SourceUnknown = "unknown"
+)
+
+var validator = model.NewDefaultValidator()
+
+// Lookup resolves a tool ID to its definition.
+type Lookup func(ctx context.Context, toolID string) (model.Tool, error)
+
+// Options configures Run.
+type Options struct {
+	Lookup Lookup
+	// Mocks maps tool IDs to the structured output a call would return.
+	Mocks map[string]any
+}
+
+// Step is one step to walk. Args may contain step references.
+type Step struct {
+	ID          string
+	ToolID      string
+	Args        map[string]any
+	UsePrevious bool
+	OnError     *stepgraph.Policy
+}
+
+// StepTrace is what a step would have done.
+type StepTrace struct {
+	// Index is the step's position in the steps passed to Run.
+	Index  int
+	ID     string
+	ToolID string
+	// Args are the resolved arguments, or the raw ones when a reference
+	// could not be resolved.
+	Args   map[string]any
+	Output any
+	Source string
+	// Note explains a step whose arguments were not validated.
+	Note string
+	Err  error
+}
+
+// Run walks steps in graph order. Every step is checked, so one run reports
+// all problems; the returned error is the first step error. A step whose
+// tool resolves still provides its output to later steps when its own
+// arguments are invalid.
+func Run(ctx context.Context, graph *stepgraph.Graph, steps []Step, opts Options) ([]StepTrace, error) {
+	if opts.Lookup == nil {
+		return nil, fmt.Errorf("dry run requires a tool lookup")
+	}
+	positions := make(map[string]int, len(steps))
+	for i, step := range steps {
+		if step.ID != "" {
+			positions[step.ID] = i
+		}
+	}
+
+	refs := stepref.NewResults()
+	known := make([]bool, len(steps))
+	outputs := make([]any, len(steps))
+	traces := make([]StepTrace, 0, len(steps))
+	var firstErr error
+	for _, i := range graph.Order() {
+		if err := ctx.Err(); err != nil {
+			return traces, err
+		}
+		step := steps[i]
+		tr := StepTrace{Index: i, ID: step.ID, ToolID: step.ToolID, Args: step.Args, Source: SourceUnknown}
+
+		tool, err := opts.Lookup(ctx, step.ToolID)
+		if err != nil {
+			tr.Err = fmt.Errorf("%w: %q: %v", merrors.ErrToolNotFound, step.ToolID, err)
+		} else {
+			var previous any
+			if i > 0 {
+				previous = outputs[i-1]
+			}
+			tr.Err = checkArgs(&tr, step, tool, refs, previous, pending(step, i, positions, known))
+			tr.Output, tr.Source = output(step.ToolID, tool, opts.Mocks)
+			if tr.Err == nil && tr.Source == SourceMock && tool.OutputSchema != nil {
+				instance, _ := jsonshape.Normalize(tr.Output)
+				if err := validator.Validate(tool.OutputSchema, instance); err != nil {
+					tr.Err = fmt.Errorf("%w: mock for %q: %v", merrors.ErrValidationOutput, step.ToolID, err)
+				}
+			}
+		}
+		if tr.Err == nil {
+			if p := step.OnError; p != nil && p.Action == stepgraph.ActionFallback {
+				if _, err := opts.Lookup(ctx, p.ToolID); err != nil {
+					tr.Err = fmt.Errorf("%w: fallback %q: %v", merrors.ErrToolNotFound, p.ToolID, err)
+				}
+			}
+		}
+
+		if tr.Source != SourceUnknown {
+			known[i] = true
+			outputs[i] = tr.Output
+			refs.Set(i, step.ID, tr.Output)
+		}
+		if tr.Err != nil && firstErr == nil {
+			firstErr = tr.Err
+		}
+		traces = append(traces, tr)
+	}
+	return traces, firstErr
+}
+
+// pending returns the first step referenced by step, or used through
+// use_previous, whose output is unknown; "" when all are known.
+func pending(step Step, i int, positions map[string]int, known []bool) string {
+	if step.UsePrevious && i > 0 && !known[i-1] {
+		return fmt.Sprintf("steps[%d]", i-1)
+	}
+	found, err := stepref.Collect(step.Args)
+	if err != nil {
+		return ""
+	}
+	for _, ref := range found {
+		pos := ref.Index
+		if pos < 0 {
+			var ok bool
+			if pos, ok = positions[ref.StepID]; !ok {
+				continue
+			}
+		}
+		if pos < len(known) && !known[pos] {
+			return ref.Step()
+		}
+	}
+	return ""
+}
+
+func checkArgs(tr *StepTrace, step Step, tool model.Tool, refs *stepref.Results, previous any, unknown string) error {
+	if unknown != "" {
+		tr.Note = fmt.Sprintf("arguments not validated: %s has no mock or output schema", unknown)
+		return nil
+	}
+	args, err := stepref.Resolve(step.Args, refs)
+	if err != nil {
+		return fmt.Errorf("%w: %v", merrors.ErrValidationInput, err)
+	}
+	if step.UsePrevious {
+		args = withPrevious(args, previous)
+	}
+	tr.Args = args
+	if tool.InputSchema == nil {
+		return nil
+	}
+	instance, _ := jsonshape.Normalize(args)
+	if instance == nil {
+		instance = map[string]any{}
+	}
+	if err := validator.Validate(tool.InputSchema, instance); err != nil {
+		return fmt.Errorf("%w: %v", merrors.ErrValidationInput, err)
+	}
+	return nil
+}
+
+func withPrevious(args map[string]any, previous any) map[string]any {
+	out := make(map[string]any, len(args)+1)
+	for k, v := range args {
+		out[k] = v
+	}
+	out["previous"] = previous
+	return out
+}
+
+func output(toolID string, tool model.Tool, mocks map[string]any) (any, string) {
+	if mock, ok := mocks[toolID]; ok {
+		return mock, SourceMock
+	}
+	if value, ok := Sample(tool.OutputSchema); ok {
+		return value, SourceSchema
+	}
+	return nil, SourceUnknown
+}
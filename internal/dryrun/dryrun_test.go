@@ -0,0 +1,148 @@
+package dryrun
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	merrors "github.com/jonwraymond/metatools-mcp/internal/errors"
+	"github.com/jonwraymond/metatools-mcp/internal/stepgraph"
+	"github.com/jonwraymond/toolfoundation/model"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLookup(tools ...model.Tool) Lookup {
+	byID := make(map[string]model.Tool, len(tools))
+	for _, tool := range tools {
+		byID[tool.ToolID()] = tool
+	}
+	return func(_ context.Context, id string) (model.Tool, error) {
+		tool, ok := byID[id]
+		if !ok {
+			return model.Tool{}, errors.New("not found")
+		}
+		return tool, nil
+	}
+}
+
+func testTool(name string, input, output map[string]any) model.Tool {
+	return model.Tool{Namespace: "t", Tool: mcp.Tool{Name: name, InputSchema: input, OutputSchema: output}}
+}
+
+func build(t *testing.T, steps []Step) *stepgraph.Graph {
+	t.Helper()
+	specs := make([]stepgraph.Spec, len(steps))
+	for i, step := range steps {
+		specs[i] = stepgraph.Spec{ID: step.ID, Args: step.Args, UsePrevious: step.UsePrevious, OnError: step.OnError}
+	}
+	graph, err := stepgraph.Build(specs)
+	require.NoError(t, err)
+	return graph
+}
+
+var (
+	searchTool = testTool("search",
+		map[string]any{"type": "object", "properties": map[string]any{"q": map[string]any{"type": "string"}}},
+		map[string]any{"type": "object", "properties": map[string]any{
+			"items": map[string]any{"type": "array", "items": map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"id": map[string]any{"type": "string"}},
+			}},
+		}},
+	)
+	fetchTool = testTool("fetch",
+		map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"id": map[string]any{"type": "string"}},
+			"required":   []any{"id"},
+		},
+		nil,
+	)
+)
+
+func TestSample(t *testing.T) {
+	v, ok := Sample(searchTool.OutputSchema)
+	require.True(t, ok)
+	assert.Equal(t, map[string]any{"items": []any{map[string]any{"id": ""}}}, v)
+
+	v, ok = Sample(map[string]any{"type": "string", "enum": []any{"a", "b"}})
+	require.True(t, ok)
+	assert.Equal(t, "a", v)
+
+	v, ok = Sample(map[string]any{"type": []any{"null", "integer"}})
+	require.True(t, ok)
+	assert.Equal(t, float64(0), v)
+
+	_, ok = Sample(nil)
+	assert.False(t, ok)
+	_, ok = Sample(map[string]any{"type": "object"})
+	assert.False(t, ok, "open objects have no keys to reference")
+}
+
+func TestRun_SchemaOutputsFeedReferences(t *testing.T) {
+	steps := []Step{
+		{ID: "s", ToolID: "t:search", Args: map[string]any{"q": "go"}},
+		{ID: "f", ToolID: "t:fetch", Args: map[string]any{"id": map[string]any{"$ref": "steps.s.structured.items[0].id"}}},
+	}
+	traces, err := Run(context.Background(), build(t, steps), steps, Options{Lookup: testLookup(searchTool, fetchTool)})
+	require.NoError(t, err)
+	require.Len(t, traces, 2)
+	assert.Equal(t, SourceSchema, traces[0].Source)
+	assert.Equal(t, map[string]any{"id": ""}, traces[1].Args)
+	assert.Equal(t, SourceUnknown, traces[1].Source)
+}
+
+func TestRun_Mocks(t *testing.T) {
+	steps := []Step{
+		{ID: "s", ToolID: "t:search"},
+		{ID: "f", ToolID: "t:fetch", Args: map[string]any{"id": "${steps.s.result.items[0].id}"}},
+	}
+	graph := build(t, steps)
+
+	traces, err := Run(context.Background(), graph, steps, Options{
+		Lookup: testLookup(searchTool, fetchTool),
+		Mocks:  map[string]any{"t:search": map[string]any{"items": []any{map[string]any{"id": "x1"}}}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, SourceMock, traces[0].Source)
+	assert.Equal(t, map[string]any{"id": "x1"}, traces[1].Args)
+
+	// Mocks must satisfy the tool's output schema.
+	traces, err = Run(context.Background(), graph, steps, Options{
+		Lookup: testLookup(searchTool, fetchTool),
+		Mocks:  map[string]any{"t:search": map[string]any{"items": "nope"}},
+	})
+	require.ErrorIs(t, err, merrors.ErrValidationOutput)
+	assert.Error(t, traces[0].Err)
+}
+
+func TestRun_ValidationErrors(t *testing.T) {
+	steps := []Step{
+		{ID: "missing", ToolID: "t:nope"},
+		{ID: "bad", ToolID: "t:fetch", Args: map[string]any{"id": 7}},
+		{ID: "ref", ToolID: "t:fetch", Args: map[string]any{"id": "${steps.bad.structured.x}"}},
+	}
+	traces, err := Run(context.Background(), build(t, steps), steps, Options{Lookup: testLookup(fetchTool)})
+	require.ErrorIs(t, err, merrors.ErrToolNotFound)
+	require.Len(t, traces, 3)
+	assert.ErrorIs(t, traces[0].Err, merrors.ErrToolNotFound)
+	assert.ErrorIs(t, traces[1].Err, merrors.ErrValidationInput)
+
+	// fetch has no output schema, so the reference is noted, not validated.
+	assert.NoError(t, traces[2].Err)
+	assert.Contains(t, traces[2].Note, "steps.bad")
+}
+
+func TestRun_UsePreviousAndFallback(t *testing.T) {
+	steps := []Step{
+		{ID: "s", ToolID: "t:search"},
+		{ID: "f", ToolID: "t:fetch", Args: map[string]any{"id": "x"}, UsePrevious: true, OnError: &stepgraph.Policy{Action: stepgraph.ActionFallback, ToolID: "t:gone"}},
+	}
+	traces, err := Run(context.Background(), build(t, steps), steps, Options{Lookup: testLookup(searchTool, fetchTool)})
+	require.ErrorIs(t, err, merrors.ErrToolNotFound)
+	assert.Contains(t, traces[1].Args, "previous")
+	assert.ErrorIs(t, traces[1].Err, merrors.ErrToolNotFound)
+	assert.Contains(t, traces[1].Err.Error(), "fallback")
+}
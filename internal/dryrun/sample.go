@@ -0,0 +1,106 @@
+package dryrun
+
+import "github.com/jonwraymond/metatools-mcp/internal/jsonshape"
+
+// maxSampleDepth stops recursive schemas from expanding forever.
+const maxSampleDepth = 8
+
+// Sample derives a representative value from a JSON Schema: const, default,
+// the first example or enum value when present, otherwise a zero value of the
+// declared type. Objects get every declared property and arrays one item, so
+// step references into them resolve. It reports false when the schema is
+// missing, declares no usable type, or is an object without properties or an
+// array without items.
+func Sample(schema any) (any, bool) {
+	m, _ := jsonshape.Map(schema)
+	if len(m) == 0 {
+		return nil, false
+	}
+	return sample(m, 0)
+}
+
+func sample(schema map[string]any, depth int) (any, bool) {
+	if depth > maxSampleDepth {
+		return nil, false
+	}
+	if v, ok := schema["const"]; ok {
+		return v, true
+	}
+	if v, ok := schema["default"]; ok {
+		return v, true
+	}
+	if list, ok := schema["examples"].([]any); ok && len(list) > 0 {
+		return list[0], true
+	}
+	if list, ok := schema["enum"].([]any); ok && len(list) > 0 {
+		return list[0], true
+	}
+	for _, key := range []string{"oneOf", "anyOf", "allOf"} {
+		if list, ok := schema[key].([]any); ok && len(list) > 0 {
+			if sub, ok := list[0].(map[string]any); ok {
+				return sample(sub, depth+1)
+			}
+		}
+	}
+
+	switch schemaType(schema) {
+	case "object":
+		props, ok := schema["properties"].(map[string]any)
+		if !ok {
+			// An open object gives no keys to reference.
+			return nil, false
+		}
+		out := make(map[string]any, len(props))
+		for name, raw := range props {
+			sub, ok := raw.(map[string]any)
+			if !ok {
+				continue
+			}
+			if v, ok := sample(sub, depth+1); ok {
+				out[name] = v
+			}
+		}
+		return out, true
+	case "array":
+		items, ok := schema["items"].(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		item, ok := sample(items, depth+1)
+		if !ok {
+			return nil, false
+		}
+		return []any{item}, true
+	case "string":
+		return "", true
+	case "integer", "number":
+		return float64(0), true
+	case "boolean":
+		return false, true
+	case "null":
+		return nil, true
+	}
+	return nil, false
+}
+
+// schemaType returns the declared type, preferring the first non-null one
+// of a type list. A schema with properties and no type is an object.
+func schemaType(schema map[string]any) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []any:
+		for _, v := range t {
+			if s, ok := v.(string); ok && s != "null" {
+				return s
+			}
+		}
+		if len(t) > 0 {
+			return "null"
+		}
+	}
+	if _, ok := schema["properties"]; ok {
+		return "object"
+	}
+	return ""
+}
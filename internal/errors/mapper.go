@@ -14,6 +14,7 @@ type ErrorCode string
 // Error code values returned in ErrorObject.Code.
 const (
 	CodeToolNotFound           ErrorCode = "tool_not_found"
+	CodeToolNotActive          ErrorCode = "tool_not_active"
 	CodeNoBackends             ErrorCode = "no_backends"
 	CodeBackendOverrideInvalid ErrorCode = "backend_override_invalid"
 	CodeBackendOverrideNoMatch ErrorCode = "backend_override_no_match"
@@ -26,11 +27,17 @@ const (
 	CodeCancelled              ErrorCode = "cancelled"
 	CodeTimeout                ErrorCode = "timeout"
 	CodeInternal               ErrorCode = "internal"
+	CodeApprovalRequired       ErrorCode = "approval_required"
+	CodeApprovalDenied         ErrorCode = "approval_denied"
+	CodeSamplingUnavailable    ErrorCode = "sampling_unavailable"
+	CodeSamplingBudget         ErrorCode = "sampling_budget_exhausted"
+	CodePathOutsideRoots       ErrorCode = "path_outside_roots"
 )
 
 // Sentinel errors for mapping
 var (
 	ErrToolNotFound           = errors.New("tool not found")
+	ErrToolNotActive          = errors.New("tool not in an active toolset")
 	ErrNoBackends             = errors.New("no backends available")
 	ErrBackendOverrideInvalid = errors.New("backend override invalid")
 	ErrBackendOverrideNoMatch = errors.New("backend override no match")
@@ -38,6 +45,11 @@ var (
 	ErrValidationOutput       = errors.New("output validation failed")
 	ErrStreamNotSupported     = errors.New("streaming not supported")
 	ErrExecution              = errors.New("execution failed")
+	ErrApprovalRequired       = errors.New("approval required")
+	ErrApprovalDenied         = errors.New("approval denied")
+	ErrSamplingUnavailable    = errors.New("sampling unavailable")
+	ErrSamplingBudget         = errors.New("sampling budget exhausted")
+	ErrPathOutsideRoots       = errors.New("path outside the client's roots")
 )
 
 // BackendInfo represents backend information for error context
@@ -59,6 +71,41 @@ func (e *ToolError) Unwrap() error {
 	return e.Err
 }
 
+// CanceledError reports a call that stopped because its context ended.
+type CanceledError struct {
+	// Reason is the context's cancellation cause.
+	Reason string
+	// Timeout is set when the context's deadline passed.
+	Timeout bool
+	Err     error
+}
+
+func (e *CanceledError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CanceledError) Unwrap() error {
+	return e.Err
+}
+
+// WrapCanceled records ctx's cancellation cause on err when ctx has ended,
+// so the cause survives backends that report cancellation as an execution
+// failure. Otherwise err is returned unchanged.
+func WrapCanceled(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+	var canceled *CanceledError
+	if errors.As(err, &canceled) {
+		return err
+	}
+	return &CanceledError{
+		Reason:  context.Cause(ctx).Error(),
+		Timeout: errors.Is(ctx.Err(), context.DeadlineExceeded),
+		Err:     err,
+	}
+}
+
 // ErrorObject is the structured error returned in metatool responses
 type ErrorObject struct {
 	Code        ErrorCode              `json:"code"`
@@ -79,6 +126,12 @@ func MapToolError(err error, toolID string, backend *BackendInfo, stepIndex int)
 		Message: err.Error(),
 	}
 
+	// Record why a canceled call stopped; later unwrapping may drop it.
+	var canceled *CanceledError
+	if errors.As(err, &canceled) {
+		result.Details = map[string]interface{}{"reason": canceled.Reason}
+	}
+
 	// Extract run.ToolError context when present.
 	var trErr *run.ToolError
 	if errors.As(err, &trErr) {
@@ -114,6 +167,12 @@ func MapToolError(err error, toolID string, backend *BackendInfo, stepIndex int)
 
 	// Map error to code
 	result.Code = mapErrorToCode(err)
+	if canceled != nil {
+		result.Code = CodeCancelled
+		if canceled.Timeout {
+			result.Code = CodeTimeout
+		}
+	}
 	result.Retryable = isRetryable(result.Code)
 
 	return result
@@ -121,6 +180,18 @@ func MapToolError(err error, toolID string, backend *BackendInfo, stepIndex int)
 
 func mapErrorToCode(err error) ErrorCode {
 	switch {
+	case errors.Is(err, ErrApprovalRequired):
+		return CodeApprovalRequired
+	case errors.Is(err, ErrApprovalDenied):
+		return CodeApprovalDenied
+	case errors.Is(err, ErrSamplingUnavailable):
+		return CodeSamplingUnavailable
+	case errors.Is(err, ErrSamplingBudget):
+		return CodeSamplingBudget
+	case errors.Is(err, ErrToolNotActive):
+		return CodeToolNotActive
+	case errors.Is(err, ErrPathOutsideRoots):
+		return CodePathOutsideRoots
 	case errors.Is(err, ErrToolNotFound) || errors.Is(err, run.ErrToolNotFound):
 		return CodeToolNotFound
 	case errors.Is(err, ErrNoBackends) || errors.Is(err, run.ErrNoBackends):
@@ -4,6 +4,7 @@ package errors
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -138,3 +139,58 @@ func TestMapToolError_SetsOp(t *testing.T) {
 	assert.NotNil(t, result.Op)
 	assert.Equal(t, "execute", *result.Op)
 }
+
+func TestMapToolError_Approval(t *testing.T) {
+	required := MapToolError(fmt.Errorf("%w: t:delete", ErrApprovalRequired), "t:delete", nil, -1)
+	assert.Equal(t, CodeApprovalRequired, required.Code)
+	assert.False(t, required.Retryable)
+
+	// Approval errors keep their code when wrapped by an execution failure.
+	denied := MapToolError(fmt.Errorf("%w: %w", ErrExecution, ErrApprovalDenied), "t:delete", nil, -1)
+	assert.Equal(t, CodeApprovalDenied, denied.Code)
+}
+
+func TestMapToolError_ToolNotActive(t *testing.T) {
+	result := MapToolError(fmt.Errorf("%w: gh:merge", ErrToolNotActive), "gh:merge", nil, -1)
+	assert.Equal(t, CodeToolNotActive, result.Code)
+	assert.False(t, result.Retryable)
+}
+
+func TestMapToolError_PathOutsideRoots(t *testing.T) {
+	result := MapToolError(fmt.Errorf("%w: args.path /etc/passwd", ErrPathOutsideRoots), "fs:read", nil, -1)
+	assert.Equal(t, CodePathOutsideRoots, result.Code)
+	assert.False(t, result.Retryable)
+}
+
+func TestMapToolError_Sampling(t *testing.T) {
+	unavailable := MapToolError(fmt.Errorf("%w: no client support", ErrSamplingUnavailable), "llm:sample", nil, -1)
+	assert.Equal(t, CodeSamplingUnavailable, unavailable.Code)
+	assert.False(t, unavailable.Retryable)
+
+	budget := MapToolError(fmt.Errorf("%w: 10 calls per run", ErrSamplingBudget), "llm:sample", nil, -1)
+	assert.Equal(t, CodeSamplingBudget, budget.Code)
+}
+
+func TestMapToolError_CancelReason(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(errors.New("canceled by cancel_job"))
+
+	// Backends may report cancellation as an execution failure.
+	err := WrapCanceled(ctx, fmt.Errorf("%w: context canceled", ErrExecution))
+	result := MapToolError(err, "mcp.github:search", nil, -1)
+	assert.Equal(t, CodeCancelled, result.Code)
+	assert.False(t, result.Retryable)
+	assert.Equal(t, "canceled by cancel_job", result.Details["reason"])
+
+	step := MapToolError(err, "", nil, 1)
+	assert.Equal(t, CodeChainStepFailed, step.Code)
+	assert.Equal(t, "canceled by cancel_job", step.Details["reason"])
+
+	timeoutCtx, cancelTimeout := context.WithTimeout(context.Background(), 0)
+	defer cancelTimeout()
+	<-timeoutCtx.Done()
+	assert.Equal(t, CodeTimeout, MapToolError(WrapCanceled(timeoutCtx, ErrExecution), "", nil, -1).Code)
+
+	live := errors.New("boom")
+	assert.Equal(t, live, WrapCanceled(context.Background(), live))
+}
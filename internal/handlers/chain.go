@@ -2,19 +2,31 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 
+	"github.com/jonwraymond/metatools-mcp/internal/dryrun"
 	merrors "github.com/jonwraymond/metatools-mcp/internal/errors"
+	"github.com/jonwraymond/metatools-mcp/internal/jobs"
+	"github.com/jonwraymond/metatools-mcp/internal/state/runs"
+	"github.com/jonwraymond/metatools-mcp/internal/stepgraph"
 	"github.com/jonwraymond/metatools-mcp/pkg/metatools"
 )
 
 // ChainHandler handles the run_chain metatool
 type ChainHandler struct {
 	runner Runner
+	tools  ToolLookup
+	runs   *RunRecorder
+	jobs   *jobs.Manager
 }
 
-// NewChainHandler creates a new chain handler
-func NewChainHandler(runner Runner) *ChainHandler {
-	return &ChainHandler{runner: runner}
+// NewChainHandler creates a new chain handler. It uses the WithTools,
+// WithRuns and WithJobs options.
+func NewChainHandler(runner Runner, opts ...Option) *ChainHandler {
+	o := applyOptions(opts)
+	return &ChainHandler{runner: runner, tools: o.tools, runs: o.runs, jobs: o.jobs}
 }
 
 // Handle executes the run_chain metatool
@@ -33,16 +45,85 @@ func (h *ChainHandler) HandleWithProgress(ctx context.Context, input metatools.R
 		return nil, false, err
 	}
 
+	if input.DryRun {
+		return h.dryRun(ctx, input)
+	}
+	if input.Async {
+		input.Async = false
+		id, err := submitJob(ctx, h.jobs, "run_chain", func(ctx context.Context) (any, bool, error) {
+			return h.HandleWithProgress(ctx, input, jobProgress(ctx))
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		return &metatools.RunChainOutput{Results: []metatools.ChainStepResult{}, JobID: id}, false, nil
+	}
+	if h.runs == nil {
+		return h.execute(ctx, input, onProgress, nil, nil)
+	}
+
+	rec, err := h.runs.begin(ctx, runs.KindChain, input, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	output, isError, err := h.execute(rec.ctx, input, onProgress, rec, nil)
+	rec.finish(runOutcome(err, isError, chainError(output)))
+	if output != nil {
+		output.RunID = rec.id
+	}
+	return output, isError, err
+}
+
+// resume continues a recorded chain run from its first incomplete step.
+func (h *ChainHandler) resume(rec *recording, run runs.Run) (*metatools.RunChainOutput, bool, error) {
+	var input metatools.RunChainInput
+	if err := json.Unmarshal(run.Request, &input); err != nil {
+		err = fmt.Errorf("decode run request: %w", err)
+		rec.finish(err)
+		return nil, false, err
+	}
+	done := make(map[int]any)
+	for _, step := range run.Steps {
+		if step.Status == runs.StatusSucceeded {
+			done[step.Index] = step.Value
+		}
+	}
+	output, isError, err := h.execute(rec.ctx, input, nil, rec, done)
+	rec.finish(runOutcome(err, isError, chainError(output)))
+	if output != nil {
+		output.RunID = rec.id
+	}
+	return output, isError, err
+}
+
+// execute runs the chain. With a recording, each step is recorded as it
+// finishes and steps in done, keyed by index, are replayed.
+func (h *ChainHandler) execute(ctx context.Context, input metatools.RunChainInput, onProgress func(ProgressEvent), rec *recording, done map[int]any) (*metatools.RunChainOutput, bool, error) {
 	includeBackends := input.GetIncludeBackends()
 	includeTools := input.GetIncludeTools()
 
+	// Replay, per-step records and partial job output need a runner that
+	// reports each step.
+	report := jobs.Report(ctx)
+	checkpointRunner, checkpoint := h.runner.(CheckpointRunner)
+	checkpoint = checkpoint && (rec != nil || report != nil)
+
 	// Convert input steps to handler steps
 	steps := make([]ChainStep, len(input.Steps))
 	for i, s := range input.Steps {
 		steps[i] = ChainStep{
+			ID:          s.ID,
 			ToolID:      s.ToolID,
 			Args:        s.Args,
 			UsePrevious: s.UsePrevious,
+			DependsOn:   s.DependsOn,
+			OnError:     stepPolicy(s.OnError),
+		}
+		if rec != nil {
+			steps[i].Args = rec.withIdempotencyKey(ctx, s.ToolID, s.Args, stepKey(s.ID, i))
+		}
+		if value, ok := done[i]; ok && checkpoint && rec != nil {
+			steps[i].Recorded = &StepResult{Index: i, StepID: s.ID, ToolID: s.ToolID, Structured: value}
 		}
 	}
 
@@ -50,7 +131,22 @@ func (h *ChainHandler) HandleWithProgress(ctx context.Context, input metatools.R
 	var finalResult RunResult
 	var stepResults []StepResult
 	var chainErr error
-	if onProgress != nil {
+	if checkpoint {
+		var partial []metatools.ChainStepResult
+		finalResult, stepResults, chainErr = checkpointRunner.RunChainWithCheckpoint(ctx, steps, onProgress, func(i int, sr StepResult) {
+			if rec != nil {
+				rec.step(i, sr.StepID, sr.ToolID, sr.Structured, sr.Error)
+			}
+			if report != nil {
+				step := metatools.ChainStepResult{ID: sr.StepID, ToolID: sr.ToolID, Structured: sr.Structured, Content: sr.Content}
+				if sr.Error != nil {
+					step.Error = stepErrorObject(sr.Error, sr.ToolID)
+				}
+				partial = append(partial, step)
+				report.Partial(append([]metatools.ChainStepResult(nil), partial...))
+			}
+		})
+	} else if onProgress != nil {
 		if progressRunner, ok := h.runner.(ProgressRunner); ok {
 			finalResult, stepResults, chainErr = progressRunner.RunChainWithProgress(ctx, steps, onProgress)
 		} else {
@@ -72,8 +168,11 @@ func (h *ChainHandler) HandleWithProgress(ctx context.Context, input metatools.R
 
 	for i, sr := range stepResults {
 		results[i] = metatools.ChainStepResult{
+			ID:         sr.StepID,
 			ToolID:     sr.ToolID,
 			Structured: sr.Structured,
+			Policy:     stepPolicyResult(sr.Policy, sr.ToolID),
+			Content:    sr.Content,
 		}
 
 		// Include optional fields based on input flags
@@ -84,22 +183,13 @@ func (h *ChainHandler) HandleWithProgress(ctx context.Context, input metatools.R
 			results[i].Tool = sr.Tool
 		}
 
-		// Track failing step
+		// Track the first failing step; errors kept by on_error continue do
+		// not fail the chain.
 		if sr.Error != nil {
-			failingStepIndex = i
-			errObj := merrors.MapToolError(sr.Error, sr.ToolID, nil, -1)
-			results[i].Error = &metatools.ErrorObject{
-				Code:      string(errObj.Code),
-				Message:   errObj.Message,
-				ToolID:    sr.ToolID,
-				Retryable: errObj.Retryable,
-			}
-			if errObj.Op != nil {
-				results[i].Error.Op = errObj.Op
-			}
-			if errObj.BackendKind != nil {
-				results[i].Error.BackendKind = errObj.BackendKind
+			if failingStepIndex < 0 && !sr.Policy.Continued() {
+				failingStepIndex = sr.Index
 			}
+			results[i].Error = stepErrorObject(sr.Error, sr.ToolID)
 		}
 	}
 
@@ -109,27 +199,7 @@ func (h *ChainHandler) HandleWithProgress(ctx context.Context, input metatools.R
 
 	// Handle chain error
 	if chainErr != nil {
-		// Map the chain error
-		errObj := merrors.MapToolError(chainErr, "", nil, failingStepIndex)
-
-		// Get the underlying cause code
-		causeErrObj := merrors.MapToolError(chainErr, "", nil, -1)
-
-		output.Error = &metatools.ErrorObject{
-			Code:      string(errObj.Code),
-			Message:   errObj.Message,
-			StepIndex: errObj.StepIndex,
-			Retryable: errObj.Retryable,
-			Details: map[string]any{
-				"cause_code": string(causeErrObj.Code),
-			},
-		}
-		if causeErrObj.Op != nil {
-			output.Error.Details["cause_op"] = *causeErrObj.Op
-		}
-		if causeErrObj.BackendKind != nil {
-			output.Error.Details["cause_backend_kind"] = *causeErrObj.BackendKind
-		}
+		output.Error = chainErrorObject(chainErr, failingStepIndex)
 
 		// Set final to last successful structured value if any
 		for i := len(stepResults) - 1; i >= 0; i-- {
@@ -147,3 +217,126 @@ func (h *ChainHandler) HandleWithProgress(ctx context.Context, input metatools.R
 
 	return output, false, nil
 }
+
+// dryRun walks the chain without calling any tool and returns the would-be
+// execution trace.
+func (h *ChainHandler) dryRun(ctx context.Context, input metatools.RunChainInput) (*metatools.RunChainOutput, bool, error) {
+	if h.tools == nil {
+		return nil, false, errors.New("dry_run requires a tool index")
+	}
+	specs := make([]stepgraph.Spec, len(input.Steps))
+	steps := make([]dryrun.Step, len(input.Steps))
+	for i, s := range input.Steps {
+		policy := stepPolicy(s.OnError)
+		specs[i] = stepgraph.Spec{
+			ID:          s.ID,
+			DependsOn:   s.DependsOn,
+			Args:        s.Args,
+			UsePrevious: s.UsePrevious,
+			OnError:     policy,
+		}
+		steps[i] = dryrun.Step{
+			ID:          s.ID,
+			ToolID:      s.ToolID,
+			Args:        s.Args,
+			UsePrevious: s.UsePrevious,
+			OnError:     policy,
+		}
+	}
+	output := &metatools.RunChainOutput{Results: []metatools.ChainStepResult{}}
+	graph, err := stepgraph.Build(specs)
+	if err != nil {
+		output.Error = chainErrorObject(err, -1)
+		return output, true, nil
+	}
+
+	traces, err := dryrun.Run(ctx, graph, steps, dryrun.Options{Lookup: h.tools.GetTool, Mocks: input.Mocks})
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, false, ctxErr
+	}
+	output.Trace = dryRunTrace(traces)
+	failingStepIndex := -1
+	for _, tr := range traces {
+		if tr.Err != nil && failingStepIndex < 0 {
+			failingStepIndex = tr.Index
+		}
+		if tr.Source != dryrun.SourceUnknown {
+			output.Final = tr.Output
+		}
+	}
+	if err != nil {
+		output.Error = chainErrorObject(err, failingStepIndex)
+		return output, true, nil
+	}
+	return output, false, nil
+}
+
+// dryRunTrace converts dry-run traces to their wire form.
+func dryRunTrace(traces []dryrun.StepTrace) []metatools.DryRunStep {
+	out := make([]metatools.DryRunStep, len(traces))
+	for i, tr := range traces {
+		out[i] = metatools.DryRunStep{
+			ID:           tr.ID,
+			ToolID:       tr.ToolID,
+			Args:         tr.Args,
+			Output:       tr.Output,
+			OutputSource: tr.Source,
+			Note:         tr.Note,
+		}
+		if tr.Err != nil {
+			out[i].Error = stepErrorObject(tr.Err, tr.ToolID)
+		}
+	}
+	return out
+}
+
+// stepErrorObject maps a single step's error.
+func stepErrorObject(err error, toolID string) *metatools.ErrorObject {
+	errObj := merrors.MapToolError(err, toolID, nil, -1)
+	out := &metatools.ErrorObject{
+		Code:      string(errObj.Code),
+		Message:   errObj.Message,
+		ToolID:    toolID,
+		Retryable: errObj.Retryable,
+		Details:   errObj.Details,
+	}
+	if errObj.Op != nil {
+		out.Op = errObj.Op
+	}
+	if errObj.BackendKind != nil {
+		out.BackendKind = errObj.BackendKind
+	}
+	return out
+}
+
+// chainErrorObject maps a chain error, recording the underlying cause.
+func chainErrorObject(err error, failingStepIndex int) *metatools.ErrorObject {
+	errObj := merrors.MapToolError(err, "", nil, failingStepIndex)
+	causeErrObj := merrors.MapToolError(err, "", nil, -1)
+	out := &metatools.ErrorObject{
+		Code:      string(errObj.Code),
+		Message:   errObj.Message,
+		StepIndex: errObj.StepIndex,
+		Retryable: errObj.Retryable,
+		Details: map[string]any{
+			"cause_code": string(causeErrObj.Code),
+		},
+	}
+	if causeErrObj.Op != nil {
+		out.Details["cause_op"] = *causeErrObj.Op
+	}
+	if causeErrObj.BackendKind != nil {
+		out.Details["cause_backend_kind"] = *causeErrObj.BackendKind
+	}
+	if reason, ok := causeErrObj.Details["reason"]; ok {
+		out.Details["reason"] = reason
+	}
+	return out
+}
+
+func chainError(output *metatools.RunChainOutput) *metatools.ErrorObject {
+	if output == nil {
+		return nil
+	}
+	return output.Error
+}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	merrors "github.com/jonwraymond/metatools-mcp/internal/errors"
+	"github.com/jonwraymond/metatools-mcp/internal/stepgraph"
 	"github.com/jonwraymond/metatools-mcp/pkg/metatools"
+	"github.com/jonwraymond/toolfoundation/model"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -257,9 +261,9 @@ func TestRunChain_ErrorHasStepIndex(t *testing.T) {
 	runner := &mockRunner{
 		runChainFunc: func(_ context.Context, _ []ChainStep) (RunResult, []StepResult, error) {
 			return RunResult{}, []StepResult{
-				{ToolID: "step1", Structured: map[string]any{"s": 1}},
-				{ToolID: "step2", Structured: map[string]any{"s": 2}},
-				{ToolID: "step3", Error: merrors.ErrExecution},
+				{Index: 0, ToolID: "step1", Structured: map[string]any{"s": 1}},
+				{Index: 1, ToolID: "step2", Structured: map[string]any{"s": 2}},
+				{Index: 2, ToolID: "step3", Error: merrors.ErrExecution},
 			}, merrors.ErrExecution
 		},
 	}
@@ -282,6 +286,31 @@ func TestRunChain_ErrorHasStepIndex(t *testing.T) {
 	assert.Equal(t, 2, *result.Error.StepIndex)
 }
 
+func TestRunChain_ErrorStepIndexIsDeclaredIndex(t *testing.T) {
+	runner := &mockRunner{
+		runChainFunc: func(_ context.Context, _ []ChainStep) (RunResult, []StepResult, error) {
+			// The first declared step depends on the second, so it finishes
+			// last.
+			return RunResult{}, []StepResult{
+				{Index: 1, StepID: "fetch", ToolID: "t:fetch", Structured: "data"},
+				{Index: 0, StepID: "report", ToolID: "t:report", Error: merrors.ErrExecution},
+			}, merrors.ErrExecution
+		},
+	}
+
+	handler := NewChainHandler(runner)
+	result, isError, err := handler.Handle(context.Background(), metatools.RunChainInput{
+		Steps: []metatools.ChainStep{
+			{ID: "report", ToolID: "t:report", DependsOn: []string{"fetch"}},
+			{ID: "fetch", ToolID: "t:fetch"},
+		},
+	})
+	require.NoError(t, err)
+	assert.True(t, isError)
+	require.NotNil(t, result.Error.StepIndex)
+	assert.Equal(t, 0, *result.Error.StepIndex)
+}
+
 func TestRunChain_ErrorHasCauseDetails(t *testing.T) {
 	runner := &mockRunner{
 		runChainFunc: func(_ context.Context, _ []ChainStep) (RunResult, []StepResult, error) {
@@ -318,3 +347,150 @@ func TestRunChain_EmptySteps(t *testing.T) {
 	_, _, err := handler.Handle(context.Background(), input)
 	assert.Error(t, err)
 }
+
+func TestRunChain_PassesStepIDs(t *testing.T) {
+	runner := &mockRunner{
+		runChainFunc: func(_ context.Context, steps []ChainStep) (RunResult, []StepResult, error) {
+			require.Len(t, steps, 2)
+			assert.Equal(t, "search", steps[0].ID)
+			assert.Empty(t, steps[1].ID)
+			return RunResult{}, []StepResult{
+				{StepID: "search", ToolID: "a"},
+				{ToolID: "b"},
+			}, nil
+		},
+	}
+
+	handler := NewChainHandler(runner)
+	result, _, err := handler.Handle(context.Background(), metatools.RunChainInput{
+		Steps: []metatools.ChainStep{{ID: "search", ToolID: "a"}, {ToolID: "b"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "search", result.Results[0].ID)
+	assert.Empty(t, result.Results[1].ID)
+}
+
+func TestRunChain_DuplicateStepIDs(t *testing.T) {
+	handler := NewChainHandler(&mockRunner{})
+	_, _, err := handler.Handle(context.Background(), metatools.RunChainInput{
+		Steps: []metatools.ChainStep{{ID: "x", ToolID: "a"}, {ID: "x", ToolID: "b"}},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `duplicate step id "x"`)
+}
+
+func TestRunChain_ErrorPolicies(t *testing.T) {
+	cause := errors.New("primary down")
+	runner := &mockRunner{
+		runChainFunc: func(_ context.Context, steps []ChainStep) (RunResult, []StepResult, error) {
+			require.Len(t, steps, 3)
+			assert.Equal(t, &stepgraph.Policy{Action: "retry", Attempts: 2, Backoff: 50 * time.Millisecond}, steps[0].OnError)
+			boom := errors.New("boom")
+			return RunResult{}, []StepResult{
+				{ToolID: "a", Structured: "ok", Policy: &stepgraph.Report{Action: "retry", Attempts: 2, Cause: cause}},
+				{Index: 1, ToolID: "b", Error: cause, Policy: &stepgraph.Report{Action: "continue", Cause: cause}},
+				{Index: 2, ToolID: "c", Error: boom},
+			}, boom
+		},
+	}
+
+	handler := NewChainHandler(runner)
+	result, isError, err := handler.Handle(context.Background(), metatools.RunChainInput{
+		Steps: []metatools.ChainStep{
+			{ToolID: "a", OnError: &metatools.StepOnError{Action: "retry", Attempts: 2, BackoffMs: 50}},
+			{ToolID: "b", OnError: &metatools.StepOnError{Action: "continue"}},
+			{ToolID: "c"},
+		},
+	})
+	require.NoError(t, err)
+	assert.True(t, isError)
+
+	require.NotNil(t, result.Results[0].Policy)
+	assert.Equal(t, 2, result.Results[0].Policy.Attempts)
+	require.NotNil(t, result.Results[0].Policy.Cause)
+	assert.Equal(t, "primary down", result.Results[0].Policy.Cause.Message)
+	assert.Equal(t, "continue", result.Results[1].Policy.Action)
+	require.NotNil(t, result.Results[1].Error)
+
+	// The continued step is not the failing step.
+	require.NotNil(t, result.Error.StepIndex)
+	assert.Equal(t, 2, *result.Error.StepIndex)
+}
+
+type stubToolLookup map[string]model.Tool
+
+func (s stubToolLookup) GetTool(_ context.Context, id string) (model.Tool, error) {
+	tool, ok := s[id]
+	if !ok {
+		return model.Tool{}, errors.New("not found")
+	}
+	return tool, nil
+}
+
+func dryRunTools() stubToolLookup {
+	return stubToolLookup{
+		"t:find": {Namespace: "t", Tool: mcp.Tool{
+			Name:        "find",
+			InputSchema: map[string]any{"type": "object"},
+			OutputSchema: map[string]any{"type": "object", "properties": map[string]any{
+				"ids": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			}},
+		}},
+		"t:use": {Namespace: "t", Tool: mcp.Tool{
+			Name: "use",
+			InputSchema: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"id": map[string]any{"type": "string"}},
+				"required":   []any{"id"},
+			},
+		}},
+	}
+}
+
+func TestRunChain_DryRun(t *testing.T) {
+	runner := &mockRunner{
+		runChainFunc: func(_ context.Context, _ []ChainStep) (RunResult, []StepResult, error) {
+			t.Fatal("dry run must not execute the chain")
+			return RunResult{}, nil, nil
+		},
+	}
+	handler := NewChainHandler(runner, WithTools(dryRunTools()))
+
+	result, isError, err := handler.Handle(context.Background(), metatools.RunChainInput{
+		DryRun: true,
+		Mocks:  map[string]any{"t:find": map[string]any{"ids": []any{"x1"}}},
+		Steps: []metatools.ChainStep{
+			{ID: "find", ToolID: "t:find"},
+			{ToolID: "t:use", Args: map[string]any{"id": map[string]any{"$ref": "steps.find.structured.ids[0]"}}},
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, isError, "unexpected error: %+v", result.Error)
+	require.Len(t, result.Trace, 2)
+	assert.Equal(t, "mock", result.Trace[0].OutputSource)
+	assert.Equal(t, map[string]any{"id": "x1"}, result.Trace[1].Args)
+	assert.Equal(t, "unknown", result.Trace[1].OutputSource)
+	assert.Equal(t, map[string]any{"ids": []any{"x1"}}, result.Final)
+
+	result, isError, err = handler.Handle(context.Background(), metatools.RunChainInput{
+		DryRun: true,
+		Steps: []metatools.ChainStep{
+			{ToolID: "t:find"},
+			{ToolID: "t:use", Args: map[string]any{"id": 1}},
+			{ToolID: "t:missing"},
+		},
+	})
+	require.NoError(t, err)
+	assert.True(t, isError)
+	require.NotNil(t, result.Error)
+	require.NotNil(t, result.Error.StepIndex)
+	assert.Equal(t, 1, *result.Error.StepIndex)
+	assert.Equal(t, "validation_input", result.Trace[1].Error.Code)
+	assert.Equal(t, "tool_not_found", result.Trace[2].Error.Code)
+
+	_, _, err = NewChainHandler(runner).Handle(context.Background(), metatools.RunChainInput{
+		DryRun: true,
+		Steps:  []metatools.ChainStep{{ToolID: "t:find"}},
+	})
+	require.Error(t, err)
+}
@@ -3,18 +3,23 @@ package handlers
 import (
 	"context"
 	"errors"
+	"unicode/utf8"
 
+	"github.com/jonwraymond/metatools-mcp/internal/jobs"
+	"github.com/jonwraymond/metatools-mcp/internal/mcplog"
 	"github.com/jonwraymond/metatools-mcp/pkg/metatools"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 // CodeHandler handles the execute_code metatool
 type CodeHandler struct {
 	executor Executor
+	jobs     *jobs.Manager
 }
 
-// NewCodeHandler creates a new code handler
-func NewCodeHandler(executor Executor) *CodeHandler {
-	return &CodeHandler{executor: executor}
+// NewCodeHandler creates a new code handler. It uses the WithJobs option.
+func NewCodeHandler(executor Executor, opts ...Option) *CodeHandler {
+	return &CodeHandler{executor: executor, jobs: applyOptions(opts).jobs}
 }
 
 // Handle executes the execute_code metatool
@@ -26,6 +31,23 @@ func (h *CodeHandler) Handle(ctx context.Context, input metatools.ExecuteCodeInp
 	if input.Code == "" {
 		return nil, errors.New("code is required")
 	}
+	if input.Async {
+		input.Async = false
+		id, err := submitJob(ctx, h.jobs, "execute_code", func(ctx context.Context) (any, bool, error) {
+			progress := jobProgress(ctx)
+			progress(ProgressEvent{Progress: 0, Total: 1, Message: "started"})
+			out, err := h.Handle(ctx, input)
+			if err != nil {
+				return nil, false, err
+			}
+			progress(ProgressEvent{Progress: 1, Total: 1, Message: "completed"})
+			return out, false, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &metatools.ExecuteCodeOutput{JobID: id}, nil
+	}
 
 	// Build execution params
 	params := ExecuteParams{
@@ -45,6 +67,10 @@ func (h *CodeHandler) Handle(ctx context.Context, input metatools.ExecuteCodeInp
 	if err != nil {
 		return nil, err
 	}
+	// Surface program output in the client's log as well as the result. It
+	// stays out of the process log, since programs may print secrets.
+	logOutput(ctx, "stdout", result.Stdout)
+	logOutput(ctx, "stderr", result.Stderr)
 
 	return &metatools.ExecuteCodeOutput{
 		Value:      result.Value,
@@ -53,3 +79,25 @@ func (h *CodeHandler) Handle(ctx context.Context, input metatools.ExecuteCodeInp
 		DurationMs: result.DurationMs,
 	}, nil
 }
+
+// maxLoggedOutput caps the bytes of each program output stream sent to the
+// client's log; the result carries the full output.
+const maxLoggedOutput = 16 << 10
+
+// logOutput sends a program output stream to the calling session's log, if
+// the call has one, cut to maxLoggedOutput bytes.
+func logOutput(ctx context.Context, stream, output string) {
+	if output == "" {
+		return
+	}
+	data := map[string]any{"msg": "execute_code output", "stream": stream, "output": output}
+	if len(output) > maxLoggedOutput {
+		cut := maxLoggedOutput
+		for cut > 0 && !utf8.RuneStart(output[cut]) {
+			cut--
+		}
+		data["output"] = output[:cut]
+		data["truncated_bytes"] = len(output) - cut
+	}
+	mcplog.Relay(ctx, &mcp.LoggingMessageParams{Level: "info", Logger: mcplog.LoggerName, Data: data})
+}
@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"reflect"
 
+	"github.com/jonwraymond/metatools-mcp/internal/jsonshape"
 	"github.com/jonwraymond/metatools-mcp/pkg/metatools"
 )
 
@@ -42,6 +44,10 @@ func (h *DescribeHandler) Handle(ctx context.Context, input metatools.DescribeTo
 		return nil, err
 	}
 
+	if input.DetailLevel == "compact" {
+		return h.compact(ctx, input)
+	}
+
 	// Get documentation from store
 	doc, err := h.store.DescribeTool(ctx, input.ToolID, input.DetailLevel)
 	if err != nil {
@@ -65,3 +71,81 @@ func (h *DescribeHandler) Handle(ctx context.Context, input metatools.DescribeTo
 
 	return output, nil
 }
+
+// compact renders a TypeScript-like signature in place of raw JSON Schema.
+// When MaxTokens is set, detail is dropped in a fixed order until the output
+// fits: field docs, nested fields, examples, notes, external refs, and
+// finally optional fields. Each dropped category is listed in Elided.
+func (h *DescribeHandler) compact(ctx context.Context, input metatools.DescribeToolInput) (*metatools.DescribeToolOutput, error) {
+	doc, err := h.store.DescribeTool(ctx, input.ToolID, "full")
+	if err != nil {
+		return nil, err
+	}
+
+	tool, _ := jsonshape.Map(nilIfTypedNil(doc.Tool))
+	inputSchema := asSchema(tool["inputSchema"])
+	outputSchema := asSchema(tool["outputSchema"])
+	opts := fullSignature
+
+	output := &metatools.DescribeToolOutput{
+		Summary:      doc.Summary,
+		Notes:        doc.Notes,
+		Examples:     doc.Examples,
+		ExternalRefs: doc.ExternalRefs,
+	}
+	if input.ExamplesMax != nil && len(output.Examples) > *input.ExamplesMax {
+		output.Examples = output.Examples[:*input.ExamplesMax]
+	}
+	render := func() int {
+		output.Signature = renderSignature(input.ToolID, inputSchema, outputSchema, opts)
+		output.TokenEstimate = estimateTokens(output)
+		return output.TokenEstimate
+	}
+	tokens := render()
+	if input.MaxTokens == nil {
+		return output, nil
+	}
+
+	stages := []struct {
+		name  string
+		apply func()
+	}{
+		{"field_descriptions", func() { opts.descriptions = false }},
+		{"nested_fields", func() { opts.maxDepth = 0 }},
+		{"examples", func() { output.Examples = nil }},
+		{"notes", func() { output.Notes = nil }},
+		{"external_refs", func() { output.ExternalRefs = nil }},
+		{"optional_fields", func() { opts.optional = false }},
+	}
+	for _, stage := range stages {
+		if tokens <= *input.MaxTokens {
+			break
+		}
+		before := contentTokens(output)
+		stage.apply()
+		render()
+		if contentTokens(output) < before {
+			output.Elided = append(output.Elided, stage.name)
+		}
+		tokens = render()
+	}
+	return output, nil
+}
+
+// contentTokens estimates output size excluding budget bookkeeping fields,
+// so a stage only counts as elided when it removed content.
+func contentTokens(output *metatools.DescribeToolOutput) int {
+	content := *output
+	content.Elided = nil
+	content.TokenEstimate = 0
+	return estimateTokens(content)
+}
+
+// estimateTokens approximates the token cost of v at ~4 bytes per token.
+func estimateTokens(v any) int {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return (len(data) + 3) / 4
+}
@@ -271,3 +271,87 @@ func TestDescribeTool_InvalidDetailLevel(t *testing.T) {
 	_, err := handler.Handle(context.Background(), input)
 	assert.Error(t, err)
 }
+
+func compactStore(t *testing.T) *mockStore {
+	notes := "Long usage notes that explain pagination, auth scopes and error semantics in detail."
+	return &mockStore{
+		describeToolFunc: func(_ context.Context, _ string, level string) (ToolDoc, error) {
+			assert.Equal(t, "full", level)
+			return ToolDoc{
+				Summary: "Create a pull request",
+				Tool: map[string]any{
+					"name":        "create_pull_request",
+					"inputSchema": prSchema(),
+				},
+				Notes: &notes,
+				Examples: []metatools.ToolExample{
+					{Title: "Draft", Args: map[string]any{"repo": "acme/api", "draft": true}},
+				},
+				ExternalRefs: []string{"https://docs.github.com/rest/pulls"},
+			}, nil
+		},
+	}
+}
+
+func TestDescribeTool_Compact(t *testing.T) {
+	handler := NewDescribeHandler(compactStore(t))
+
+	result, err := handler.Handle(context.Background(), metatools.DescribeToolInput{
+		ToolID:      "github:create_pull_request",
+		DetailLevel: "compact",
+	})
+	require.NoError(t, err)
+	assert.Nil(t, result.Tool)
+	assert.Nil(t, result.SchemaInfo)
+	assert.Contains(t, result.Signature, "github:create_pull_request(args: {")
+	assert.Contains(t, result.Signature, "draft?: boolean = false; // Open as draft")
+	assert.Len(t, result.Examples, 1)
+	assert.Empty(t, result.Elided)
+	assert.Positive(t, result.TokenEstimate)
+}
+
+func TestDescribeTool_CompactMaxTokens(t *testing.T) {
+	handler := NewDescribeHandler(compactStore(t))
+
+	full, err := handler.Handle(context.Background(), metatools.DescribeToolInput{
+		ToolID:      "github:create_pull_request",
+		DetailLevel: "compact",
+	})
+	require.NoError(t, err)
+
+	budget := full.TokenEstimate - 3
+	result, err := handler.Handle(context.Background(), metatools.DescribeToolInput{
+		ToolID:      "github:create_pull_request",
+		DetailLevel: "compact",
+		MaxTokens:   &budget,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"field_descriptions"}, result.Elided)
+	assert.LessOrEqual(t, result.TokenEstimate, budget)
+	assert.NotContains(t, result.Signature, "//")
+	assert.Len(t, result.Examples, 1)
+
+	tiny := 1
+	result, err = handler.Handle(context.Background(), metatools.DescribeToolInput{
+		ToolID:      "github:create_pull_request",
+		DetailLevel: "compact",
+		MaxTokens:   &tiny,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"field_descriptions", "nested_fields", "examples", "notes", "external_refs", "optional_fields"}, result.Elided)
+	assert.Empty(t, result.Examples)
+	assert.Nil(t, result.Notes)
+	assert.Contains(t, result.Signature, "repo: string;")
+	assert.Contains(t, result.Signature, "// ...5 optional")
+}
+
+func TestDescribeTool_MaxTokensRequiresCompact(t *testing.T) {
+	handler := NewDescribeHandler(&mockStore{})
+	budget := 100
+	_, err := handler.Handle(context.Background(), metatools.DescribeToolInput{
+		ToolID:      "test.tool",
+		DetailLevel: "full",
+		MaxTokens:   &budget,
+	})
+	assert.Error(t, err)
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/jonwraymond/metatools-mcp/internal/skills"
+	"github.com/jonwraymond/metatools-mcp/internal/stepgraph"
 	"github.com/jonwraymond/metatools-mcp/internal/toolset"
 	"github.com/jonwraymond/metatools-mcp/pkg/metatools"
 	"github.com/jonwraymond/toolfoundation/model"
@@ -22,6 +23,12 @@ type Index interface {
 	GetAllBackends(ctx context.Context, id string) ([]model.ToolBackend, error)
 }
 
+// ToolLookup resolves a tool ID to its definition. Dry runs use it to check
+// tools and schemas without executing anything.
+type ToolLookup interface {
+	GetTool(ctx context.Context, id string) (model.Tool, error)
+}
+
 // Refresher optionally triggers backend refreshes before discovery operations.
 type Refresher interface {
 	MaybeRefresh(ctx context.Context) error
@@ -55,13 +62,26 @@ type RunResult struct {
 	Tool       any
 	MCPResult  any
 	DurationMs int
+	// Content holds the tool result's non-text MCP content blocks (images,
+	// audio, resources), passed through as native content.
+	Content []any
 }
 
 // ChainStep represents a chain step input
 type ChainStep struct {
+	// ID optionally names the step for references from later steps.
+	ID          string
 	ToolID      string
 	Args        map[string]any
 	UsePrevious bool
+	// DependsOn lists step IDs that must finish first. When any step sets it,
+	// the chain runs as a dependency graph instead of strictly in order.
+	DependsOn []string
+	// OnError is the step's error policy; nil fails the chain.
+	OnError *stepgraph.Policy
+	// Recorded, when set, is the step's result from an earlier attempt of
+	// the same run. The runner replays it instead of calling the tool.
+	Recorded *StepResult
 }
 
 // ProgressEvent represents a progress update during execution.
@@ -73,11 +93,19 @@ type ProgressEvent struct {
 
 // StepResult represents a step result
 type StepResult struct {
+	// Index is the step's position in the declared chain; results are
+	// listed in the order steps finish.
+	Index      int
+	StepID     string
 	ToolID     string
 	Structured any
 	Backend    any
 	Tool       any
 	Error      error
+	// Policy reports how the step's on_error policy was applied, if at all.
+	Policy *stepgraph.Report
+	// Content holds the step's non-text MCP content blocks.
+	Content []any
 }
 
 // Runner provides tool execution.
@@ -99,6 +127,16 @@ type ProgressRunner interface {
 	RunChainWithProgress(ctx context.Context, steps []ChainStep, onProgress func(ProgressEvent)) (RunResult, []StepResult, error)
 }
 
+// CheckpointRunner is an optional interface for runners that report each
+// chain step as it finishes, so run records survive a restart mid-chain.
+//
+// Contract:
+// - onStep is called in topological order and never concurrently; index is
+// the step's position in steps.
+type CheckpointRunner interface {
+	RunChainWithCheckpoint(ctx context.Context, steps []ChainStep, onProgress func(ProgressEvent), onStep func(index int, result StepResult)) (RunResult, []StepResult, error)
+}
+
 // ExecuteParams represents code execution parameters
 type ExecuteParams struct {
 	Language     string
@@ -141,4 +179,7 @@ type SkillDefaults struct {
 	MaxSteps     int
 	MaxToolCalls int
 	Timeout      time.Duration
+	// MaxParallel bounds concurrently running steps; zero uses
+	// stepgraph.DefaultMaxParallel.
+	MaxParallel int
 }
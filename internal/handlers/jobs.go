@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jonwraymond/metatools-mcp/internal/jobs"
+	"github.com/jonwraymond/metatools-mcp/pkg/metatools"
+)
+
+// errAsyncNotConfigured is returned for async calls when no job manager is
+// configured.
+var errAsyncNotConfigured = errors.New("async jobs not configured")
+
+// JobsHandler handles the get_job, list_jobs and cancel_job metatools. Jobs
+// are scoped to the caller's authenticated principal, or to its MCP session
+// without one, see jobs.Owner.
+type JobsHandler struct {
+	jobs *jobs.Manager
+}
+
+// NewJobsHandler creates a jobs handler.
+func NewJobsHandler(manager *jobs.Manager) *JobsHandler {
+	return &JobsHandler{jobs: manager}
+}
+
+// Get handles get_job.
+func (h *JobsHandler) Get(ctx context.Context, input metatools.JobIDInput) (*metatools.JobOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	job, err := h.jobs.Get(jobs.Owner(ctx), input.JobID)
+	if err != nil {
+		return nil, err
+	}
+	return &metatools.JobOutput{Job: jobRecord(job, true)}, nil
+}
+
+// List handles list_jobs.
+func (h *JobsHandler) List(ctx context.Context, input metatools.ListJobsInput) (*metatools.ListJobsOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	out := &metatools.ListJobsOutput{Jobs: []metatools.Job{}}
+	for _, job := range h.jobs.List(jobs.Owner(ctx)) {
+		if input.Status != "" && job.Status != input.Status {
+			continue
+		}
+		out.Jobs = append(out.Jobs, jobRecord(job, false))
+	}
+	return out, nil
+}
+
+// Cancel handles cancel_job.
+func (h *JobsHandler) Cancel(ctx context.Context, input metatools.JobIDInput) (*metatools.JobOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	job, err := h.jobs.Cancel(ctx, jobs.Owner(ctx), input.JobID)
+	if err != nil {
+		return nil, err
+	}
+	return &metatools.JobOutput{Job: jobRecord(job, true)}, nil
+}
+
+// submitJob runs fn as a job of kind owned by the caller and returns its ID.
+func submitJob(ctx context.Context, manager *jobs.Manager, kind string, fn jobs.Func) (string, error) {
+	if manager == nil {
+		return "", errAsyncNotConfigured
+	}
+	job, err := manager.Submit(ctx, kind, jobs.Owner(ctx), fn)
+	if err != nil {
+		return "", err
+	}
+	return job.ID, nil
+}
+
+// jobProgress forwards progress events to the job running with ctx.
+func jobProgress(ctx context.Context) func(ProgressEvent) {
+	report := jobs.Report(ctx)
+	return func(ev ProgressEvent) {
+		report.Progress(jobs.Progress{Progress: ev.Progress, Total: ev.Total, Message: ev.Message})
+	}
+}
+
+func jobRecord(job jobs.Job, withOutput bool) metatools.Job {
+	out := metatools.Job{
+		JobID:     job.ID,
+		Kind:      job.Kind,
+		Status:    job.Status,
+		IsError:   job.IsError,
+		Error:     job.Error,
+		CreatedAt: job.CreatedAt.Format(time.RFC3339Nano),
+	}
+	if job.Progress != nil {
+		out.Progress = &metatools.JobProgress{
+			Progress: job.Progress.Progress,
+			Total:    job.Progress.Total,
+			Message:  job.Progress.Message,
+		}
+	}
+	if withOutput {
+		out.Partial = job.Partial
+		out.Result = job.Result
+	}
+	if !job.StartedAt.IsZero() {
+		out.StartedAt = job.StartedAt.Format(time.RFC3339Nano)
+	}
+	if !job.FinishedAt.IsZero() {
+		out.FinishedAt = job.FinishedAt.Format(time.RFC3339Nano)
+	}
+	return out
+}
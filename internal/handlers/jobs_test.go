@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jonwraymond/metatools-mcp/internal/jobs"
+	"github.com/jonwraymond/metatools-mcp/pkg/metatools"
+	"github.com/jonwraymond/toolops/auth"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestJobs(t *testing.T) *jobs.Manager {
+	t.Helper()
+	manager := jobs.NewManager(jobs.Options{})
+	t.Cleanup(func() { _ = manager.Close() })
+	return manager
+}
+
+func waitJob(t *testing.T, h *JobsHandler, ctx context.Context, id string) metatools.Job {
+	t.Helper()
+	var job metatools.Job
+	require.Eventually(t, func() bool {
+		out, err := h.Get(ctx, metatools.JobIDInput{JobID: id})
+		require.NoError(t, err)
+		job = out.Job
+		return job.FinishedAt != ""
+	}, 2*time.Second, 5*time.Millisecond)
+	return job
+}
+
+func TestRunTool_Async(t *testing.T) {
+	manager := newTestJobs(t)
+	runner := &mockRunner{
+		runFunc: func(_ context.Context, _ string, args map[string]any) (RunResult, error) {
+			return RunResult{Structured: args}, nil
+		},
+	}
+	handler := NewRunHandler(runner, WithJobs(manager))
+	jobsHandler := NewJobsHandler(manager)
+	alice := auth.WithIdentity(context.Background(), &auth.Identity{Principal: "alice"})
+
+	out, isError, err := handler.Handle(alice, metatools.RunToolInput{ToolID: "t:echo", Args: map[string]any{"x": 1}, Async: true})
+	require.NoError(t, err)
+	require.False(t, isError)
+	require.NotEmpty(t, out.JobID)
+	require.Nil(t, out.Structured)
+
+	job := waitJob(t, jobsHandler, alice, out.JobID)
+	require.Equal(t, jobs.StatusSucceeded, job.Status)
+	require.Equal(t, "run_tool", job.Kind)
+	require.Equal(t, &metatools.RunToolOutput{Structured: map[string]any{"x": 1}}, job.Result)
+	require.Equal(t, &metatools.JobProgress{Progress: 1, Total: 1, Message: "completed"}, job.Progress)
+
+	list, err := jobsHandler.List(alice, metatools.ListJobsInput{})
+	require.NoError(t, err)
+	require.Len(t, list.Jobs, 1)
+	require.Nil(t, list.Jobs[0].Result)
+
+	// Jobs are scoped to the caller.
+	bob := auth.WithIdentity(context.Background(), &auth.Identity{Principal: "bob"})
+	_, err = jobsHandler.Get(bob, metatools.JobIDInput{JobID: out.JobID})
+	require.ErrorIs(t, err, jobs.ErrNotFound)
+	list, err = jobsHandler.List(bob, metatools.ListJobsInput{})
+	require.NoError(t, err)
+	require.Empty(t, list.Jobs)
+}
+
+func TestRunTool_AsyncNotConfigured(t *testing.T) {
+	handler := NewRunHandler(&mockRunner{})
+	_, _, err := handler.Handle(context.Background(), metatools.RunToolInput{ToolID: "t:echo", Async: true})
+	require.ErrorIs(t, err, errAsyncNotConfigured)
+}
+
+// checkpointRunner reports each chain step to onStep as it finishes.
+type checkpointRunner struct {
+	mockRunner
+	block chan struct{}
+}
+
+func (r *checkpointRunner) RunChainWithCheckpoint(ctx context.Context, steps []ChainStep, _ func(ProgressEvent), onStep func(int, StepResult)) (RunResult, []StepResult, error) {
+	var results []StepResult
+	for i, step := range steps {
+		if i == len(steps)-1 {
+			select {
+			case <-r.block:
+			case <-ctx.Done():
+				return RunResult{}, results, ctx.Err()
+			}
+		}
+		res := StepResult{StepID: step.ID, ToolID: step.ToolID, Structured: i}
+		results = append(results, res)
+		onStep(i, res)
+	}
+	return RunResult{Structured: len(steps) - 1}, results, nil
+}
+
+func TestRunChain_AsyncPartialAndCancel(t *testing.T) {
+	manager := newTestJobs(t)
+	runner := &checkpointRunner{block: make(chan struct{})}
+	handler := NewChainHandler(runner, WithJobs(manager))
+	jobsHandler := NewJobsHandler(manager)
+	ctx := context.Background()
+
+	out, _, err := handler.Handle(ctx, metatools.RunChainInput{
+		Steps: []metatools.ChainStep{{ID: "a", ToolID: "t:a"}, {ID: "b", ToolID: "t:b"}},
+		Async: true,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, out.JobID)
+	require.NotNil(t, out.Results)
+
+	var job metatools.Job
+	require.Eventually(t, func() bool {
+		got, err := jobsHandler.Get(ctx, metatools.JobIDInput{JobID: out.JobID})
+		require.NoError(t, err)
+		job = got.Job
+		return job.Partial != nil
+	}, 2*time.Second, 5*time.Millisecond)
+	require.Equal(t, jobs.StatusRunning, job.Status)
+	require.Equal(t, []metatools.ChainStepResult{{ID: "a", ToolID: "t:a", Structured: 0}}, job.Partial)
+
+	canceled, err := jobsHandler.Cancel(ctx, metatools.JobIDInput{JobID: out.JobID})
+	require.NoError(t, err)
+	require.Equal(t, jobs.StatusCanceled, canceled.Job.Status)
+
+	_, _, err = handler.Handle(ctx, metatools.RunChainInput{
+		Steps:  []metatools.ChainStep{{ToolID: "t:a"}},
+		Async:  true,
+		DryRun: true,
+	})
+	require.Error(t, err)
+}
+
+func TestExecuteCode_AsyncFailure(t *testing.T) {
+	manager := newTestJobs(t)
+	executor := &mockExecutor{
+		executeCodeFunc: func(_ context.Context, _ ExecuteParams) (ExecuteResult, error) {
+			return ExecuteResult{}, errors.New("syntax error")
+		},
+	}
+	handler := NewCodeHandler(executor, WithJobs(manager))
+	ctx := context.Background()
+
+	out, err := handler.Handle(ctx, metatools.ExecuteCodeInput{Language: "go", Code: "x", Async: true})
+	require.NoError(t, err)
+	require.NotEmpty(t, out.JobID)
+
+	job := waitJob(t, NewJobsHandler(manager), ctx, out.JobID)
+	require.Equal(t, jobs.StatusFailed, job.Status)
+	require.Equal(t, "syntax error", job.Error)
+	require.Nil(t, job.Result)
+}
@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"strings"
 
+	"github.com/jonwraymond/metatools-mcp/internal/scope"
 	"github.com/jonwraymond/metatools-mcp/pkg/metatools"
 	"github.com/jonwraymond/tooldiscovery/index"
 	"github.com/jonwraymond/toolfoundation/model"
@@ -40,7 +41,7 @@ func (h *ListToolsHandler) Handle(ctx context.Context, input metatools.ListTools
 
 	if h.refresher != nil {
 		if err := h.refresher.MaybeRefresh(ctx); err != nil {
-			slog.Default().Warn("mcp backend refresh failed", "err", err)
+			slog.Default().WarnContext(ctx, "mcp backend refresh failed", "err", err)
 		}
 	}
 
@@ -74,31 +75,52 @@ func (h *ListToolsHandler) Handle(ctx context.Context, input metatools.ListTools
 }
 
 func (h *ListToolsHandler) listPage(ctx context.Context, limit int, cursor string, backendKind string, backendName string) ([]metatools.ToolSummary, string, error) {
-	if backendKind == "" && backendName == "" {
+	sc := scope.FromContext(ctx)
+	if backendKind == "" && backendName == "" && !sc.Restricted() {
 		return h.index.SearchPage(ctx, "", limit, cursor)
 	}
 
-	var out []metatools.ToolSummary
+	fetch := func(cursor string, n int) ([]metatools.ToolSummary, string, error) {
+		return h.index.SearchPage(ctx, "", n, cursor)
+	}
+	return filterPages(limit, cursor, fetch, func(summary metatools.ToolSummary) (bool, error) {
+		if !sc.Allows(summary.ID) {
+			return false, nil
+		}
+		if backendKind == "" && backendName == "" {
+			return true, nil
+		}
+		backends, err := h.index.GetAllBackends(ctx, summary.ID)
+		if err != nil {
+			if errors.Is(err, index.ErrNotFound) {
+				return false, nil
+			}
+			return false, err
+		}
+		return backendMatches(backends, backendKind, backendName), nil
+	})
+}
+
+// filterPages reads pages from fetch starting at cursor and keeps the
+// entries accepted by keep until limit entries are found. Each page asks for
+// no more entries than are still missing, so no page is left half read and
+// the returned cursor continues right after the last entry examined.
+func filterPages[T any](limit int, cursor string, fetch func(cursor string, n int) ([]T, string, error), keep func(T) (bool, error)) ([]T, string, error) {
+	out := make([]T, 0, limit)
 	nextCursor := cursor
 
 	for len(out) < limit {
-		page, next, err := h.index.SearchPage(ctx, "", limit, nextCursor)
+		page, next, err := fetch(nextCursor, limit-len(out))
 		if err != nil {
 			return nil, "", err
 		}
-		for _, summary := range page {
-			backends, err := h.index.GetAllBackends(ctx, summary.ID)
+		for _, entry := range page {
+			ok, err := keep(entry)
 			if err != nil {
-				if errors.Is(err, index.ErrNotFound) {
-					continue
-				}
 				return nil, "", err
 			}
-			if backendMatches(backends, backendKind, backendName) {
-				out = append(out, summary)
-				if len(out) >= limit {
-					return out, next, nil
-				}
+			if ok {
+				out = append(out, entry)
 			}
 		}
 		if next == "" {
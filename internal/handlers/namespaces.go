@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"github.com/jonwraymond/metatools-mcp/internal/scope"
 	"github.com/jonwraymond/metatools-mcp/pkg/metatools"
 	"github.com/jonwraymond/tooldiscovery/index"
 	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
@@ -35,7 +36,19 @@ func (h *NamespacesHandler) Handle(ctx context.Context, input metatools.ListName
 		cursorStr = *input.Cursor
 	}
 
-	namespaces, nextCursor, err := h.index.ListNamespacesPage(ctx, limit, cursorStr)
+	fetch := func(cursor string, n int) ([]string, string, error) {
+		return h.index.ListNamespacesPage(ctx, n, cursor)
+	}
+	var namespaces []string
+	var nextCursor string
+	var err error
+	if sc := scope.FromContext(ctx); sc.Restricted() {
+		namespaces, nextCursor, err = filterPages(limit, cursorStr, fetch, func(ns string) (bool, error) {
+			return sc.AllowsNamespace(ns), nil
+		})
+	} else {
+		namespaces, nextCursor, err = fetch(cursorStr, limit)
+	}
 	if err != nil {
 		if errors.Is(err, index.ErrInvalidCursor) {
 			return nil, &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: "invalid cursor"}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"github.com/jonwraymond/metatools-mcp/internal/jobs"
+	"github.com/jonwraymond/metatools-mcp/internal/results"
+)
+
+// Option configures the handlers that run tools: run_tool, run_chain,
+// execute_code and run_skill. Each handler uses the options that apply to
+// it and ignores the rest.
+type Option func(*options)
+
+type options struct {
+	tools   ToolLookup
+	runs    *RunRecorder
+	jobs    *jobs.Manager
+	results *results.Store
+}
+
+func applyOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithTools resolves tools through tools, so run_chain and run_skill can
+// serve dry runs.
+func WithTools(tools ToolLookup) Option {
+	return func(o *options) { o.tools = tools }
+}
+
+// WithRuns records each run_chain and run_skill run with recorder so it can
+// be inspected and resumed.
+func WithRuns(recorder *RunRecorder) Option {
+	return func(o *options) { o.runs = recorder }
+}
+
+// WithJobs runs async run_tool, run_chain and execute_code calls as jobs of
+// manager.
+func WithJobs(manager *jobs.Manager) Option {
+	return func(o *options) { o.jobs = manager }
+}
+
+// WithResults cuts run_tool results over the size limits of store down to
+// previews, keeping the full results in store.
+func WithResults(store *results.Store) Option {
+	return func(o *options) { o.results = store }
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"unicode/utf8"
+
+	"github.com/jonwraymond/metatools-mcp/internal/results"
+	"github.com/jonwraymond/metatools-mcp/pkg/metatools"
+)
+
+// defaultReadBytes bounds read_result responses when the store has no
+// default limit.
+const defaultReadBytes = 64 << 10
+
+// ResultsHandler handles the read_result metatool
+type ResultsHandler struct {
+	store *results.Store
+}
+
+// NewResultsHandler creates a new results handler
+func NewResultsHandler(store *results.Store) *ResultsHandler {
+	return &ResultsHandler{store: store}
+}
+
+// Read pages through a result kept by run_tool, either by JSON path or by
+// byte range. Path reads over the size limit return a preview that paths
+// into the selection can narrow.
+func (h *ResultsHandler) Read(ctx context.Context, input metatools.ReadResultInput) (*metatools.ReadResultOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	if h.store == nil {
+		return nil, errors.New("result store not configured")
+	}
+	data, err := h.store.Get(ctx, input.ResultID)
+	if err != nil {
+		return nil, err
+	}
+	limit := h.store.MaxBytes("", input.MaxBytes)
+	if limit <= 0 {
+		limit = defaultReadBytes
+	}
+	out := &metatools.ReadResultOutput{ResultID: input.ResultID, TotalBytes: len(data)}
+
+	if input.Path != "" {
+		value, err := results.Decode(data)
+		if err != nil {
+			return nil, err
+		}
+		selected, path, err := results.Select(value, input.Path)
+		if err != nil {
+			return nil, err
+		}
+		var trunc *results.Truncation
+		out.Path = path
+		out.Value, trunc = h.store.Preview(selected, path, limit)
+		if trunc != nil {
+			trunc.ResultID = input.ResultID
+		}
+		out.Truncated = truncatedResult(trunc)
+		return out, nil
+	}
+
+	offset := min(input.Offset, len(data))
+	length := limit
+	if input.Length > 0 {
+		length = min(input.Length, limit)
+	}
+	// Keep ranges on rune boundaries so they encode as valid strings: an
+	// offset inside a rune moves back to its start, and a range always
+	// covers at least one whole rune so next_offset advances.
+	for i := 0; i < utf8.UTFMax-1 && offset > 0 && offset < len(data) && !utf8.RuneStart(data[offset]); i++ {
+		offset--
+	}
+	end := min(offset+length, len(data))
+	for end < len(data) && end > offset && !utf8.RuneStart(data[end]) {
+		end--
+	}
+	if end == offset && offset < len(data) {
+		_, size := utf8.DecodeRune(data[offset:])
+		end = offset + size
+	}
+	out.Data = string(data[offset:end])
+	out.Offset = &offset
+	if end < len(data) {
+		out.NextOffset = &end
+	}
+	return out, nil
+}
+
+func truncatedResult(t *results.Truncation) *metatools.TruncatedResult {
+	if t == nil {
+		return nil
+	}
+	return &metatools.TruncatedResult{
+		ResultID:   t.ResultID,
+		TotalBytes: t.TotalBytes,
+		Arrays:     t.Arrays,
+		Strings:    t.Strings,
+	}
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/jonwraymond/metatools-mcp/internal/jobs"
+	"github.com/jonwraymond/metatools-mcp/internal/results"
+	"github.com/jonwraymond/metatools-mcp/pkg/metatools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func largeRunner() *mockRunner {
+	return &mockRunner{
+		runFunc: func(_ context.Context, _ string, _ map[string]any) (RunResult, error) {
+			rows := make([]any, 200)
+			for i := range rows {
+				rows[i] = map[string]any{"n": i, "text": "row"}
+			}
+			return RunResult{
+				Structured: map[string]any{"rows": rows},
+				MCPResult:  map[string]any{"content": "raw"},
+			}, nil
+		},
+	}
+}
+
+func TestRunTool_TruncatesLargeResults(t *testing.T) {
+	store := results.NewStore(results.Options{MaxBytes: 512})
+	handler := NewRunHandler(largeRunner(), WithResults(store))
+	ctx := results.WithSession(context.Background(), "session")
+
+	out, isError, err := handler.Handle(ctx, metatools.RunToolInput{ToolID: "db:query", IncludeMCPResult: true})
+	require.NoError(t, err)
+	require.False(t, isError)
+	require.NotNil(t, out.Truncated)
+	assert.NotEmpty(t, out.Truncated.ResultID)
+	assert.Equal(t, 200, out.Truncated.Arrays["$.rows"])
+	assert.Nil(t, out.MCPResult, "the raw result would repeat the full output")
+	data, err := json.Marshal(out.Structured)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(data), 512)
+
+	// A per-call limit can let the full result through.
+	out, _, err = handler.Handle(ctx, metatools.RunToolInput{ToolID: "db:query", MaxResultBytes: 1 << 20})
+	require.NoError(t, err)
+	assert.Nil(t, out.Truncated)
+	assert.Len(t, out.Structured.(map[string]any)["rows"], 200)
+}
+
+func TestResultsHandler_Read(t *testing.T) {
+	store := results.NewStore(results.Options{MaxBytes: 512})
+	ctx := results.WithSession(context.Background(), "session")
+	out, _, err := NewRunHandler(largeRunner(), WithJobs(jobs.NewManager(jobs.Options{})), WithResults(store)).Handle(ctx, metatools.RunToolInput{ToolID: "db:query"})
+	require.NoError(t, err)
+	id := out.Truncated.ResultID
+	handler := NewResultsHandler(store)
+
+	read, err := handler.Read(ctx, metatools.ReadResultInput{ResultID: id, Path: "$.rows[150:152]"})
+	require.NoError(t, err)
+	assert.Equal(t, "$.rows[150:152]", read.Path)
+	assert.Nil(t, read.Truncated)
+	rows := read.Value.([]any)
+	require.Len(t, rows, 2)
+	assert.Equal(t, json.Number("150"), rows[0].(map[string]any)["n"])
+
+	// Large selections are previewed in turn.
+	read, err = handler.Read(ctx, metatools.ReadResultInput{ResultID: id, Path: "rows"})
+	require.NoError(t, err)
+	require.NotNil(t, read.Truncated)
+	assert.Equal(t, id, read.Truncated.ResultID)
+	assert.Equal(t, 200, read.Truncated.Arrays["$.rows"])
+
+	// Byte ranges page through the JSON text.
+	var text strings.Builder
+	offset := 0
+	for {
+		read, err = handler.Read(ctx, metatools.ReadResultInput{ResultID: id, Offset: offset, Length: 1000})
+		require.NoError(t, err)
+		text.WriteString(read.Data)
+		if read.NextOffset == nil {
+			break
+		}
+		offset = *read.NextOffset
+	}
+	assert.Equal(t, read.TotalBytes, text.Len())
+	assert.True(t, json.Valid([]byte(text.String())))
+
+	_, err = handler.Read(ctx, metatools.ReadResultInput{ResultID: "missing"})
+	require.ErrorIs(t, err, results.ErrNotFound)
+	_, err = handler.Read(ctx, metatools.ReadResultInput{ResultID: id, Path: "$.rows", Offset: 5})
+	require.Error(t, err)
+}
+
+func TestResultsHandler_ReadKeepsRunesWhole(t *testing.T) {
+	store := results.NewStore(results.Options{MaxBytes: 64})
+	ctx := results.WithSession(context.Background(), "session")
+	runner := &mockRunner{
+		runFunc: func(_ context.Context, _ string, _ map[string]any) (RunResult, error) {
+			return RunResult{Structured: map[string]any{"text": strings.Repeat("€", 40)}}, nil
+		},
+	}
+	out, _, err := NewRunHandler(runner, WithJobs(jobs.NewManager(jobs.Options{})), WithResults(store)).Handle(ctx, metatools.RunToolInput{ToolID: "text:euros"})
+	require.NoError(t, err)
+	require.NotNil(t, out.Truncated)
+	id := out.Truncated.ResultID
+	handler := NewResultsHandler(store)
+
+	// A length shorter than a rune still advances by the whole rune.
+	var text strings.Builder
+	offset := 0
+	for reads := 0; ; reads++ {
+		require.Less(t, reads, 200, "byte ranges did not advance")
+		read, err := handler.Read(ctx, metatools.ReadResultInput{ResultID: id, Offset: offset, Length: 1})
+		require.NoError(t, err)
+		require.True(t, utf8.ValidString(read.Data))
+		text.WriteString(read.Data)
+		if read.NextOffset == nil {
+			break
+		}
+		require.Greater(t, *read.NextOffset, offset)
+		offset = *read.NextOffset
+	}
+	full := text.String()
+	assert.Contains(t, full, strings.Repeat("€", 40))
+
+	// An offset inside a rune reads from the rune's start.
+	start := strings.Index(full, "€")
+	read, err := handler.Read(ctx, metatools.ReadResultInput{ResultID: id, Offset: start + 1, Length: 6})
+	require.NoError(t, err)
+	require.NotNil(t, read.Offset)
+	assert.Equal(t, start, *read.Offset)
+	assert.Equal(t, "€€", read.Data)
+}
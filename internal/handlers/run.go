@@ -5,17 +5,23 @@ import (
 	"fmt"
 
 	merrors "github.com/jonwraymond/metatools-mcp/internal/errors"
+	"github.com/jonwraymond/metatools-mcp/internal/jobs"
+	"github.com/jonwraymond/metatools-mcp/internal/results"
 	"github.com/jonwraymond/metatools-mcp/pkg/metatools"
 )
 
 // RunHandler handles the run_tool metatool
 type RunHandler struct {
-	runner Runner
+	runner  Runner
+	jobs    *jobs.Manager
+	results *results.Store
 }
 
-// NewRunHandler creates a new run handler
-func NewRunHandler(runner Runner) *RunHandler {
-	return &RunHandler{runner: runner}
+// NewRunHandler creates a new run handler. It uses the WithJobs and
+// WithResults options.
+func NewRunHandler(runner Runner, opts ...Option) *RunHandler {
+	o := applyOptions(opts)
+	return &RunHandler{runner: runner, jobs: o.jobs, results: o.results}
 }
 
 // Handle executes the run_tool metatool
@@ -33,6 +39,16 @@ func (h *RunHandler) HandleWithProgress(ctx context.Context, input metatools.Run
 	if err := input.Validate(); err != nil {
 		return nil, false, err
 	}
+	if input.Async {
+		input.Async = false
+		id, err := submitJob(ctx, h.jobs, "run_tool", func(ctx context.Context) (any, bool, error) {
+			return h.HandleWithProgress(ctx, input, jobProgress(ctx))
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		return &metatools.RunToolOutput{JobID: id}, false, nil
+	}
 
 	buildToolError := func(err error) (*metatools.RunToolOutput, bool, error) {
 		errObj := merrors.MapToolError(err, input.ToolID, nil, -1)
@@ -42,6 +58,7 @@ func (h *RunHandler) HandleWithProgress(ctx context.Context, input metatools.Run
 				Message:   errObj.Message,
 				ToolID:    errObj.ToolID,
 				Retryable: errObj.Retryable,
+				Details:   errObj.Details,
 			},
 		}
 		if errObj.Op != nil {
@@ -90,6 +107,7 @@ func (h *RunHandler) HandleWithProgress(ctx context.Context, input metatools.Run
 	// Build successful output
 	output := &metatools.RunToolOutput{
 		Structured: result.Structured,
+		Content:    result.Content,
 	}
 
 	if result.DurationMs > 0 {
@@ -103,7 +121,14 @@ func (h *RunHandler) HandleWithProgress(ctx context.Context, input metatools.Run
 	if input.IncludeBackend && result.Backend != nil {
 		output.Backend = result.Backend
 	}
-	if input.IncludeMCPResult && result.MCPResult != nil {
+	if h.results != nil {
+		var trunc *results.Truncation
+		output.Structured, trunc = h.results.Limit(ctx, input.ToolID, output.Structured, input.MaxResultBytes)
+		output.Truncated = truncatedResult(trunc)
+	}
+	// The raw MCP result repeats the full output, so it is left out of
+	// truncated results.
+	if input.IncludeMCPResult && result.MCPResult != nil && output.Truncated == nil {
 		output.MCPResult = result.MCPResult
 	}
 
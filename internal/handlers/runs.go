@@ -0,0 +1,383 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jonwraymond/metatools-mcp/internal/state/runs"
+	"github.com/jonwraymond/metatools-mcp/pkg/metatools"
+)
+
+// IdempotencyKeyArg is the argument recorded runs pass to tools whose input
+// schema declares it. The key is stable across resumes of the same step.
+const IdempotencyKeyArg = "idempotency_key"
+
+// statusInterrupted is reported for running records that no call in this
+// process is executing, e.g. after a restart. It is never stored.
+const statusInterrupted = "interrupted"
+
+const canceledMessage = "canceled by cancel_run"
+
+// errRunCanceled is the cancellation cause of runs stopped by cancel_run.
+var errRunCanceled = errors.New(canceledMessage)
+
+// RunRecorder persists run_chain and run_skill executions and tracks the
+// ones in flight so they can be canceled.
+type RunRecorder struct {
+	store     runs.Store
+	retention time.Duration
+	tools     ToolLookup
+
+	mu     sync.Mutex
+	active map[string]*recording
+}
+
+// NewRunRecorder creates a recorder backed by store. Finished runs older
+// than retention are pruned when new runs start; retention <= 0 keeps them.
+// tools, when set, is used to find tools that accept an idempotency key.
+func NewRunRecorder(store runs.Store, retention time.Duration, tools ToolLookup) *RunRecorder {
+	return &RunRecorder{
+		store:     store,
+		retention: retention,
+		tools:     tools,
+		active:    make(map[string]*recording),
+	}
+}
+
+// recording is a run executing in this process.
+type recording struct {
+	recorder *RunRecorder
+	id       string
+	ctx      context.Context
+	cancel   context.CancelCauseFunc
+	done     chan struct{}
+
+	mu       sync.Mutex
+	canceled bool
+}
+
+// begin creates a run record and registers the run as active.
+func (r *RunRecorder) begin(ctx context.Context, kind string, request, plan any) (*recording, error) {
+	r.prune(ctx)
+	id, err := newRunID()
+	if err != nil {
+		return nil, err
+	}
+	run := runs.Run{ID: id, Kind: kind, Status: runs.StatusRunning}
+	if run.Request, err = json.Marshal(request); err != nil {
+		return nil, fmt.Errorf("encode run request: %w", err)
+	}
+	if plan != nil {
+		if run.Plan, err = json.Marshal(plan); err != nil {
+			return nil, fmt.Errorf("encode run plan: %w", err)
+		}
+	}
+	if err := r.store.Create(ctx, run); err != nil {
+		return nil, err
+	}
+	return r.track(ctx, id), nil
+}
+
+// reopen marks an existing run as running again for resume_run.
+func (r *RunRecorder) reopen(ctx context.Context, id string) (*recording, error) {
+	r.mu.Lock()
+	if _, ok := r.active[id]; ok {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("run %q is still running", id)
+	}
+	// Reserve the ID so a concurrent resume of the same run fails.
+	r.active[id] = nil
+	r.mu.Unlock()
+
+	if err := r.store.SetStatus(ctx, id, runs.StatusRunning, ""); err != nil {
+		r.mu.Lock()
+		delete(r.active, id)
+		r.mu.Unlock()
+		return nil, err
+	}
+	return r.track(ctx, id), nil
+}
+
+func (r *RunRecorder) track(ctx context.Context, id string) *recording {
+	runCtx, cancel := context.WithCancelCause(ctx)
+	rec := &recording{recorder: r, id: id, ctx: runCtx, cancel: cancel, done: make(chan struct{})}
+	r.mu.Lock()
+	r.active[id] = rec
+	r.mu.Unlock()
+	return rec
+}
+
+func (r *RunRecorder) isActive(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.active[id]
+	return ok
+}
+
+// cancelActive cancels a run executing in this process and waits for it to
+// record its final status. It reports false when the run is not active.
+func (r *RunRecorder) cancelActive(ctx context.Context, id string) (bool, error) {
+	r.mu.Lock()
+	rec := r.active[id]
+	r.mu.Unlock()
+	if rec == nil {
+		return false, nil
+	}
+	rec.mu.Lock()
+	rec.canceled = true
+	rec.mu.Unlock()
+	rec.cancel(errRunCanceled)
+	select {
+	case <-rec.done:
+		return true, nil
+	case <-ctx.Done():
+		return true, ctx.Err()
+	}
+}
+
+func (r *RunRecorder) prune(ctx context.Context) {
+	if r.retention <= 0 {
+		return
+	}
+	if _, err := r.store.Prune(ctx, time.Now().Add(-r.retention)); err != nil {
+		slog.Warn("prune run records", "error", err)
+	}
+}
+
+// step records a finished step. Failures to persist are logged rather than
+// failing the run.
+func (rec *recording) step(index int, id, toolID string, value any, err error) {
+	step := runs.Step{Index: index, ID: id, ToolID: toolID, Status: runs.StatusSucceeded, Value: value}
+	if err != nil {
+		step.Status = runs.StatusFailed
+		step.Error = err.Error()
+		step.Value = nil
+	}
+	if err := rec.recorder.store.SaveStep(context.WithoutCancel(rec.ctx), rec.id, step); err != nil {
+		slog.Warn("record run step", "run_id", rec.id, "step", index, "error", err)
+	}
+}
+
+// finish stores the run's final status and unregisters it.
+func (rec *recording) finish(err error) {
+	rec.mu.Lock()
+	canceled := rec.canceled
+	rec.mu.Unlock()
+
+	status, msg := runs.StatusSucceeded, ""
+	switch {
+	case canceled:
+		status, msg = runs.StatusCanceled, canceledMessage
+	case err != nil:
+		status, msg = runs.StatusFailed, err.Error()
+	}
+	if err := rec.recorder.store.SetStatus(context.WithoutCancel(rec.ctx), rec.id, status, msg); err != nil {
+		slog.Warn("record run status", "run_id", rec.id, "error", err)
+	}
+	rec.cancel(nil)
+	rec.recorder.mu.Lock()
+	delete(rec.recorder.active, rec.id)
+	rec.recorder.mu.Unlock()
+	close(rec.done)
+}
+
+// withIdempotencyKey adds an idempotency key for stepKey to args when the
+// tool's input schema declares IdempotencyKeyArg and args do not set it.
+func (rec *recording) withIdempotencyKey(ctx context.Context, toolID string, args map[string]any, stepKey string) map[string]any {
+	tools := rec.recorder.tools
+	if tools == nil {
+		return args
+	}
+	if _, ok := args[IdempotencyKeyArg]; ok {
+		return args
+	}
+	tool, err := tools.GetTool(ctx, toolID)
+	if err != nil {
+		return args
+	}
+	schema, _ := tool.InputSchema.(map[string]any)
+	props, _ := schema["properties"].(map[string]any)
+	if _, ok := props[IdempotencyKeyArg]; !ok {
+		return args
+	}
+	out := make(map[string]any, len(args)+1)
+	maps.Copy(out, args)
+	out[IdempotencyKeyArg] = rec.id + "/" + stepKey
+	return out
+}
+
+// runOutcome reduces a handler result to the error recorded for the run.
+func runOutcome(err error, isError bool, errObj *metatools.ErrorObject) error {
+	switch {
+	case err != nil:
+		return err
+	case !isError:
+		return nil
+	case errObj != nil:
+		return fmt.Errorf("%s: %s", errObj.Code, errObj.Message)
+	default:
+		return errors.New("run failed")
+	}
+}
+
+// stepKey names a step in idempotency keys: its ID, or its index.
+func stepKey(id string, index int) string {
+	if id != "" {
+		return id
+	}
+	return strconv.Itoa(index)
+}
+
+func newRunID() (string, error) {
+	var b [12]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("generate run id: %w", err)
+	}
+	return "run_" + hex.EncodeToString(b[:]), nil
+}
+
+// RunsHandler handles the get_run, resume_run and cancel_run metatools.
+type RunsHandler struct {
+	runs   *RunRecorder
+	chain  *ChainHandler
+	skills *SkillsHandler
+}
+
+// NewRunsHandler creates a runs handler. chain and skills resume runs of
+// their kind and may be nil when the matching metatool is not served.
+func NewRunsHandler(recorder *RunRecorder, chain *ChainHandler, skills *SkillsHandler) *RunsHandler {
+	return &RunsHandler{runs: recorder, chain: chain, skills: skills}
+}
+
+// Get handles get_run.
+func (h *RunsHandler) Get(ctx context.Context, input metatools.RunIDInput) (*metatools.RunRecordOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	run, err := h.runs.store.Get(ctx, input.RunID)
+	if err != nil {
+		return nil, err
+	}
+	return &metatools.RunRecordOutput{Run: h.record(run)}, nil
+}
+
+// Resume handles resume_run. The run continues from its first incomplete
+// step: steps that succeeded are replayed from the record, failed ones run
+// again.
+func (h *RunsHandler) Resume(ctx context.Context, input metatools.RunIDInput) (*metatools.ResumeRunOutput, bool, error) {
+	if err := input.Validate(); err != nil {
+		return nil, false, err
+	}
+	run, err := h.runs.store.Get(ctx, input.RunID)
+	if err != nil {
+		return nil, false, err
+	}
+	if run.Status == runs.StatusSucceeded {
+		return nil, false, fmt.Errorf("run %q already succeeded", run.ID)
+	}
+	switch run.Kind {
+	case runs.KindChain:
+		if h.chain == nil {
+			return nil, false, errors.New("run_chain not configured")
+		}
+	case runs.KindSkill:
+		if h.skills == nil {
+			return nil, false, errors.New("run_skill not configured")
+		}
+	default:
+		return nil, false, fmt.Errorf("run %q has unknown kind %q", run.ID, run.Kind)
+	}
+
+	rec, err := h.runs.reopen(ctx, run.ID)
+	if err != nil {
+		return nil, false, err
+	}
+	out := &metatools.ResumeRunOutput{}
+	var isError bool
+	if run.Kind == runs.KindChain {
+		out.Chain, isError, err = h.chain.resume(rec, run)
+	} else {
+		out.Skill, isError, err = h.skills.resume(rec, run)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if run, err = h.runs.store.Get(ctx, run.ID); err != nil {
+		return nil, false, err
+	}
+	out.Run = h.record(run)
+	return out, isError, nil
+}
+
+// Cancel handles cancel_run. A run executing in this process is stopped; an
+// interrupted or failed run is only marked canceled.
+func (h *RunsHandler) Cancel(ctx context.Context, input metatools.RunIDInput) (*metatools.RunRecordOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	run, err := h.runs.store.Get(ctx, input.RunID)
+	if err != nil {
+		return nil, err
+	}
+	if run.Status == runs.StatusSucceeded {
+		return nil, fmt.Errorf("run %q already succeeded", run.ID)
+	}
+	if run.Status != runs.StatusCanceled {
+		active, err := h.runs.cancelActive(ctx, run.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !active {
+			if err := h.runs.store.SetStatus(ctx, run.ID, runs.StatusCanceled, canceledMessage); err != nil {
+				return nil, err
+			}
+		}
+		if run, err = h.runs.store.Get(ctx, run.ID); err != nil {
+			return nil, err
+		}
+	}
+	return &metatools.RunRecordOutput{Run: h.record(run)}, nil
+}
+
+func (h *RunsHandler) record(run runs.Run) metatools.RunRecord {
+	out := metatools.RunRecord{
+		RunID:     run.ID,
+		Kind:      run.Kind,
+		Status:    run.Status,
+		Error:     run.Error,
+		Steps:     make([]metatools.RunStepRecord, len(run.Steps)),
+		CreatedAt: run.CreatedAt.Format(time.RFC3339Nano),
+		UpdatedAt: run.UpdatedAt.Format(time.RFC3339Nano),
+	}
+	if run.Status == runs.StatusRunning && !h.runs.isActive(run.ID) {
+		out.Status = statusInterrupted
+	}
+	if !run.FinishedAt.IsZero() {
+		out.FinishedAt = run.FinishedAt.Format(time.RFC3339Nano)
+	}
+	_ = json.Unmarshal(run.Request, &out.Request)
+	if len(run.Plan) > 0 {
+		_ = json.Unmarshal(run.Plan, &out.Plan)
+	}
+	for i, step := range run.Steps {
+		out.Steps[i] = metatools.RunStepRecord{
+			Index:  step.Index,
+			ID:     step.ID,
+			ToolID: step.ToolID,
+			Status: step.Status,
+			Value:  step.Value,
+			Error:  step.Error,
+		}
+	}
+	return out
+}
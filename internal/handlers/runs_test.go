@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jonwraymond/metatools-mcp/internal/state/runs"
+	"github.com/jonwraymond/metatools-mcp/internal/toolset"
+	"github.com/jonwraymond/metatools-mcp/pkg/metatools"
+	"github.com/jonwraymond/toolfoundation/model"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRunRecorder(t *testing.T, tools ToolLookup) *RunRecorder {
+	t.Helper()
+	store, closeFn, err := runs.OpenSQLite(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = closeFn() })
+	return NewRunRecorder(store, time.Hour, tools)
+}
+
+func TestRunsHandler_ResumeSkill(t *testing.T) {
+	tools := stubToolLookup{
+		"t:pay": model.Tool{Tool: mcp.Tool{
+			Name: "pay",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					IdempotencyKeyArg: map[string]any{"type": "string"},
+				},
+			},
+		}},
+	}
+	recorder := newTestRunRecorder(t, tools)
+
+	var mu sync.Mutex
+	calls := map[string]int{}
+	var keys []any
+	runner := &mockRunner{
+		runFunc: func(_ context.Context, toolID string, args map[string]any) (RunResult, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			calls[toolID]++
+			switch toolID {
+			case "t:quote":
+				return RunResult{Structured: map[string]any{"amount": float64(5)}}, nil
+			case "t:pay":
+				keys = append(keys, args[IdempotencyKeyArg])
+				if calls[toolID] == 1 {
+					return RunResult{}, errors.New("gateway timeout")
+				}
+				return RunResult{Structured: map[string]any{"paid": args["amount"]}}, nil
+			}
+			return RunResult{}, nil
+		},
+	}
+	skills := NewSkillsHandler(nil, toolset.NewRegistry(nil), runner, SkillDefaults{}, WithTools(tools), WithRuns(recorder))
+	handler := NewRunsHandler(recorder, nil, skills)
+
+	def := &metatools.SkillDefinition{
+		Name: "checkout",
+		Steps: []metatools.SkillStep{
+			{ID: "a_quote", ToolID: "t:quote"},
+			{ID: "b_pay", ToolID: "t:pay", Inputs: map[string]any{"amount": "${steps.a_quote.result.amount}"}},
+		},
+	}
+	out, isError, err := skills.Run(context.Background(), metatools.RunSkillInput{Skill: def})
+	require.NoError(t, err)
+	require.True(t, isError)
+	require.NotEmpty(t, out.RunID)
+
+	got, err := handler.Get(context.Background(), metatools.RunIDInput{RunID: out.RunID})
+	require.NoError(t, err)
+	require.Equal(t, runs.StatusFailed, got.Run.Status)
+	require.Equal(t, runs.KindSkill, got.Run.Kind)
+	require.Len(t, got.Run.Steps, 2)
+	require.Equal(t, runs.StatusSucceeded, got.Run.Steps[0].Status)
+	require.Equal(t, runs.StatusFailed, got.Run.Steps[1].Status)
+	require.Equal(t, "gateway timeout", got.Run.Steps[1].Error)
+
+	resumed, isError, err := handler.Resume(context.Background(), metatools.RunIDInput{RunID: out.RunID})
+	require.NoError(t, err)
+	require.False(t, isError, "unexpected error: %+v", resumed.Skill.Error)
+	require.Equal(t, runs.StatusSucceeded, resumed.Run.Status)
+	require.NotEmpty(t, resumed.Run.FinishedAt)
+	require.Equal(t, out.RunID, resumed.Skill.RunID)
+
+	// The quote is replayed from the record; only the payment runs again,
+	// with the same idempotency key.
+	require.Equal(t, 1, calls["t:quote"])
+	require.Equal(t, 2, calls["t:pay"])
+	require.Equal(t, []any{out.RunID + "/b_pay", out.RunID + "/b_pay"}, keys)
+	require.Equal(t, map[string]any{"paid": float64(5)}, resumed.Run.Steps[1].Value)
+
+	_, _, err = handler.Resume(context.Background(), metatools.RunIDInput{RunID: out.RunID})
+	require.ErrorContains(t, err, "already succeeded")
+}
+
+func TestRunsHandler_RecordsReorderedSkillSteps(t *testing.T) {
+	recorder := newTestRunRecorder(t, nil)
+	runner := &mockRunner{
+		runFunc: func(_ context.Context, toolID string, _ map[string]any) (RunResult, error) {
+			if toolID == "t:report" {
+				return RunResult{}, errors.New("report failed")
+			}
+			return RunResult{Structured: toolID}, nil
+		},
+	}
+	skills := NewSkillsHandler(nil, toolset.NewRegistry(nil), runner, SkillDefaults{}, WithRuns(recorder))
+	handler := NewRunsHandler(recorder, nil, skills)
+
+	// a_report is declared first but runs after b_fetch.
+	def := &metatools.SkillDefinition{
+		Name: "report",
+		Steps: []metatools.SkillStep{
+			{ID: "a_report", ToolID: "t:report", DependsOn: []string{"b_fetch"}},
+			{ID: "b_fetch", ToolID: "t:fetch"},
+		},
+	}
+	out, isError, err := skills.Run(context.Background(), metatools.RunSkillInput{Skill: def})
+	require.NoError(t, err)
+	require.True(t, isError)
+
+	got, err := handler.Get(context.Background(), metatools.RunIDInput{RunID: out.RunID})
+	require.NoError(t, err)
+	require.Equal(t, []metatools.RunStepRecord{
+		{Index: 0, ID: "b_fetch", ToolID: "t:fetch", Status: runs.StatusSucceeded, Value: "t:fetch"},
+		{Index: 1, ID: "a_report", ToolID: "t:report", Status: runs.StatusFailed, Error: "report failed"},
+	}, got.Run.Steps)
+}
+
+func TestRunsHandler_Cancel(t *testing.T) {
+	recorder := newTestRunRecorder(t, nil)
+	started := make(chan struct{})
+	runner := &mockRunner{
+		runFunc: func(ctx context.Context, _ string, _ map[string]any) (RunResult, error) {
+			close(started)
+			<-ctx.Done()
+			return RunResult{}, ctx.Err()
+		},
+	}
+	skills := NewSkillsHandler(nil, toolset.NewRegistry(nil), runner, SkillDefaults{}, WithRuns(recorder))
+	handler := NewRunsHandler(recorder, nil, skills)
+
+	def := &metatools.SkillDefinition{
+		Name:  "slow",
+		Steps: []metatools.SkillStep{{ID: "wait", ToolID: "t:wait"}},
+	}
+	type result struct {
+		out *metatools.RunSkillOutput
+		err error
+	}
+	results := make(chan result, 1)
+	go func() {
+		out, _, err := skills.Run(context.Background(), metatools.RunSkillInput{Skill: def})
+		results <- result{out, err}
+	}()
+	<-started
+
+	var runID string
+	recorder.mu.Lock()
+	for id := range recorder.active {
+		runID = id
+	}
+	recorder.mu.Unlock()
+	require.NotEmpty(t, runID)
+
+	got, err := handler.Get(context.Background(), metatools.RunIDInput{RunID: runID})
+	require.NoError(t, err)
+	require.Equal(t, runs.StatusRunning, got.Run.Status)
+
+	canceled, err := handler.Cancel(context.Background(), metatools.RunIDInput{RunID: runID})
+	require.NoError(t, err)
+	require.Equal(t, runs.StatusCanceled, canceled.Run.Status)
+
+	res := <-results
+	require.NoError(t, res.err)
+	require.Equal(t, runID, res.out.RunID)
+}
+
+func TestRunsHandler_GetInterruptedAndMissing(t *testing.T) {
+	recorder := newTestRunRecorder(t, nil)
+	handler := NewRunsHandler(recorder, nil, nil)
+	ctx := context.Background()
+
+	require.NoError(t, recorder.store.Create(ctx, runs.Run{ID: "run_old", Kind: runs.KindChain, Status: runs.StatusRunning, Request: []byte(`{}`)}))
+	got, err := handler.Get(ctx, metatools.RunIDInput{RunID: "run_old"})
+	require.NoError(t, err)
+	require.Equal(t, statusInterrupted, got.Run.Status)
+
+	canceled, err := handler.Cancel(ctx, metatools.RunIDInput{RunID: "run_old"})
+	require.NoError(t, err)
+	require.Equal(t, runs.StatusCanceled, canceled.Run.Status)
+
+	_, err = handler.Get(ctx, metatools.RunIDInput{RunID: "run_missing"})
+	require.ErrorIs(t, err, runs.ErrNotFound)
+
+	_, err = handler.Get(ctx, metatools.RunIDInput{})
+	require.Error(t, err)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"log/slog"
 
+	"github.com/jonwraymond/metatools-mcp/internal/scope"
 	"github.com/jonwraymond/metatools-mcp/pkg/metatools"
 	"github.com/jonwraymond/tooldiscovery/index"
 	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
@@ -40,7 +41,7 @@ func (h *SearchHandler) Handle(ctx context.Context, input metatools.SearchToolsI
 	if h.refresher != nil {
 		if err := h.refresher.MaybeRefresh(ctx); err != nil {
 			// Keep search working on stale data if refresh fails.
-			slog.Default().Warn("mcp backend refresh failed", "err", err)
+			slog.Default().WarnContext(ctx, "mcp backend refresh failed", "err", err)
 		}
 	}
 
@@ -52,7 +53,20 @@ func (h *SearchHandler) Handle(ctx context.Context, input metatools.SearchToolsI
 		cursorStr = *input.Cursor
 	}
 
-	tools, nextCursor, err := h.index.SearchPage(ctx, input.Query, limit, cursorStr)
+	fetch := func(cursor string, n int) ([]metatools.ToolSummary, string, error) {
+		return h.index.SearchPage(ctx, input.Query, n, cursor)
+	}
+	var tools []metatools.ToolSummary
+	var nextCursor string
+	var err error
+	if sc := scope.FromContext(ctx); sc.Restricted() {
+		// Results outside the session's active toolsets are skipped.
+		tools, nextCursor, err = filterPages(limit, cursorStr, fetch, func(summary metatools.ToolSummary) (bool, error) {
+			return sc.Allows(summary.ID), nil
+		})
+	} else {
+		tools, nextCursor, err = fetch(cursorStr, limit)
+	}
 	if err != nil {
 		if errors.Is(err, index.ErrInvalidCursor) {
 			return nil, &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: "invalid cursor"}
@@ -3,10 +3,14 @@ package handlers
 import (
 	"context"
 	"errors"
+	"strconv"
 	"testing"
 
+	"github.com/jonwraymond/metatools-mcp/internal/scope"
+	"github.com/jonwraymond/metatools-mcp/internal/toolset"
 	"github.com/jonwraymond/metatools-mcp/pkg/metatools"
 	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolfoundation/adapter"
 	"github.com/jonwraymond/toolfoundation/model"
 	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
 	"github.com/stretchr/testify/assert"
@@ -206,3 +210,71 @@ func TestSearchTools_InvalidCursor(t *testing.T) {
 func strPtr(s string) *string {
 	return &s
 }
+
+func TestSearchTools_ActiveToolsets(t *testing.T) {
+	idx := &mockIndex{
+		searchFunc: func(_ context.Context, _ string, _ int, cursor string) ([]metatools.ToolSummary, string, error) {
+			if cursor == "" {
+				return []metatools.ToolSummary{{ID: "ops:deploy"}, {ID: "github:create_issue"}}, "page2", nil
+			}
+			return []metatools.ToolSummary{{ID: "github:list_issues"}, {ID: "ops:rollback"}}, "", nil
+		},
+	}
+	sc := scope.New(toolset.NewRegistry([]*toolset.Toolset{{ID: "toolset:github", Tools: []*adapter.CanonicalTool{
+		{Namespace: "github", Name: "create_issue"},
+		{Namespace: "github", Name: "list_issues"},
+	}}}), nil)
+	sc.Activate("toolset:github", false)
+
+	limit := 2
+	out, err := NewSearchHandler(idx).Handle(scope.WithScope(context.Background(), sc), metatools.SearchToolsInput{Query: "", Limit: &limit})
+	require.NoError(t, err)
+	require.Len(t, out.Tools, 2)
+	assert.Equal(t, "github:create_issue", out.Tools[0].ID)
+	assert.Equal(t, "github:list_issues", out.Tools[1].ID)
+}
+
+func TestSearchTools_ActiveToolsetsLimitReachedMidPage(t *testing.T) {
+	ids := []string{"ops:deploy", "github:a", "github:b", "github:c", "ops:rollback", "github:d"}
+	idx := &mockIndex{
+		searchFunc: func(_ context.Context, _ string, limit int, cursor string) ([]metatools.ToolSummary, string, error) {
+			offset := 0
+			if cursor != "" {
+				offset, _ = strconv.Atoi(cursor)
+			}
+			end := min(offset+limit, len(ids))
+			page := make([]metatools.ToolSummary, 0, end-offset)
+			for _, id := range ids[offset:end] {
+				page = append(page, metatools.ToolSummary{ID: id})
+			}
+			if end == len(ids) {
+				return page, "", nil
+			}
+			return page, strconv.Itoa(end), nil
+		},
+	}
+	var members []*adapter.CanonicalTool
+	for _, name := range []string{"a", "b", "c", "d"} {
+		members = append(members, &adapter.CanonicalTool{Namespace: "github", Name: name})
+	}
+	sc := scope.New(toolset.NewRegistry([]*toolset.Toolset{{ID: "toolset:github", Tools: members}}), nil)
+	sc.Activate("toolset:github", false)
+	ctx := scope.WithScope(context.Background(), sc)
+
+	// Every allowed tool is returned exactly once across pages.
+	var got []string
+	limit := 2
+	input := metatools.SearchToolsInput{Limit: &limit}
+	for {
+		out, err := NewSearchHandler(idx).Handle(ctx, input)
+		require.NoError(t, err)
+		for _, tool := range out.Tools {
+			got = append(got, tool.ID)
+		}
+		if out.NextCursor == nil {
+			break
+		}
+		input.Cursor = out.NextCursor
+	}
+	assert.Equal(t, []string{"github:a", "github:b", "github:c", "github:d"}, got)
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxFieldDocLen caps one-line field docs in compact signatures.
+const maxFieldDocLen = 80
+
+// signatureOptions controls how much detail renderSignature emits.
+type signatureOptions struct {
+	// descriptions appends one-line field docs as trailing comments.
+	descriptions bool
+	// maxDepth is how many levels of nested objects are expanded;
+	// deeper objects render as "object".
+	maxDepth int
+	// optional includes non-required fields.
+	optional bool
+}
+
+var fullSignature = signatureOptions{descriptions: true, maxDepth: 3, optional: true}
+
+// renderSignature renders a TypeScript-like signature for a tool from its
+// input and (optional) output JSON Schemas.
+func renderSignature(name string, input, output map[string]any, opts signatureOptions) string {
+	var b strings.Builder
+	b.WriteString(name)
+	b.WriteString("(args: ")
+	b.WriteString(renderType(input, opts, 0))
+	b.WriteString(")")
+	if output != nil {
+		b.WriteString(" => ")
+		b.WriteString(renderType(output, opts, 0))
+	}
+	return b.String()
+}
+
+func renderType(schema map[string]any, opts signatureOptions, depth int) string {
+	if schema == nil {
+		return "unknown"
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		return ref[strings.LastIndex(ref, "/")+1:]
+	}
+	if c, ok := schema["const"]; ok {
+		return literal(c)
+	}
+	if enum, ok := schema["enum"].([]any); ok && len(enum) > 0 {
+		parts := make([]string, len(enum))
+		for i, v := range enum {
+			parts[i] = literal(v)
+		}
+		return strings.Join(parts, " | ")
+	}
+	for _, key := range []string{"anyOf", "oneOf"} {
+		if variants, ok := schema[key].([]any); ok && len(variants) > 0 {
+			parts := make([]string, 0, len(variants))
+			for _, v := range variants {
+				parts = append(parts, renderType(asSchema(v), opts, depth))
+			}
+			return strings.Join(parts, " | ")
+		}
+	}
+
+	types := schemaTypes(schema)
+	if len(types) == 0 {
+		if _, ok := schema["properties"]; ok {
+			types = []string{"object"}
+		} else {
+			return "unknown"
+		}
+	}
+	parts := make([]string, len(types))
+	for i, t := range types {
+		switch t {
+		case "object":
+			parts[i] = renderObject(schema, opts, depth)
+		case "array":
+			item := renderType(asSchema(schema["items"]), opts, depth)
+			if strings.Contains(item, " | ") {
+				item = "(" + item + ")"
+			}
+			parts[i] = item + "[]"
+		default:
+			parts[i] = t
+		}
+	}
+	return strings.Join(parts, " | ")
+}
+
+func renderObject(schema map[string]any, opts signatureOptions, depth int) string {
+	props, _ := schema["properties"].(map[string]any)
+	if len(props) == 0 {
+		if extra := asSchema(schema["additionalProperties"]); extra != nil {
+			return "Record<string, " + renderType(extra, opts, depth) + ">"
+		}
+		return "object"
+	}
+	if depth >= opts.maxDepth && depth > 0 {
+		return "object"
+	}
+
+	required := make(map[string]bool)
+	if req, ok := schema["required"].([]any); ok {
+		for _, r := range req {
+			if s, ok := r.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	// Required fields first, then alphabetical, for stable output.
+	sort.Slice(names, func(i, j int) bool {
+		if required[names[i]] != required[names[j]] {
+			return required[names[i]]
+		}
+		return names[i] < names[j]
+	})
+
+	indent := strings.Repeat("  ", depth+1)
+	var b strings.Builder
+	b.WriteString("{\n")
+	omitted := 0
+	for _, name := range names {
+		if !required[name] && !opts.optional {
+			omitted++
+			continue
+		}
+		prop := asSchema(props[name])
+		b.WriteString(indent)
+		b.WriteString(name)
+		if !required[name] {
+			b.WriteString("?")
+		}
+		b.WriteString(": ")
+		b.WriteString(renderType(prop, opts, depth+1))
+		if def, ok := prop["default"]; ok {
+			b.WriteString(" = ")
+			b.WriteString(literal(def))
+		}
+		b.WriteString(";")
+		if opts.descriptions {
+			if doc := fieldDoc(prop); doc != "" {
+				b.WriteString(" // ")
+				b.WriteString(doc)
+			}
+		}
+		b.WriteString("\n")
+	}
+	if omitted > 0 {
+		fmt.Fprintf(&b, "%s// ...%d optional\n", indent, omitted)
+	}
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString("}")
+	return b.String()
+}
+
+func schemaTypes(schema map[string]any) []string {
+	switch t := schema["type"].(type) {
+	case string:
+		return []string{t}
+	case []any:
+		out := make([]string, 0, len(t))
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func fieldDoc(schema map[string]any) string {
+	desc, _ := schema["description"].(string)
+	desc = strings.TrimSpace(desc)
+	if i := strings.IndexByte(desc, '\n'); i >= 0 {
+		desc = strings.TrimSpace(desc[:i])
+	}
+	if r := []rune(desc); len(r) > maxFieldDocLen {
+		desc = strings.TrimSpace(string(r[:maxFieldDocLen-3])) + "..."
+	}
+	return desc
+}
+
+func literal(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func asSchema(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	return m
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func prSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"repo":  map[string]any{"type": "string", "description": "Repository in owner/name form, for example acme/api or octo-org/octo-repo\nIgnored line."},
+			"draft": map[string]any{"type": "boolean", "default": false, "description": "Open as draft"},
+			"state": map[string]any{"type": "string", "enum": []any{"open", "closed"}},
+			"labels": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"type": "string"},
+			},
+			"head": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"ref": map[string]any{"type": "string"},
+				},
+				"required": []any{"ref"},
+			},
+			"meta": map[string]any{
+				"type":                 "object",
+				"additionalProperties": map[string]any{"type": "string"},
+			},
+		},
+		"required": []any{"repo"},
+	}
+}
+
+func TestRenderSignature_Full(t *testing.T) {
+	got := renderSignature("github:create_pull_request", prSchema(),
+		map[string]any{"type": "object", "properties": map[string]any{"url": map[string]any{"type": "string"}}, "required": []any{"url"}},
+		fullSignature)
+
+	want := `github:create_pull_request(args: {
+  repo: string; // Repository in owner/name form, for example acme/api or octo-org/octo-repo
+  draft?: boolean = false; // Open as draft
+  head?: {
+    ref: string;
+  };
+  labels?: string[];
+  meta?: Record<string, string>;
+  state?: "open" | "closed";
+}) => {
+  url: string;
+}`
+	assert.Equal(t, want, got)
+}
+
+func TestRenderSignature_Reduced(t *testing.T) {
+	got := renderSignature("t", prSchema(), nil, signatureOptions{maxDepth: 0})
+	assert.Equal(t, "t(args: {\n  repo: string;\n  // ...5 optional\n})", got)
+
+	got = renderSignature("t", prSchema(), nil, signatureOptions{maxDepth: 0, optional: true})
+	assert.Contains(t, got, "head?: object;")
+	assert.NotContains(t, got, "//")
+}
+
+func TestRenderType_Variants(t *testing.T) {
+	assert.Equal(t, "string | null", renderType(map[string]any{"type": []any{"string", "null"}}, fullSignature, 0))
+	assert.Equal(t, "(string | integer)[]", renderType(map[string]any{
+		"type":  "array",
+		"items": map[string]any{"anyOf": []any{map[string]any{"type": "string"}, map[string]any{"type": "integer"}}},
+	}, fullSignature, 0))
+	assert.Equal(t, "Issue", renderType(map[string]any{"$ref": "#/$defs/Issue"}, fullSignature, 0))
+	assert.Equal(t, `"fixed"`, renderType(map[string]any{"const": "fixed"}, fullSignature, 0))
+	assert.Equal(t, "unknown", renderType(nil, fullSignature, 0))
+}
@@ -1,12 +1,19 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"sync"
 	"time"
 
+	"github.com/jonwraymond/metatools-mcp/internal/dryrun"
 	merrors "github.com/jonwraymond/metatools-mcp/internal/errors"
 	internalskills "github.com/jonwraymond/metatools-mcp/internal/skills"
+	"github.com/jonwraymond/metatools-mcp/internal/state/runs"
+	"github.com/jonwraymond/metatools-mcp/internal/stepgraph"
 	"github.com/jonwraymond/metatools-mcp/pkg/metatools"
 	"github.com/jonwraymond/toolcompose/skill"
 )
@@ -17,15 +24,21 @@ type SkillsHandler struct {
 	toolsets ToolsetRegistry
 	runner   Runner
 	defaults SkillDefaults
+	tools    ToolLookup
+	runs     *RunRecorder
 }
 
-// NewSkillsHandler creates a new skills handler.
-func NewSkillsHandler(registry SkillRegistry, toolsets ToolsetRegistry, runner Runner, defaults SkillDefaults) *SkillsHandler {
+// NewSkillsHandler creates a new skills handler. It uses the WithTools and
+// WithRuns options.
+func NewSkillsHandler(registry SkillRegistry, toolsets ToolsetRegistry, runner Runner, defaults SkillDefaults, opts ...Option) *SkillsHandler {
+	o := applyOptions(opts)
 	return &SkillsHandler{
 		registry: registry,
 		toolsets: toolsets,
 		runner:   runner,
 		defaults: defaults,
+		tools:    o.tools,
+		runs:     o.runs,
 	}
 }
 
@@ -53,6 +66,7 @@ func (h *SkillsHandler) List(ctx context.Context, input metatools.ListSkillsInpu
 			Description: s.Description,
 			StepCount:   len(s.Steps),
 			ToolsetID:   s.ToolsetID,
+			InputSchema: s.InputSchema,
 		})
 	}
 	return &metatools.ListSkillsOutput{Skills: out}, nil
@@ -93,6 +107,9 @@ func (h *SkillsHandler) Plan(ctx context.Context, input metatools.PlanSkillInput
 	if err != nil {
 		return nil, err
 	}
+	if def, err = def.WithInputs(input.Inputs); err != nil {
+		return nil, err
+	}
 
 	guards = append(guards, defaultMaxStepsGuard(h.defaults)...)
 	plan, err := internalskills.CompilePlan(def, guards)
@@ -100,12 +117,7 @@ func (h *SkillsHandler) Plan(ctx context.Context, input metatools.PlanSkillInput
 		return nil, err
 	}
 
-	return &metatools.PlanSkillOutput{
-		Plan: metatools.SkillPlan{
-			Name:  plan.Name,
-			Steps: stepsToMetatools(plan.Steps),
-		},
-	}, nil
+	return &metatools.PlanSkillOutput{Plan: skillPlan(plan)}, nil
 }
 
 // Run handles run_skill.
@@ -120,14 +132,75 @@ func (h *SkillsHandler) Run(ctx context.Context, input metatools.RunSkillInput)
 		return nil, false, errors.New("runner not configured")
 	}
 
+	plan, skillID, errOutput := h.compileRun(input)
+	if errOutput != nil {
+		return errOutput, true, nil
+	}
+	if input.DryRun {
+		return h.dryRun(ctx, plan, skillID, input.Mocks)
+	}
+	if h.runs == nil {
+		return h.execute(ctx, input, plan, skillID, nil, nil)
+	}
+
+	rec, err := h.runs.begin(ctx, runs.KindSkill, input, skillPlan(plan))
+	if err != nil {
+		return nil, false, err
+	}
+	output, isError, err := h.execute(rec.ctx, input, plan, skillID, rec, nil)
+	rec.finish(runOutcome(err, isError, output.Error))
+	output.RunID = rec.id
+	return output, isError, err
+}
+
+// resume continues a recorded skill run from its first incomplete step. The
+// skill must compile to the plan recorded when the run started.
+func (h *SkillsHandler) resume(rec *recording, run runs.Run) (*metatools.RunSkillOutput, bool, error) {
+	var input metatools.RunSkillInput
+	if err := json.Unmarshal(run.Request, &input); err != nil {
+		err = fmt.Errorf("decode run request: %w", err)
+		rec.finish(err)
+		return nil, false, err
+	}
+	plan, skillID, output := h.compileRun(input)
+	if output == nil {
+		if current, err := json.Marshal(skillPlan(plan)); err != nil || !bytes.Equal(current, run.Plan) {
+			err = fmt.Errorf("%w: skill %q changed since the run started", merrors.ErrValidationInput, skillID)
+			output = skillErrorOutput(err, skillID, false)
+		}
+	}
+	if output != nil {
+		rec.finish(runOutcome(nil, true, output.Error))
+		output.RunID = rec.id
+		return output, true, nil
+	}
+
+	done := make(map[string]any)
+	for _, step := range run.Steps {
+		if step.Status == runs.StatusSucceeded {
+			done[step.ID] = step.Value
+		}
+	}
+	output, isError, err := h.execute(rec.ctx, input, plan, skillID, rec, done)
+	rec.finish(runOutcome(err, isError, output.Error))
+	output.RunID = rec.id
+	return output, isError, err
+}
+
+// compileRun resolves and compiles the skill of a run_skill input and checks
+// its step limits. Failures are returned as an error output.
+func (h *SkillsHandler) compileRun(input metatools.RunSkillInput) (internalskills.Plan, string, *metatools.RunSkillOutput) {
 	def, guards, skillID, err := h.resolveSkill(input.SkillID, input.Skill)
 	if err != nil {
-		return skillErrorOutput(err, input.SkillID, false), true, nil
+		return internalskills.Plan{}, input.SkillID, skillErrorOutput(err, input.SkillID, false)
+	}
+	if def, err = def.WithInputs(input.Inputs); err != nil {
+		return internalskills.Plan{}, skillID, skillErrorOutput(err, skillID, false)
 	}
 
 	plan, err := internalskills.CompilePlan(def, append(guards, defaultMaxStepsGuard(h.defaults)...))
 	if err != nil {
-		return skillErrorOutput(err, skillID, false), true, nil
+		return internalskills.Plan{}, skillID, skillErrorOutput(err, skillID, false)
 	}
 
 	effectiveMaxSteps := h.defaults.MaxSteps
@@ -135,17 +208,25 @@ func (h *SkillsHandler) Run(ctx context.Context, input metatools.RunSkillInput)
 		effectiveMaxSteps = *input.MaxSteps
 	}
 	if effectiveMaxSteps > 0 && len(plan.Steps) > effectiveMaxSteps {
-		return skillErrorOutput(skill.ErrMaxStepsExceeded, skillID, false), true, nil
+		return internalskills.Plan{}, skillID, skillErrorOutput(skill.ErrMaxStepsExceeded, skillID, false)
 	}
 
-	effectiveMaxCalls := h.defaults.MaxToolCalls
-	if input.MaxToolCalls != nil {
-		effectiveMaxCalls = *input.MaxToolCalls
+	if maxCalls := h.maxToolCalls(input); maxCalls > 0 && len(plan.Steps) > maxCalls {
+		return internalskills.Plan{}, skillID, skillErrorOutput(skill.ErrMaxStepsExceeded, skillID, false)
 	}
-	if effectiveMaxCalls > 0 && len(plan.Steps) > effectiveMaxCalls {
-		return skillErrorOutput(skill.ErrMaxStepsExceeded, skillID, false), true, nil
+	return plan, skillID, nil
+}
+
+func (h *SkillsHandler) maxToolCalls(input metatools.RunSkillInput) int {
+	if input.MaxToolCalls != nil {
+		return *input.MaxToolCalls
 	}
+	return h.defaults.MaxToolCalls
+}
 
+// execute runs a compiled plan. With a recording, each step is recorded as
+// it finishes and steps in done, keyed by step ID, are replayed.
+func (h *SkillsHandler) execute(ctx context.Context, input metatools.RunSkillInput, plan internalskills.Plan, skillID string, rec *recording, done map[string]any) (*metatools.RunSkillOutput, bool, error) {
 	timeout := h.defaults.Timeout
 	if input.TimeoutMs != nil {
 		timeout = time.Duration(*input.TimeoutMs) * time.Millisecond
@@ -156,12 +237,32 @@ func (h *SkillsHandler) Run(ctx context.Context, input metatools.RunSkillInput)
 		defer cancel()
 	}
 
+	checkpoint := internalskills.Checkpoint{Done: done}
+	if rec != nil {
+		checkpoint.OnResult = func(i int, res internalskills.StepResult) {
+			rec.step(i, res.StepID, res.ToolID, res.Value, res.Err)
+		}
+	}
 	start := time.Now()
-	stepResults, err := skill.Execute(ctx, plan, skillRunner{runner: h.runner})
+	content := &stepContent{}
+	stepResults, err := internalskills.ExecuteWithCheckpoint(ctx, plan, skillRunner{runner: h.runner, rec: rec, content: content}, stepgraph.Options{
+		MaxParallel: h.defaults.MaxParallel,
+		Budget:      stepgraph.NewCallBudget(h.maxToolCalls(input)),
+	}, checkpoint)
 	duration := int(time.Since(start).Milliseconds())
 
+	var result map[string]any
+	if err == nil {
+		result, err = internalskills.BuildOutput(plan, stepResults)
+	}
+
+	results := stepResultsToMetatools(stepResults, plan, content)
+	if input.IncludeStepResults != nil && !*input.IncludeStepResults {
+		results = failedStepResults(results)
+	}
 	output := &metatools.RunSkillOutput{
-		Results:    stepResultsToMetatools(stepResults, plan),
+		Output:     result,
+		Results:    results,
 		DurationMs: &duration,
 	}
 
@@ -172,40 +273,110 @@ func (h *SkillsHandler) Run(ctx context.Context, input metatools.RunSkillInput)
 	return output, false, nil
 }
 
+// dryRun walks a compiled plan without calling any tool and returns the
+// would-be execution trace.
+func (h *SkillsHandler) dryRun(ctx context.Context, plan internalskills.Plan, skillID string, mocks map[string]any) (*metatools.RunSkillOutput, bool, error) {
+	if h.tools == nil {
+		return nil, false, errors.New("dry_run requires a tool index")
+	}
+	traces, result, err := internalskills.DryRun(ctx, plan, dryrun.Options{Lookup: h.tools.GetTool, Mocks: mocks})
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, false, ctxErr
+	}
+	output := &metatools.RunSkillOutput{
+		Output: result,
+		Trace:  dryRunTrace(traces),
+	}
+	if err != nil {
+		output.Error = mapSkillError(err, skillID)
+		return output, true, nil
+	}
+	return output, false, nil
+}
+
 type skillRunner struct {
-	runner Runner
+	runner  Runner
+	rec     *recording
+	content *stepContent
 }
 
 func (r skillRunner) Run(ctx context.Context, step skill.Step) (any, error) {
-	result, err := r.runner.Run(ctx, step.ToolID, step.Inputs)
+	inputs := step.Inputs
+	if r.rec != nil {
+		inputs = r.rec.withIdempotencyKey(ctx, step.ToolID, inputs, step.ID)
+	}
+	result, err := r.runner.Run(ctx, step.ToolID, inputs)
 	if err != nil {
 		return nil, err
 	}
+	r.content.set(step.ID, result.Content)
 	return result.Structured, nil
 }
 
-func (h *SkillsHandler) resolveSkill(id string, def *metatools.SkillDefinition) (skill.Skill, []skill.Guard, string, error) {
+// stepContent records the content blocks of each skill step by step ID.
+// Steps may run in parallel.
+type stepContent struct {
+	mu     sync.Mutex
+	blocks map[string][]any
+}
+
+func (c *stepContent) set(stepID string, blocks []any) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.blocks == nil {
+		c.blocks = make(map[string][]any)
+	}
+	c.blocks[stepID] = blocks
+}
+
+func (c *stepContent) get(stepID string) []any {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.blocks[stepID]
+}
+
+func (h *SkillsHandler) resolveSkill(id string, def *metatools.SkillDefinition) (internalskills.Definition, []skill.Guard, string, error) {
 	if id != "" {
 		if h.registry == nil {
-			return skill.Skill{}, nil, "", errors.New("skill registry not configured")
+			return internalskills.Definition{}, nil, "", errors.New("skill registry not configured")
 		}
 		s, ok := h.registry.Get(id)
 		if !ok || s == nil {
-			return skill.Skill{}, nil, "", errors.New("skill not found")
+			return internalskills.Definition{}, nil, "", errors.New("skill not found")
 		}
-		return skill.Skill{Name: s.Name, Steps: s.Steps}, s.Guards, s.ID, nil
+		return s.Definition(), s.Guards, s.ID, nil
 	}
 	if def == nil {
-		return skill.Skill{}, nil, "", errors.New("skill definition required")
+		return internalskills.Definition{}, nil, "", errors.New("skill definition required")
 	}
 
 	steps := make([]skill.Step, len(def.Steps))
+	var dependsOn map[string][]string
+	var onError map[string]*stepgraph.Policy
 	for i, step := range def.Steps {
 		steps[i] = skill.Step{
 			ID:     step.ID,
 			ToolID: step.ToolID,
 			Inputs: step.Inputs,
 		}
+		if step.DependsOn != nil {
+			if dependsOn == nil {
+				dependsOn = make(map[string][]string)
+			}
+			dependsOn[step.ID] = step.DependsOn
+		}
+		if step.OnError != nil {
+			if onError == nil {
+				onError = make(map[string]*stepgraph.Policy)
+			}
+			onError[step.ID] = stepPolicy(step.OnError)
+		}
 	}
 
 	if def.ToolsetID != "" && h.toolsets != nil {
@@ -216,37 +387,62 @@ func (h *SkillsHandler) resolveSkill(id string, def *metatools.SkillDefinition)
 			}
 			for _, step := range steps {
 				if _, ok := allowed[step.ToolID]; !ok {
-					return skill.Skill{}, nil, def.Name, skill.ErrToolNotAllowed
+					return internalskills.Definition{}, nil, def.Name, skill.ErrToolNotAllowed
+				}
+				if p := onError[step.ID]; p != nil && p.Action == stepgraph.ActionFallback {
+					if _, ok := allowed[p.ToolID]; !ok {
+						return internalskills.Definition{}, nil, def.Name, skill.ErrToolNotAllowed
+					}
 				}
 			}
 		}
 	}
 
-	return skill.Skill{Name: def.Name, Steps: steps}, nil, def.Name, nil
+	return internalskills.Definition{
+		Skill:        skill.Skill{Name: def.Name, Steps: steps},
+		DependsOn:    dependsOn,
+		OnError:      onError,
+		InputSchema:  def.InputSchema,
+		Output:       def.Output,
+		OutputSchema: def.OutputSchema,
+	}, nil, def.Name, nil
 }
 
 func skillDefinitionFromInternal(s *internalskills.Skill) metatools.SkillDefinition {
 	return metatools.SkillDefinition{
-		Name:        s.Name,
-		Description: s.Description,
-		Steps:       stepsToMetatools(s.Steps),
-		ToolsetID:   s.ToolsetID,
+		Name:         s.Name,
+		Description:  s.Description,
+		InputSchema:  s.InputSchema,
+		Steps:        stepsToMetatools(s.Steps, s.DependsOn, s.OnError),
+		Output:       s.Output,
+		OutputSchema: s.OutputSchema,
+		ToolsetID:    s.ToolsetID,
+	}
+}
+
+func skillPlan(plan internalskills.Plan) metatools.SkillPlan {
+	return metatools.SkillPlan{
+		Name:   plan.Name,
+		Steps:  stepsToMetatools(plan.Steps, plan.DependsOn, plan.OnError),
+		Output: plan.Output,
 	}
 }
 
-func stepsToMetatools(steps []skill.Step) []metatools.SkillStep {
+func stepsToMetatools(steps []skill.Step, dependsOn map[string][]string, onError map[string]*stepgraph.Policy) []metatools.SkillStep {
 	out := make([]metatools.SkillStep, len(steps))
 	for i, step := range steps {
 		out[i] = metatools.SkillStep{
-			ID:     step.ID,
-			ToolID: step.ToolID,
-			Inputs: step.Inputs,
+			ID:        step.ID,
+			ToolID:    step.ToolID,
+			Inputs:    step.Inputs,
+			DependsOn: dependsOn[step.ID],
+			OnError:   stepOnError(onError[step.ID]),
 		}
 	}
 	return out
 }
 
-func stepResultsToMetatools(results []skill.StepResult, plan skill.Plan) []metatools.SkillStepResult {
+func stepResultsToMetatools(results []internalskills.StepResult, plan internalskills.Plan, content *stepContent) []metatools.SkillStepResult {
 	stepToolIDs := make(map[string]string, len(plan.Steps))
 	for _, step := range plan.Steps {
 		stepToolIDs[step.ID] = step.ToolID
@@ -254,8 +450,10 @@ func stepResultsToMetatools(results []skill.StepResult, plan skill.Plan) []metat
 	out := make([]metatools.SkillStepResult, len(results))
 	for i, res := range results {
 		out[i] = metatools.SkillStepResult{
-			StepID: res.StepID,
-			Value:  res.Value,
+			StepID:  res.StepID,
+			Value:   res.Value,
+			Policy:  stepPolicyResult(res.Policy, stepToolIDs[res.StepID]),
+			Content: content.get(res.StepID),
 		}
 		if res.Err != nil {
 			out[i].Error = mapSkillError(res.Err, stepToolIDs[res.StepID])
@@ -264,6 +462,18 @@ func stepResultsToMetatools(results []skill.StepResult, plan skill.Plan) []metat
 	return out
 }
 
+// failedStepResults keeps only the results that carry an error, for callers
+// that opted out of step results.
+func failedStepResults(results []metatools.SkillStepResult) []metatools.SkillStepResult {
+	var out []metatools.SkillStepResult
+	for _, res := range results {
+		if res.Error != nil {
+			out = append(out, res)
+		}
+	}
+	return out
+}
+
 func defaultMaxStepsGuard(defaults SkillDefaults) []skill.Guard {
 	if defaults.MaxSteps <= 0 {
 		return nil
@@ -281,6 +491,7 @@ func mapSkillError(err error, toolID string) *metatools.ErrorObject {
 		errors.Is(err, skill.ErrInvalidToolID) ||
 		errors.Is(err, skill.ErrNoSteps) ||
 		errors.Is(err, skill.ErrMaxStepsExceeded) ||
+		errors.Is(err, stepgraph.ErrMaxCalls) ||
 		errors.Is(err, skill.ErrToolNotAllowed) {
 		errObj.Code = merrors.CodeValidationInput
 		errObj.Retryable = false
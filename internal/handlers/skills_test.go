@@ -3,8 +3,10 @@ package handlers
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
 
+	merrors "github.com/jonwraymond/metatools-mcp/internal/errors"
 	internalskills "github.com/jonwraymond/metatools-mcp/internal/skills"
 	"github.com/jonwraymond/metatools-mcp/internal/toolset"
 	"github.com/jonwraymond/metatools-mcp/pkg/metatools"
@@ -57,3 +59,377 @@ func TestSkillsHandler_PlanAndRun(t *testing.T) {
 	require.Equal(t, "b", out.Results[1].StepID)
 	require.NotNil(t, out.Results[1].Error)
 }
+
+func TestSkillsHandler_RunStepContent(t *testing.T) {
+	reg := internalskills.NewRegistry([]*internalskills.Skill{{
+		ID:   "skill:chart",
+		Name: "chart",
+		Steps: []skill.Step{
+			{ID: "data", ToolID: "tool:data"},
+			{ID: "render", ToolID: "tool:render"},
+		},
+	}})
+	image := map[string]any{"type": "image", "mimeType": "image/png"}
+	runner := &mockRunner{runFunc: func(_ context.Context, toolID string, _ map[string]any) (RunResult, error) {
+		if toolID == "tool:render" {
+			return RunResult{Structured: "ok", Content: []any{image}}, nil
+		}
+		return RunResult{Structured: "ok"}, nil
+	}}
+
+	handler := NewSkillsHandler(reg, toolset.NewRegistry(nil), runner, SkillDefaults{})
+	out, isError, err := handler.Run(context.Background(), metatools.RunSkillInput{SkillID: "skill:chart"})
+	require.NoError(t, err)
+	require.False(t, isError)
+	require.Len(t, out.Results, 2)
+	for _, res := range out.Results {
+		if res.StepID == "render" {
+			require.Equal(t, []any{image}, res.Content)
+		} else {
+			require.Empty(t, res.Content)
+		}
+	}
+}
+
+func TestSkillsHandler_RunResolvesStepReferences(t *testing.T) {
+	var gotInputs map[string]any
+	runner := &mockRunner{
+		runFunc: func(_ context.Context, toolID string, args map[string]any) (RunResult, error) {
+			if toolID == "tool:find" {
+				return RunResult{Structured: map[string]any{"ids": []any{"x1"}}}, nil
+			}
+			gotInputs = args
+			return RunResult{Structured: "ok"}, nil
+		},
+	}
+	handler := NewSkillsHandler(nil, toolset.NewRegistry(nil), runner, SkillDefaults{})
+
+	out, isError, err := handler.Run(context.Background(), metatools.RunSkillInput{
+		Skill: &metatools.SkillDefinition{
+			Name: "refs",
+			Steps: []metatools.SkillStep{
+				{ID: "a_find", ToolID: "tool:find"},
+				{ID: "b_use", ToolID: "tool:use", Inputs: map[string]any{
+					"id": map[string]any{"$ref": "steps.a_find.structured.ids[0]"},
+				}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, isError, "unexpected error: %+v", out.Error)
+	require.Equal(t, map[string]any{"id": "x1"}, gotInputs)
+
+	// References to steps that run later are rejected when planning.
+	plan, err := handler.Plan(context.Background(), metatools.PlanSkillInput{
+		Skill: &metatools.SkillDefinition{
+			Name: "forward",
+			Steps: []metatools.SkillStep{
+				{ID: "a", ToolID: "tool:a", Inputs: map[string]any{"v": "${steps.b.structured}"}},
+				{ID: "b", ToolID: "tool:b"},
+			},
+		},
+	})
+	require.Error(t, err)
+	require.Nil(t, plan)
+	require.ErrorIs(t, err, merrors.ErrValidationInput)
+}
+
+func TestSkillsHandler_DependsOn(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	runner := &mockRunner{
+		runFunc: func(_ context.Context, toolID string, _ map[string]any) (RunResult, error) {
+			mu.Lock()
+			calls = append(calls, toolID)
+			mu.Unlock()
+			return RunResult{Structured: toolID}, nil
+		},
+	}
+	handler := NewSkillsHandler(nil, toolset.NewRegistry(nil), runner, SkillDefaults{MaxParallel: 2})
+	def := &metatools.SkillDefinition{
+		Name: "fan-in",
+		Steps: []metatools.SkillStep{
+			{ID: "a_report", ToolID: "tool:report", DependsOn: []string{"b_issues", "c_pulls"}},
+			{ID: "b_issues", ToolID: "tool:issues"},
+			{ID: "c_pulls", ToolID: "tool:pulls"},
+		},
+	}
+
+	plan, err := handler.Plan(context.Background(), metatools.PlanSkillInput{Skill: def})
+	require.NoError(t, err)
+	require.Len(t, plan.Plan.Steps, 3)
+	require.Equal(t, "a_report", plan.Plan.Steps[2].ID)
+	require.Equal(t, []string{"b_issues", "c_pulls"}, plan.Plan.Steps[2].DependsOn)
+
+	out, isError, err := handler.Run(context.Background(), metatools.RunSkillInput{Skill: def})
+	require.NoError(t, err)
+	require.False(t, isError, "unexpected error: %+v", out.Error)
+	require.Len(t, out.Results, 3)
+	require.Equal(t, "a_report", out.Results[2].StepID)
+	require.Len(t, calls, 3)
+	require.Equal(t, "tool:report", calls[2])
+
+	// Cycles are rejected when planning.
+	_, err = handler.Plan(context.Background(), metatools.PlanSkillInput{
+		Skill: &metatools.SkillDefinition{
+			Name: "loop",
+			Steps: []metatools.SkillStep{
+				{ID: "a", ToolID: "tool:a", DependsOn: []string{"b"}},
+				{ID: "b", ToolID: "tool:b", DependsOn: []string{"a"}},
+			},
+		},
+	})
+	require.ErrorIs(t, err, merrors.ErrValidationInput)
+	require.ErrorContains(t, err, "dependency cycle")
+}
+
+func TestSkillsHandler_ErrorPolicies(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	runner := &mockRunner{
+		runFunc: func(_ context.Context, toolID string, _ map[string]any) (RunResult, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			switch toolID {
+			case "tool:flaky":
+				attempts++
+				if attempts < 2 {
+					return RunResult{}, merrors.ErrExecution
+				}
+			case "tool:down":
+				return RunResult{}, errors.New("down")
+			}
+			return RunResult{Structured: toolID}, nil
+		},
+	}
+	handler := NewSkillsHandler(nil, toolset.NewRegistry(nil), runner, SkillDefaults{MaxToolCalls: 5})
+
+	out, isError, err := handler.Run(context.Background(), metatools.RunSkillInput{
+		Skill: &metatools.SkillDefinition{
+			Name: "policies",
+			Steps: []metatools.SkillStep{
+				{ID: "a", ToolID: "tool:flaky", OnError: &metatools.StepOnError{Action: "retry", Attempts: 3, BackoffMs: 1}},
+				{ID: "b", ToolID: "tool:down", OnError: &metatools.StepOnError{Action: "fallback", ToolID: "tool:backup"}},
+				{ID: "c", ToolID: "tool:down", OnError: &metatools.StepOnError{Action: "continue"}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, isError, "unexpected error: %+v", out.Error)
+	require.Len(t, out.Results, 3)
+
+	require.Equal(t, "tool:flaky", out.Results[0].Value)
+	require.Equal(t, &metatools.StepPolicyResult{
+		Action:   "retry",
+		Attempts: 2,
+		Cause:    out.Results[0].Policy.Cause,
+	}, out.Results[0].Policy)
+	require.Equal(t, "tool:backup", out.Results[1].Value)
+	require.Equal(t, "tool:backup", out.Results[1].Policy.FallbackToolID)
+	require.Equal(t, "continue", out.Results[2].Policy.Action)
+	require.NotNil(t, out.Results[2].Error)
+
+	// Retries and fallbacks share the skill's tool call budget.
+	maxCalls := 1
+	out, isError, err = handler.Run(context.Background(), metatools.RunSkillInput{
+		MaxToolCalls: &maxCalls,
+		Skill: &metatools.SkillDefinition{
+			Name: "budget",
+			Steps: []metatools.SkillStep{
+				{ID: "a", ToolID: "tool:down", OnError: &metatools.StepOnError{Action: "fallback", ToolID: "tool:backup"}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, isError)
+	require.Equal(t, string(merrors.CodeValidationInput), out.Error.Code)
+	require.Contains(t, out.Error.Message, "max tool calls exceeded")
+
+	// Unknown actions are rejected before running.
+	_, err = handler.Plan(context.Background(), metatools.PlanSkillInput{
+		Skill: &metatools.SkillDefinition{
+			Name:  "bad",
+			Steps: []metatools.SkillStep{{ID: "a", ToolID: "tool:a", OnError: &metatools.StepOnError{Action: "skip"}}},
+		},
+	})
+	require.ErrorIs(t, err, merrors.ErrValidationInput)
+}
+
+func TestSkillsHandler_Inputs(t *testing.T) {
+	var gotInputs map[string]any
+	runner := &mockRunner{
+		runFunc: func(_ context.Context, _ string, args map[string]any) (RunResult, error) {
+			gotInputs = args
+			return RunResult{Structured: "ok"}, nil
+		},
+	}
+	reg := internalskills.NewRegistry([]*internalskills.Skill{{
+		ID:   "skill:triage",
+		Name: "triage",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"repo":  map[string]any{"type": "string"},
+				"limit": map[string]any{"type": "integer", "default": 10},
+			},
+			"required": []any{"repo"},
+		},
+		Steps: []skill.Step{{ID: "issues", ToolID: "tool:issues", Inputs: map[string]any{
+			"query": "repo:{{ inputs.repo }}",
+			"limit": "{{ inputs.limit }}",
+		}}},
+	}})
+	handler := NewSkillsHandler(reg, toolset.NewRegistry(nil), runner, SkillDefaults{})
+
+	list, err := handler.List(context.Background(), metatools.ListSkillsInput{})
+	require.NoError(t, err)
+	require.Contains(t, list.Skills[0].InputSchema, "properties")
+	desc, err := handler.Describe(context.Background(), metatools.DescribeSkillInput{SkillID: "skill:triage"})
+	require.NoError(t, err)
+	require.Equal(t, reg.List()[0].InputSchema, desc.Skill.InputSchema)
+	require.Equal(t, "repo:{{ inputs.repo }}", desc.Skill.Steps[0].Inputs["query"])
+
+	plan, err := handler.Plan(context.Background(), metatools.PlanSkillInput{
+		SkillID: "skill:triage",
+		Inputs:  map[string]any{"repo": "acme/api"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"query": "repo:acme/api", "limit": 10}, plan.Plan.Steps[0].Inputs)
+
+	_, err = handler.Plan(context.Background(), metatools.PlanSkillInput{SkillID: "skill:triage"})
+	require.ErrorIs(t, err, merrors.ErrValidationInput)
+
+	out, isError, err := handler.Run(context.Background(), metatools.RunSkillInput{
+		SkillID: "skill:triage",
+		Inputs:  map[string]any{"repo": "acme/web", "limit": 3},
+	})
+	require.NoError(t, err)
+	require.False(t, isError, "unexpected error: %+v", out.Error)
+	require.Equal(t, map[string]any{"query": "repo:acme/web", "limit": 3}, gotInputs)
+
+	out, isError, err = handler.Run(context.Background(), metatools.RunSkillInput{
+		SkillID: "skill:triage",
+		Inputs:  map[string]any{"repo": 7},
+	})
+	require.NoError(t, err)
+	require.True(t, isError)
+	require.Equal(t, string(merrors.CodeValidationInput), out.Error.Code)
+
+	// Inline skills must declare what they reference.
+	_, err = handler.Plan(context.Background(), metatools.PlanSkillInput{
+		Skill: &metatools.SkillDefinition{
+			Name:  "inline",
+			Steps: []metatools.SkillStep{{ID: "a", ToolID: "tool:a", Inputs: map[string]any{"q": "{{ inputs.q }}"}}},
+		},
+		Inputs: map[string]any{"q": "x"},
+	})
+	require.ErrorIs(t, err, merrors.ErrValidationInput)
+	require.ErrorContains(t, err, `undeclared skill input "q"`)
+}
+
+func TestSkillsHandler_Output(t *testing.T) {
+	runner := &mockRunner{
+		runFunc: func(_ context.Context, toolID string, _ map[string]any) (RunResult, error) {
+			switch toolID {
+			case "tool:issues":
+				return RunResult{Structured: map[string]any{"items": []any{map[string]any{"id": 7.0}}, "total": 1.0}}, nil
+			default:
+				return RunResult{Structured: map[string]any{"url": "https://example.test/7"}}, nil
+			}
+		},
+	}
+	def := &metatools.SkillDefinition{
+		Name: "summary",
+		Steps: []metatools.SkillStep{
+			{ID: "a_issues", ToolID: "tool:issues"},
+			{ID: "b_link", ToolID: "tool:link"},
+		},
+		Output: map[string]any{
+			"first": "${steps.a_issues.result.items[0].id}",
+			"count": map[string]any{"$ref": "steps.a_issues.result.total"},
+			"link":  "see ${steps.b_link.result.url}",
+		},
+		OutputSchema: map[string]any{
+			"type":     "object",
+			"required": []any{"first", "count", "link"},
+		},
+	}
+	handler := NewSkillsHandler(nil, toolset.NewRegistry(nil), runner, SkillDefaults{})
+
+	out, isError, err := handler.Run(context.Background(), metatools.RunSkillInput{Skill: def})
+	require.NoError(t, err)
+	require.False(t, isError, "unexpected error: %+v", out.Error)
+	require.Equal(t, map[string]any{"first": 7.0, "count": 1.0, "link": "see https://example.test/7"}, out.Output)
+	require.Len(t, out.Results, 2)
+
+	exclude := false
+	out, isError, err = handler.Run(context.Background(), metatools.RunSkillInput{Skill: def, IncludeStepResults: &exclude})
+	require.NoError(t, err)
+	require.False(t, isError)
+	require.NotNil(t, out.Output)
+	require.Empty(t, out.Results)
+
+	// A schema violation fails the run after execution.
+	def.OutputSchema = map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"first": map[string]any{"type": "string"}},
+	}
+	out, isError, err = handler.Run(context.Background(), metatools.RunSkillInput{Skill: def})
+	require.NoError(t, err)
+	require.True(t, isError)
+	require.Nil(t, out.Output)
+	require.Equal(t, string(merrors.CodeValidationOutput), out.Error.Code)
+	require.Len(t, out.Results, 2)
+
+	// Output references must name steps of the skill.
+	def.Output = map[string]any{"x": "${steps.missing.result}"}
+	_, err = handler.Plan(context.Background(), metatools.PlanSkillInput{Skill: def})
+	require.ErrorIs(t, err, merrors.ErrValidationInput)
+	require.ErrorContains(t, err, `unknown step "missing"`)
+}
+
+func TestSkillsHandler_DryRun(t *testing.T) {
+	runner := &mockRunner{
+		runFunc: func(_ context.Context, toolID string, _ map[string]any) (RunResult, error) {
+			t.Fatalf("dry run must not call %s", toolID)
+			return RunResult{}, nil
+		},
+	}
+	handler := NewSkillsHandler(nil, toolset.NewRegistry(nil), runner, SkillDefaults{}, WithTools(dryRunTools()))
+	def := &metatools.SkillDefinition{
+		Name: "dry",
+		Steps: []metatools.SkillStep{
+			{ID: "a_find", ToolID: "t:find"},
+			{ID: "b_use", ToolID: "t:use", Inputs: map[string]any{"id": "${steps.a_find.result.ids[0]}"}},
+		},
+		Output: map[string]any{"first": "${steps.a_find.result.ids[0]}"},
+	}
+
+	// t:use has no output schema, so the output mapping needs a mock.
+	out, isError, err := handler.Run(context.Background(), metatools.RunSkillInput{Skill: def, DryRun: true})
+	require.NoError(t, err)
+	require.False(t, isError, "unexpected error: %+v", out.Error)
+	require.Len(t, out.Trace, 2)
+	require.Equal(t, "schema", out.Trace[0].OutputSource)
+	require.Equal(t, map[string]any{"id": ""}, out.Trace[1].Args)
+	require.Nil(t, out.Output)
+
+	out, isError, err = handler.Run(context.Background(), metatools.RunSkillInput{
+		Skill:  def,
+		DryRun: true,
+		Mocks: map[string]any{
+			"t:find": map[string]any{"ids": []any{"x1"}},
+			"t:use":  map[string]any{"ok": true},
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, isError, "unexpected error: %+v", out.Error)
+	require.Equal(t, map[string]any{"first": "x1"}, out.Output)
+
+	def.Steps[1].Inputs = map[string]any{}
+	out, isError, err = handler.Run(context.Background(), metatools.RunSkillInput{Skill: def, DryRun: true})
+	require.NoError(t, err)
+	require.True(t, isError)
+	require.Equal(t, string(merrors.CodeValidationInput), out.Error.Code)
+	require.NotNil(t, out.Trace[1].Error)
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/jonwraymond/metatools-mcp/internal/stepgraph"
+	"github.com/jonwraymond/metatools-mcp/pkg/metatools"
+)
+
+// stepPolicy converts a step's on_error setting into a stepgraph policy.
+func stepPolicy(in *metatools.StepOnError) *stepgraph.Policy {
+	if in == nil {
+		return nil
+	}
+	return &stepgraph.Policy{
+		Action:   in.Action,
+		Attempts: in.Attempts,
+		Backoff:  time.Duration(in.BackoffMs) * time.Millisecond,
+		ToolID:   in.ToolID,
+		Args:     in.Args,
+	}
+}
+
+// stepOnError is the inverse of stepPolicy, for describing configured skills.
+func stepOnError(p *stepgraph.Policy) *metatools.StepOnError {
+	if p == nil {
+		return nil
+	}
+	return &metatools.StepOnError{
+		Action:    p.Action,
+		Attempts:  p.Attempts,
+		BackoffMs: int(p.Backoff / time.Millisecond),
+		ToolID:    p.ToolID,
+		Args:      p.Args,
+	}
+}
+
+// stepPolicyResult reports an applied policy; the cause is mapped against the
+// step's own tool.
+func stepPolicyResult(report *stepgraph.Report, toolID string) *metatools.StepPolicyResult {
+	if report == nil {
+		return nil
+	}
+	out := &metatools.StepPolicyResult{
+		Action:         report.Action,
+		Attempts:       report.Attempts,
+		FallbackToolID: report.FallbackToolID,
+	}
+	if report.Cause != nil {
+		out.Cause = mapSkillError(report.Cause, toolID)
+	}
+	return out
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"strings"
 
+	"github.com/jonwraymond/metatools-mcp/internal/scope"
+	"github.com/jonwraymond/metatools-mcp/internal/toolset"
 	"github.com/jonwraymond/metatools-mcp/pkg/metatools"
 	"github.com/jonwraymond/toolfoundation/adapter"
 )
@@ -31,6 +33,11 @@ func (h *ToolsetsHandler) List(ctx context.Context, input metatools.ListToolsets
 		return nil, errors.New("toolset registry not configured")
 	}
 
+	sc := scope.FromContext(ctx)
+	active := make(map[string]bool)
+	for _, a := range sc.Active() {
+		active[a.ID] = true
+	}
 	toolsets := h.registry.List()
 	out := make([]metatools.ToolsetSummary, 0, len(toolsets))
 	for _, ts := range toolsets {
@@ -42,11 +49,97 @@ func (h *ToolsetsHandler) List(ctx context.Context, input metatools.ListToolsets
 			Name:        ts.Name,
 			Description: ts.Description,
 			ToolCount:   len(ts.Tools),
+			Active:      active[ts.ID],
 		})
 	}
 	return &metatools.ListToolsetsOutput{Toolsets: out}, nil
 }
 
+// Activate handles activate_toolset. It limits the calling session's
+// searches, listings and runs to the members of its active toolsets.
+func (h *ToolsetsHandler) Activate(ctx context.Context, input metatools.ActivateToolsetInput) (*metatools.ActiveToolsetsOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	sc, err := h.sessionScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ts, err := h.resolve(input.ToolsetID)
+	if err != nil {
+		return nil, err
+	}
+	changed := sc.Activate(ts.ID, input.Direct)
+	return h.activeOutput(sc, changed), nil
+}
+
+// Deactivate handles deactivate_toolset. Deactivating the last active
+// toolset lifts the session's restriction.
+func (h *ToolsetsHandler) Deactivate(ctx context.Context, input metatools.DeactivateToolsetInput) (*metatools.ActiveToolsetsOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	sc, err := h.sessionScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id := strings.TrimSpace(input.ToolsetID)
+	// A toolset removed by a reload can still be deactivated by ID.
+	if ts, err := h.resolve(id); err == nil {
+		id = ts.ID
+	}
+	changed := sc.Deactivate(id)
+	return h.activeOutput(sc, changed), nil
+}
+
+func (h *ToolsetsHandler) sessionScope(ctx context.Context) (*scope.Scope, error) {
+	if h.registry == nil {
+		return nil, errors.New("toolset registry not configured")
+	}
+	sc := scope.FromContext(ctx)
+	if sc == nil {
+		return nil, errors.New("toolset activation requires a session")
+	}
+	return sc, nil
+}
+
+// resolve finds a toolset by ID, by ID without the "toolset:" prefix, or by
+// name.
+func (h *ToolsetsHandler) resolve(raw string) (*toolset.Toolset, error) {
+	raw = strings.TrimSpace(raw)
+	for _, id := range []string{raw, "toolset:" + raw} {
+		if ts, ok := h.registry.Get(id); ok && ts != nil {
+			return ts, nil
+		}
+	}
+	for _, ts := range h.registry.List() {
+		if ts != nil && ts.Name == raw {
+			return ts, nil
+		}
+	}
+	return nil, errors.New("toolset not found")
+}
+
+func (h *ToolsetsHandler) activeOutput(sc *scope.Scope, changed bool) *metatools.ActiveToolsetsOutput {
+	active := sc.Active()
+	out := &metatools.ActiveToolsetsOutput{Active: make([]metatools.ActiveToolset, 0, len(active)), Changed: changed}
+	for _, a := range active {
+		entry := metatools.ActiveToolset{ID: a.ID, Direct: a.Direct}
+		if ts, ok := h.registry.Get(a.ID); ok && ts != nil {
+			entry.Name = ts.Name
+			entry.ToolCount = len(ts.Tools)
+		}
+		out.Active = append(out.Active, entry)
+	}
+	return out
+}
+
 // Describe handles describe_toolset.
 func (h *ToolsetsHandler) Describe(ctx context.Context, input metatools.DescribeToolsetInput) (*metatools.DescribeToolsetOutput, error) {
 	if err := ctx.Err(); err != nil {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/jonwraymond/metatools-mcp/internal/scope"
 	"github.com/jonwraymond/metatools-mcp/internal/toolset"
 	"github.com/jonwraymond/metatools-mcp/pkg/metatools"
 	"github.com/jonwraymond/toolfoundation/adapter"
@@ -40,3 +41,41 @@ func TestToolsetsHandler_ListAndDescribe(t *testing.T) {
 	require.Len(t, desc.Toolset.Tools, 1)
 	require.Equal(t, "ns:alpha", desc.Toolset.Tools[0].ID)
 }
+
+func TestToolsetsHandler_ActivateAndDeactivate(t *testing.T) {
+	reg := toolset.NewRegistry([]*toolset.Toolset{
+		{ID: "toolset:alpha", Name: "Alpha", Tools: []*adapter.CanonicalTool{{Namespace: "ns", Name: "alpha"}}},
+		{ID: "toolset:beta", Name: "Beta"},
+	})
+	handler := NewToolsetsHandler(reg)
+
+	_, err := handler.Activate(context.Background(), metatools.ActivateToolsetInput{ToolsetID: "alpha"})
+	require.ErrorContains(t, err, "requires a session")
+
+	sc := scope.New(reg, nil)
+	ctx := scope.WithScope(context.Background(), sc)
+
+	out, err := handler.Activate(ctx, metatools.ActivateToolsetInput{ToolsetID: "Alpha", Direct: true})
+	require.NoError(t, err)
+	require.True(t, out.Changed)
+	require.Equal(t, []metatools.ActiveToolset{{ID: "toolset:alpha", Name: "Alpha", Direct: true, ToolCount: 1}}, out.Active)
+	require.True(t, sc.Publishes("ns:alpha"))
+
+	_, err = handler.Activate(ctx, metatools.ActivateToolsetInput{ToolsetID: "missing"})
+	require.ErrorContains(t, err, "toolset not found")
+
+	list, err := handler.List(ctx, metatools.ListToolsetsInput{})
+	require.NoError(t, err)
+	require.True(t, list.Toolsets[0].Active)
+	require.False(t, list.Toolsets[1].Active)
+
+	out, err = handler.Deactivate(ctx, metatools.DeactivateToolsetInput{ToolsetID: "alpha"})
+	require.NoError(t, err)
+	require.True(t, out.Changed)
+	require.Empty(t, out.Active)
+	require.False(t, sc.Restricted())
+
+	out, err = handler.Deactivate(ctx, metatools.DeactivateToolsetInput{ToolsetID: "alpha"})
+	require.NoError(t, err)
+	require.False(t, out.Changed)
+}
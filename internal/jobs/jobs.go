@@ -0,0 +1,385 @@
+// Package jobs runs async metatool calls in the background. A Manager bounds
+// how many jobs run at once, tracks their progress and partial output, and
+// keeps finished jobs in memory until their TTL expires.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jonwraymond/toolops/auth"
+)
+
+// Job statuses.
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCanceled  = "canceled"
+)
+
+// Defaults applied to zero Options fields.
+const (
+	DefaultMaxConcurrent = 4
+	DefaultTTL           = time.Hour
+)
+
+var (
+	// ErrNotFound is returned for unknown jobs and jobs of another owner.
+	ErrNotFound = errors.New("job not found")
+	// ErrClosed is returned by Submit after Close. It is also the
+	// cancellation cause of jobs stopped by Close.
+	ErrClosed = errors.New("job manager closed")
+	// ErrCanceled is the cancellation cause of jobs stopped by Cancel.
+	ErrCanceled = errors.New("job canceled")
+)
+
+// Options configures a Manager.
+type Options struct {
+	// MaxConcurrent bounds how many jobs run at once; further jobs queue.
+	MaxConcurrent int
+	// TTL is how long finished jobs are kept.
+	TTL time.Duration
+}
+
+// Progress is the latest progress a job reported.
+type Progress struct {
+	Progress float64
+	Total    float64
+	Message  string
+}
+
+// Job is a snapshot of a submitted job.
+type Job struct {
+	ID     string
+	Kind   string
+	Owner  string
+	Status string
+	// Progress is nil until the job reports progress.
+	Progress *Progress
+	// Partial is the latest partial output reported while running.
+	Partial any
+	// Result is the job's output once finished. IsError marks a result that
+	// reports a tool error.
+	Result  any
+	IsError bool
+	// Error is set when the job failed without a result or was canceled.
+	Error      string
+	CreatedAt  time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// Finished reports whether the job has stopped.
+func (j Job) Finished() bool {
+	return j.Status != StatusQueued && j.Status != StatusRunning
+}
+
+// Func is the work of a job. It runs with a context that is canceled by
+// Cancel and Close, with ErrCanceled or ErrClosed as the cause; Report(ctx)
+// returns the job's Reporter. A non-nil error
+// fails the job; isError fails it while keeping result.
+type Func func(ctx context.Context) (result any, isError bool, err error)
+
+// Manager runs jobs in a bounded worker pool.
+type Manager struct {
+	ttl   time.Duration
+	slots chan struct{}
+
+	mu     sync.Mutex
+	jobs   map[string]*entry
+	closed bool
+	wg     sync.WaitGroup
+}
+
+type entry struct {
+	mu     sync.Mutex
+	job    Job
+	cancel context.CancelCauseFunc
+	done   chan struct{}
+}
+
+// NewManager creates a job manager.
+func NewManager(opts Options) *Manager {
+	if opts.MaxConcurrent <= 0 {
+		opts.MaxConcurrent = DefaultMaxConcurrent
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultTTL
+	}
+	return &Manager{
+		ttl:   opts.TTL,
+		slots: make(chan struct{}, opts.MaxConcurrent),
+		jobs:  make(map[string]*entry),
+	}
+}
+
+// Submit queues fn as a job of kind for owner and returns immediately. The
+// job keeps ctx's values but not its cancellation, so it outlives the call
+// that submitted it.
+func (m *Manager) Submit(ctx context.Context, kind, owner string, fn Func) (Job, error) {
+	m.prune()
+	id, err := newJobID()
+	if err != nil {
+		return Job{}, err
+	}
+	jobCtx, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+	e := &entry{
+		job: Job{
+			ID:        id,
+			Kind:      kind,
+			Owner:     owner,
+			Status:    StatusQueued,
+			CreatedAt: time.Now().UTC(),
+		},
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		cancel(ErrClosed)
+		return Job{}, ErrClosed
+	}
+	m.jobs[id] = e
+	m.wg.Add(1)
+	m.mu.Unlock()
+
+	go m.run(context.WithValue(jobCtx, reporterKey{}, &Reporter{entry: e}), e, fn)
+	return e.snapshot(), nil
+}
+
+func (m *Manager) run(ctx context.Context, e *entry, fn Func) {
+	defer m.wg.Done()
+	defer close(e.done)
+	defer e.cancel(nil)
+
+	select {
+	case m.slots <- struct{}{}:
+	case <-ctx.Done():
+		e.finish(nil, false, ctx.Err())
+		return
+	}
+	defer func() { <-m.slots }()
+
+	e.mu.Lock()
+	if e.job.Status == StatusCanceled {
+		e.mu.Unlock()
+		return
+	}
+	e.job.Status = StatusRunning
+	e.job.StartedAt = time.Now().UTC()
+	e.mu.Unlock()
+
+	result, isError, err := call(ctx, fn)
+	e.finish(result, isError, err)
+}
+
+// call runs fn, turning a panic into a job error so one job cannot take the
+// server down.
+func call(ctx context.Context, fn Func) (result any, isError bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, isError, err = nil, false, fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
+
+// Get returns owner's job id.
+func (m *Manager) Get(owner, id string) (Job, error) {
+	m.prune()
+	e, err := m.lookup(owner, id)
+	if err != nil {
+		return Job{}, err
+	}
+	return e.snapshot(), nil
+}
+
+// List returns owner's jobs, newest first.
+func (m *Manager) List(owner string) []Job {
+	m.prune()
+	m.mu.Lock()
+	out := make([]Job, 0, len(m.jobs))
+	for _, e := range m.jobs {
+		if job := e.snapshot(); job.Owner == owner {
+			out = append(out, job)
+		}
+	}
+	m.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].ID > out[j].ID
+		}
+		return out[i].CreatedAt.After(out[j].CreatedAt)
+	})
+	return out
+}
+
+// Cancel stops owner's job id and waits until it has stopped or ctx ends.
+// Canceling a job that already finished returns it unchanged.
+func (m *Manager) Cancel(ctx context.Context, owner, id string) (Job, error) {
+	e, err := m.lookup(owner, id)
+	if err != nil {
+		return Job{}, err
+	}
+	e.mu.Lock()
+	if !e.job.Finished() {
+		e.setFinished(StatusCanceled, ErrCanceled.Error())
+	}
+	e.mu.Unlock()
+	e.cancel(ErrCanceled)
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		return e.snapshot(), ctx.Err()
+	}
+	return e.snapshot(), nil
+}
+
+// Close cancels every job and waits for them to stop. Submit fails
+// afterwards.
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	m.closed = true
+	for _, e := range m.jobs {
+		e.cancel(ErrClosed)
+	}
+	m.mu.Unlock()
+	m.wg.Wait()
+	return nil
+}
+
+func (m *Manager) lookup(owner, id string) (*entry, error) {
+	m.mu.Lock()
+	e, ok := m.jobs[id]
+	m.mu.Unlock()
+	if !ok || e.snapshot().Owner != owner {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return e, nil
+}
+
+// prune drops finished jobs older than the TTL.
+func (m *Manager) prune() {
+	cutoff := time.Now().Add(-m.ttl)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, e := range m.jobs {
+		job := e.snapshot()
+		if job.Finished() && job.FinishedAt.Before(cutoff) {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+func (e *entry) snapshot() Job {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	job := e.job
+	if job.Progress != nil {
+		p := *job.Progress
+		job.Progress = &p
+	}
+	return job
+}
+
+// finish records the outcome of fn unless the job was canceled first.
+func (e *entry) finish(result any, isError bool, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.job.Finished() {
+		return
+	}
+	e.job.Result = result
+	e.job.IsError = isError
+	switch {
+	case err != nil:
+		status := StatusFailed
+		if errors.Is(err, context.Canceled) {
+			status = StatusCanceled
+		}
+		e.setFinished(status, err.Error())
+	case isError:
+		e.setFinished(StatusFailed, "")
+	default:
+		e.setFinished(StatusSucceeded, "")
+	}
+}
+
+func (e *entry) setFinished(status, msg string) {
+	e.job.Status = status
+	e.job.Error = msg
+	e.job.FinishedAt = time.Now().UTC()
+}
+
+type sessionKey struct{}
+
+// WithSession returns a context whose caller is identified by session when
+// it has no authenticated principal.
+func WithSession(ctx context.Context, session string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, session)
+}
+
+// Owner returns the owner of the jobs of ctx's caller: its authenticated
+// principal, else its session. Without either, every caller shares the
+// owner "".
+func Owner(ctx context.Context) string {
+	if principal := auth.PrincipalFromContext(ctx); principal != "" {
+		return principal
+	}
+	if session, _ := ctx.Value(sessionKey{}).(string); session != "" {
+		return "session:" + session
+	}
+	return ""
+}
+
+// Reporter updates a running job's progress and partial output.
+type Reporter struct {
+	entry *entry
+}
+
+type reporterKey struct{}
+
+// Report returns the Reporter of the job running with ctx, or nil outside a
+// job. A nil Reporter ignores updates.
+func Report(ctx context.Context) *Reporter {
+	r, _ := ctx.Value(reporterKey{}).(*Reporter)
+	return r
+}
+
+// Progress records the job's latest progress.
+func (r *Reporter) Progress(p Progress) {
+	if r == nil {
+		return
+	}
+	r.entry.mu.Lock()
+	r.entry.job.Progress = &p
+	r.entry.mu.Unlock()
+}
+
+// Partial replaces the job's partial output.
+func (r *Reporter) Partial(v any) {
+	if r == nil {
+		return
+	}
+	r.entry.mu.Lock()
+	r.entry.job.Partial = v
+	r.entry.mu.Unlock()
+}
+
+func newJobID() (string, error) {
+	var b [12]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("generate job id: %w", err)
+	}
+	return "job_" + hex.EncodeToString(b[:]), nil
+}
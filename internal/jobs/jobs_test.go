@@ -0,0 +1,176 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jonwraymond/toolops/auth"
+	"github.com/stretchr/testify/require"
+)
+
+func waitFinished(t *testing.T, m *Manager, owner, id string) Job {
+	t.Helper()
+	var job Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = m.Get(owner, id)
+		require.NoError(t, err)
+		return job.Finished()
+	}, 2*time.Second, 5*time.Millisecond)
+	return job
+}
+
+func TestManager_SubmitReportsProgressAndResult(t *testing.T) {
+	m := NewManager(Options{})
+	defer func() { _ = m.Close() }()
+
+	release := make(chan struct{})
+	reported := make(chan struct{})
+	job, err := m.Submit(context.Background(), "run_tool", "alice", func(ctx context.Context) (any, bool, error) {
+		Report(ctx).Progress(Progress{Progress: 1, Total: 2, Message: "half"})
+		Report(ctx).Partial([]string{"first"})
+		close(reported)
+		<-release
+		return map[string]any{"ok": true}, false, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, StatusQueued, job.Status)
+	require.Equal(t, "run_tool", job.Kind)
+
+	<-reported
+	running, err := m.Get("alice", job.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusRunning, running.Status)
+	require.Equal(t, &Progress{Progress: 1, Total: 2, Message: "half"}, running.Progress)
+	require.Equal(t, []string{"first"}, running.Partial)
+
+	close(release)
+	done := waitFinished(t, m, "alice", job.ID)
+	require.Equal(t, StatusSucceeded, done.Status)
+	require.Equal(t, map[string]any{"ok": true}, done.Result)
+	require.False(t, done.FinishedAt.IsZero())
+}
+
+func TestManager_Failures(t *testing.T) {
+	m := NewManager(Options{})
+	defer func() { _ = m.Close() }()
+
+	failed, err := m.Submit(context.Background(), "run_tool", "", func(context.Context) (any, bool, error) {
+		return nil, false, errors.New("boom")
+	})
+	require.NoError(t, err)
+	toolError, err := m.Submit(context.Background(), "run_tool", "", func(context.Context) (any, bool, error) {
+		return "bad args", true, nil
+	})
+	require.NoError(t, err)
+	panicked, err := m.Submit(context.Background(), "run_tool", "", func(context.Context) (any, bool, error) {
+		panic("oops")
+	})
+	require.NoError(t, err)
+
+	job := waitFinished(t, m, "", failed.ID)
+	require.Equal(t, StatusFailed, job.Status)
+	require.Equal(t, "boom", job.Error)
+
+	job = waitFinished(t, m, "", toolError.ID)
+	require.Equal(t, StatusFailed, job.Status)
+	require.True(t, job.IsError)
+	require.Equal(t, "bad args", job.Result)
+
+	job = waitFinished(t, m, "", panicked.ID)
+	require.Equal(t, StatusFailed, job.Status)
+	require.Contains(t, job.Error, "oops")
+}
+
+func TestManager_ConcurrencyLimitAndCancel(t *testing.T) {
+	m := NewManager(Options{MaxConcurrent: 1})
+	defer func() { _ = m.Close() }()
+
+	started := make(chan struct{})
+	blocking := func(ctx context.Context) (any, bool, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, false, ctx.Err()
+	}
+	first, err := m.Submit(context.Background(), "run_chain", "alice", blocking)
+	require.NoError(t, err)
+	<-started
+	second, err := m.Submit(context.Background(), "run_chain", "alice", func(context.Context) (any, bool, error) {
+		t.Error("canceled queued job must not run")
+		return nil, false, nil
+	})
+	require.NoError(t, err)
+
+	job, err := m.Get("alice", second.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusQueued, job.Status)
+
+	job, err = m.Cancel(context.Background(), "alice", second.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusCanceled, job.Status)
+
+	job, err = m.Cancel(context.Background(), "alice", first.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusCanceled, job.Status)
+	require.False(t, job.StartedAt.IsZero())
+}
+
+func TestManager_OwnerScopeAndTTL(t *testing.T) {
+	m := NewManager(Options{TTL: 20 * time.Millisecond})
+	defer func() { _ = m.Close() }()
+
+	job, err := m.Submit(context.Background(), "execute_code", "alice", func(context.Context) (any, bool, error) {
+		return 1, false, nil
+	})
+	require.NoError(t, err)
+	waitFinished(t, m, "alice", job.ID)
+
+	_, err = m.Get("bob", job.ID)
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = m.Cancel(context.Background(), "bob", job.ID)
+	require.ErrorIs(t, err, ErrNotFound)
+	require.Empty(t, m.List("bob"))
+	require.Len(t, m.List("alice"), 1)
+
+	require.Eventually(t, func() bool {
+		_, err := m.Get("alice", job.ID)
+		return errors.Is(err, ErrNotFound)
+	}, 2*time.Second, 5*time.Millisecond)
+}
+
+func TestManager_Close(t *testing.T) {
+	m := NewManager(Options{})
+	started := make(chan struct{})
+	job, err := m.Submit(context.Background(), "run_tool", "", func(ctx context.Context) (any, bool, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, false, ctx.Err()
+	})
+	require.NoError(t, err)
+	<-started
+
+	require.NoError(t, m.Close())
+	got, err := m.Get("", job.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusCanceled, got.Status)
+
+	_, err = m.Submit(context.Background(), "run_tool", "", func(context.Context) (any, bool, error) { return nil, false, nil })
+	require.ErrorIs(t, err, ErrClosed)
+}
+
+func TestOwner(t *testing.T) {
+	ctx := context.Background()
+	require.Empty(t, Owner(ctx))
+
+	a := Owner(WithSession(ctx, "a"))
+	b := Owner(WithSession(ctx, "b"))
+	require.NotEmpty(t, a)
+	require.NotEqual(t, a, b)
+
+	// An authenticated principal owns its jobs across sessions.
+	alice := auth.WithIdentity(ctx, &auth.Identity{Principal: "alice"})
+	require.Equal(t, "alice", Owner(WithSession(alice, "a")))
+	require.Equal(t, "alice", Owner(WithSession(alice, "b")))
+}
@@ -0,0 +1,39 @@
+// Package jsonshape converts Go values to the generic shape encoding/json
+// decodes into: map[string]any, []any, string, float64, bool and nil.
+// Schema validators, path lookups and schema renderers work on that shape,
+// while tool results, schemas and YAML-decoded values arrive typed.
+package jsonshape
+
+import "encoding/json"
+
+// Normalize returns v in the generic JSON shape by round-tripping it
+// through encoding/json. When v cannot be encoded or decoded, Normalize
+// returns v unchanged together with the error.
+func Normalize(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v, err
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return v, err
+	}
+	return out, nil
+}
+
+// Map returns v, which must encode to a JSON object or null, as a generic
+// JSON object. It returns nil for nil values and values encoding to null.
+func Map(v any) (map[string]any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
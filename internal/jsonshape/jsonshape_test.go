@@ -0,0 +1,48 @@
+package jsonshape
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type point struct {
+	X int `json:"x"`
+	Y int `json:"y,omitempty"`
+}
+
+func TestNormalize(t *testing.T) {
+	got, err := Normalize(map[string]any{"p": point{X: 1}, "n": []int{2, 3}, "s": map[string]string{"a": "b"}})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"p": map[string]any{"x": float64(1)},
+		"n": []any{float64(2), float64(3)},
+		"s": map[string]any{"a": "b"},
+	}, got)
+
+	got, err = Normalize(nil)
+	require.NoError(t, err)
+	require.Nil(t, got)
+
+	// Values that cannot be encoded are returned as they are.
+	ch := make(chan int)
+	got, err = Normalize(ch)
+	require.Error(t, err)
+	require.Equal(t, ch, got)
+}
+
+func TestMap(t *testing.T) {
+	got, err := Map(point{X: 1, Y: 2})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"x": float64(1), "y": float64(2)}, got)
+
+	got, err = Map(nil)
+	require.NoError(t, err)
+	require.Nil(t, got)
+	got, err = Map((*point)(nil))
+	require.NoError(t, err)
+	require.Nil(t, got)
+
+	_, err = Map([]int{1})
+	require.Error(t, err)
+}
@@ -17,6 +17,8 @@ import (
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/jonwraymond/metatools-mcp/internal/mcplog"
+	"github.com/jonwraymond/metatools-mcp/internal/roots"
 	"github.com/jonwraymond/tooldiscovery/index"
 	"github.com/jonwraymond/toolexec/run"
 	"github.com/jonwraymond/toolfoundation/model"
@@ -28,6 +30,8 @@ type Config struct {
 	URL        string
 	Headers    map[string]string
 	MaxRetries int
+	// ShareRoots offers each caller's roots to the backend, see syncRoots.
+	ShareRoots bool
 	// Transport overrides URL handling when provided (used for tests).
 	Transport mcp.Transport
 }
@@ -48,6 +52,13 @@ type backend struct {
 	mu          sync.RWMutex
 	connected   bool
 	lastRefresh time.Time
+	// calls holds the contexts of calls in flight, keyed by a unique
+	// pointer, so backend log notifications reach their callers.
+	calls sync.Map
+	// roots are the client roots offered to the backend, those of its most
+	// recent caller with known roots; rootsMu serializes their updates.
+	rootsMu sync.Mutex
+	roots   []*mcp.Root
 }
 
 // RefreshPolicy controls MCP backend refresh behavior.
@@ -331,7 +342,11 @@ func (m *Manager) ToolsSnapshot() map[string][]model.Tool {
 	return out
 }
 
-// CallTool executes a tool on a remote MCP backend.
+// CallTool executes a tool on a remote MCP backend. When ctx ends first the
+// upstream request is canceled and the error records the cancellation cause.
+// When the backend shares roots and ctx carries the caller's roots, the
+// backend is offered those roots and told about the change before the call.
+// The backend keeps one roots list for all callers, see syncRoots.
 func (m *Manager) CallTool(ctx context.Context, serverName string, params *mcp.CallToolParams) (*mcp.CallToolResult, error) {
 	backend, err := m.lookupBackend(serverName)
 	if err != nil {
@@ -347,7 +362,19 @@ func (m *Manager) CallTool(ctx context.Context, serverName string, params *mcp.C
 	if session == nil {
 		return nil, fmt.Errorf("mcp backend %q not connected", serverName)
 	}
-	return session.CallTool(ctx, params)
+	if list, ok := roots.FromContext(ctx); ok && backend.config.ShareRoots {
+		backend.syncRoots(list)
+	}
+	defer backend.trackCall(ctx)()
+	res, err := session.CallTool(ctx, params)
+	if err != nil && ctx.Err() != nil {
+		// The SDK has sent notifications/cancelled for the upstream request,
+		// so the backend can stop work the caller no longer waits for.
+		reason := context.Cause(ctx).Error()
+		slog.Default().InfoContext(ctx, "mcp backend call canceled", "backend", serverName, "tool", params.Name, "reason", reason)
+		return nil, fmt.Errorf("mcp backend %q: call %q canceled: %s: %w", serverName, params.Name, reason, err)
+	}
+	return res, err
 }
 
 // CallToolStream is not supported by the MCP SDK client yet.
@@ -415,21 +442,134 @@ func (b *backend) connect(ctx context.Context) error {
 		return err
 	}
 
-	client := mcp.NewClient(&mcp.Implementation{Name: "metatools-mcp-backend"}, nil)
+	client := mcp.NewClient(&mcp.Implementation{Name: "metatools-mcp-backend"}, &mcp.ClientOptions{
+		LoggingMessageHandler: b.handleLog,
+	})
+	b.rootsMu.Lock()
+	offered := b.roots
+	client.AddRoots(offered...)
+	b.rootsMu.Unlock()
 	session, err := client.Connect(ctx, transport, nil)
 	if err != nil {
 		return err
 	}
+	if res := session.InitializeResult(); res != nil && res.Capabilities != nil && res.Capabilities.Logging != nil {
+		// Ask for everything; each client session filters by its own level.
+		if err := session.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "debug"}); err != nil {
+			slog.Default().WarnContext(ctx, "mcp backend set logging level failed", "backend", b.config.Name, "err", err)
+		}
+	}
 
+	b.rootsMu.Lock()
+	defer b.rootsMu.Unlock()
 	b.mu.Lock()
 	b.client = client
 	b.session = session
 	b.connected = true
 	b.lastRefresh = time.Now()
 	b.mu.Unlock()
+	// Callers may have brought new roots while the client connected.
+	if !sameRoots(offered, b.roots) {
+		applyRoots(client, offered, b.roots)
+	}
 	return nil
 }
 
+// syncRoots offers list as the roots of the backend's client. The client
+// notifies the backend when they change, so it can fetch them again.
+//
+// The connection is shared by every session, so the backend only ever sees
+// the roots of its latest caller. While sessions with different roots call
+// concurrently, a call may run upstream with another session's roots, which
+// is why roots are only shared with backends configured to share them. The
+// roots guard checks each call against its own caller's roots.
+func (b *backend) syncRoots(list []*mcp.Root) {
+	b.rootsMu.Lock()
+	defer b.rootsMu.Unlock()
+	if sameRoots(b.roots, list) {
+		return
+	}
+	b.mu.RLock()
+	client := b.client
+	b.mu.RUnlock()
+	if client != nil {
+		applyRoots(client, b.roots, list)
+	}
+	b.roots = list
+}
+
+// applyRoots replaces the roots old of client with list.
+func applyRoots(client *mcp.Client, old, list []*mcp.Root) {
+	keep := make(map[string]struct{}, len(list))
+	for _, r := range list {
+		keep[r.URI] = struct{}{}
+	}
+	var stale []string
+	for _, r := range old {
+		if _, ok := keep[r.URI]; !ok {
+			stale = append(stale, r.URI)
+		}
+	}
+	if len(stale) > 0 {
+		client.RemoveRoots(stale...)
+	}
+	client.AddRoots(list...)
+}
+
+func sameRoots(a, b []*mcp.Root) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].URI != b[i].URI || a[i].Name != b[i].Name {
+			return false
+		}
+	}
+	return true
+}
+
+// trackCall registers ctx as a call in flight until the returned func runs.
+func (b *backend) trackCall(ctx context.Context) func() {
+	key := new(byte)
+	b.calls.Store(key, ctx)
+	return func() { b.calls.Delete(key) }
+}
+
+// handleLog relays a backend log notification to the client session of the
+// calls in flight on the backend. Notifications are not tied to a request,
+// so they are only relayed while every call in flight belongs to one
+// session; otherwise, or without callers, the entry goes to the process log
+// so that no session sees logs produced for another.
+func (b *backend) handleLog(ctx context.Context, req *mcp.LoggingMessageRequest) {
+	params := *req.Params
+	if params.Logger == "" {
+		params.Logger = b.config.Name
+	} else {
+		params.Logger = b.config.Name + "/" + params.Logger
+	}
+	var (
+		caller  context.Context
+		session *mcp.ServerSession
+		shared  bool
+	)
+	b.calls.Range(func(_, v any) bool {
+		callCtx := v.(context.Context)
+		s := mcplog.Session(callCtx)
+		switch {
+		case s == nil:
+		case session == nil:
+			caller, session = callCtx, s
+		case s != session:
+			shared = true
+			return false
+		}
+		return true
+	})
+	if shared || caller == nil || !mcplog.Relay(caller, &params) {
+		slog.Default().Log(ctx, mcplog.Level(params.Level), "mcp backend log", "backend", b.config.Name, "logger", req.Params.Logger, "data", params.Data)
+	}
+}
+
 func (b *backend) fetchTools(ctx context.Context) ([]model.Tool, error) {
 	if err := b.ensureConnected(ctx); err != nil {
 		return nil, err
@@ -3,11 +3,16 @@ package mcpbackend
 import (
 	"context"
 	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/jonwraymond/metatools-mcp/internal/mcplog"
+	"github.com/jonwraymond/metatools-mcp/internal/roots"
 	"github.com/jonwraymond/tooldiscovery/index"
 	"github.com/jonwraymond/toolexec/run"
 	"github.com/stretchr/testify/require"
@@ -65,6 +70,62 @@ func TestManagerConnectAndCall(t *testing.T) {
 	require.True(t, errors.Is(err, run.ErrStreamNotSupported))
 }
 
+// rootsManager connects a manager to a backend whose tool lists the roots
+// it is offered, and returns a function calling that tool.
+func rootsManager(t *testing.T, share bool) func(context.Context) string {
+	t.Helper()
+	ctx := context.Background()
+
+	upstream := mcp.NewServer(&mcp.Implementation{Name: "upstream", Version: "0.0.0"}, nil)
+	tool := &mcp.Tool{Name: "roots", InputSchema: map[string]any{"type": "object"}}
+	mcp.AddTool[map[string]any, any](upstream, tool, func(ctx context.Context, req *mcp.CallToolRequest, _ map[string]any) (*mcp.CallToolResult, any, error) {
+		res, err := req.Session.ListRoots(ctx, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		uris := make([]string, 0, len(res.Roots))
+		for _, r := range res.Roots {
+			uris = append(uris, r.URI)
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: strings.Join(uris, ",")}}}, nil, nil
+	})
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := upstream.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	manager, err := NewManager([]Config{{Name: "backend", Transport: clientTransport, ShareRoots: share}})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = manager.Close() })
+
+	return func(ctx context.Context) string {
+		res, err := manager.CallTool(ctx, "backend", &mcp.CallToolParams{Name: "roots"})
+		require.NoError(t, err)
+		return res.Content[0].(*mcp.TextContent).Text
+	}
+}
+
+func TestManagerProxiesCallerRoots(t *testing.T) {
+	ctx := context.Background()
+	listed := rootsManager(t, true)
+	require.Empty(t, listed(ctx))
+
+	callerCtx := roots.WithRoots(ctx, []*mcp.Root{{URI: "file:///work/a"}, {URI: "file:///work/b"}})
+	require.Equal(t, "file:///work/a,file:///work/b", listed(callerCtx))
+
+	callerCtx = roots.WithRoots(ctx, []*mcp.Root{{URI: "file:///work/b"}})
+	require.Equal(t, "file:///work/b", listed(callerCtx))
+
+	// Calls without known roots leave the last caller's roots in place.
+	require.Equal(t, "file:///work/b", listed(ctx))
+}
+
+func TestManagerKeepsCallerRootsFromUnsharedBackends(t *testing.T) {
+	listed := rootsManager(t, false)
+	callerCtx := roots.WithRoots(context.Background(), []*mcp.Root{{URI: "file:///work/a"}})
+	require.Empty(t, listed(callerCtx))
+}
+
 func TestManagerRefreshAllContinuesOnError(t *testing.T) {
 	ctx := context.Background()
 
@@ -185,3 +246,187 @@ func TestRefresherMaybeRefreshStale(t *testing.T) {
 	_, _, err = idx.GetTool("mcp.backend:newtool")
 	require.NoError(t, err)
 }
+
+func TestManagerRelaysBackendLogs(t *testing.T) {
+	ctx := context.Background()
+
+	// Notifications are only relayed while the call is in flight, so the
+	// upstream tool returns once the client has seen its log.
+	logs := make(chan *mcp.LoggingMessageParams, 1)
+	seen := make(chan struct{})
+	upstream := mcp.NewServer(&mcp.Implementation{Name: "upstream", Version: "0.0.0"}, nil)
+	mcp.AddTool[map[string]any, any](upstream, &mcp.Tool{Name: "chatty", InputSchema: map[string]any{"type": "object"}}, func(ctx context.Context, req *mcp.CallToolRequest, _ map[string]any) (*mcp.CallToolResult, any, error) {
+		_ = req.Session.Log(ctx, &mcp.LoggingMessageParams{Level: "info", Logger: "db", Data: "querying"})
+		select {
+		case <-seen:
+		case <-time.After(time.Second):
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "done"}}}, nil, nil
+	})
+	upstreamServerTransport, upstreamClientTransport := mcp.NewInMemoryTransports()
+	upstreamSession, err := upstream.Connect(ctx, upstreamServerTransport, nil)
+	require.NoError(t, err)
+	defer func() { _ = upstreamSession.Close() }()
+
+	manager, err := NewManager([]Config{{Name: "backend", Transport: upstreamClientTransport}})
+	require.NoError(t, err)
+	require.NoError(t, manager.ConnectAll(ctx))
+
+	// A downstream server proxies to the backend with its session in ctx.
+	downstream := mcp.NewServer(&mcp.Implementation{Name: "downstream", Version: "0.0.0"}, nil)
+	mcp.AddTool[map[string]any, any](downstream, &mcp.Tool{Name: "proxy", InputSchema: map[string]any{"type": "object"}}, func(ctx context.Context, req *mcp.CallToolRequest, _ map[string]any) (*mcp.CallToolResult, any, error) {
+		res, err := manager.CallTool(mcplog.WithSession(ctx, req.Session), "backend", &mcp.CallToolParams{Name: "chatty"})
+		return res, nil, err
+	})
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := downstream.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	defer func() { _ = serverSession.Close() }()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, &mcp.ClientOptions{
+		LoggingMessageHandler: func(_ context.Context, req *mcp.LoggingMessageRequest) {
+			logs <- req.Params
+			close(seen)
+		},
+	})
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	defer func() { _ = session.Close() }()
+	require.NoError(t, session.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "info"}))
+
+	_, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "proxy", Arguments: map[string]any{}})
+	require.NoError(t, err)
+
+	select {
+	case msg := <-logs:
+		require.Equal(t, "backend/db", msg.Logger)
+		require.Equal(t, "querying", msg.Data)
+	case <-time.After(time.Second):
+		t.Fatal("backend log was not relayed")
+	}
+}
+
+func TestManagerKeepsBackendLogsFromConcurrentSessions(t *testing.T) {
+	ctx := context.Background()
+
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	upstream := mcp.NewServer(&mcp.Implementation{Name: "upstream", Version: "0.0.0"}, nil)
+	mcp.AddTool[map[string]any, any](upstream, &mcp.Tool{Name: "wait", InputSchema: map[string]any{"type": "object"}}, func(_ context.Context, _ *mcp.CallToolRequest, _ map[string]any) (*mcp.CallToolResult, any, error) {
+		started <- struct{}{}
+		<-release
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "done"}}}, nil, nil
+	})
+	upstreamServerTransport, upstreamClientTransport := mcp.NewInMemoryTransports()
+	upstreamSession, err := upstream.Connect(ctx, upstreamServerTransport, nil)
+	require.NoError(t, err)
+	defer func() { _ = upstreamSession.Close() }()
+
+	manager, err := NewManager([]Config{{Name: "backend", Transport: upstreamClientTransport}})
+	require.NoError(t, err)
+	require.NoError(t, manager.ConnectAll(ctx))
+
+	downstream := mcp.NewServer(&mcp.Implementation{Name: "downstream", Version: "0.0.0"}, nil)
+	mcp.AddTool[map[string]any, any](downstream, &mcp.Tool{Name: "proxy", InputSchema: map[string]any{"type": "object"}}, func(ctx context.Context, req *mcp.CallToolRequest, _ map[string]any) (*mcp.CallToolResult, any, error) {
+		res, err := manager.CallTool(mcplog.WithSession(ctx, req.Session), "backend", &mcp.CallToolParams{Name: "wait"})
+		return res, nil, err
+	})
+
+	// Two clients, each with its own downstream session, call the backend
+	// at once.
+	var received atomic.Int32
+	var calls sync.WaitGroup
+	for range 2 {
+		serverTransport, clientTransport := mcp.NewInMemoryTransports()
+		serverSession, err := downstream.Connect(ctx, serverTransport, nil)
+		require.NoError(t, err)
+		defer func() { _ = serverSession.Close() }()
+		client := mcp.NewClient(&mcp.Implementation{Name: "client"}, &mcp.ClientOptions{
+			LoggingMessageHandler: func(context.Context, *mcp.LoggingMessageRequest) { received.Add(1) },
+		})
+		session, err := client.Connect(ctx, clientTransport, nil)
+		require.NoError(t, err)
+		defer func() { _ = session.Close() }()
+		require.NoError(t, session.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "info"}))
+		calls.Go(func() {
+			_, _ = session.CallTool(ctx, &mcp.CallToolParams{Name: "proxy", Arguments: map[string]any{}})
+		})
+	}
+	// Runs before the deferred closes above, so a failure does not leave
+	// the upstream calls blocked.
+	stop := sync.OnceFunc(func() { close(release) })
+	defer stop()
+	<-started
+	<-started
+
+	// The log cannot be attributed to either session, so neither gets it.
+	require.NoError(t, upstreamSession.Log(ctx, &mcp.LoggingMessageParams{Level: "info", Data: "for whom?"}))
+	require.Never(t, func() bool { return received.Load() > 0 }, 200*time.Millisecond, 20*time.Millisecond)
+	stop()
+	calls.Wait()
+}
+
+func TestManagerCancelsUpstreamCalls(t *testing.T) {
+	ctx := context.Background()
+
+	started := make(chan struct{}, 2)
+	stopped := make(chan struct{}, 2)
+	cancelled := make(chan *mcp.CancelledParams, 2)
+	upstream := mcp.NewServer(&mcp.Implementation{Name: "upstream", Version: "0.0.0"}, nil)
+	upstream.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method == "notifications/cancelled" {
+				cancelled <- req.GetParams().(*mcp.CancelledParams)
+			}
+			return next(ctx, method, req)
+		}
+	})
+	mcp.AddTool[map[string]any, any](upstream, &mcp.Tool{Name: "slow", InputSchema: map[string]any{"type": "object"}}, func(ctx context.Context, _ *mcp.CallToolRequest, _ map[string]any) (*mcp.CallToolResult, any, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		stopped <- struct{}{}
+		return nil, nil, ctx.Err()
+	})
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := upstream.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	defer func() { _ = serverSession.Close() }()
+
+	manager, err := NewManager([]Config{{Name: "backend", Transport: clientTransport}})
+	require.NoError(t, err)
+	require.NoError(t, manager.ConnectAll(ctx))
+
+	// Two calls share the downstream context, like parallel chain steps.
+	callCtx, cancel := context.WithCancelCause(ctx)
+	errs := make(chan error, 2)
+	for range 2 {
+		go func() {
+			_, err := manager.CallTool(callCtx, "backend", &mcp.CallToolParams{Name: "slow"})
+			errs <- err
+		}()
+	}
+	for range 2 {
+		<-started
+	}
+	cancel(errors.New("canceled by cancel_job"))
+
+	ids := map[any]bool{}
+	for range 2 {
+		err := <-errs
+		require.ErrorIs(t, err, context.Canceled)
+		require.ErrorContains(t, err, "canceled by cancel_job")
+
+		select {
+		case params := <-cancelled:
+			ids[params.RequestID] = true
+		case <-time.After(time.Second):
+			t.Fatal("upstream was not notified of the cancellation")
+		}
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Fatal("upstream call kept running")
+		}
+	}
+	require.Len(t, ids, 2)
+}
@@ -0,0 +1,134 @@
+// Package mcplog forwards slog records to MCP clients as
+// notifications/message. Records logged with a context that carries a client
+// session go to that session, filtered by the level it set with
+// logging/setLevel, as well as to the process log.
+package mcplog
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// LoggerName is the logger reported in forwarded notifications.
+const LoggerName = "metatools"
+
+type sessionKey struct{}
+
+type sessionLog struct {
+	session *mcp.ServerSession
+	handler slog.Handler
+}
+
+// WithSession returns a context whose log records are also sent to session.
+func WithSession(ctx context.Context, session *mcp.ServerSession) context.Context {
+	return context.WithValue(ctx, sessionKey{}, &sessionLog{
+		session: session,
+		handler: mcp.NewLoggingHandler(session, &mcp.LoggingHandlerOptions{LoggerName: LoggerName}),
+	})
+}
+
+func sessionFromContext(ctx context.Context) *sessionLog {
+	if ctx == nil {
+		return nil
+	}
+	s, _ := ctx.Value(sessionKey{}).(*sessionLog)
+	return s
+}
+
+// Session returns the client session set by WithSession, or nil.
+func Session(ctx context.Context) *mcp.ServerSession {
+	if s := sessionFromContext(ctx); s != nil {
+		return s.session
+	}
+	return nil
+}
+
+// Relay sends a log notification received from elsewhere, such as an
+// upstream MCP backend, to the session in ctx. It reports whether ctx had a
+// session.
+func Relay(ctx context.Context, params *mcp.LoggingMessageParams) bool {
+	s := sessionFromContext(ctx)
+	if s == nil {
+		return false
+	}
+	_ = s.session.Log(ctx, params)
+	return true
+}
+
+// Handler writes records to a base handler and forwards them to the session
+// in the record's context.
+type Handler struct {
+	base slog.Handler
+	// scope replays WithAttrs and WithGroup calls on session handlers.
+	scope []func(slog.Handler) slog.Handler
+}
+
+// NewHandler creates a forwarding handler around base.
+func NewHandler(base slog.Handler) *Handler {
+	return &Handler{base: base}
+}
+
+// Enabled reports whether the base handler or the context's session wants
+// records at level.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.base.Enabled(ctx, level) {
+		return true
+	}
+	s := sessionFromContext(ctx)
+	return s != nil && s.handler.Enabled(ctx, level)
+}
+
+// Handle writes r to the base handler and forwards it to the context's
+// session. Forwarding failures, such as a closed session, are ignored.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	var err error
+	if h.base.Enabled(ctx, r.Level) {
+		err = h.base.Handle(ctx, r)
+	}
+	if s := sessionFromContext(ctx); s != nil && s.handler.Enabled(ctx, r.Level) {
+		sh := s.handler
+		for _, apply := range h.scope {
+			sh = apply(sh)
+		}
+		_ = sh.Handle(ctx, r)
+	}
+	return err
+}
+
+// WithAttrs returns a handler that adds attrs to every record.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(h.base.WithAttrs(attrs), func(sh slog.Handler) slog.Handler { return sh.WithAttrs(attrs) })
+}
+
+// WithGroup returns a handler that nests later attributes under name.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return h.with(h.base.WithGroup(name), func(sh slog.Handler) slog.Handler { return sh.WithGroup(name) })
+}
+
+func (h *Handler) with(base slog.Handler, apply func(slog.Handler) slog.Handler) *Handler {
+	scope := make([]func(slog.Handler) slog.Handler, len(h.scope), len(h.scope)+1)
+	copy(scope, h.scope)
+	return &Handler{base: base, scope: append(scope, apply)}
+}
+
+var levels = map[mcp.LoggingLevel]slog.Level{
+	"debug":     mcp.LevelDebug,
+	"info":      mcp.LevelInfo,
+	"notice":    mcp.LevelNotice,
+	"warning":   mcp.LevelWarning,
+	"error":     mcp.LevelError,
+	"critical":  mcp.LevelCritical,
+	"alert":     mcp.LevelAlert,
+	"emergency": mcp.LevelEmergency,
+}
+
+// Level returns the slog level of an MCP logging level. Unknown levels map
+// to info.
+func Level(level mcp.LoggingLevel) slog.Level {
+	if l, ok := levels[level]; ok {
+		return l
+	}
+	return slog.LevelInfo
+}
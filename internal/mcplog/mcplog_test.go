@@ -0,0 +1,113 @@
+package mcplog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+type received struct {
+	mu   sync.Mutex
+	msgs []*mcp.LoggingMessageParams
+}
+
+func (r *received) add(_ context.Context, req *mcp.LoggingMessageRequest) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.msgs = append(r.msgs, req.Params)
+}
+
+func (r *received) snapshot() []*mcp.LoggingMessageParams {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*mcp.LoggingMessageParams(nil), r.msgs...)
+}
+
+// connect serves a "log" tool that runs fn with a context carrying its
+// session and returns a client session that records log notifications.
+func connect(t *testing.T, fn func(ctx context.Context)) (*mcp.ClientSession, *received) {
+	t.Helper()
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "log"}, func(ctx context.Context, req *mcp.CallToolRequest, _ map[string]any) (*mcp.CallToolResult, any, error) {
+		fn(WithSession(ctx, req.Session))
+		return &mcp.CallToolResult{}, nil, nil
+	})
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	got := &received{}
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, &mcp.ClientOptions{LoggingMessageHandler: got.add})
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = session.Close() })
+	return session, got
+}
+
+func callLog(t *testing.T, session *mcp.ClientSession) {
+	t.Helper()
+	_, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "log", Arguments: map[string]any{}})
+	require.NoError(t, err)
+}
+
+func TestHandler_ForwardsBySessionLevel(t *testing.T) {
+	var base bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewTextHandler(&base, nil))).With("component", "test")
+	session, got := connect(t, func(ctx context.Context) {
+		logger.DebugContext(ctx, "debug detail")
+		logger.WithGroup("call").InfoContext(ctx, "tool call", "tool_id", "a:b")
+	})
+
+	// Nothing is forwarded before the client sets a level.
+	callLog(t, session)
+	require.Empty(t, got.snapshot())
+	require.Contains(t, base.String(), "tool call")
+	require.NotContains(t, base.String(), "debug detail")
+
+	require.NoError(t, session.SetLoggingLevel(context.Background(), &mcp.SetLoggingLevelParams{Level: "info"}))
+	callLog(t, session)
+	require.Eventually(t, func() bool { return len(got.snapshot()) == 1 }, time.Second, 5*time.Millisecond)
+	msg := got.snapshot()[0]
+	require.Equal(t, mcp.LoggingLevel("info"), msg.Level)
+	require.Equal(t, LoggerName, msg.Logger)
+	data := msg.Data.(map[string]any)
+	require.Equal(t, "tool call", data["msg"])
+	require.Equal(t, "test", data["component"])
+	require.Equal(t, map[string]any{"tool_id": "a:b"}, data["call"])
+
+	// Debug records reach the session once it asks for them, even though
+	// the process log stays at info.
+	require.NoError(t, session.SetLoggingLevel(context.Background(), &mcp.SetLoggingLevelParams{Level: "debug"}))
+	callLog(t, session)
+	require.Eventually(t, func() bool { return len(got.snapshot()) == 3 }, time.Second, 5*time.Millisecond)
+	require.NotContains(t, base.String(), "debug detail")
+}
+
+func TestRelay(t *testing.T) {
+	require.False(t, Relay(context.Background(), &mcp.LoggingMessageParams{Level: "info", Data: "x"}))
+	require.Nil(t, Session(context.Background()))
+
+	session, got := connect(t, func(ctx context.Context) {
+		require.NotNil(t, Session(ctx))
+		require.True(t, Relay(ctx, &mcp.LoggingMessageParams{Level: "warning", Logger: "upstream", Data: "disk low"}))
+	})
+	require.NoError(t, session.SetLoggingLevel(context.Background(), &mcp.SetLoggingLevelParams{Level: "info"}))
+	callLog(t, session)
+	require.Eventually(t, func() bool { return len(got.snapshot()) == 1 }, time.Second, 5*time.Millisecond)
+	require.Equal(t, "upstream", got.snapshot()[0].Logger)
+	require.Equal(t, "disk low", got.snapshot()[0].Data)
+}
+
+func TestLevel(t *testing.T) {
+	require.Equal(t, slog.LevelWarn, Level("warning"))
+	require.Equal(t, slog.LevelDebug, Level("debug"))
+	require.Equal(t, slog.LevelInfo, Level("unknown"))
+}
@@ -39,6 +39,11 @@ type AuditEntry struct {
 	// ErrorMsg contains the error message if Success is false.
 	ErrorMsg string
 
+	// CancelReason is the cancellation cause when the call failed after its
+	// context ended. A call that succeeds despite a late cancellation keeps
+	// Success and has no reason.
+	CancelReason string
+
 	// Args contains the input arguments (if IncludeArgs is enabled).
 	Args map[string]any
 }
@@ -168,6 +173,9 @@ func (a *auditProvider) Handle(ctx context.Context, req *mcp.CallToolRequest, ar
 	} else {
 		entry.Success = true
 	}
+	if !entry.Success && ctx.Err() != nil {
+		entry.CancelReason = context.Cause(ctx).Error()
+	}
 
 	// Include args if configured
 	if a.config.IncludeArgs && args != nil {
@@ -209,6 +217,9 @@ func (a *auditProvider) logEntry(ctx context.Context, entry AuditEntry) {
 	if entry.ErrorMsg != "" {
 		attrs = append(attrs, "error", entry.ErrorMsg)
 	}
+	if entry.CancelReason != "" {
+		attrs = append(attrs, "cancel_reason", entry.CancelReason)
+	}
 	if entry.Args != nil {
 		attrs = append(attrs, "args", entry.Args)
 	}
@@ -172,6 +172,48 @@ func TestAuditMiddleware_LogsFailure(t *testing.T) {
 	assert.Contains(t, entry.ErrorMsg, "tool execution failed")
 }
 
+func TestAuditMiddleware_LogsCancelReason(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	mock := &mockProvider{
+		name: "slow-tool",
+		handleFunc: func(_ context.Context, _ *mcp.CallToolRequest, _ map[string]any) (*mcp.CallToolResult, any, error) {
+			cancel(errors.New("canceled by cancel_job"))
+			return nil, nil, context.Cause(ctx)
+		},
+	}
+	auditLogger := &mockAuditLogger{}
+
+	wrapped := NewAuditLoggingMiddleware(AuditConfig{AuditLogger: auditLogger})(mock)
+	_, _, err := wrapped.Handle(ctx, &mcp.CallToolRequest{}, nil)
+	require.Error(t, err)
+
+	entries := auditLogger.Entries()
+	require.Len(t, entries, 1)
+	assert.False(t, entries[0].Success)
+	assert.Equal(t, "canceled by cancel_job", entries[0].CancelReason)
+}
+
+func TestAuditMiddleware_CompletedCallSurvivesLateCancel(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	mock := &mockProvider{
+		name: "fast-tool",
+		handleFunc: func(_ context.Context, _ *mcp.CallToolRequest, _ map[string]any) (*mcp.CallToolResult, any, error) {
+			cancel(errors.New("canceled by cancel_job"))
+			return &mcp.CallToolResult{}, nil, nil
+		},
+	}
+	auditLogger := &mockAuditLogger{}
+
+	wrapped := NewAuditLoggingMiddleware(AuditConfig{AuditLogger: auditLogger})(mock)
+	_, _, err := wrapped.Handle(ctx, &mcp.CallToolRequest{}, nil)
+	require.NoError(t, err)
+
+	entries := auditLogger.Entries()
+	require.Len(t, entries, 1)
+	assert.True(t, entries[0].Success)
+	assert.Empty(t, entries[0].CancelReason)
+}
+
 func TestAuditMiddleware_LogsIsErrorResult(t *testing.T) {
 	mock := &mockProvider{
 		name: "error-result-tool",
@@ -180,6 +180,24 @@ func (r *toolopsRunner) RunChainWithProgress(ctx context.Context, steps []handle
 	return r.RunChain(ctx, steps)
 }
 
+func (r *toolopsRunner) RunChainWithCheckpoint(ctx context.Context, steps []handlers.ChainStep, onProgress func(handlers.ProgressEvent), onStep func(int, handlers.StepResult)) (handlers.RunResult, []handlers.StepResult, error) {
+	cr, ok := r.base.(handlers.CheckpointRunner)
+	if !ok {
+		return r.RunChainWithProgress(ctx, steps, onProgress)
+	}
+	meta := observe.ToolMeta{ID: "run_chain", Name: "run_chain"}
+	exec := func(ctx context.Context) (handlers.RunResult, []handlers.StepResult, error) {
+		return cr.RunChainWithCheckpoint(ctx, steps, onProgress, onStep)
+	}
+	if r.resilience != nil {
+		exec = wrapResilienceChain(exec, r.resilience)
+	}
+	if r.observe != nil {
+		exec = wrapObserveChain(exec, r.observe, meta, steps)
+	}
+	return exec(ctx)
+}
+
 type toolopsExecutor struct {
 	base       handlers.Executor
 	observe    *observe.Middleware
@@ -44,3 +44,25 @@ func progressNotifier(ctx context.Context, req *mcp.CallToolRequest) func(handle
 		_ = req.Session.NotifyProgress(ctx, params)
 	}
 }
+
+// contentResult builds a tool result carrying blocks as native MCP content.
+// The SDK only adds the JSON text of the structured output when Content is
+// empty, so it is added here first for clients that ignore
+// structuredContent.
+func contentResult(isError bool, out any, blocks []any) (*mcp.CallToolResult, error) {
+	res := &mcp.CallToolResult{IsError: isError}
+	if len(blocks) == 0 {
+		return res, nil
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return nil, fmt.Errorf("marshal tool output: %w", err)
+	}
+	res.Content = append(res.Content, &mcp.TextContent{Text: string(data)})
+	for _, b := range blocks {
+		if c, ok := b.(mcp.Content); ok {
+			res.Content = append(res.Content, c)
+		}
+	}
+	return res, nil
+}
@@ -222,7 +222,11 @@ func (p *RunToolProvider) Handle(ctx context.Context, req *mcp.CallToolRequest,
 	if out == nil {
 		out = &metatools.RunToolOutput{}
 	}
-	return &mcp.CallToolResult{IsError: isError}, *out, nil
+	res, err := contentResult(isError, out, out.Content)
+	if err != nil {
+		return nil, nil, err
+	}
+	return res, *out, nil
 }
 
 // RunChainProvider serves the run_chain built-in tool.
@@ -381,6 +385,72 @@ func (p *DescribeToolsetProvider) Handle(ctx context.Context, _ *mcp.CallToolReq
 	return nil, *out, nil
 }
 
+// ActivateToolsetProvider serves the activate_toolset built-in tool.
+type ActivateToolsetProvider struct {
+	handler *handlers.ToolsetsHandler
+	enabled bool
+}
+
+// NewActivateToolsetProvider builds an ActivateToolsetProvider.
+func NewActivateToolsetProvider(handler *handlers.ToolsetsHandler, enabled bool) *ActivateToolsetProvider {
+	return &ActivateToolsetProvider{handler: handler, enabled: enabled}
+}
+
+// Name returns the MCP tool name.
+func (p *ActivateToolsetProvider) Name() string { return "activate_toolset" }
+
+// Enabled reports whether the provider is enabled.
+func (p *ActivateToolsetProvider) Enabled() bool { return p.enabled }
+
+// Tool returns the MCP tool schema.
+func (p *ActivateToolsetProvider) Tool() mcp.Tool { return activateToolsetTool() }
+
+// Handle executes the activate_toolset request.
+func (p *ActivateToolsetProvider) Handle(ctx context.Context, _ *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+	var input metatools.ActivateToolsetInput
+	if err := decodeArgs(args, &input); err != nil {
+		return nil, nil, err
+	}
+	out, err := p.handler.Activate(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, *out, nil
+}
+
+// DeactivateToolsetProvider serves the deactivate_toolset built-in tool.
+type DeactivateToolsetProvider struct {
+	handler *handlers.ToolsetsHandler
+	enabled bool
+}
+
+// NewDeactivateToolsetProvider builds a DeactivateToolsetProvider.
+func NewDeactivateToolsetProvider(handler *handlers.ToolsetsHandler, enabled bool) *DeactivateToolsetProvider {
+	return &DeactivateToolsetProvider{handler: handler, enabled: enabled}
+}
+
+// Name returns the MCP tool name.
+func (p *DeactivateToolsetProvider) Name() string { return "deactivate_toolset" }
+
+// Enabled reports whether the provider is enabled.
+func (p *DeactivateToolsetProvider) Enabled() bool { return p.enabled }
+
+// Tool returns the MCP tool schema.
+func (p *DeactivateToolsetProvider) Tool() mcp.Tool { return deactivateToolsetTool() }
+
+// Handle executes the deactivate_toolset request.
+func (p *DeactivateToolsetProvider) Handle(ctx context.Context, _ *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+	var input metatools.DeactivateToolsetInput
+	if err := decodeArgs(args, &input); err != nil {
+		return nil, nil, err
+	}
+	out, err := p.handler.Deactivate(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, *out, nil
+}
+
 // ListSkillsProvider serves the list_skills built-in tool.
 type ListSkillsProvider struct {
 	handler *handlers.SkillsHandler
@@ -524,3 +594,234 @@ func (p *RunSkillProvider) Handle(ctx context.Context, _ *mcp.CallToolRequest, a
 	}
 	return &mcp.CallToolResult{IsError: isError}, *out, nil
 }
+
+// GetRunProvider serves the get_run built-in tool.
+type GetRunProvider struct {
+	handler *handlers.RunsHandler
+	enabled bool
+}
+
+// NewGetRunProvider builds a GetRunProvider.
+func NewGetRunProvider(handler *handlers.RunsHandler, enabled bool) *GetRunProvider {
+	return &GetRunProvider{handler: handler, enabled: enabled}
+}
+
+// Name returns the MCP tool name.
+func (p *GetRunProvider) Name() string { return "get_run" }
+
+// Enabled reports whether the provider is enabled.
+func (p *GetRunProvider) Enabled() bool { return p.enabled }
+
+// Tool returns the MCP tool schema.
+func (p *GetRunProvider) Tool() mcp.Tool { return getRunTool() }
+
+// Handle executes the get_run request.
+func (p *GetRunProvider) Handle(ctx context.Context, _ *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+	var input metatools.RunIDInput
+	if err := decodeArgs(args, &input); err != nil {
+		return nil, nil, err
+	}
+	out, err := p.handler.Get(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, *out, nil
+}
+
+// ResumeRunProvider serves the resume_run built-in tool.
+type ResumeRunProvider struct {
+	handler *handlers.RunsHandler
+	enabled bool
+}
+
+// NewResumeRunProvider builds a ResumeRunProvider.
+func NewResumeRunProvider(handler *handlers.RunsHandler, enabled bool) *ResumeRunProvider {
+	return &ResumeRunProvider{handler: handler, enabled: enabled}
+}
+
+// Name returns the MCP tool name.
+func (p *ResumeRunProvider) Name() string { return "resume_run" }
+
+// Enabled reports whether the provider is enabled.
+func (p *ResumeRunProvider) Enabled() bool { return p.enabled }
+
+// Tool returns the MCP tool schema.
+func (p *ResumeRunProvider) Tool() mcp.Tool { return resumeRunTool() }
+
+// Handle executes the resume_run request.
+func (p *ResumeRunProvider) Handle(ctx context.Context, _ *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+	var input metatools.RunIDInput
+	if err := decodeArgs(args, &input); err != nil {
+		return nil, nil, err
+	}
+	out, isError, err := p.handler.Resume(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &mcp.CallToolResult{IsError: isError}, *out, nil
+}
+
+// CancelRunProvider serves the cancel_run built-in tool.
+type CancelRunProvider struct {
+	handler *handlers.RunsHandler
+	enabled bool
+}
+
+// NewCancelRunProvider builds a CancelRunProvider.
+func NewCancelRunProvider(handler *handlers.RunsHandler, enabled bool) *CancelRunProvider {
+	return &CancelRunProvider{handler: handler, enabled: enabled}
+}
+
+// Name returns the MCP tool name.
+func (p *CancelRunProvider) Name() string { return "cancel_run" }
+
+// Enabled reports whether the provider is enabled.
+func (p *CancelRunProvider) Enabled() bool { return p.enabled }
+
+// Tool returns the MCP tool schema.
+func (p *CancelRunProvider) Tool() mcp.Tool { return cancelRunTool() }
+
+// Handle executes the cancel_run request.
+func (p *CancelRunProvider) Handle(ctx context.Context, _ *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+	var input metatools.RunIDInput
+	if err := decodeArgs(args, &input); err != nil {
+		return nil, nil, err
+	}
+	out, err := p.handler.Cancel(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, *out, nil
+}
+
+// GetJobProvider serves the get_job built-in tool.
+type GetJobProvider struct {
+	handler *handlers.JobsHandler
+	enabled bool
+}
+
+// NewGetJobProvider builds a GetJobProvider.
+func NewGetJobProvider(handler *handlers.JobsHandler, enabled bool) *GetJobProvider {
+	return &GetJobProvider{handler: handler, enabled: enabled}
+}
+
+// Name returns the MCP tool name.
+func (p *GetJobProvider) Name() string { return "get_job" }
+
+// Enabled reports whether the provider is enabled.
+func (p *GetJobProvider) Enabled() bool { return p.enabled }
+
+// Tool returns the MCP tool schema.
+func (p *GetJobProvider) Tool() mcp.Tool { return getJobTool() }
+
+// Handle executes the get_job request.
+func (p *GetJobProvider) Handle(ctx context.Context, _ *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+	var input metatools.JobIDInput
+	if err := decodeArgs(args, &input); err != nil {
+		return nil, nil, err
+	}
+	out, err := p.handler.Get(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, *out, nil
+}
+
+// ListJobsProvider serves the list_jobs built-in tool.
+type ListJobsProvider struct {
+	handler *handlers.JobsHandler
+	enabled bool
+}
+
+// NewListJobsProvider builds a ListJobsProvider.
+func NewListJobsProvider(handler *handlers.JobsHandler, enabled bool) *ListJobsProvider {
+	return &ListJobsProvider{handler: handler, enabled: enabled}
+}
+
+// Name returns the MCP tool name.
+func (p *ListJobsProvider) Name() string { return "list_jobs" }
+
+// Enabled reports whether the provider is enabled.
+func (p *ListJobsProvider) Enabled() bool { return p.enabled }
+
+// Tool returns the MCP tool schema.
+func (p *ListJobsProvider) Tool() mcp.Tool { return listJobsTool() }
+
+// Handle executes the list_jobs request.
+func (p *ListJobsProvider) Handle(ctx context.Context, _ *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+	var input metatools.ListJobsInput
+	if err := decodeArgs(args, &input); err != nil {
+		return nil, nil, err
+	}
+	out, err := p.handler.List(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, *out, nil
+}
+
+// CancelJobProvider serves the cancel_job built-in tool.
+type CancelJobProvider struct {
+	handler *handlers.JobsHandler
+	enabled bool
+}
+
+// NewCancelJobProvider builds a CancelJobProvider.
+func NewCancelJobProvider(handler *handlers.JobsHandler, enabled bool) *CancelJobProvider {
+	return &CancelJobProvider{handler: handler, enabled: enabled}
+}
+
+// Name returns the MCP tool name.
+func (p *CancelJobProvider) Name() string { return "cancel_job" }
+
+// Enabled reports whether the provider is enabled.
+func (p *CancelJobProvider) Enabled() bool { return p.enabled }
+
+// Tool returns the MCP tool schema.
+func (p *CancelJobProvider) Tool() mcp.Tool { return cancelJobTool() }
+
+// Handle executes the cancel_job request.
+func (p *CancelJobProvider) Handle(ctx context.Context, _ *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+	var input metatools.JobIDInput
+	if err := decodeArgs(args, &input); err != nil {
+		return nil, nil, err
+	}
+	out, err := p.handler.Cancel(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, *out, nil
+}
+
+// ReadResultProvider serves the read_result built-in tool.
+type ReadResultProvider struct {
+	handler *handlers.ResultsHandler
+	enabled bool
+}
+
+// NewReadResultProvider builds a ReadResultProvider.
+func NewReadResultProvider(handler *handlers.ResultsHandler, enabled bool) *ReadResultProvider {
+	return &ReadResultProvider{handler: handler, enabled: enabled}
+}
+
+// Name returns the MCP tool name.
+func (p *ReadResultProvider) Name() string { return "read_result" }
+
+// Enabled reports whether the provider is enabled.
+func (p *ReadResultProvider) Enabled() bool { return p.enabled }
+
+// Tool returns the MCP tool schema.
+func (p *ReadResultProvider) Tool() mcp.Tool { return readResultTool() }
+
+// Handle executes the read_result request.
+func (p *ReadResultProvider) Handle(ctx context.Context, _ *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+	var input metatools.ReadResultInput
+	if err := decodeArgs(args, &input); err != nil {
+		return nil, nil, err
+	}
+	out, err := p.handler.Read(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, *out, nil
+}
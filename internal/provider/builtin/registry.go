@@ -20,6 +20,9 @@ type Deps struct {
 	Code       *handlers.CodeHandler
 	Toolsets   *handlers.ToolsetsHandler
 	Skills     *handlers.SkillsHandler
+	Runs       *handlers.RunsHandler
+	Jobs       *handlers.JobsHandler
+	Results    *handlers.ResultsHandler
 }
 
 // RegistryOptions configures built-in provider registration.
@@ -121,6 +124,24 @@ func NewRegistry(deps Deps, opts RegistryOptions) (*provider.Registry, error) {
 		}
 	}
 
+	if opts.Providers.ActivateToolset.Enabled {
+		if deps.Toolsets == nil {
+			return nil, fmt.Errorf("activate_toolset provider enabled but handler is nil")
+		}
+		if err := registry.Register(NewActivateToolsetProvider(deps.Toolsets, true)); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Providers.DeactivateToolset.Enabled {
+		if deps.Toolsets == nil {
+			return nil, fmt.Errorf("deactivate_toolset provider enabled but handler is nil")
+		}
+		if err := registry.Register(NewDeactivateToolsetProvider(deps.Toolsets, true)); err != nil {
+			return nil, err
+		}
+	}
+
 	if opts.Providers.ListSkills.Enabled {
 		if deps.Skills == nil {
 			return nil, fmt.Errorf("list_skills provider enabled but handler is nil")
@@ -157,6 +178,69 @@ func NewRegistry(deps Deps, opts RegistryOptions) (*provider.Registry, error) {
 		}
 	}
 
+	if opts.Providers.GetRun.Enabled {
+		if deps.Runs == nil {
+			return nil, fmt.Errorf("get_run provider enabled but handler is nil")
+		}
+		if err := registry.Register(NewGetRunProvider(deps.Runs, true)); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Providers.ResumeRun.Enabled {
+		if deps.Runs == nil {
+			return nil, fmt.Errorf("resume_run provider enabled but handler is nil")
+		}
+		if err := registry.Register(NewResumeRunProvider(deps.Runs, true)); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Providers.CancelRun.Enabled {
+		if deps.Runs == nil {
+			return nil, fmt.Errorf("cancel_run provider enabled but handler is nil")
+		}
+		if err := registry.Register(NewCancelRunProvider(deps.Runs, true)); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Providers.GetJob.Enabled {
+		if deps.Jobs == nil {
+			return nil, fmt.Errorf("get_job provider enabled but handler is nil")
+		}
+		if err := registry.Register(NewGetJobProvider(deps.Jobs, true)); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Providers.ListJobs.Enabled {
+		if deps.Jobs == nil {
+			return nil, fmt.Errorf("list_jobs provider enabled but handler is nil")
+		}
+		if err := registry.Register(NewListJobsProvider(deps.Jobs, true)); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Providers.CancelJob.Enabled {
+		if deps.Jobs == nil {
+			return nil, fmt.Errorf("cancel_job provider enabled but handler is nil")
+		}
+		if err := registry.Register(NewCancelJobProvider(deps.Jobs, true)); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Providers.ReadResult.Enabled {
+		if deps.Results == nil {
+			return nil, fmt.Errorf("read_result provider enabled but handler is nil")
+		}
+		if err := registry.Register(NewReadResultProvider(deps.Results, true)); err != nil {
+			return nil, err
+		}
+	}
+
 	return registry, nil
 }
 
@@ -173,9 +257,18 @@ func Providers() []string {
 		"execute_code",
 		"list_toolsets",
 		"describe_toolset",
+		"activate_toolset",
+		"deactivate_toolset",
 		"list_skills",
 		"describe_skill",
 		"plan_skill",
 		"run_skill",
+		"get_run",
+		"resume_run",
+		"cancel_run",
+		"get_job",
+		"list_jobs",
+		"cancel_job",
+		"read_result",
 	}
 }
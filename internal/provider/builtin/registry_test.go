@@ -6,6 +6,8 @@ import (
 
 	"github.com/jonwraymond/metatools-mcp/internal/config"
 	"github.com/jonwraymond/metatools-mcp/internal/handlers"
+	"github.com/jonwraymond/metatools-mcp/internal/jobs"
+	"github.com/jonwraymond/metatools-mcp/internal/results"
 	"github.com/jonwraymond/metatools-mcp/internal/skills"
 	"github.com/jonwraymond/metatools-mcp/internal/toolset"
 	"github.com/jonwraymond/metatools-mcp/pkg/metatools"
@@ -59,6 +61,8 @@ func TestNewRegistry_Defaults(t *testing.T) {
 		Chain:      handlers.NewChainHandler(runner),
 		Code:       nil,
 		Toolsets:   handlers.NewToolsetsHandler(toolsets),
+		Jobs:       handlers.NewJobsHandler(jobs.NewManager(jobs.Options{})),
+		Results:    handlers.NewResultsHandler(results.NewStore(results.Options{})),
 		Skills: handlers.NewSkillsHandler(
 			skillsRegistry,
 			toolsets,
@@ -87,10 +91,16 @@ func TestNewRegistry_Defaults(t *testing.T) {
 		"run_chain",
 		"list_toolsets",
 		"describe_toolset",
+		"activate_toolset",
+		"deactivate_toolset",
 		"list_skills",
 		"describe_skill",
 		"plan_skill",
 		"run_skill",
+		"get_job",
+		"list_jobs",
+		"cancel_job",
+		"read_result",
 	}, names)
 }
 
@@ -109,6 +119,8 @@ func TestNewRegistry_ExecuteCodeEnabledMissingHandler(t *testing.T) {
 		Chain:      handlers.NewChainHandler(runner),
 		Code:       nil,
 		Toolsets:   handlers.NewToolsetsHandler(toolsets),
+		Jobs:       handlers.NewJobsHandler(jobs.NewManager(jobs.Options{})),
+		Results:    handlers.NewResultsHandler(results.NewStore(results.Options{})),
 		Skills: handlers.NewSkillsHandler(
 			skillsRegistry,
 			toolsets,
@@ -143,6 +155,8 @@ func TestNewRegistry_DisabledProviders(t *testing.T) {
 		Chain:      handlers.NewChainHandler(runner),
 		Code:       nil,
 		Toolsets:   handlers.NewToolsetsHandler(toolsets),
+		Jobs:       handlers.NewJobsHandler(jobs.NewManager(jobs.Options{})),
+		Results:    handlers.NewResultsHandler(results.NewStore(results.Options{})),
 		Skills: handlers.NewSkillsHandler(
 			skillsRegistry,
 			toolsets,
@@ -7,6 +7,7 @@ import (
 
 var errorCodes = []string{
 	string(errors.CodeToolNotFound),
+	string(errors.CodeToolNotActive),
 	string(errors.CodeNoBackends),
 	string(errors.CodeBackendOverrideInvalid),
 	string(errors.CodeBackendOverrideNoMatch),
@@ -19,6 +20,11 @@ var errorCodes = []string{
 	string(errors.CodeCancelled),
 	string(errors.CodeTimeout),
 	string(errors.CodeInternal),
+	string(errors.CodeApprovalRequired),
+	string(errors.CodeApprovalDenied),
+	string(errors.CodeSamplingUnavailable),
+	string(errors.CodeSamplingBudget),
+	string(errors.CodePathOutsideRoots),
 }
 
 func searchToolsTool() mcp.Tool {
@@ -142,8 +148,9 @@ func describeToolTool() mcp.Tool {
 			"type": "object",
 			"properties": map[string]any{
 				"tool_id":      map[string]any{"type": "string"},
-				"detail_level": map[string]any{"type": "string", "enum": []string{"summary", "schema", "full"}},
+				"detail_level": map[string]any{"type": "string", "enum": []string{"summary", "schema", "full", "compact"}},
 				"examples_max": map[string]any{"type": "integer", "minimum": 0, "maximum": 5},
+				"max_tokens":   map[string]any{"type": "integer", "minimum": 1},
 			},
 			"required":             []string{"tool_id", "detail_level"},
 			"additionalProperties": false,
@@ -160,6 +167,12 @@ func describeToolTool() mcp.Tool {
 					"type":  "array",
 					"items": map[string]any{"type": "string"},
 				},
+				"signature": map[string]any{"type": "string"},
+				"elided": map[string]any{
+					"type":  "array",
+					"items": map[string]any{"type": "string"},
+				},
+				"tokenEstimate": map[string]any{"type": "integer"},
 			},
 			"required":             []string{"summary"},
 			"additionalProperties": false,
@@ -218,6 +231,12 @@ func runToolTool() mcp.Tool {
 				"include_tool":       map[string]any{"type": "boolean", "default": false},
 				"include_backend":    map[string]any{"type": "boolean", "default": false},
 				"include_mcp_result": map[string]any{"type": "boolean", "default": false},
+				"async":              asyncSchema(),
+				"max_result_bytes": map[string]any{
+					"type":        "integer",
+					"minimum":     0,
+					"description": "Results over this many bytes of JSON are cut to a preview readable in full with read_result",
+				},
 				"backend_override": map[string]any{
 					"type": "object",
 					"properties": map[string]any{
@@ -241,7 +260,7 @@ func runToolTool() mcp.Tool {
 func runChainTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "run_chain",
-		Description: "Execute multiple tools in sequence",
+		Description: "Execute multiple tools in sequence or as a depends_on graph",
 		InputSchema: map[string]any{
 			"type": "object",
 			"properties": map[string]any{
@@ -251,9 +270,15 @@ func runChainTool() mcp.Tool {
 					"items": map[string]any{
 						"type": "object",
 						"properties": map[string]any{
+							"id":           map[string]any{"type": "string"},
 							"tool_id":      map[string]any{"type": "string"},
 							"args":         map[string]any{"type": "object"},
 							"use_previous": map[string]any{"type": "boolean"},
+							"depends_on": map[string]any{
+								"type":  "array",
+								"items": map[string]any{"type": "string"},
+							},
+							"on_error": onErrorSchema(),
 						},
 						"required":             []string{"tool_id"},
 						"additionalProperties": false,
@@ -261,6 +286,9 @@ func runChainTool() mcp.Tool {
 				},
 				"include_backends": map[string]any{"type": "boolean", "default": true},
 				"include_tools":    map[string]any{"type": "boolean", "default": false},
+				"dry_run":          dryRunSchema(),
+				"mocks":            mocksSchema(),
+				"async":            asyncSchema(),
 			},
 			"required":             []string{"steps"},
 			"additionalProperties": false,
@@ -280,6 +308,7 @@ func executeCodeTool() mcp.Tool {
 				"code":           map[string]any{"type": "string"},
 				"timeout_ms":     map[string]any{"type": "integer", "minimum": 1, "maximum": 60000},
 				"max_tool_calls": map[string]any{"type": "integer", "minimum": 1, "maximum": 1000},
+				"async":          asyncSchema(),
 			},
 			"required":             []string{"language", "code"},
 			"additionalProperties": false,
@@ -291,8 +320,12 @@ func executeCodeTool() mcp.Tool {
 				"stdout":     map[string]any{"type": "string"},
 				"stderr":     map[string]any{"type": "string"},
 				"durationMs": map[string]any{"type": "integer"},
+				"job_id":     map[string]any{"type": "string"},
+			},
+			"anyOf": []map[string]any{
+				{"required": []string{"value"}},
+				{"required": []string{"job_id"}},
 			},
-			"required":             []string{"value"},
 			"additionalProperties": false,
 		},
 	}
@@ -318,6 +351,7 @@ func listToolsetsTool() mcp.Tool {
 							"name":        map[string]any{"type": "string"},
 							"description": map[string]any{"type": "string"},
 							"toolCount":   map[string]any{"type": "integer"},
+							"active":      map[string]any{"type": "boolean"},
 						},
 						"required":             []string{"id", "name", "toolCount"},
 						"additionalProperties": false,
@@ -330,6 +364,68 @@ func listToolsetsTool() mcp.Tool {
 	}
 }
 
+func activateToolsetTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "activate_toolset",
+		Description: "Limit this session's searches, listings and runs to the tools of the active toolsets",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"toolset_id": map[string]any{"type": "string", "description": "Toolset ID or name"},
+				"direct": map[string]any{
+					"type":        "boolean",
+					"default":     false,
+					"description": "Also publish the toolset's tools as MCP tools for this session",
+				},
+			},
+			"required":             []string{"toolset_id"},
+			"additionalProperties": false,
+		},
+		OutputSchema: activeToolsetsOutputSchema(),
+	}
+}
+
+func deactivateToolsetTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "deactivate_toolset",
+		Description: "Remove a toolset from this session's active toolsets; with none left, every tool is reachable again",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"toolset_id": map[string]any{"type": "string", "description": "Toolset ID or name"},
+			},
+			"required":             []string{"toolset_id"},
+			"additionalProperties": false,
+		},
+		OutputSchema: activeToolsetsOutputSchema(),
+	}
+}
+
+func activeToolsetsOutputSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"active": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"id":        map[string]any{"type": "string"},
+						"name":      map[string]any{"type": "string"},
+						"direct":    map[string]any{"type": "boolean"},
+						"toolCount": map[string]any{"type": "integer"},
+					},
+					"required":             []string{"id", "toolCount"},
+					"additionalProperties": false,
+				},
+			},
+			"changed": map[string]any{"type": "boolean"},
+		},
+		"required":             []string{"active", "changed"},
+		"additionalProperties": false,
+	}
+}
+
 func describeToolsetTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "describe_toolset",
@@ -382,11 +478,12 @@ func listSkillsTool() mcp.Tool {
 					"items": map[string]any{
 						"type": "object",
 						"properties": map[string]any{
-							"id":          map[string]any{"type": "string"},
-							"name":        map[string]any{"type": "string"},
-							"description": map[string]any{"type": "string"},
-							"stepCount":   map[string]any{"type": "integer"},
-							"toolset_id":  map[string]any{"type": "string"},
+							"id":           map[string]any{"type": "string"},
+							"name":         map[string]any{"type": "string"},
+							"description":  map[string]any{"type": "string"},
+							"stepCount":    map[string]any{"type": "integer"},
+							"toolset_id":   map[string]any{"type": "string"},
+							"input_schema": map[string]any{"type": "object"},
 						},
 						"required":             []string{"id", "name", "stepCount"},
 						"additionalProperties": false,
@@ -433,8 +530,9 @@ func planSkillTool() mcp.Tool {
 				"plan": map[string]any{
 					"type": "object",
 					"properties": map[string]any{
-						"name":  map[string]any{"type": "string"},
-						"steps": skillStepArraySchema(),
+						"name":   map[string]any{"type": "string"},
+						"steps":  skillStepArraySchema(),
+						"output": map[string]any{"type": "object"},
 					},
 					"required":             []string{"name", "steps"},
 					"additionalProperties": false,
@@ -455,6 +553,122 @@ func runSkillTool() mcp.Tool {
 	}
 }
 
+func getRunTool() mcp.Tool {
+	return mcp.Tool{
+		Name:         "get_run",
+		Description:  "Get a recorded run_chain or run_skill execution",
+		InputSchema:  runIDInputSchema(),
+		OutputSchema: runRecordOutputSchema(),
+	}
+}
+
+func resumeRunTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "resume_run",
+		Description: "Resume a failed or interrupted run from its first incomplete step",
+		InputSchema: runIDInputSchema(),
+		OutputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"run":   runRecordSchema(),
+				"chain": runChainOutputSchema(),
+				"skill": runSkillOutputSchema(),
+			},
+			"required":             []string{"run"},
+			"additionalProperties": false,
+		},
+	}
+}
+
+func cancelRunTool() mcp.Tool {
+	return mcp.Tool{
+		Name:         "cancel_run",
+		Description:  "Cancel a running or resumable run",
+		InputSchema:  runIDInputSchema(),
+		OutputSchema: runRecordOutputSchema(),
+	}
+}
+
+func getJobTool() mcp.Tool {
+	return mcp.Tool{
+		Name:         "get_job",
+		Description:  "Get the status, progress and result of an async job",
+		InputSchema:  jobIDInputSchema(),
+		OutputSchema: jobOutputSchema(),
+	}
+}
+
+func listJobsTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "list_jobs",
+		Description: "List the caller's async jobs",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"status": jobStatusSchema(),
+			},
+			"additionalProperties": false,
+		},
+		OutputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"jobs": map[string]any{
+					"type":  "array",
+					"items": jobSchema(),
+				},
+			},
+			"required":             []string{"jobs"},
+			"additionalProperties": false,
+		},
+	}
+}
+
+func cancelJobTool() mcp.Tool {
+	return mcp.Tool{
+		Name:         "cancel_job",
+		Description:  "Cancel an async job",
+		InputSchema:  jobIDInputSchema(),
+		OutputSchema: jobOutputSchema(),
+	}
+}
+
+func readResultTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "read_result",
+		Description: "Read part of a truncated tool result by JSON path or byte range",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"result_id": map[string]any{"type": "string"},
+				"path": map[string]any{
+					"type":        "string",
+					"description": "JSON path such as $.items[10:20] or $.data.name",
+				},
+				"offset":    map[string]any{"type": "integer", "minimum": 0, "description": "Byte offset into the result's JSON"},
+				"length":    map[string]any{"type": "integer", "minimum": 0, "description": "Bytes to read from offset"},
+				"max_bytes": map[string]any{"type": "integer", "minimum": 0},
+			},
+			"required":             []string{"result_id"},
+			"additionalProperties": false,
+		},
+		OutputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"result_id":   map[string]any{"type": "string"},
+				"total_bytes": map[string]any{"type": "integer"},
+				"path":        map[string]any{"type": "string"},
+				"value":       map[string]any{},
+				"data":        map[string]any{"type": "string"},
+				"offset":      map[string]any{"type": "integer"},
+				"next_offset": map[string]any{"type": "integer"},
+				"truncated":   truncatedSchema(),
+			},
+			"required":             []string{"result_id", "total_bytes"},
+			"additionalProperties": false,
+		},
+	}
+}
+
 func errorSchema() map[string]any {
 	return map[string]any{
 		"type": "object",
@@ -486,11 +700,15 @@ func runToolOutputSchema() map[string]any {
 			"backend":    map[string]any{"type": "object"},
 			"mcpResult":  map[string]any{"type": "object"},
 			"durationMs": map[string]any{"type": "integer"},
+			"job_id":     map[string]any{"type": "string"},
+			"content":    contentSchema(),
+			"truncated":  truncatedSchema(),
 		},
 		"additionalProperties": false,
 		"anyOf": []map[string]any{
 			{"required": []string{"structured"}},
 			{"required": []string{"error"}},
+			{"required": []string{"job_id"}},
 		},
 	}
 }
@@ -499,11 +717,14 @@ func runChainOutputSchema() map[string]any {
 	stepSchema := map[string]any{
 		"type": "object",
 		"properties": map[string]any{
+			"id":         map[string]any{"type": "string"},
 			"tool_id":    map[string]any{"type": "string"},
 			"structured": map[string]any{},
 			"backend":    map[string]any{"type": "object"},
 			"tool":       map[string]any{"type": "object"},
 			"error":      errorSchema(),
+			"policy":     stepPolicySchema(),
+			"content":    contentSchema(),
 		},
 		"required":             []string{"tool_id"},
 		"additionalProperties": false,
@@ -516,14 +737,121 @@ func runChainOutputSchema() map[string]any {
 				"type":  "array",
 				"items": stepSchema,
 			},
-			"final": map[string]any{},
-			"error": errorSchema(),
+			"final":  map[string]any{},
+			"error":  errorSchema(),
+			"run_id": map[string]any{"type": "string"},
+			"job_id": map[string]any{"type": "string"},
+			"trace":  dryRunTraceSchema(),
 		},
 		"required":             []string{"results"},
 		"additionalProperties": false,
 	}
 }
 
+func dryRunSchema() map[string]any {
+	return map[string]any{
+		"type":        "boolean",
+		"default":     false,
+		"description": "Validate tools, arguments and step references without calling any tool; the result carries a trace",
+	}
+}
+
+func mocksSchema() map[string]any {
+	return map[string]any{
+		"type":                 "object",
+		"description":          "Dry-run outputs keyed by tool_id; tools without a mock use values derived from their output schema",
+		"additionalProperties": true,
+	}
+}
+
+func dryRunTraceSchema() map[string]any {
+	return map[string]any{
+		"type": "array",
+		"items": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"id":            map[string]any{"type": "string"},
+				"tool_id":       map[string]any{"type": "string"},
+				"args":          map[string]any{"type": "object"},
+				"output":        map[string]any{},
+				"output_source": map[string]any{"type": "string", "enum": []string{"mock", "schema", "unknown"}},
+				"note":          map[string]any{"type": "string"},
+				"error":         errorSchema(),
+			},
+			"required":             []string{"tool_id", "output_source"},
+			"additionalProperties": false,
+		},
+	}
+}
+
+func onErrorSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type": "string",
+				"enum": []string{"fail", "continue", "retry", "fallback"},
+			},
+			"attempts":   map[string]any{"type": "integer", "minimum": 1, "maximum": 10},
+			"backoff_ms": map[string]any{"type": "integer", "minimum": 0},
+			"tool_id":    map[string]any{"type": "string"},
+			"args":       map[string]any{"type": "object"},
+		},
+		"required":             []string{"action"},
+		"additionalProperties": false,
+	}
+}
+
+// contentSchema describes a step's MCP content blocks: images, audio,
+// embedded resources and resource links.
+func truncatedSchema() map[string]any {
+	lengths := map[string]any{
+		"type":                 "object",
+		"additionalProperties": map[string]any{"type": "integer"},
+	}
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"result_id":   map[string]any{"type": "string"},
+			"total_bytes": map[string]any{"type": "integer"},
+			"arrays":      lengths,
+			"strings":     lengths,
+		},
+		"required":             []string{"total_bytes"},
+		"additionalProperties": false,
+	}
+}
+
+func contentSchema() map[string]any {
+	return map[string]any{
+		"type": "array",
+		"items": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"type": map[string]any{
+					"type": "string",
+					"enum": []string{"text", "image", "audio", "resource", "resource_link"},
+				},
+			},
+			"required": []string{"type"},
+		},
+	}
+}
+
+func stepPolicySchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action":           map[string]any{"type": "string"},
+			"attempts":         map[string]any{"type": "integer"},
+			"fallback_tool_id": map[string]any{"type": "string"},
+			"cause":            errorSchema(),
+		},
+		"required":             []string{"action"},
+		"additionalProperties": false,
+	}
+}
+
 func toolSummarySchema() map[string]any {
 	return map[string]any{
 		"type": "object",
@@ -549,6 +877,11 @@ func skillStepSchema() map[string]any {
 			"id":      map[string]any{"type": "string"},
 			"tool_id": map[string]any{"type": "string"},
 			"inputs":  map[string]any{"type": "object"},
+			"depends_on": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"type": "string"},
+			},
+			"on_error": onErrorSchema(),
 		},
 		"required":             []string{"id", "tool_id"},
 		"additionalProperties": false,
@@ -569,7 +902,19 @@ func skillSchema() map[string]any {
 			"name":        map[string]any{"type": "string"},
 			"description": map[string]any{"type": "string"},
 			"toolset_id":  map[string]any{"type": "string"},
-			"steps":       skillStepArraySchema(),
+			"input_schema": map[string]any{
+				"type":        "object",
+				"description": "JSON Schema for the skill's inputs; step inputs reference them as {{ inputs.<name> }}",
+			},
+			"steps": skillStepArraySchema(),
+			"output": map[string]any{
+				"type":        "object",
+				"description": "Final output built from step results with step references",
+			},
+			"output_schema": map[string]any{
+				"type":        "object",
+				"description": "JSON Schema the resolved output must satisfy",
+			},
 		},
 		"required":             []string{"name", "steps"},
 		"additionalProperties": false,
@@ -582,6 +927,10 @@ func planSkillInputSchema() map[string]any {
 		"properties": map[string]any{
 			"skill_id": map[string]any{"type": "string"},
 			"skill":    skillSchema(),
+			"inputs": map[string]any{
+				"type":        "object",
+				"description": "Values for the skill's input_schema",
+			},
 		},
 		"anyOf": []map[string]any{
 			{"required": []string{"skill_id"}},
@@ -597,6 +946,13 @@ func runSkillInputSchema() map[string]any {
 	props["max_steps"] = map[string]any{"type": "integer", "minimum": 1}
 	props["max_tool_calls"] = map[string]any{"type": "integer", "minimum": 1}
 	props["timeout_ms"] = map[string]any{"type": "integer", "minimum": 1}
+	props["include_step_results"] = map[string]any{
+		"type":        "boolean",
+		"default":     true,
+		"description": "When false, only failed steps are listed in results",
+	}
+	props["dry_run"] = dryRunSchema()
+	props["mocks"] = mocksSchema()
 	return schema
 }
 
@@ -607,6 +963,8 @@ func runSkillOutputSchema() map[string]any {
 			"step_id": map[string]any{"type": "string"},
 			"value":   map[string]any{},
 			"error":   errorSchema(),
+			"policy":  stepPolicySchema(),
+			"content": contentSchema(),
 		},
 		"required":             []string{"step_id"},
 		"additionalProperties": false,
@@ -615,14 +973,145 @@ func runSkillOutputSchema() map[string]any {
 	return map[string]any{
 		"type": "object",
 		"properties": map[string]any{
+			"output": map[string]any{"type": "object"},
 			"results": map[string]any{
 				"type":  "array",
 				"items": stepResultSchema,
 			},
 			"error":      errorSchema(),
 			"durationMs": map[string]any{"type": "integer"},
+			"run_id":     map[string]any{"type": "string"},
+			"trace":      dryRunTraceSchema(),
 		},
-		"required":             []string{"results"},
+		"additionalProperties": false,
+	}
+}
+
+func runIDInputSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"run_id": map[string]any{"type": "string"},
+		},
+		"required":             []string{"run_id"},
+		"additionalProperties": false,
+	}
+}
+
+func runRecordSchema() map[string]any {
+	stepSchema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"index":   map[string]any{"type": "integer", "minimum": 0},
+			"id":      map[string]any{"type": "string"},
+			"tool_id": map[string]any{"type": "string"},
+			"status":  map[string]any{"type": "string", "enum": []string{"succeeded", "failed"}},
+			"value":   map[string]any{},
+			"error":   map[string]any{"type": "string"},
+		},
+		"required":             []string{"index", "tool_id", "status"},
+		"additionalProperties": false,
+	}
+
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"run_id": map[string]any{"type": "string"},
+			"kind":   map[string]any{"type": "string", "enum": []string{"chain", "skill"}},
+			"status": map[string]any{
+				"type": "string",
+				"enum": []string{"running", "interrupted", "succeeded", "failed", "canceled"},
+			},
+			"error":   map[string]any{"type": "string"},
+			"request": map[string]any{},
+			"plan":    map[string]any{},
+			"steps": map[string]any{
+				"type":  "array",
+				"items": stepSchema,
+			},
+			"created_at":  map[string]any{"type": "string"},
+			"updated_at":  map[string]any{"type": "string"},
+			"finished_at": map[string]any{"type": "string"},
+		},
+		"required":             []string{"run_id", "kind", "status", "steps", "created_at", "updated_at"},
+		"additionalProperties": false,
+	}
+}
+
+func runRecordOutputSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"run": runRecordSchema(),
+		},
+		"required":             []string{"run"},
+		"additionalProperties": false,
+	}
+}
+
+func asyncSchema() map[string]any {
+	return map[string]any{
+		"type":        "boolean",
+		"default":     false,
+		"description": "Return a job_id at once and run in the background; poll with get_job",
+	}
+}
+
+func jobIDInputSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"job_id": map[string]any{"type": "string"},
+		},
+		"required":             []string{"job_id"},
+		"additionalProperties": false,
+	}
+}
+
+func jobStatusSchema() map[string]any {
+	return map[string]any{
+		"type": "string",
+		"enum": []string{"queued", "running", "succeeded", "failed", "canceled"},
+	}
+}
+
+func jobSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"job_id": map[string]any{"type": "string"},
+			"kind":   map[string]any{"type": "string", "enum": []string{"run_tool", "run_chain", "execute_code"}},
+			"status": jobStatusSchema(),
+			"progress": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"progress": map[string]any{"type": "number"},
+					"total":    map[string]any{"type": "number"},
+					"message":  map[string]any{"type": "string"},
+				},
+				"required":             []string{"progress"},
+				"additionalProperties": false,
+			},
+			"partial":     map[string]any{},
+			"result":      map[string]any{},
+			"is_error":    map[string]any{"type": "boolean"},
+			"error":       map[string]any{"type": "string"},
+			"created_at":  map[string]any{"type": "string"},
+			"started_at":  map[string]any{"type": "string"},
+			"finished_at": map[string]any{"type": "string"},
+		},
+		"required":             []string{"job_id", "kind", "status", "created_at"},
+		"additionalProperties": false,
+	}
+}
+
+func jobOutputSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"job": jobSchema(),
+		},
+		"required":             []string{"job"},
 		"additionalProperties": false,
 	}
 }
@@ -0,0 +1,64 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jonwraymond/metatools-mcp/internal/handlers"
+	"github.com/jonwraymond/metatools-mcp/internal/skills"
+	"github.com/jonwraymond/metatools-mcp/pkg/metatools"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SkillToolProvider serves one skill as its own MCP tool. Arguments are the
+// skill's inputs; calls delegate to run_skill.
+type SkillToolProvider struct {
+	handler *handlers.SkillsHandler
+	skill   *skills.Skill
+	name    string
+}
+
+// NewSkillToolProvider builds a SkillToolProvider publishing skill as name.
+func NewSkillToolProvider(handler *handlers.SkillsHandler, skill *skills.Skill, name string) *SkillToolProvider {
+	return &SkillToolProvider{handler: handler, skill: skill, name: name}
+}
+
+// Name returns the MCP tool name.
+func (p *SkillToolProvider) Name() string { return p.name }
+
+// Enabled reports whether the provider is enabled.
+func (p *SkillToolProvider) Enabled() bool { return true }
+
+// SkillID returns the ID of the served skill.
+func (p *SkillToolProvider) SkillID() string { return p.skill.ID }
+
+// Tool returns the MCP tool schema.
+func (p *SkillToolProvider) Tool() mcp.Tool {
+	description := p.skill.Description
+	if description == "" {
+		description = fmt.Sprintf("Run the %s skill.", p.skill.Name)
+	}
+	var input any = map[string]any{"type": "object"}
+	if p.skill.InputSchema != nil {
+		input = p.skill.InputSchema
+	}
+	return mcp.Tool{
+		Name:         p.name,
+		Title:        p.skill.Name,
+		Description:  description,
+		InputSchema:  input,
+		OutputSchema: runSkillTool().OutputSchema,
+	}
+}
+
+// Handle runs the skill with args as its inputs.
+func (p *SkillToolProvider) Handle(ctx context.Context, _ *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+	out, isError, err := p.handler.Run(ctx, metatools.RunSkillInput{SkillID: p.skill.ID, Inputs: args})
+	if err != nil {
+		return nil, nil, err
+	}
+	if out == nil {
+		out = &metatools.RunSkillOutput{}
+	}
+	return &mcp.CallToolResult{IsError: isError}, *out, nil
+}
@@ -0,0 +1,72 @@
+package query
+
+// Distance returns the optimal string alignment distance between a and b:
+// insertions, deletions, substitutions and adjacent transpositions each cost 1.
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 {
+		return len(rb)
+	}
+	if len(rb) == 0 {
+		return len(ra)
+	}
+
+	// Three rolling rows are enough for the transposition lookback.
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
+
+// Correct returns the vocabulary terms closest to term within maxDistance.
+// Ties are all returned in vocabulary order; vocab must be sorted for
+// deterministic output.
+func Correct(term string, vocab []string, maxDistance int) []string {
+	if maxDistance <= 0 {
+		return nil
+	}
+	best := maxDistance + 1
+	var out []string
+	termLen := len([]rune(term))
+	for _, candidate := range vocab {
+		if candidate == term {
+			return nil
+		}
+		diff := len([]rune(candidate)) - termLen
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff > maxDistance {
+			continue
+		}
+		d := Distance(term, candidate)
+		switch {
+		case d < best:
+			best = d
+			out = []string{candidate}
+		case d == best:
+			out = append(out, candidate)
+		}
+	}
+	if best > maxDistance {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,226 @@
+// Package query rewrites tool search queries before they reach a searcher.
+// It splits identifiers, expands a synonym dictionary, stems terms, and
+// corrects typos against tool-name vocabulary so that every search strategy
+// benefits from the same normalization.
+package query
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	porterstemmer "github.com/blevesearch/go-porterstemmer"
+)
+
+// Options configures a Processor.
+type Options struct {
+	// SplitIdentifiers splits snake_case, kebab-case, dotted and camelCase
+	// identifiers into separate terms.
+	SplitIdentifiers bool
+	// Stemming reduces terms to their Porter stem.
+	Stemming bool
+	// Synonyms maps a term or phrase to equivalent terms or phrases. Each entry
+	// forms an equivalence group, so expansion works in both directions.
+	Synonyms map[string][]string
+	// Fuzzy configures edit-distance typo correction on tool names.
+	Fuzzy FuzzyOptions
+}
+
+// FuzzyOptions configures typo correction.
+type FuzzyOptions struct {
+	Enabled bool
+	// MaxDistance is the maximum edit distance for a correction.
+	MaxDistance int
+	// MinTermLength is the minimum query term length eligible for correction.
+	MinTermLength int
+}
+
+// Processor normalizes queries and documents into comparable terms.
+//
+// Contract:
+// - Concurrency: safe for concurrent use after construction.
+// - Determinism: identical inputs always yield identical term order.
+type Processor struct {
+	opts         Options
+	synonyms     map[string][][]string
+	maxPhraseLen int
+}
+
+// NewProcessor builds a Processor from options.
+func NewProcessor(opts Options) *Processor {
+	p := &Processor{
+		opts:     opts,
+		synonyms: make(map[string][][]string),
+	}
+	for key, values := range opts.Synonyms {
+		group := make([][]string, 0, len(values)+1)
+		if tokens := p.Tokenize(key); len(tokens) > 0 {
+			group = append(group, tokens)
+		}
+		for _, value := range values {
+			if tokens := p.Tokenize(value); len(tokens) > 0 {
+				group = append(group, tokens)
+			}
+		}
+		for _, phrase := range group {
+			phraseKey := strings.Join(phrase, " ")
+			for _, alt := range group {
+				if strings.Join(alt, " ") == phraseKey {
+					continue
+				}
+				p.synonyms[phraseKey] = append(p.synonyms[phraseKey], alt)
+			}
+			if len(phrase) > p.maxPhraseLen {
+				p.maxPhraseLen = len(phrase)
+			}
+		}
+	}
+	for key, alts := range p.synonyms {
+		sort.Slice(alts, func(i, j int) bool {
+			return strings.Join(alts[i], " ") < strings.Join(alts[j], " ")
+		})
+		p.synonyms[key] = alts
+	}
+	return p
+}
+
+// Options returns the processor configuration.
+func (p *Processor) Options() Options {
+	return p.opts
+}
+
+// Tokenize lowercases text and splits it into raw tokens.
+// Identifier splitting is applied when enabled.
+func (p *Processor) Tokenize(text string) []string {
+	var out []string
+	for _, word := range splitWords(text, p.opts.SplitIdentifiers) {
+		if p.opts.SplitIdentifiers {
+			out = append(out, splitCamel(word)...)
+			continue
+		}
+		out = append(out, strings.ToLower(word))
+	}
+	return out
+}
+
+// Terms returns the expanded, normalized terms for a query.
+// Raw tokens are kept alongside synonyms and stems so exact matches still win.
+func (p *Processor) Terms(query string) []string {
+	var terms []string
+	seen := make(map[string]struct{})
+	add := func(term string) {
+		if term == "" {
+			return
+		}
+		if _, ok := seen[term]; ok {
+			return
+		}
+		seen[term] = struct{}{}
+		terms = append(terms, term)
+	}
+
+	// Keep compound identifiers intact so searchers that index whole names
+	// still see an exact token.
+	for _, word := range splitWords(query, false) {
+		add(strings.ToLower(word))
+	}
+
+	tokens := p.Tokenize(query)
+	expanded := p.expandSynonyms(tokens)
+	for _, token := range expanded {
+		add(token)
+		if p.opts.Stemming {
+			add(Stem(token))
+		}
+	}
+	return terms
+}
+
+// DocTerms returns the normalized terms for document text.
+// Synonyms are not applied to documents; expansion happens on the query side.
+func (p *Processor) DocTerms(text string) []string {
+	tokens := p.Tokenize(text)
+	if !p.opts.Stemming {
+		return tokens
+	}
+	out := make([]string, 0, len(tokens)*2)
+	for _, token := range tokens {
+		out = append(out, token)
+		if stem := Stem(token); stem != token {
+			out = append(out, stem)
+		}
+	}
+	return out
+}
+
+func (p *Processor) expandSynonyms(tokens []string) []string {
+	if len(p.synonyms) == 0 {
+		return tokens
+	}
+	out := make([]string, 0, len(tokens))
+	for i := 0; i < len(tokens); {
+		matched := 0
+		for n := min(p.maxPhraseLen, len(tokens)-i); n > 0; n-- {
+			phrase := strings.Join(tokens[i:i+n], " ")
+			alts, ok := p.synonyms[phrase]
+			if !ok {
+				continue
+			}
+			out = append(out, tokens[i:i+n]...)
+			for _, alt := range alts {
+				out = append(out, alt...)
+			}
+			matched = n
+			break
+		}
+		if matched == 0 {
+			out = append(out, tokens[i])
+			matched = 1
+		}
+		i += matched
+	}
+	return out
+}
+
+// Stem returns the Porter stem of a lowercase term.
+func Stem(term string) string {
+	if len(term) < 3 {
+		return term
+	}
+	return porterstemmer.StemString(term)
+}
+
+// splitWords splits text on characters that are not letters or digits.
+// When splitJoiners is true, underscores and hyphens also separate words.
+func splitWords(text string, splitJoiners bool) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return false
+		}
+		if !splitJoiners && (r == '_' || r == '-') {
+			return false
+		}
+		return true
+	})
+}
+
+// splitCamel splits a word at camelCase boundaries and lowercases the parts.
+// Digits stay attached to their word so abbreviations like "k8s" survive.
+func splitCamel(word string) []string {
+	runes := []rune(word)
+	var parts []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		boundary := unicode.IsLower(prev) && unicode.IsUpper(cur)
+		if !boundary && unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			boundary = true
+		}
+		if boundary {
+			parts = append(parts, strings.ToLower(string(runes[start:i])))
+			start = i
+		}
+	}
+	parts = append(parts, strings.ToLower(string(runes[start:])))
+	return parts
+}
@@ -0,0 +1,80 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessor_TokenizeSplitsIdentifiers(t *testing.T) {
+	p := NewProcessor(Options{SplitIdentifiers: true})
+
+	assert.Equal(t, []string{"create", "pull", "request"}, p.Tokenize("create_pull_request"))
+	assert.Equal(t, []string{"list", "issues"}, p.Tokenize("listIssues"))
+	assert.Equal(t, []string{"http", "server"}, p.Tokenize("HTTPServer"))
+	assert.Equal(t, []string{"get", "k8s", "pods"}, p.Tokenize("get-k8s.pods"))
+}
+
+func TestProcessor_TokenizeWithoutSplitting(t *testing.T) {
+	p := NewProcessor(Options{})
+	assert.Equal(t, []string{"create_pull_request", "listissues"}, p.Tokenize("create_pull_request listIssues"))
+}
+
+func TestProcessor_TermsKeepsCompoundAndParts(t *testing.T) {
+	p := NewProcessor(Options{SplitIdentifiers: true})
+	assert.Equal(t, []string{"create_pull_request", "create", "pull", "request"}, p.Terms("create_pull_request"))
+}
+
+func TestProcessor_TermsStems(t *testing.T) {
+	p := NewProcessor(Options{Stemming: true})
+	terms := p.Terms("listing repositories")
+	assert.Contains(t, terms, "listing")
+	assert.Contains(t, terms, "list")
+	assert.Contains(t, terms, "repositori")
+}
+
+func TestProcessor_SynonymsAreBidirectional(t *testing.T) {
+	p := NewProcessor(Options{
+		SplitIdentifiers: true,
+		Synonyms:         map[string][]string{"k8s": {"kubernetes"}, "pr": {"pull request"}},
+	})
+
+	assert.Equal(t, []string{"k8s", "kubernetes"}, p.Terms("k8s"))
+	assert.Equal(t, []string{"kubernetes", "k8s"}, p.Terms("kubernetes"))
+	assert.Equal(t, []string{"open", "pull", "request", "pr"}, p.Terms("open pull request"))
+	assert.Equal(t, []string{"open", "pr", "pull", "request"}, p.Terms("open pr"))
+}
+
+func TestProcessor_DocTermsSkipsSynonyms(t *testing.T) {
+	p := NewProcessor(Options{
+		SplitIdentifiers: true,
+		Synonyms:         map[string][]string{"k8s": {"kubernetes"}},
+	})
+	assert.Equal(t, []string{"k8s", "pods"}, p.DocTerms("k8s_pods"))
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"issue", "issue", 0},
+		{"isue", "issue", 1},
+		{"isseu", "issue", 1},
+		{"kitten", "sitting", 3},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, Distance(tc.a, tc.b), "%q vs %q", tc.a, tc.b)
+	}
+}
+
+func TestCorrect(t *testing.T) {
+	vocab := []string{"issue", "issues", "list", "pull"}
+
+	assert.Equal(t, []string{"issue"}, Correct("isue", vocab, 1))
+	assert.Nil(t, Correct("issue", vocab, 1), "exact match needs no correction")
+	assert.Nil(t, Correct("zzzz", vocab, 1))
+	assert.Nil(t, Correct("isue", vocab, 0))
+}
@@ -0,0 +1,280 @@
+package query
+
+import (
+	"hash/fnv"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+)
+
+// Searcher runs queries through a Processor before delegating to an inner
+// searcher. Documents are augmented with their normalized terms so that
+// split identifiers and stems are matchable by any strategy.
+//
+// When inner is nil, a token-based lexical ranking is used instead of the
+// index default, because substring matching cannot consume expanded terms.
+type Searcher struct {
+	inner     index.Searcher
+	processor *Processor
+
+	mu       sync.Mutex
+	prepared *preparedDocs
+}
+
+type preparedDocs struct {
+	fingerprint uint64
+	docs        []index.SearchDoc
+	nameTerms   []map[string]struct{}
+	nsTerms     []map[string]struct{}
+	docTerms    []map[string]struct{}
+	vocab       map[string]struct{}
+	nameVocab   []string
+}
+
+// Processed is a search whose query has been processed, next to the query
+// and documents as given.
+type Processed struct {
+	Query string
+	Docs  []index.SearchDoc
+	// Terms is the processed query and TermDocs are Docs augmented with
+	// their normalized terms.
+	Terms    string
+	TermDocs []index.SearchDoc
+}
+
+// ProcessedSearcher is implemented by inner searchers that score with more
+// than the processed terms. Embedding searchers use it to embed the query
+// and documents as written while their lexical scoring uses the terms.
+type ProcessedSearcher interface {
+	SearchProcessed(p Processed, limit int) ([]index.Summary, error)
+}
+
+// NewSearcher wraps inner with query processing.
+func NewSearcher(inner index.Searcher, processor *Processor) *Searcher {
+	if processor == nil {
+		processor = NewProcessor(Options{})
+	}
+	return &Searcher{inner: inner, processor: processor}
+}
+
+// Inner returns the wrapped searcher (nil for the built-in lexical ranking).
+func (s *Searcher) Inner() index.Searcher {
+	return s.inner
+}
+
+// Processor returns the query processor.
+func (s *Searcher) Processor() *Processor {
+	return s.processor
+}
+
+// Deterministic reports whether ordering is stable for identical inputs.
+func (s *Searcher) Deterministic() bool {
+	if s.inner == nil {
+		return true
+	}
+	ds, ok := s.inner.(index.DeterministicSearcher)
+	return ok && ds.Deterministic()
+}
+
+// Close releases resources held by the inner searcher.
+func (s *Searcher) Close() error {
+	if closer, ok := s.inner.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Rewrite returns the processed query string that is handed to the inner
+// searcher for the given documents.
+func (s *Searcher) Rewrite(query string, docs []index.SearchDoc) string {
+	return strings.Join(s.terms(query, s.prepare(docs)), " ")
+}
+
+// Search implements index.Searcher.
+func (s *Searcher) Search(query string, limit int, docs []index.SearchDoc) ([]index.Summary, error) {
+	if limit <= 0 {
+		return []index.Summary{}, nil
+	}
+	if strings.TrimSpace(query) == "" {
+		if s.inner != nil {
+			return s.inner.Search(query, limit, docs)
+		}
+		n := min(limit, len(docs))
+		out := make([]index.Summary, n)
+		for i := range n {
+			out[i] = docs[i].Summary
+		}
+		return out, nil
+	}
+
+	prepared := s.prepare(docs)
+	terms := s.terms(query, prepared)
+	if ps, ok := s.inner.(ProcessedSearcher); ok {
+		return ps.SearchProcessed(Processed{
+			Query:    query,
+			Docs:     docs,
+			Terms:    strings.Join(terms, " "),
+			TermDocs: prepared.docs,
+		}, limit)
+	}
+	if s.inner != nil {
+		return s.inner.Search(strings.Join(terms, " "), limit, prepared.docs)
+	}
+	return lexicalSearch(terms, limit, prepared), nil
+}
+
+func (s *Searcher) terms(query string, prepared *preparedDocs) []string {
+	terms := s.processor.Terms(query)
+	fuzzy := s.processor.opts.Fuzzy
+	if !fuzzy.Enabled || fuzzy.MaxDistance <= 0 {
+		return terms
+	}
+	seen := make(map[string]struct{}, len(terms))
+	for _, term := range terms {
+		seen[term] = struct{}{}
+	}
+	out := terms
+	for _, term := range terms {
+		if len([]rune(term)) < fuzzy.MinTermLength {
+			continue
+		}
+		if _, known := prepared.vocab[term]; known {
+			continue
+		}
+		for _, corrected := range Correct(term, prepared.nameVocab, fuzzy.MaxDistance) {
+			if _, ok := seen[corrected]; ok {
+				continue
+			}
+			seen[corrected] = struct{}{}
+			out = append(out, corrected)
+		}
+	}
+	return out
+}
+
+func (s *Searcher) prepare(docs []index.SearchDoc) *preparedDocs {
+	fp := fingerprint(docs)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.prepared != nil && s.prepared.fingerprint == fp {
+		return s.prepared
+	}
+
+	p := &preparedDocs{
+		fingerprint: fp,
+		docs:        make([]index.SearchDoc, len(docs)),
+		nameTerms:   make([]map[string]struct{}, len(docs)),
+		nsTerms:     make([]map[string]struct{}, len(docs)),
+		docTerms:    make([]map[string]struct{}, len(docs)),
+		vocab:       make(map[string]struct{}),
+	}
+	nameVocab := make(map[string]struct{})
+	for i, doc := range docs {
+		nameTerms := s.processor.DocTerms(doc.Summary.Name)
+		nsTerms := s.processor.DocTerms(doc.Summary.Namespace)
+		textTerms := s.processor.DocTerms(doc.DocText)
+
+		p.nameTerms[i] = toSet(nameTerms)
+		p.nsTerms[i] = toSet(nsTerms)
+		p.docTerms[i] = toSet(textTerms)
+		for term := range p.docTerms[i] {
+			p.vocab[term] = struct{}{}
+		}
+		for _, term := range nameTerms {
+			p.vocab[term] = struct{}{}
+			nameVocab[term] = struct{}{}
+		}
+		for _, term := range nsTerms {
+			p.vocab[term] = struct{}{}
+		}
+
+		augmented := doc
+		extra := append(append(append([]string{}, nameTerms...), nsTerms...), textTerms...)
+		if len(extra) > 0 {
+			augmented.DocText = doc.DocText + " " + strings.Join(extra, " ")
+		}
+		p.docs[i] = augmented
+	}
+	p.nameVocab = make([]string, 0, len(nameVocab))
+	for term := range nameVocab {
+		p.nameVocab = append(p.nameVocab, term)
+	}
+	sort.Strings(p.nameVocab)
+
+	s.prepared = p
+	return p
+}
+
+type scoredSummary struct {
+	summary index.Summary
+	score   int
+}
+
+// lexicalSearch ranks documents by weighted term overlap:
+// name terms outrank namespace terms, which outrank body text.
+func lexicalSearch(terms []string, limit int, prepared *preparedDocs) []index.Summary {
+	var scored []scoredSummary
+	for i, doc := range prepared.docs {
+		score := 0
+		for _, term := range terms {
+			switch {
+			case has(prepared.nameTerms[i], term):
+				score += 3
+			case has(prepared.nsTerms[i], term):
+				score += 2
+			case has(prepared.docTerms[i], term):
+				score++
+			}
+		}
+		if score > 0 {
+			scored = append(scored, scoredSummary{summary: doc.Summary, score: score})
+		}
+	}
+	sort.Slice(scored, func(i, j int) bool {
+		if scored[i].score == scored[j].score {
+			return scored[i].summary.ID < scored[j].summary.ID
+		}
+		return scored[i].score > scored[j].score
+	})
+	if len(scored) > limit {
+		scored = scored[:limit]
+	}
+	out := make([]index.Summary, len(scored))
+	for i, sr := range scored {
+		out[i] = sr.summary
+	}
+	return out
+}
+
+func fingerprint(docs []index.SearchDoc) uint64 {
+	h := fnv.New64a()
+	for _, doc := range docs {
+		_, _ = h.Write([]byte(doc.ID))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(doc.Summary.Name))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(doc.Summary.Namespace))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(doc.DocText))
+		_, _ = h.Write([]byte{1})
+	}
+	return h.Sum64()
+}
+
+func toSet(values []string) map[string]struct{} {
+	out := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		out[v] = struct{}{}
+	}
+	return out
+}
+
+func has(set map[string]struct{}, term string) bool {
+	_, ok := set[term]
+	return ok
+}
+
+var _ index.DeterministicSearcher = (*Searcher)(nil)
@@ -0,0 +1,146 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDocs() []index.SearchDoc {
+	mk := func(ns, name, desc string) index.SearchDoc {
+		id := ns + ":" + name
+		return index.SearchDoc{
+			ID:      id,
+			DocText: name + " " + ns + " " + desc,
+			Summary: index.Summary{ID: id, Name: name, Namespace: ns, ShortDescription: desc},
+		}
+	}
+	return []index.SearchDoc{
+		mk("github", "create_pull_request", "Open a new pull request"),
+		mk("github", "list_issues", "List repository issues"),
+		mk("kubernetes", "get_pods", "Get pods in a cluster"),
+	}
+}
+
+func defaultProcessor(synonyms map[string][]string) *Processor {
+	return NewProcessor(Options{
+		SplitIdentifiers: true,
+		Stemming:         true,
+		Synonyms:         synonyms,
+		Fuzzy:            FuzzyOptions{Enabled: true, MaxDistance: 1, MinTermLength: 4},
+	})
+}
+
+func ids(results []index.Summary) []string {
+	out := make([]string, len(results))
+	for i, r := range results {
+		out[i] = r.ID
+	}
+	return out
+}
+
+func TestSearcher_LexicalSynonyms(t *testing.T) {
+	s := NewSearcher(nil, defaultProcessor(map[string][]string{"k8s": {"kubernetes"}}))
+
+	results, err := s.Search("k8s pods", 10, testDocs())
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	assert.Equal(t, "kubernetes:get_pods", results[0].ID)
+}
+
+func TestSearcher_LexicalTypoTolerance(t *testing.T) {
+	s := NewSearcher(nil, defaultProcessor(nil))
+
+	results, err := s.Search("isues", 10, testDocs())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"github:list_issues"}, ids(results))
+}
+
+func TestSearcher_LexicalStemming(t *testing.T) {
+	s := NewSearcher(nil, defaultProcessor(nil))
+
+	results, err := s.Search("listing", 10, testDocs())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"github:list_issues"}, ids(results))
+}
+
+func TestSearcher_LexicalRanksNamesFirst(t *testing.T) {
+	s := NewSearcher(nil, defaultProcessor(nil))
+
+	results, err := s.Search("pull request", 10, testDocs())
+	require.NoError(t, err)
+	assert.Equal(t, "github:create_pull_request", results[0].ID)
+}
+
+func TestSearcher_EmptyQueryAndLimit(t *testing.T) {
+	s := NewSearcher(nil, defaultProcessor(nil))
+
+	results, err := s.Search("", 2, testDocs())
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	results, err = s.Search("issues", 0, testDocs())
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+type recordingSearcher struct {
+	query string
+	docs  []index.SearchDoc
+}
+
+func (r *recordingSearcher) Search(query string, limit int, docs []index.SearchDoc) ([]index.Summary, error) {
+	r.query = query
+	r.docs = docs
+	return nil, nil
+}
+
+func TestSearcher_DelegatesRewrittenQuery(t *testing.T) {
+	inner := &recordingSearcher{}
+	s := NewSearcher(inner, defaultProcessor(map[string][]string{"k8s": {"kubernetes"}}))
+
+	_, err := s.Search("k8s isues", 5, testDocs())
+	require.NoError(t, err)
+	assert.Equal(t, "k8s isues k8 kubernetes kubernet isu issues", inner.query)
+	require.Len(t, inner.docs, 3)
+	assert.Contains(t, inner.docs[0].DocText, "pull request")
+	assert.False(t, s.Deterministic(), "inner searcher is not deterministic")
+}
+
+func TestSearcher_RewriteIsStable(t *testing.T) {
+	s := NewSearcher(nil, defaultProcessor(nil))
+	docs := testDocs()
+	assert.Equal(t, s.Rewrite("ListIssues", docs), s.Rewrite("ListIssues", docs))
+	assert.True(t, s.Deterministic())
+}
+
+// processedInner records the processed search it is given.
+type processedInner struct {
+	got Processed
+}
+
+func (p *processedInner) Search(string, int, []index.SearchDoc) ([]index.Summary, error) {
+	panic("Search called instead of SearchProcessed")
+}
+
+func (p *processedInner) SearchProcessed(got Processed, _ int) ([]index.Summary, error) {
+	p.got = got
+	return nil, nil
+}
+
+func TestSearcher_ProcessedSearcherGetsQueryAsWritten(t *testing.T) {
+	inner := &processedInner{}
+	s := NewSearcher(inner, defaultProcessor(map[string][]string{"k8s": {"kubernetes"}}))
+	docs := testDocs()
+
+	_, err := s.Search("k8s pods", 10, docs)
+	require.NoError(t, err)
+	assert.Equal(t, "k8s pods", inner.got.Query)
+	assert.Equal(t, docs, inner.got.Docs)
+	assert.Equal(t, s.Rewrite("k8s pods", docs), inner.got.Terms)
+	assert.Contains(t, inner.got.Terms, "kubernetes")
+	require.Len(t, inner.got.TermDocs, len(docs))
+	assert.Contains(t, inner.got.TermDocs[0].DocText, "pull")
+}
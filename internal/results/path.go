@@ -0,0 +1,146 @@
+package results
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// preview copies value, cutting arrays to items entries and strings to chars
+// bytes, and records each cut in t under its JSON path.
+func preview(value any, path string, items, chars int, t *Truncation) any {
+	switch v := value.(type) {
+	case string:
+		if len(v) <= chars {
+			return v
+		}
+		t.Strings[path] = len(v)
+		cut := chars
+		for cut > 0 && !utf8.RuneStart(v[cut]) {
+			cut--
+		}
+		return v[:cut]
+	case []any:
+		n := len(v)
+		if n > items {
+			t.Arrays[path] = n
+			n = items
+		}
+		out := make([]any, n)
+		for i := range out {
+			out[i] = preview(v[i], fmt.Sprintf("%s[%d]", path, i), items, chars, t)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			out[k] = preview(item, childPath(path, k), items, chars, t)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// childPath appends key to a JSON path, e.g. $.items or $["a b"].
+func childPath(path, key string) string {
+	if identifier.MatchString(key) {
+		return path + "." + key
+	}
+	quoted, _ := json.Marshal(key)
+	return path + "[" + string(quoted) + "]"
+}
+
+// pathToken matches one step of a JSON path: .key, ["key"], [n] or [a:b].
+var pathToken = regexp.MustCompile(`^(?:\.([A-Za-z_][A-Za-z0-9_]*)|\[("(?:[^"\\]|\\.)*")\]|\[(-?\d+)\]|\[(-?\d*):(-?\d*)\])`)
+
+// Select returns the part of value at path. Paths start with "$" and use
+// .key, ["key"], [index] and [start:end] steps; negative indexes count from
+// the end of an array. path is returned as normalized, so that a preview of
+// the selection reports the same paths as the full result.
+func Select(value any, path string) (any, string, error) {
+	rest := strings.TrimSpace(path)
+	if rest == "" {
+		rest = "$"
+	}
+	if !strings.HasPrefix(rest, "$") {
+		rest = "$" + ensureStep(rest)
+	}
+	rest = rest[1:]
+	current, at := value, "$"
+	for rest != "" {
+		m := pathToken.FindStringSubmatch(rest)
+		if m == nil {
+			return nil, "", fmt.Errorf("invalid path %q at %q", path, rest)
+		}
+		rest = rest[len(m[0]):]
+		switch {
+		case m[1] != "" || m[2] != "":
+			key := m[1]
+			if m[2] != "" {
+				if err := json.Unmarshal([]byte(m[2]), &key); err != nil {
+					return nil, "", fmt.Errorf("invalid path %q: %w", path, err)
+				}
+			}
+			obj, ok := current.(map[string]any)
+			if !ok {
+				return nil, "", fmt.Errorf("path %s is not an object", at)
+			}
+			if current, ok = obj[key]; !ok {
+				return nil, "", fmt.Errorf("path %s has no key %q", at, key)
+			}
+			at = childPath(at, key)
+		case m[3] != "":
+			arr, ok := current.([]any)
+			if !ok {
+				return nil, "", fmt.Errorf("path %s is not an array", at)
+			}
+			i, _ := strconv.Atoi(m[3])
+			if i < 0 {
+				i += len(arr)
+			}
+			if i < 0 || i >= len(arr) {
+				return nil, "", fmt.Errorf("path %s has no index %s", at, m[3])
+			}
+			current = arr[i]
+			at = fmt.Sprintf("%s[%d]", at, i)
+		default:
+			arr, ok := current.([]any)
+			if !ok {
+				return nil, "", fmt.Errorf("path %s is not an array", at)
+			}
+			start, end := bound(m[4], 0, len(arr)), bound(m[5], len(arr), len(arr))
+			if start > end {
+				start = end
+			}
+			current = arr[start:end]
+			at = fmt.Sprintf("%s[%d:%d]", at, start, end)
+		}
+	}
+	return current, at, nil
+}
+
+// ensureStep lets paths omit the leading "$" and ".", e.g. items[0].
+func ensureStep(path string) string {
+	if strings.HasPrefix(path, "[") || strings.HasPrefix(path, ".") {
+		return path
+	}
+	return "." + path
+}
+
+// bound resolves a slice bound against an array of length n.
+func bound(raw string, def, n int) int {
+	if raw == "" {
+		return def
+	}
+	i, _ := strconv.Atoi(raw)
+	if i < 0 {
+		i += n
+	}
+	return max(0, min(i, n))
+}
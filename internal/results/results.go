@@ -0,0 +1,232 @@
+// Package results keeps oversized tool results out of metatool responses. A
+// Store cuts results over a size limit down to a structural preview and keeps
+// the full payload behind a result ID, so the caller can page through it
+// instead of receiving it whole.
+package results
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Defaults applied to zero Options fields.
+const (
+	DefaultPreviewItems = 10
+	DefaultTTL          = time.Hour
+	DefaultSessionQuota = 64 << 20
+)
+
+// ErrNotFound is returned for unknown, expired or foreign result IDs.
+var ErrNotFound = errors.New("result not found or expired")
+
+// Options configures a Store.
+type Options struct {
+	// MaxBytes caps the JSON size of a tool's result when neither the call
+	// nor PerTool sets a limit. Zero applies no default limit.
+	MaxBytes int
+	// PerTool maps tool IDs to their own limits.
+	PerTool map[string]int
+	// PreviewItems is the most array items a preview keeps.
+	PreviewItems int
+	// TTL is how long full payloads are kept.
+	TTL time.Duration
+	// SessionQuota caps the bytes stored per session. The session's oldest
+	// payloads are dropped to make room; a payload larger than the quota is
+	// not stored.
+	SessionQuota int
+}
+
+// Truncation describes a result that was cut to fit a size limit.
+type Truncation struct {
+	// ResultID identifies the stored full payload. It is empty when the
+	// payload did not fit the session quota.
+	ResultID string
+	// TotalBytes is the JSON size of the uncut value.
+	TotalBytes int
+	// Arrays and Strings map the JSON paths of the cut arrays and strings
+	// to their full lengths.
+	Arrays  map[string]int
+	Strings map[string]int
+}
+
+type entry struct {
+	id      string
+	data    []byte
+	expires time.Time
+}
+
+// Store truncates oversized results and keeps their full payloads in memory
+// per session until their TTL expires. It is safe for concurrent use.
+type Store struct {
+	opts Options
+	now  func() time.Time
+
+	mu       sync.Mutex
+	sessions map[any][]entry // in insertion order
+}
+
+// NewStore creates a Store, applying defaults to zero Options fields.
+func NewStore(opts Options) *Store {
+	if opts.PreviewItems <= 0 {
+		opts.PreviewItems = DefaultPreviewItems
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultTTL
+	}
+	if opts.SessionQuota <= 0 {
+		opts.SessionQuota = DefaultSessionQuota
+	}
+	return &Store{opts: opts, now: time.Now, sessions: make(map[any][]entry)}
+}
+
+type sessionKey struct{}
+
+// WithSession returns a context whose results are stored under, and can
+// only be read back by, session. session must be comparable.
+func WithSession(ctx context.Context, session any) context.Context {
+	return context.WithValue(ctx, sessionKey{}, session)
+}
+
+func sessionFrom(ctx context.Context) any {
+	return ctx.Value(sessionKey{})
+}
+
+// MaxBytes returns the size limit for a result of toolID: maxBytes when
+// positive, else the tool's own limit, else the default. Zero means no limit.
+func (s *Store) MaxBytes(toolID string, maxBytes int) int {
+	if maxBytes > 0 {
+		return maxBytes
+	}
+	if n := s.opts.PerTool[toolID]; n > 0 {
+		return n
+	}
+	return s.opts.MaxBytes
+}
+
+// Limit returns value unchanged when its JSON fits the limit for toolID (see
+// MaxBytes). Otherwise it stores the full payload under ctx's session and
+// returns a preview of value that fits the limit, with a Truncation
+// describing what was cut.
+func (s *Store) Limit(ctx context.Context, toolID string, value any, maxBytes int) (any, *Truncation) {
+	limit := s.MaxBytes(toolID, maxBytes)
+	if limit <= 0 || value == nil {
+		return value, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil || len(data) <= limit {
+		return value, nil
+	}
+	generic, err := Decode(data)
+	if err != nil {
+		return value, nil
+	}
+	preview, trunc := s.Preview(generic, "$", limit)
+	trunc.ResultID = s.put(sessionFrom(ctx), data)
+	return preview, trunc
+}
+
+// Preview returns value unchanged when its JSON fits limit bytes. Otherwise
+// it cuts value, found at path in its result, down to fit: arrays keep their
+// first items and strings their first bytes, both cut shorter until the
+// preview fits. A value that cannot fit, e.g. an object with too many keys,
+// previews as nil.
+func (s *Store) Preview(value any, path string, limit int) (any, *Truncation) {
+	data, err := json.Marshal(value)
+	if err != nil || len(data) <= limit {
+		return value, nil
+	}
+	items, chars := s.opts.PreviewItems, limit/2
+	for {
+		t := &Truncation{TotalBytes: len(data), Arrays: map[string]int{}, Strings: map[string]int{}}
+		p := preview(value, path, items, chars, t)
+		if out, err := json.Marshal(p); err == nil && len(out) <= limit {
+			return p, t
+		}
+		if items == 0 && chars == 0 {
+			return nil, t
+		}
+		items, chars = items/2, chars/2
+	}
+}
+
+// Get returns the full payload of a result stored under ctx's session.
+func (s *Store) Get(ctx context.Context, id string) ([]byte, error) {
+	session := sessionFrom(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep()
+	for _, e := range s.sessions[session] {
+		if e.id == id {
+			return e.data, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// DropSession discards every result stored under session.
+func (s *Store) DropSession(session any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, session)
+}
+
+// put stores data under session and returns its ID, or "" when data is
+// larger than the session quota.
+func (s *Store) put(session any, data []byte) string {
+	if len(data) > s.opts.SessionQuota {
+		return ""
+	}
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	id := hex.EncodeToString(b[:])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep()
+	entries := s.sessions[session]
+	used := len(data)
+	for _, e := range entries {
+		used += len(e.data)
+	}
+	for used > s.opts.SessionQuota {
+		used -= len(entries[0].data)
+		entries = entries[1:]
+	}
+	s.sessions[session] = append(entries, entry{id: id, data: data, expires: s.now().Add(s.opts.TTL)})
+	return id
+}
+
+// sweep drops expired entries; the caller holds s.mu.
+func (s *Store) sweep() {
+	now := s.now()
+	for session, entries := range s.sessions {
+		kept := entries[:0]
+		for _, e := range entries {
+			if !now.After(e.expires) {
+				kept = append(kept, e)
+			}
+		}
+		if len(kept) == 0 {
+			delete(s.sessions, session)
+			continue
+		}
+		s.sessions[session] = kept
+	}
+}
+
+// Decode parses a stored payload, keeping numbers exact.
+func Decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
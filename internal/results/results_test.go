@@ -0,0 +1,117 @@
+package results
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func bigResult(n int) map[string]any {
+	items := make([]any, n)
+	for i := range items {
+		items[i] = map[string]any{"id": i, "name": fmt.Sprintf("item-%d", i)}
+	}
+	return map[string]any{"items": items, "note": strings.Repeat("x", 2000), "count": n}
+}
+
+func TestStore_LimitPassesSmallResults(t *testing.T) {
+	s := NewStore(Options{MaxBytes: 1 << 20})
+	value := bigResult(3)
+	got, trunc := s.Limit(context.Background(), "ns:tool", value, 0)
+	require.Nil(t, trunc)
+	require.Equal(t, value, got)
+
+	// Without a limit nothing is cut.
+	got, trunc = NewStore(Options{}).Limit(context.Background(), "ns:tool", bigResult(1000), 0)
+	require.Nil(t, trunc)
+	require.Len(t, got.(map[string]any)["items"], 1000)
+}
+
+func TestStore_LimitPreviewsLargeResults(t *testing.T) {
+	s := NewStore(Options{MaxBytes: 1024, PreviewItems: 5})
+	ctx := WithSession(context.Background(), "session-a")
+	got, trunc := s.Limit(ctx, "ns:tool", bigResult(1000), 0)
+	require.NotNil(t, trunc)
+
+	data, err := json.Marshal(got)
+	require.NoError(t, err)
+	require.LessOrEqual(t, len(data), 1024)
+	preview := got.(map[string]any)
+	require.LessOrEqual(t, len(preview["items"].([]any)), 5)
+	require.Equal(t, json.Number("1000"), preview["count"])
+	require.Equal(t, 1000, trunc.Arrays["$.items"])
+	require.Equal(t, 2000, trunc.Strings["$.note"])
+
+	full, err := s.Get(ctx, trunc.ResultID)
+	require.NoError(t, err)
+	require.Equal(t, trunc.TotalBytes, len(full))
+
+	// Results are only readable from the session that produced them.
+	_, err = s.Get(WithSession(context.Background(), "session-b"), trunc.ResultID)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestStore_Limits(t *testing.T) {
+	s := NewStore(Options{MaxBytes: 100, PerTool: map[string]int{"ns:big": 1 << 20}})
+	require.Equal(t, 100, s.MaxBytes("ns:tool", 0))
+	require.Equal(t, 1<<20, s.MaxBytes("ns:big", 0))
+	require.Equal(t, 50, s.MaxBytes("ns:big", 50))
+}
+
+func TestStore_QuotaAndExpiry(t *testing.T) {
+	s := NewStore(Options{MaxBytes: 10, SessionQuota: 100, TTL: time.Minute})
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, first := s.Limit(ctx, "ns:tool", strings.Repeat("a", 60), 0)
+	_, second := s.Limit(ctx, "ns:tool", strings.Repeat("b", 30), 0)
+	_, third := s.Limit(ctx, "ns:tool", strings.Repeat("c", 30), 0)
+	// The oldest result makes room for the newest.
+	_, err := s.Get(ctx, first.ResultID)
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = s.Get(ctx, second.ResultID)
+	require.NoError(t, err)
+
+	// Results larger than the quota are not kept.
+	_, huge := s.Limit(ctx, "ns:tool", strings.Repeat("d", 200), 0)
+	require.Empty(t, huge.ResultID)
+	require.Equal(t, 202, huge.TotalBytes)
+
+	now = now.Add(2 * time.Minute)
+	_, err = s.Get(ctx, third.ResultID)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestSelect(t *testing.T) {
+	value, err := Decode([]byte(`{"items":[{"id":1},{"id":2},{"id":3}],"odd key":{"a":true}}`))
+	require.NoError(t, err)
+
+	tests := []struct {
+		path string
+		want any
+		norm string
+	}{
+		{"", value, "$"},
+		{"$.items[1].id", json.Number("2"), "$.items[1].id"},
+		{"items[-1]", map[string]any{"id": json.Number("3")}, "$.items[2]"},
+		{"$.items[1:]", []any{map[string]any{"id": json.Number("2")}, map[string]any{"id": json.Number("3")}}, "$.items[1:3]"},
+		{`$["odd key"].a`, true, `$["odd key"].a`},
+	}
+	for _, tt := range tests {
+		got, norm, err := Select(value, tt.path)
+		require.NoError(t, err, tt.path)
+		require.Equal(t, tt.want, got, tt.path)
+		require.Equal(t, tt.norm, norm, tt.path)
+	}
+
+	for _, path := range []string{"$.missing", "$.items.id", "$.items[9]", "$..items"} {
+		_, _, err := Select(value, path)
+		require.Error(t, err, path)
+	}
+}
@@ -0,0 +1,202 @@
+package roots
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	merrors "github.com/jonwraymond/metatools-mcp/internal/errors"
+	"github.com/jonwraymond/metatools-mcp/internal/runcheck"
+	"github.com/jonwraymond/toolexec/run"
+	"github.com/jonwraymond/toolfoundation/model"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DefaultTags are the tags of the tools a Guard checks when its Policy
+// names none.
+var DefaultTags = []string{"fs"}
+
+// Policy selects the tools whose arguments must stay within the roots.
+type Policy struct {
+	// Tags selects tools carrying any of these tags.
+	Tags []string
+}
+
+// Applies reports whether tool is checked.
+func (p Policy) Applies(tool model.Tool) bool {
+	for _, tag := range tool.Tags {
+		if slices.Contains(p.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// ToolLookup resolves tool IDs to tool definitions.
+type ToolLookup interface {
+	GetTool(ctx context.Context, id string) (model.Tool, error)
+}
+
+// GuardOptions configures a Guard.
+type GuardOptions struct {
+	Policy Policy
+	// Tools resolves the tools being called. Tools it cannot find are not
+	// checked; the runner reports them as not found.
+	Tools ToolLookup
+}
+
+// Guard rejects calls of selected tools whose arguments name paths outside
+// the calling client's roots.
+type Guard struct {
+	policy Policy
+	tools  ToolLookup
+}
+
+// NewGuard creates a roots guard.
+func NewGuard(opts GuardOptions) *Guard {
+	if len(opts.Policy.Tags) == 0 {
+		opts.Policy.Tags = DefaultTags
+	}
+	return &Guard{policy: opts.Policy, tools: opts.Tools}
+}
+
+// Check returns nil when the call of toolID with args may run. Calls of
+// selected tools fail with ErrPathOutsideRoots when a string argument, at
+// any depth, is a path outside every file root of the caller. Absolute
+// paths, file:// URIs, ~ paths and relative paths leading out of their
+// directory count as paths; paths are compared lexically, without resolving
+// symlinks. Calls whose roots are unknown, because the client does not
+// support roots or they could not be fetched, allow no paths.
+func (g *Guard) Check(ctx context.Context, toolID string, args map[string]any) error {
+	if g.tools == nil {
+		return nil
+	}
+	tool, err := g.tools.GetTool(ctx, toolID)
+	if err != nil || !g.policy.Applies(tool) {
+		return nil
+	}
+	roots, known := FromContext(ctx)
+	dirs := rootDirs(roots)
+	return walk(args, "args", func(at, value string) error {
+		path, ok := argPath(value)
+		if !ok || within(path, dirs) {
+			return nil
+		}
+		if !known {
+			return fmt.Errorf("%w: %s: %s %q: the client's roots are unknown", merrors.ErrPathOutsideRoots, toolID, at, value)
+		}
+		if len(dirs) == 0 {
+			return fmt.Errorf("%w: %s: %s %q: the client shared no file roots", merrors.ErrPathOutsideRoots, toolID, at, value)
+		}
+		return fmt.Errorf("%w: %s: %s %q is not within %s", merrors.ErrPathOutsideRoots, toolID, at, value, strings.Join(dirs, ", "))
+	})
+}
+
+// WrapRunner returns a runner that checks every tool call with guard before
+// delegating to base, see runcheck.Wrap.
+func WrapRunner(base run.Runner, guard *Guard) run.Runner {
+	return runcheck.Wrap(base, guard.Check)
+}
+
+// walk calls fn for every string in value, naming each by its path from at.
+func walk(value any, at string, fn func(at, value string) error) error {
+	switch v := value.(type) {
+	case string:
+		return fn(at, v)
+	case []any:
+		for i, item := range v {
+			if err := walk(item, at+"["+strconv.Itoa(i)+"]", fn); err != nil {
+				return err
+			}
+		}
+	case []string:
+		for i, item := range v {
+			if err := fn(at+"["+strconv.Itoa(i)+"]", item); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := walk(v[k], at+"."+k, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// argPath returns the path an argument names, or false when it does not
+// look like a path that could leave the roots.
+func argPath(value string) (string, bool) {
+	s := strings.TrimSpace(value)
+	if s == "" || strings.ContainsAny(s, "\n\r") {
+		return "", false
+	}
+	switch {
+	case strings.HasPrefix(s, "file://"):
+		return uriPath(s), true
+	case s == "~" || strings.HasPrefix(s, "~/"):
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return s, true
+		}
+		return filepath.Join(home, s[1:]), true
+	case filepath.IsAbs(s):
+		return filepath.Clean(s), true
+	case strings.Contains(s, "://"):
+		return "", false
+	}
+	clean := filepath.Clean(s)
+	if clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return clean, true
+	}
+	return "", false
+}
+
+// rootDirs returns the directories of the file roots.
+func rootDirs(roots []*mcp.Root) []string {
+	dirs := make([]string, 0, len(roots))
+	for _, r := range roots {
+		if r == nil || !strings.HasPrefix(r.URI, "file://") {
+			continue
+		}
+		if dir := uriPath(r.URI); dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// uriPath returns the local path of a file:// URI, or "" when it has none.
+func uriPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Path == "" {
+		return ""
+	}
+	return filepath.Clean(filepath.FromSlash(u.Path))
+}
+
+// within reports whether path is one of dirs or lies below one of them.
+func within(path string, dirs []string) bool {
+	if path == "" || !filepath.IsAbs(path) {
+		return false
+	}
+	for _, dir := range dirs {
+		rel, err := filepath.Rel(dir, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
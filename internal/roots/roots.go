@@ -0,0 +1,125 @@
+// Package roots tracks the filesystem roots MCP clients share with the
+// server. A Tracker fetches each session's roots/list, tool calls carry the
+// caller's roots on their context, and a Guard rejects calls whose arguments
+// name paths outside those roots.
+package roots
+
+import (
+	"context"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type rootsKey struct{}
+
+type contextRoots struct {
+	roots []*mcp.Root
+}
+
+// WithRoots returns a context carrying the roots the calling client shared.
+// An empty list records a client that shared no roots.
+func WithRoots(ctx context.Context, roots []*mcp.Root) context.Context {
+	return context.WithValue(ctx, rootsKey{}, contextRoots{roots: roots})
+}
+
+// FromContext returns the roots set by WithRoots. ok is false when the
+// caller's roots are unknown, e.g. because its client does not support
+// roots.
+func FromContext(ctx context.Context) (roots []*mcp.Root, ok bool) {
+	v, ok := ctx.Value(rootsKey{}).(contextRoots)
+	return v.roots, ok
+}
+
+// Session is the client session asked for its roots.
+type Session interface {
+	ListRoots(ctx context.Context, params *mcp.ListRootsParams) (*mcp.ListRootsResult, error)
+}
+
+type state struct {
+	roots   []*mcp.Root
+	ok      bool
+	fetched bool
+	// gen orders fetches so that a slow stale answer does not overwrite a
+	// newer one.
+	gen uint64
+}
+
+// Tracker keeps the latest roots of each client session. It is safe for
+// concurrent use.
+type Tracker struct {
+	mu       sync.Mutex
+	gen      uint64
+	sessions map[Session]*state
+}
+
+// NewTracker creates an empty Tracker.
+func NewTracker() *Tracker {
+	return &Tracker{sessions: make(map[Session]*state)}
+}
+
+// Track starts recording the roots of session. Roots of untracked sessions
+// are fetched but not kept, so a fetch that ends after Forget leaves nothing
+// behind.
+func (t *Tracker) Track(session Session) {
+	t.mu.Lock()
+	if _, found := t.sessions[session]; !found {
+		t.sessions[session] = &state{}
+	}
+	t.mu.Unlock()
+}
+
+// Refresh fetches the roots of session and records them. Clients that fail
+// to answer are recorded as having unknown roots. Callers only pass sessions
+// whose client declared the roots capability, as others must not be sent
+// roots/list.
+func (t *Tracker) Refresh(ctx context.Context, session Session) ([]*mcp.Root, bool) {
+	t.mu.Lock()
+	t.gen++
+	gen := t.gen
+	t.mu.Unlock()
+
+	var roots []*mcp.Root
+	res, err := session.ListRoots(ctx, &mcp.ListRootsParams{})
+	ok := err == nil && res != nil
+	if ok {
+		roots = make([]*mcp.Root, 0, len(res.Roots))
+		for _, r := range res.Roots {
+			if r != nil {
+				roots = append(roots, r)
+			}
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	cur, found := t.sessions[session]
+	if !found {
+		return roots, ok
+	}
+	if cur.fetched && cur.gen > gen {
+		return cur.roots, cur.ok
+	}
+	t.sessions[session] = &state{roots: roots, ok: ok, fetched: true, gen: gen}
+	return roots, ok
+}
+
+// Roots returns the recorded roots of session, fetching them first when the
+// session has not been asked yet. Like Refresh, it is only called for
+// clients that declared the roots capability.
+func (t *Tracker) Roots(ctx context.Context, session Session) ([]*mcp.Root, bool) {
+	t.mu.Lock()
+	cur, found := t.sessions[session]
+	t.mu.Unlock()
+	if found && cur.fetched {
+		return cur.roots, cur.ok
+	}
+	return t.Refresh(ctx, session)
+}
+
+// Forget drops the roots recorded for session.
+func (t *Tracker) Forget(session Session) {
+	t.mu.Lock()
+	delete(t.sessions, session)
+	t.mu.Unlock()
+}
@@ -0,0 +1,125 @@
+package roots
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	merrors "github.com/jonwraymond/metatools-mcp/internal/errors"
+	"github.com/jonwraymond/toolfoundation/model"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSession struct {
+	roots []*mcp.Root
+	err   error
+	calls int
+}
+
+func (s *fakeSession) ListRoots(context.Context, *mcp.ListRootsParams) (*mcp.ListRootsResult, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &mcp.ListRootsResult{Roots: s.roots}, nil
+}
+
+type fakeTools map[string]model.Tool
+
+func (f fakeTools) GetTool(_ context.Context, id string) (model.Tool, error) {
+	tool, ok := f[id]
+	if !ok {
+		return model.Tool{}, errors.New("not found")
+	}
+	return tool, nil
+}
+
+func TestFromContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	require.False(t, ok)
+
+	roots, ok := FromContext(WithRoots(context.Background(), nil))
+	require.True(t, ok)
+	require.Empty(t, roots)
+}
+
+func TestTracker(t *testing.T) {
+	tracker := NewTracker()
+	session := &fakeSession{roots: []*mcp.Root{{URI: "file:///work"}}}
+	tracker.Track(session)
+
+	roots, ok := tracker.Roots(context.Background(), session)
+	require.True(t, ok)
+	require.Equal(t, "file:///work", roots[0].URI)
+	// Recorded roots are reused until the next refresh.
+	_, _ = tracker.Roots(context.Background(), session)
+	require.Equal(t, 1, session.calls)
+
+	session.roots = []*mcp.Root{{URI: "file:///other"}}
+	roots, _ = tracker.Refresh(context.Background(), session)
+	require.Equal(t, "file:///other", roots[0].URI)
+
+	// Forgotten sessions are no longer recorded.
+	tracker.Forget(session)
+	_, _ = tracker.Roots(context.Background(), session)
+	_, _ = tracker.Roots(context.Background(), session)
+	require.Equal(t, 4, session.calls)
+
+	unsupported := &fakeSession{err: errors.New("method not found")}
+	tracker.Track(unsupported)
+	_, ok = tracker.Roots(context.Background(), unsupported)
+	require.False(t, ok)
+}
+
+func TestGuard_Check(t *testing.T) {
+	guard := NewGuard(GuardOptions{Tools: fakeTools{
+		"fs:read":  {Tool: mcp.Tool{Name: "read"}, Namespace: "fs", Tags: []string{"fs"}},
+		"web:open": {Tool: mcp.Tool{Name: "open"}, Namespace: "web"},
+	}})
+	ctx := WithRoots(context.Background(), []*mcp.Root{{URI: "file:///work/repo"}, {URI: "https://example.com"}})
+
+	allowed := []map[string]any{
+		{"path": "/work/repo"},
+		{"path": "/work/repo/src/main.go"},
+		{"path": "file:///work/repo/README.md"},
+		{"path": "src/main.go"},
+		{"url": "https://example.com/a"},
+		{"content": "/* not a path */\nfunc main() {}"},
+		{"opts": map[string]any{"paths": []any{"/work/repo/a", "b"}}},
+	}
+	for _, args := range allowed {
+		require.NoError(t, guard.Check(ctx, "fs:read", args), args)
+	}
+
+	denied := []map[string]any{
+		{"path": "/etc/passwd"},
+		{"path": "/work/repo-other/file"},
+		{"path": "/work/repo/../secret"},
+		{"path": "file:///etc/passwd"},
+		{"path": "../../etc/passwd"},
+		{"path": "~/.ssh/id_rsa"},
+		{"opts": map[string]any{"paths": []any{"/work/repo/a", "/tmp/b"}}},
+	}
+	for _, args := range denied {
+		err := guard.Check(ctx, "fs:read", args)
+		require.ErrorIs(t, err, merrors.ErrPathOutsideRoots, args)
+	}
+	err := guard.Check(ctx, "fs:read", map[string]any{"opts": map[string]any{"paths": []any{"/tmp/b"}}})
+	require.ErrorContains(t, err, `args.opts.paths[0] "/tmp/b" is not within /work/repo`)
+
+	// Untagged tools and unknown tools are not checked.
+	require.NoError(t, guard.Check(ctx, "web:open", map[string]any{"path": "/etc/passwd"}))
+	require.NoError(t, guard.Check(ctx, "fs:missing", map[string]any{"path": "/etc/passwd"}))
+
+	// Callers whose roots are unknown are allowed no paths.
+	err = guard.Check(context.Background(), "fs:read", map[string]any{"path": "/etc/passwd"})
+	require.ErrorIs(t, err, merrors.ErrPathOutsideRoots)
+	require.ErrorContains(t, err, "the client's roots are unknown")
+	require.NoError(t, guard.Check(context.Background(), "fs:read", map[string]any{"path": "src"}))
+
+	// A client that shared no roots allows no paths.
+	err = guard.Check(WithRoots(context.Background(), nil), "fs:read", map[string]any{"path": "/work/repo"})
+	require.ErrorIs(t, err, merrors.ErrPathOutsideRoots)
+	require.NoError(t, guard.Check(WithRoots(context.Background(), nil), "fs:read", map[string]any{"path": "src"}))
+}
@@ -0,0 +1,77 @@
+// Package runcheck wraps a run.Runner with a check that every tool call must
+// pass before it runs, e.g. an approval gate or a policy guard.
+package runcheck
+
+import (
+	"context"
+
+	"github.com/jonwraymond/toolexec/run"
+)
+
+// Func returns nil when the call of toolID with args may run.
+type Func func(ctx context.Context, toolID string, args map[string]any) error
+
+// Wrap returns a runner that calls check before delegating to base. Chains
+// are checked step by step before any step runs. The result implements
+// run.ProgressRunner when base does.
+func Wrap(base run.Runner, check Func) run.Runner {
+	r := &runner{base: base, check: check}
+	if pr, ok := base.(run.ProgressRunner); ok {
+		return &progressRunner{runner: r, progress: pr}
+	}
+	return r
+}
+
+type runner struct {
+	base  run.Runner
+	check Func
+}
+
+func (r *runner) Run(ctx context.Context, toolID string, args map[string]any) (run.RunResult, error) {
+	if err := r.check(ctx, toolID, args); err != nil {
+		return run.RunResult{}, err
+	}
+	return r.base.Run(ctx, toolID, args)
+}
+
+func (r *runner) RunStream(ctx context.Context, toolID string, args map[string]any) (<-chan run.StreamEvent, error) {
+	if err := r.check(ctx, toolID, args); err != nil {
+		return nil, err
+	}
+	return r.base.RunStream(ctx, toolID, args)
+}
+
+func (r *runner) RunChain(ctx context.Context, steps []run.ChainStep) (run.RunResult, []run.StepResult, error) {
+	if err := r.checkChain(ctx, steps); err != nil {
+		return run.RunResult{}, nil, err
+	}
+	return r.base.RunChain(ctx, steps)
+}
+
+func (r *runner) checkChain(ctx context.Context, steps []run.ChainStep) error {
+	for _, step := range steps {
+		if err := r.check(ctx, step.ToolID, step.Args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type progressRunner struct {
+	*runner
+	progress run.ProgressRunner
+}
+
+func (r *progressRunner) RunWithProgress(ctx context.Context, toolID string, args map[string]any, onProgress run.ProgressCallback) (run.RunResult, error) {
+	if err := r.check(ctx, toolID, args); err != nil {
+		return run.RunResult{}, err
+	}
+	return r.progress.RunWithProgress(ctx, toolID, args, onProgress)
+}
+
+func (r *progressRunner) RunChainWithProgress(ctx context.Context, steps []run.ChainStep, onProgress run.ProgressCallback) (run.RunResult, []run.StepResult, error) {
+	if err := r.checkChain(ctx, steps); err != nil {
+		return run.RunResult{}, nil, err
+	}
+	return r.progress.RunChainWithProgress(ctx, steps, onProgress)
+}
@@ -0,0 +1,89 @@
+package runcheck
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jonwraymond/toolexec/run"
+	"github.com/stretchr/testify/require"
+)
+
+type stubRunner struct {
+	calls []string
+}
+
+func (r *stubRunner) Run(_ context.Context, toolID string, _ map[string]any) (run.RunResult, error) {
+	r.calls = append(r.calls, toolID)
+	return run.RunResult{}, nil
+}
+
+func (r *stubRunner) RunStream(context.Context, string, map[string]any) (<-chan run.StreamEvent, error) {
+	return nil, run.ErrStreamNotSupported
+}
+
+func (r *stubRunner) RunChain(ctx context.Context, steps []run.ChainStep) (run.RunResult, []run.StepResult, error) {
+	for _, step := range steps {
+		_, _ = r.Run(ctx, step.ToolID, step.Args)
+	}
+	return run.RunResult{}, nil, nil
+}
+
+type stubProgressRunner struct {
+	stubRunner
+}
+
+func (r *stubProgressRunner) RunWithProgress(ctx context.Context, toolID string, args map[string]any, _ run.ProgressCallback) (run.RunResult, error) {
+	return r.Run(ctx, toolID, args)
+}
+
+func (r *stubProgressRunner) RunChainWithProgress(ctx context.Context, steps []run.ChainStep, _ run.ProgressCallback) (run.RunResult, []run.StepResult, error) {
+	return r.RunChain(ctx, steps)
+}
+
+var errDenied = errors.New("denied")
+
+func denyTool(denied string) Func {
+	return func(_ context.Context, toolID string, _ map[string]any) error {
+		if toolID == denied {
+			return errDenied
+		}
+		return nil
+	}
+}
+
+func TestWrap(t *testing.T) {
+	base := &stubRunner{}
+	runner := Wrap(base, denyTool("ops:delete"))
+	_, isProgress := runner.(run.ProgressRunner)
+	require.False(t, isProgress)
+	ctx := context.Background()
+
+	_, err := runner.Run(ctx, "ops:read", nil)
+	require.NoError(t, err)
+	_, err = runner.Run(ctx, "ops:delete", nil)
+	require.ErrorIs(t, err, errDenied)
+	_, err = runner.RunStream(ctx, "ops:delete", nil)
+	require.ErrorIs(t, err, errDenied)
+
+	// A failing step stops the chain before any step runs.
+	_, _, err = runner.RunChain(ctx, []run.ChainStep{{ToolID: "ops:read"}, {ToolID: "ops:delete"}})
+	require.ErrorIs(t, err, errDenied)
+	require.Equal(t, []string{"ops:read"}, base.calls)
+}
+
+func TestWrap_Progress(t *testing.T) {
+	base := &stubProgressRunner{}
+	runner := Wrap(base, denyTool("ops:delete"))
+	pr, ok := runner.(run.ProgressRunner)
+	require.True(t, ok)
+	ctx := context.Background()
+
+	_, err := pr.RunWithProgress(ctx, "ops:delete", nil, nil)
+	require.ErrorIs(t, err, errDenied)
+	_, _, err = pr.RunChainWithProgress(ctx, []run.ChainStep{{ToolID: "ops:read"}, {ToolID: "ops:delete"}}, nil)
+	require.ErrorIs(t, err, errDenied)
+	_, err = pr.RunWithProgress(ctx, "ops:read", nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"ops:read"}, base.calls)
+}
@@ -0,0 +1,113 @@
+package sampling
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/jonwraymond/toolexec/run"
+)
+
+// WrapRunner returns a runner that serves llm:sample calls with sampler and
+// delegates every other call to base. Chains without llm:sample steps are
+// delegated whole. The result implements run.ProgressRunner when base does.
+func WrapRunner(base run.Runner, sampler *Sampler) run.Runner {
+	r := &samplingRunner{base: base, sampler: sampler}
+	if pr, ok := base.(run.ProgressRunner); ok {
+		return &samplingProgressRunner{samplingRunner: r, progress: pr}
+	}
+	return r
+}
+
+type samplingRunner struct {
+	base    run.Runner
+	sampler *Sampler
+}
+
+func (r *samplingRunner) Run(ctx context.Context, toolID string, args map[string]any) (run.RunResult, error) {
+	if toolID != ToolID {
+		return r.base.Run(ctx, toolID, args)
+	}
+	return r.sample(ctx, args)
+}
+
+func (r *samplingRunner) RunStream(ctx context.Context, toolID string, args map[string]any) (<-chan run.StreamEvent, error) {
+	if toolID != ToolID {
+		return r.base.RunStream(ctx, toolID, args)
+	}
+	return nil, run.WrapError(toolID, nil, "stream", run.ErrStreamNotSupported)
+}
+
+func (r *samplingRunner) RunChain(ctx context.Context, steps []run.ChainStep) (run.RunResult, []run.StepResult, error) {
+	if !hasSampleStep(steps) {
+		return r.base.RunChain(ctx, steps)
+	}
+	return r.runChain(ctx, steps, nil)
+}
+
+func (r *samplingRunner) sample(ctx context.Context, args map[string]any) (run.RunResult, error) {
+	out, err := r.sampler.Sample(ctx, args)
+	if err != nil {
+		return run.RunResult{}, run.WrapError(ToolID, nil, "sample", err)
+	}
+	return run.RunResult{Tool: Tool(), Structured: out}, nil
+}
+
+// runChain runs steps one at a time with the same previous-result and
+// stop-on-error semantics as run.Runner.RunChain.
+func (r *samplingRunner) runChain(ctx context.Context, steps []run.ChainStep, onProgress run.ProgressCallback) (run.RunResult, []run.StepResult, error) {
+	total := float64(len(steps))
+	report := func(progress float64, msg string) {
+		if onProgress != nil {
+			onProgress(run.ProgressEvent{Progress: progress, Total: total, Message: msg})
+		}
+	}
+	report(0, "started")
+
+	var results []run.StepResult
+	var last run.RunResult
+	for i, step := range steps {
+		if err := ctx.Err(); err != nil {
+			return run.RunResult{}, results, err
+		}
+		args := maps.Clone(step.Args)
+		if step.UsePrevious {
+			if args == nil {
+				args = map[string]any{}
+			}
+			args["previous"] = last.Structured
+		}
+		res, err := r.Run(ctx, step.ToolID, args)
+		results = append(results, run.StepResult{ToolID: step.ToolID, Backend: res.Backend, Result: res, Err: err})
+		if err != nil {
+			return run.RunResult{}, results, err
+		}
+		last = res
+		report(float64(i+1), fmt.Sprintf("step %d/%d", i+1, len(steps)))
+	}
+	return last, results, nil
+}
+
+type samplingProgressRunner struct {
+	*samplingRunner
+	progress run.ProgressRunner
+}
+
+func (r *samplingProgressRunner) RunWithProgress(ctx context.Context, toolID string, args map[string]any, onProgress run.ProgressCallback) (run.RunResult, error) {
+	if toolID != ToolID {
+		return r.progress.RunWithProgress(ctx, toolID, args, onProgress)
+	}
+	return r.sample(ctx, args)
+}
+
+func (r *samplingProgressRunner) RunChainWithProgress(ctx context.Context, steps []run.ChainStep, onProgress run.ProgressCallback) (run.RunResult, []run.StepResult, error) {
+	if !hasSampleStep(steps) {
+		return r.progress.RunChainWithProgress(ctx, steps, onProgress)
+	}
+	return r.runChain(ctx, steps, onProgress)
+}
+
+func hasSampleStep(steps []run.ChainStep) bool {
+	return slices.ContainsFunc(steps, func(s run.ChainStep) bool { return s.ToolID == ToolID })
+}
@@ -0,0 +1,240 @@
+// Package sampling provides the llm:sample pseudo-tool. It forwards a prompt
+// to the connected client's model through MCP sampling, so chains, skills and
+// code mode can summarise or classify without a round trip to the agent.
+package sampling
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	merrors "github.com/jonwraymond/metatools-mcp/internal/errors"
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolfoundation/model"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ToolID is the ID of the sampling pseudo-tool.
+const ToolID = "llm:sample"
+
+// Defaults applied to zero Options fields.
+const (
+	DefaultMaxTokens       = 1024
+	DefaultMaxCallsPerRun  = 10
+	DefaultMaxTokensPerRun = 8192
+)
+
+// Options configures a Sampler.
+type Options struct {
+	// MaxTokens is the completion size requested when a call sets none, and
+	// the most a call may request.
+	MaxTokens int
+	// MaxCallsPerRun bounds the sampling calls of one metatool call.
+	MaxCallsPerRun int
+	// MaxTokensPerRun bounds the completion tokens requested by one metatool
+	// call; later calls get what is left.
+	MaxTokensPerRun int
+}
+
+// Session is the client session that samples.
+type Session interface {
+	CreateMessage(ctx context.Context, params *mcp.CreateMessageParams) (*mcp.CreateMessageResult, error)
+}
+
+// sampleRun tracks one metatool call's sampling session and budget use.
+type sampleRun struct {
+	session Session
+
+	mu     sync.Mutex
+	calls  int
+	tokens int
+}
+
+type runKey struct{}
+
+// WithSession returns a context whose llm:sample calls go to session. Each
+// call starts a new run with a fresh budget.
+func WithSession(ctx context.Context, session Session) context.Context {
+	return context.WithValue(ctx, runKey{}, &sampleRun{session: session})
+}
+
+// Tool returns the llm:sample tool definition.
+func Tool() model.Tool {
+	return model.Tool{
+		Namespace: "llm",
+		Tags:      []string{"llm", "sampling"},
+		Tool: mcp.Tool{
+			Name:  "sample",
+			Title: "Sample the client's model",
+			Description: "Send a prompt to the connected client's model via MCP sampling and return its completion. " +
+				"Build the prompt from earlier steps with ${steps...} references. Fails when the client does not support sampling.",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"prompt":      map[string]any{"type": "string", "description": "User message sent to the model"},
+					"system":      map[string]any{"type": "string", "description": "Optional system prompt"},
+					"max_tokens":  map[string]any{"type": "integer", "minimum": 1, "description": "Completion size; capped by the server"},
+					"temperature": map[string]any{"type": "number", "minimum": 0},
+					"model_hint":  map[string]any{"type": "string", "description": "Preferred model name or family"},
+					"previous":    map[string]any{"description": "Previous step result (use_previous); appended to the prompt as JSON"},
+				},
+				"required":             []any{"prompt"},
+				"additionalProperties": false,
+			},
+			OutputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"text":        map[string]any{"type": "string"},
+					"model":       map[string]any{"type": "string"},
+					"stop_reason": map[string]any{"type": "string"},
+				},
+				"required": []any{"text"},
+			},
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+	}
+}
+
+// Register adds the llm:sample tool to idx so it can be searched, described
+// and used in toolsets and skills. Calls are served by a runner wrapped with
+// WrapRunner.
+func Register(idx index.Index) error {
+	tool := Tool()
+	if err := idx.RegisterTool(tool, model.ToolBackend{
+		Kind:  model.BackendKindLocal,
+		Local: &model.LocalBackend{Name: "llm.sample"},
+	}); err != nil {
+		return fmt.Errorf("register tool %q: %w", ToolID, err)
+	}
+	return nil
+}
+
+// Sampler serves llm:sample calls.
+type Sampler struct {
+	opts Options
+}
+
+// NewSampler creates a sampler.
+func NewSampler(opts Options) *Sampler {
+	if opts.MaxTokens <= 0 {
+		opts.MaxTokens = DefaultMaxTokens
+	}
+	if opts.MaxCallsPerRun <= 0 {
+		opts.MaxCallsPerRun = DefaultMaxCallsPerRun
+	}
+	if opts.MaxTokensPerRun <= 0 {
+		opts.MaxTokensPerRun = DefaultMaxTokensPerRun
+	}
+	return &Sampler{opts: opts}
+}
+
+type input struct {
+	Prompt      string   `json:"prompt"`
+	System      string   `json:"system"`
+	MaxTokens   int      `json:"max_tokens"`
+	Temperature *float64 `json:"temperature"`
+	ModelHint   string   `json:"model_hint"`
+	Previous    any      `json:"previous"`
+}
+
+// Sample runs one llm:sample call. It fails with ErrSamplingUnavailable when
+// the calling client does not support sampling and with ErrSamplingBudget
+// once the run's budget is spent.
+func (s *Sampler) Sample(ctx context.Context, args map[string]any) (map[string]any, error) {
+	in, err := decodeInput(args)
+	if err != nil {
+		return nil, err
+	}
+	r, _ := ctx.Value(runKey{}).(*sampleRun)
+	if r == nil {
+		return nil, fmt.Errorf("%w: the client does not support sampling", merrors.ErrSamplingUnavailable)
+	}
+	maxTokens, err := r.reserve(s.opts, in.MaxTokens)
+	if err != nil {
+		return nil, err
+	}
+
+	prompt := in.Prompt
+	if in.Previous != nil {
+		data, err := json.Marshal(in.Previous)
+		if err != nil {
+			return nil, fmt.Errorf("%w: previous: %v", merrors.ErrValidationInput, err)
+		}
+		prompt += "\n\n" + string(data)
+	}
+	params := &mcp.CreateMessageParams{
+		Messages:     []*mcp.SamplingMessage{{Role: "user", Content: &mcp.TextContent{Text: prompt}}},
+		SystemPrompt: in.System,
+		MaxTokens:    int64(maxTokens),
+	}
+	if in.Temperature != nil {
+		params.Temperature = *in.Temperature
+	}
+	if in.ModelHint != "" {
+		params.ModelPreferences = &mcp.ModelPreferences{Hints: []*mcp.ModelHint{{Name: in.ModelHint}}}
+	}
+
+	res, err := r.session.CreateMessage(ctx, params)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%w: %v", merrors.ErrSamplingUnavailable, err)
+	}
+	text, ok := res.Content.(*mcp.TextContent)
+	if !ok {
+		return nil, fmt.Errorf("%w: sampling returned %T content, want text", merrors.ErrExecution, res.Content)
+	}
+	out := map[string]any{"text": text.Text}
+	if res.Model != "" {
+		out["model"] = res.Model
+	}
+	if res.StopReason != "" {
+		out["stop_reason"] = res.StopReason
+	}
+	return out, nil
+}
+
+// reserve takes one call and up to requested tokens from the run's budget
+// and returns the tokens granted.
+func (r *sampleRun) reserve(opts Options, requested int) (int, error) {
+	tokens := opts.MaxTokens
+	if requested > 0 && requested < tokens {
+		tokens = requested
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.calls >= opts.MaxCallsPerRun {
+		return 0, fmt.Errorf("%w: %d calls per run", merrors.ErrSamplingBudget, opts.MaxCallsPerRun)
+	}
+	left := opts.MaxTokensPerRun - r.tokens
+	if left <= 0 {
+		return 0, fmt.Errorf("%w: %d tokens per run", merrors.ErrSamplingBudget, opts.MaxTokensPerRun)
+	}
+	tokens = min(tokens, left)
+	r.calls++
+	r.tokens += tokens
+	return tokens, nil
+}
+
+func decodeInput(args map[string]any) (input, error) {
+	var in input
+	data, err := json.Marshal(args)
+	if err != nil {
+		return in, fmt.Errorf("%w: %v", merrors.ErrValidationInput, err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		return in, fmt.Errorf("%w: %v", merrors.ErrValidationInput, err)
+	}
+	if in.Prompt == "" {
+		return in, fmt.Errorf("%w: prompt is required", merrors.ErrValidationInput)
+	}
+	if in.MaxTokens < 0 {
+		return in, fmt.Errorf("%w: max_tokens cannot be negative", merrors.ErrValidationInput)
+	}
+	return in, nil
+}
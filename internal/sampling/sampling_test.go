@@ -0,0 +1,155 @@
+package sampling
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	merrors "github.com/jonwraymond/metatools-mcp/internal/errors"
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolexec/run"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSession struct {
+	requests []*mcp.CreateMessageParams
+	err      error
+}
+
+func (s *fakeSession) CreateMessage(_ context.Context, params *mcp.CreateMessageParams) (*mcp.CreateMessageResult, error) {
+	s.requests = append(s.requests, params)
+	if s.err != nil {
+		return nil, s.err
+	}
+	text := params.Messages[0].Content.(*mcp.TextContent).Text
+	return &mcp.CreateMessageResult{
+		Content:    &mcp.TextContent{Text: "echo: " + text},
+		Model:      "test-model",
+		Role:       "assistant",
+		StopReason: "endTurn",
+	}, nil
+}
+
+func TestSampler_Sample(t *testing.T) {
+	session := &fakeSession{}
+	ctx := WithSession(context.Background(), session)
+	sampler := NewSampler(Options{})
+	temperature := 0.2
+
+	out, err := sampler.Sample(ctx, map[string]any{
+		"prompt":      "Summarise:",
+		"system":      "Be brief.",
+		"temperature": temperature,
+		"model_hint":  "small",
+		"previous":    map[string]any{"title": "bug"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"text":        `echo: Summarise:` + "\n\n" + `{"title":"bug"}`,
+		"model":       "test-model",
+		"stop_reason": "endTurn",
+	}, out)
+
+	require.Len(t, session.requests, 1)
+	req := session.requests[0]
+	require.Equal(t, "Be brief.", req.SystemPrompt)
+	require.Equal(t, int64(DefaultMaxTokens), req.MaxTokens)
+	require.Equal(t, temperature, req.Temperature)
+	require.Equal(t, "small", req.ModelPreferences.Hints[0].Name)
+}
+
+func TestSampler_Errors(t *testing.T) {
+	sampler := NewSampler(Options{})
+
+	_, err := sampler.Sample(context.Background(), map[string]any{"prompt": "hi"})
+	require.ErrorIs(t, err, merrors.ErrSamplingUnavailable)
+
+	ctx := WithSession(context.Background(), &fakeSession{err: errors.New("method not found")})
+	_, err = sampler.Sample(ctx, map[string]any{"prompt": "hi"})
+	require.ErrorIs(t, err, merrors.ErrSamplingUnavailable)
+
+	_, err = sampler.Sample(ctx, map[string]any{})
+	require.ErrorIs(t, err, merrors.ErrValidationInput)
+	_, err = sampler.Sample(ctx, map[string]any{"prompt": "hi", "unknown": 1})
+	require.ErrorIs(t, err, merrors.ErrValidationInput)
+}
+
+func TestSampler_Budget(t *testing.T) {
+	sampler := NewSampler(Options{MaxTokens: 100, MaxCallsPerRun: 3, MaxTokensPerRun: 150})
+	session := &fakeSession{}
+	ctx := WithSession(context.Background(), session)
+
+	_, err := sampler.Sample(ctx, map[string]any{"prompt": "a", "max_tokens": 500})
+	require.NoError(t, err)
+	_, err = sampler.Sample(ctx, map[string]any{"prompt": "b"})
+	require.NoError(t, err)
+	_, err = sampler.Sample(ctx, map[string]any{"prompt": "c"})
+	require.ErrorIs(t, err, merrors.ErrSamplingBudget)
+
+	// Requests are capped by the per-call limit, then by what the run has left.
+	require.Equal(t, int64(100), session.requests[0].MaxTokens)
+	require.Equal(t, int64(50), session.requests[1].MaxTokens)
+
+	// A new run starts with a fresh budget.
+	_, err = sampler.Sample(WithSession(context.Background(), session), map[string]any{"prompt": "d", "max_tokens": 10})
+	require.NoError(t, err)
+	require.Equal(t, int64(10), session.requests[2].MaxTokens)
+}
+
+type stubRunner struct {
+	chains int
+}
+
+func (r *stubRunner) Run(_ context.Context, toolID string, args map[string]any) (run.RunResult, error) {
+	return run.RunResult{Structured: map[string]any{"tool": toolID, "args": args}}, nil
+}
+
+func (r *stubRunner) RunStream(context.Context, string, map[string]any) (<-chan run.StreamEvent, error) {
+	return nil, run.ErrStreamNotSupported
+}
+
+func (r *stubRunner) RunChain(context.Context, []run.ChainStep) (run.RunResult, []run.StepResult, error) {
+	r.chains++
+	return run.RunResult{}, nil, nil
+}
+
+func TestWrapRunner(t *testing.T) {
+	base := &stubRunner{}
+	runner := WrapRunner(base, NewSampler(Options{}))
+	session := &fakeSession{}
+	ctx := WithSession(context.Background(), session)
+
+	res, err := runner.Run(ctx, "local:ping", nil)
+	require.NoError(t, err)
+	require.Equal(t, "local:ping", res.Structured.(map[string]any)["tool"])
+
+	res, err = runner.Run(ctx, ToolID, map[string]any{"prompt": "hi"})
+	require.NoError(t, err)
+	require.Equal(t, "echo: hi", res.Structured.(map[string]any)["text"])
+
+	_, err = runner.Run(context.Background(), ToolID, map[string]any{"prompt": "hi"})
+	require.ErrorIs(t, err, merrors.ErrSamplingUnavailable)
+
+	// Chains without llm:sample steps go to the base runner.
+	_, _, err = runner.RunChain(ctx, []run.ChainStep{{ToolID: "local:ping"}})
+	require.NoError(t, err)
+	require.Equal(t, 1, base.chains)
+
+	final, steps, err := runner.RunChain(ctx, []run.ChainStep{
+		{ToolID: "local:ping", Args: map[string]any{"x": 1}},
+		{ToolID: ToolID, Args: map[string]any{"prompt": "Summarise:"}, UsePrevious: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, base.chains)
+	require.Len(t, steps, 2)
+	require.Contains(t, final.Structured.(map[string]any)["text"], `"tool":"local:ping"`)
+}
+
+func TestRegister(t *testing.T) {
+	idx := index.NewInMemoryIndex()
+	require.NoError(t, Register(idx))
+	tool, _, err := idx.GetTool(ToolID)
+	require.NoError(t, err)
+	require.Equal(t, "sample", tool.Name)
+}
@@ -0,0 +1,186 @@
+// Package scope narrows an MCP session to the tools of the toolsets it
+// activated. A Scope lives as long as its session; searches, listings and
+// runs made with a Scope in their context only reach members of its active
+// toolsets. A Scope with no active toolsets reaches every tool.
+package scope
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	merrors "github.com/jonwraymond/metatools-mcp/internal/errors"
+	"github.com/jonwraymond/metatools-mcp/internal/runcheck"
+	"github.com/jonwraymond/metatools-mcp/internal/toolset"
+	"github.com/jonwraymond/toolexec/run"
+)
+
+// Toolsets resolves active toolset IDs. Toolsets are resolved on every
+// check, so reloaded toolsets take effect immediately.
+type Toolsets interface {
+	Get(id string) (*toolset.Toolset, bool)
+}
+
+// Active is an activated toolset.
+type Active struct {
+	ID string
+	// Direct publishes the toolset's members as MCP tools for the session.
+	Direct bool
+}
+
+// Scope is a session's set of active toolsets. It is safe for concurrent
+// use.
+type Scope struct {
+	toolsets  Toolsets
+	onPublish func()
+
+	mu     sync.Mutex
+	active []Active
+}
+
+// New creates an empty Scope. onPublish, if set, is called after the set of
+// toolsets activated with Direct changes.
+func New(toolsets Toolsets, onPublish func()) *Scope {
+	return &Scope{toolsets: toolsets, onPublish: onPublish}
+}
+
+type scopeKey struct{}
+
+// WithScope returns a context whose searches, listings and runs are limited
+// to s.
+func WithScope(ctx context.Context, s *Scope) context.Context {
+	return context.WithValue(ctx, scopeKey{}, s)
+}
+
+// FromContext returns the Scope set by WithScope, or nil.
+func FromContext(ctx context.Context) *Scope {
+	s, _ := ctx.Value(scopeKey{}).(*Scope)
+	return s
+}
+
+// Check returns ErrToolNotActive when the Scope of ctx does not allow
+// toolID.
+func Check(ctx context.Context, toolID string, _ map[string]any) error {
+	if !FromContext(ctx).Allows(toolID) {
+		return fmt.Errorf("%w: %s", merrors.ErrToolNotActive, toolID)
+	}
+	return nil
+}
+
+// WrapRunner returns a runner that rejects calls to tools outside the Scope
+// of the call's context before delegating to base, see runcheck.Wrap.
+func WrapRunner(base run.Runner) run.Runner {
+	return runcheck.Wrap(base, Check)
+}
+
+// Activate adds the toolset id, or updates its Direct flag, and reports
+// whether the scope changed.
+func (s *Scope) Activate(id string, direct bool) bool {
+	s.mu.Lock()
+	i := slices.IndexFunc(s.active, func(a Active) bool { return a.ID == id })
+	switch {
+	case i < 0:
+		s.active = append(s.active, Active{ID: id, Direct: direct})
+	case s.active[i].Direct != direct:
+		s.active[i].Direct = direct
+	default:
+		s.mu.Unlock()
+		return false
+	}
+	s.mu.Unlock()
+	if s.onPublish != nil && (direct || i >= 0) {
+		s.onPublish()
+	}
+	return true
+}
+
+// Deactivate removes the toolset id and reports whether it was active.
+func (s *Scope) Deactivate(id string) bool {
+	s.mu.Lock()
+	i := slices.IndexFunc(s.active, func(a Active) bool { return a.ID == id })
+	if i < 0 {
+		s.mu.Unlock()
+		return false
+	}
+	removed := s.active[i]
+	s.active = slices.Delete(s.active, i, i+1)
+	s.mu.Unlock()
+	if s.onPublish != nil && removed.Direct {
+		s.onPublish()
+	}
+	return true
+}
+
+// Active returns the active toolsets in activation order.
+func (s *Scope) Active() []Active {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.active)
+}
+
+// Restricted reports whether any toolset is active.
+func (s *Scope) Restricted() bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.active) > 0
+}
+
+// Allows reports whether toolID is a member of an active toolset, or whether
+// the scope is unrestricted.
+func (s *Scope) Allows(toolID string) bool {
+	if !s.Restricted() {
+		return true
+	}
+	return s.anyMember(false, func(ts *toolset.Toolset) bool {
+		return slices.Contains(ts.ToolIDs(), toolID)
+	})
+}
+
+// AllowsNamespace reports whether an active toolset has a member in
+// namespace, or whether the scope is unrestricted.
+func (s *Scope) AllowsNamespace(namespace string) bool {
+	if !s.Restricted() {
+		return true
+	}
+	return s.anyMember(false, func(ts *toolset.Toolset) bool {
+		for _, tool := range ts.Tools {
+			if tool != nil && tool.Namespace == namespace {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Publishes reports whether toolID is a member of a toolset activated with
+// Direct.
+func (s *Scope) Publishes(toolID string) bool {
+	if s == nil {
+		return false
+	}
+	return s.anyMember(true, func(ts *toolset.Toolset) bool {
+		return slices.Contains(ts.ToolIDs(), toolID)
+	})
+}
+
+// anyMember reports whether match holds for an active toolset, only
+// considering toolsets activated with Direct when direct is set. Toolsets
+// that no longer exist match nothing.
+func (s *Scope) anyMember(direct bool, match func(*toolset.Toolset) bool) bool {
+	for _, a := range s.Active() {
+		if direct && !a.Direct {
+			continue
+		}
+		if ts, ok := s.toolsets.Get(a.ID); ok && ts != nil && match(ts) {
+			return true
+		}
+	}
+	return false
+}
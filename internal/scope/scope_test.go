@@ -0,0 +1,109 @@
+package scope
+
+import (
+	"context"
+	"testing"
+
+	merrors "github.com/jonwraymond/metatools-mcp/internal/errors"
+	"github.com/jonwraymond/metatools-mcp/internal/toolset"
+	"github.com/jonwraymond/toolexec/run"
+	"github.com/jonwraymond/toolfoundation/adapter"
+	"github.com/stretchr/testify/require"
+)
+
+func testToolsets() *toolset.Registry {
+	return toolset.NewRegistry([]*toolset.Toolset{
+		{ID: "toolset:github", Tools: []*adapter.CanonicalTool{
+			{Namespace: "github", Name: "create_issue"},
+			{Namespace: "github", Name: "list_issues"},
+		}},
+		{ID: "toolset:ops", Tools: []*adapter.CanonicalTool{{Namespace: "ops", Name: "deploy"}}},
+	})
+}
+
+func TestScope_Allows(t *testing.T) {
+	var nilScope *Scope
+	require.True(t, nilScope.Allows("ops:deploy"))
+	require.True(t, nilScope.AllowsNamespace("ops"))
+
+	s := New(testToolsets(), nil)
+	require.True(t, s.Allows("ops:deploy"))
+
+	require.True(t, s.Activate("toolset:github", false))
+	require.False(t, s.Activate("toolset:github", false))
+	require.True(t, s.Allows("github:create_issue"))
+	require.False(t, s.Allows("ops:deploy"))
+	require.True(t, s.AllowsNamespace("github"))
+	require.False(t, s.AllowsNamespace("ops"))
+	require.False(t, s.Publishes("github:create_issue"))
+
+	// A toolset that no longer exists allows nothing.
+	require.True(t, s.Activate("toolset:gone", false))
+	require.False(t, s.Allows("ops:deploy"))
+
+	require.True(t, s.Deactivate("toolset:github"))
+	require.True(t, s.Deactivate("toolset:gone"))
+	require.False(t, s.Deactivate("toolset:gone"))
+	require.True(t, s.Allows("ops:deploy"))
+}
+
+func TestScope_Publish(t *testing.T) {
+	published := 0
+	s := New(testToolsets(), func() { published++ })
+
+	s.Activate("toolset:ops", false)
+	require.Equal(t, 0, published)
+	s.Activate("toolset:ops", true)
+	require.Equal(t, 1, published)
+	require.True(t, s.Publishes("ops:deploy"))
+	require.Equal(t, []Active{{ID: "toolset:ops", Direct: true}}, s.Active())
+
+	s.Activate("toolset:github", false)
+	s.Deactivate("toolset:github")
+	require.Equal(t, 1, published)
+	s.Deactivate("toolset:ops")
+	require.Equal(t, 2, published)
+	require.False(t, s.Publishes("ops:deploy"))
+}
+
+type stubRunner struct {
+	calls []string
+}
+
+func (r *stubRunner) Run(_ context.Context, toolID string, _ map[string]any) (run.RunResult, error) {
+	r.calls = append(r.calls, toolID)
+	return run.RunResult{}, nil
+}
+
+func (r *stubRunner) RunStream(context.Context, string, map[string]any) (<-chan run.StreamEvent, error) {
+	return nil, run.ErrStreamNotSupported
+}
+
+func (r *stubRunner) RunChain(_ context.Context, steps []run.ChainStep) (run.RunResult, []run.StepResult, error) {
+	for _, step := range steps {
+		r.calls = append(r.calls, step.ToolID)
+	}
+	return run.RunResult{}, nil, nil
+}
+
+func TestWrapRunner(t *testing.T) {
+	base := &stubRunner{}
+	runner := WrapRunner(base)
+	s := New(testToolsets(), nil)
+	s.Activate("toolset:ops", false)
+	ctx := WithScope(context.Background(), s)
+
+	_, err := runner.Run(ctx, "ops:deploy", nil)
+	require.NoError(t, err)
+	_, err = runner.Run(ctx, "github:create_issue", nil)
+	require.ErrorIs(t, err, merrors.ErrToolNotActive)
+
+	// Chains are rejected before any step runs.
+	_, _, err = runner.RunChain(ctx, []run.ChainStep{{ToolID: "ops:deploy"}, {ToolID: "github:list_issues"}})
+	require.ErrorIs(t, err, merrors.ErrToolNotActive)
+
+	// Calls without a scope are not restricted.
+	_, err = runner.Run(context.Background(), "github:create_issue", nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"ops:deploy", "github:create_issue"}, base.calls)
+}
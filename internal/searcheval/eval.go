@@ -0,0 +1,125 @@
+package searcheval
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jonwraymond/metatools-mcp/internal/bootstrap"
+	"github.com/jonwraymond/metatools-mcp/internal/config"
+)
+
+// DefaultStrategies lists every strategy evaluated when none are requested.
+var DefaultStrategies = []string{"lexical", "bm25", "semantic", "hybrid"}
+
+// Report is the outcome of evaluating a golden set.
+type Report struct {
+	K          int              `json:"k"`
+	Queries    int              `json:"queries"`
+	Strategies []StrategyReport `json:"strategies"`
+}
+
+// StrategyReport holds mean metrics and per-query detail for one strategy.
+// Skipped is set when the strategy could not be built.
+type StrategyReport struct {
+	Strategy string        `json:"strategy"`
+	Skipped  string        `json:"skipped,omitempty"`
+	Metrics  Metrics       `json:"metrics"`
+	Queries  []QueryReport `json:"queries,omitempty"`
+}
+
+// QueryReport is the result of a single golden query.
+type QueryReport struct {
+	Query    string   `json:"query"`
+	Expected []string `json:"expected"`
+	Results  []string `json:"results"`
+	Metrics  Metrics  `json:"metrics"`
+}
+
+// Options configures Evaluate.
+type Options struct {
+	// Search holds the base search settings; the strategy is overridden per run.
+	Search config.AppSearchConfig
+	// Strategies to evaluate. Defaults to DefaultStrategies.
+	Strategies []string
+	// K overrides the golden set cutoff when positive.
+	K int
+}
+
+// Evaluate builds one index per strategy from the catalog and scores the
+// golden set against each. Strategies that cannot be built in this binary
+// (missing build tag or embedder) are reported as skipped rather than failing.
+func Evaluate(catalog Snapshot, golden GoldenSet, opts Options) (Report, error) {
+	if err := golden.Validate(); err != nil {
+		return Report{}, err
+	}
+	k := opts.K
+	if k <= 0 {
+		k = golden.K
+	}
+	if k <= 0 {
+		k = DefaultK
+	}
+	strategies := opts.Strategies
+	if len(strategies) == 0 {
+		strategies = DefaultStrategies
+	}
+
+	report := Report{K: k, Queries: len(golden.Queries)}
+	for _, strategy := range strategies {
+		strategy = strings.ToLower(strings.TrimSpace(strategy))
+		sr, err := evaluateStrategy(strategy, catalog, golden, k, opts.Search)
+		if err != nil {
+			return Report{}, err
+		}
+		report.Strategies = append(report.Strategies, sr)
+	}
+	return report, nil
+}
+
+func evaluateStrategy(strategy string, catalog Snapshot, golden GoldenSet, k int, search config.AppSearchConfig) (StrategyReport, error) {
+	sr := StrategyReport{Strategy: strategy}
+	switch strategy {
+	case "lexical", "bm25":
+	case "semantic", "hybrid":
+		// Without an embedder these silently fall back to another strategy,
+		// which would only duplicate that strategy's row.
+		if strings.TrimSpace(search.Semantic.Embedder) == "" {
+			sr.Skipped = "no semantic embedder configured"
+			return sr, nil
+		}
+	default:
+		return StrategyReport{}, fmt.Errorf("unknown search strategy %q", strategy)
+	}
+
+	search.Strategy = strategy
+	idx, err := bootstrap.NewIndexFromSearchConfig(search.ToSearchConfig())
+	if err != nil {
+		sr.Skipped = err.Error()
+		return sr, nil
+	}
+	if err := catalog.Register(idx); err != nil {
+		return StrategyReport{}, fmt.Errorf("%s: %w", strategy, err)
+	}
+
+	all := make([]Metrics, 0, len(golden.Queries))
+	for _, q := range golden.Queries {
+		summaries, err := idx.Search(q.Query, k)
+		if err != nil {
+			return StrategyReport{}, fmt.Errorf("%s: search %q: %w", strategy, q.Query, err)
+		}
+		results := make([]string, len(summaries))
+		for i, s := range summaries {
+			results[i] = s.ID
+		}
+		m := Score(results, q.Expected, k)
+		all = append(all, m)
+		sr.Queries = append(sr.Queries, QueryReport{
+			Query:    q.Query,
+			Expected: q.Expected,
+			Results:  results,
+			Metrics:  m,
+		})
+	}
+	sr.Metrics = Mean(all)
+	return sr, nil
+}
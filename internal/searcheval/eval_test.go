@@ -0,0 +1,128 @@
+package searcheval
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jonwraymond/metatools-mcp/internal/config"
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolfoundation/model"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCatalog(t *testing.T) Snapshot {
+	t.Helper()
+	idx := index.NewInMemoryIndex()
+	for _, tool := range []struct{ ns, name, desc string }{
+		{"github", "create_pull_request", "Open a new pull request"},
+		{"github", "list_issues", "List repository issues"},
+		{"kubernetes", "get_pods", "Get pods in a cluster"},
+	} {
+		err := idx.RegisterTool(model.Tool{
+			Tool: mcp.Tool{
+				Name:        tool.name,
+				Description: tool.desc,
+				InputSchema: map[string]any{"type": "object"},
+			},
+			Namespace: tool.ns,
+		}, model.ToolBackend{Kind: model.BackendKindMCP, MCP: &model.MCPBackend{ServerName: tool.ns}})
+		require.NoError(t, err)
+	}
+	snap, err := SnapshotIndex(idx)
+	require.NoError(t, err)
+	return snap
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	snap := testCatalog(t)
+	require.Len(t, snap.Tools, 3)
+
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	require.NoError(t, WriteSnapshot(path, snap))
+	loaded, err := LoadSnapshot(path)
+	require.NoError(t, err)
+
+	idx := index.NewInMemoryIndex()
+	require.NoError(t, loaded.Register(idx))
+	tool, backend, err := idx.GetTool("kubernetes:get_pods")
+	require.NoError(t, err)
+	assert.Equal(t, "Get pods in a cluster", tool.Description)
+	assert.Equal(t, "kubernetes", backend.MCP.ServerName)
+}
+
+func TestLoadGolden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "golden.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+k: 3
+queries:
+  - query: open pull request
+    expected: [github:create_pull_request]
+`), 0o644))
+
+	set, err := LoadGolden(path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, set.K)
+	require.Len(t, set.Queries, 1)
+	assert.Equal(t, []string{"github:create_pull_request"}, set.Queries[0].Expected)
+
+	require.NoError(t, os.WriteFile(path, []byte("queries:\n  - query: x\n"), 0o644))
+	_, err = LoadGolden(path)
+	assert.Error(t, err)
+}
+
+func TestEvaluate(t *testing.T) {
+	golden := GoldenSet{Queries: []GoldenQuery{
+		{Query: "pull request", Expected: []string{"github:create_pull_request"}},
+		{Query: "issues", Expected: []string{"github:list_issues"}},
+		{Query: "k8s", Expected: []string{"kubernetes:get_pods"}},
+	}}
+
+	report, err := Evaluate(testCatalog(t), golden, Options{
+		Search:     config.DefaultAppConfig().Search,
+		Strategies: []string{"lexical", "semantic"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultK, report.K)
+	assert.Equal(t, 3, report.Queries)
+	require.Len(t, report.Strategies, 2)
+
+	lexical := report.Strategies[0]
+	assert.Empty(t, lexical.Skipped)
+	assert.InDelta(t, 2.0/3.0, lexical.Metrics.Recall, 1e-9)
+	require.Len(t, lexical.Queries, 3)
+	assert.Empty(t, lexical.Queries[2].Results)
+
+	assert.Equal(t, "semantic", report.Strategies[1].Strategy)
+	assert.NotEmpty(t, report.Strategies[1].Skipped)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteTable(&buf, report))
+	assert.Contains(t, buf.String(), "RECALL@5")
+	assert.Contains(t, buf.String(), "0.667")
+}
+
+func TestEvaluate_UnknownStrategy(t *testing.T) {
+	golden := GoldenSet{Queries: []GoldenQuery{{Query: "x", Expected: []string{"a"}}}}
+	_, err := Evaluate(Snapshot{}, golden, Options{Strategies: []string{"nope"}})
+	assert.Error(t, err)
+}
+
+func TestCompare(t *testing.T) {
+	baseline := Report{K: 5, Strategies: []StrategyReport{
+		{Strategy: "lexical", Metrics: Metrics{Recall: 0.8, MRR: 0.7, NDCG: 0.75}},
+		{Strategy: "bm25", Skipped: "not built"},
+	}}
+	current := Report{K: 5, Strategies: []StrategyReport{
+		{Strategy: "lexical", Metrics: Metrics{Recall: 0.8, MRR: 0.6, NDCG: 0.74}},
+		{Strategy: "bm25", Metrics: Metrics{}},
+	}}
+
+	regressions := Compare(current, baseline, 0.02)
+	require.Len(t, regressions, 1)
+	assert.Equal(t, Regression{Strategy: "lexical", Metric: "mrr", Baseline: 0.7, Current: 0.6}, regressions[0])
+	assert.Len(t, Compare(current, baseline, 0.2), 0)
+}
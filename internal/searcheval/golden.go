@@ -0,0 +1,64 @@
+// Package searcheval measures tool search quality against golden query sets.
+// It scores every configured search strategy over the same tool catalog and
+// compares the results with a stored baseline so configuration changes can be
+// gated on retrieval quality.
+package searcheval
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultK is the cutoff used when neither the golden file nor the caller sets one.
+const DefaultK = 5
+
+// GoldenSet is a set of queries with the tool IDs they are expected to find.
+type GoldenSet struct {
+	K       int           `yaml:"k" json:"k"`
+	Queries []GoldenQuery `yaml:"queries" json:"queries"`
+}
+
+// GoldenQuery pairs a search query with its relevant tool IDs.
+type GoldenQuery struct {
+	Query    string   `yaml:"query" json:"query"`
+	Expected []string `yaml:"expected" json:"expected"`
+}
+
+// LoadGolden reads and validates a golden query file.
+func LoadGolden(path string) (GoldenSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return GoldenSet{}, fmt.Errorf("read golden file: %w", err)
+	}
+	var set GoldenSet
+	if err := yaml.Unmarshal(data, &set); err != nil {
+		return GoldenSet{}, fmt.Errorf("parse golden file %q: %w", path, err)
+	}
+	if err := set.Validate(); err != nil {
+		return GoldenSet{}, fmt.Errorf("golden file %q: %w", path, err)
+	}
+	return set, nil
+}
+
+// Validate checks that the set is usable for evaluation.
+func (g GoldenSet) Validate() error {
+	if g.K < 0 {
+		return errors.New("k cannot be negative")
+	}
+	if len(g.Queries) == 0 {
+		return errors.New("at least one query is required")
+	}
+	for i, q := range g.Queries {
+		if strings.TrimSpace(q.Query) == "" {
+			return fmt.Errorf("queries[%d]: query is required", i)
+		}
+		if len(q.Expected) == 0 {
+			return fmt.Errorf("queries[%d] %q: expected tool IDs are required", i, q.Query)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package searcheval
+
+import "math"
+
+// Metrics holds retrieval quality scores in the range [0, 1].
+type Metrics struct {
+	Recall float64 `json:"recall"`
+	MRR    float64 `json:"mrr"`
+	NDCG   float64 `json:"ndcg"`
+}
+
+// Score computes recall@k, reciprocal rank and nDCG@k for a single query
+// using binary relevance. Results beyond k are ignored.
+func Score(results, expected []string, k int) Metrics {
+	if len(expected) == 0 || k <= 0 {
+		return Metrics{}
+	}
+	relevant := make(map[string]struct{}, len(expected))
+	for _, id := range expected {
+		relevant[id] = struct{}{}
+	}
+	if len(results) > k {
+		results = results[:k]
+	}
+
+	var m Metrics
+	var hits int
+	var dcg float64
+	seen := make(map[string]struct{}, len(results))
+	for i, id := range results {
+		if _, ok := relevant[id]; !ok {
+			continue
+		}
+		if _, dup := seen[id]; dup {
+			continue
+		}
+		seen[id] = struct{}{}
+		hits++
+		dcg += 1 / math.Log2(float64(i+2))
+		if m.MRR == 0 {
+			m.MRR = 1 / float64(i+1)
+		}
+	}
+
+	var idcg float64
+	for i := range min(len(relevant), k) {
+		idcg += 1 / math.Log2(float64(i+2))
+	}
+	m.Recall = float64(hits) / float64(len(relevant))
+	m.NDCG = dcg / idcg
+	return m
+}
+
+// Mean averages metrics across queries.
+func Mean(all []Metrics) Metrics {
+	if len(all) == 0 {
+		return Metrics{}
+	}
+	var sum Metrics
+	for _, m := range all {
+		sum.Recall += m.Recall
+		sum.MRR += m.MRR
+		sum.NDCG += m.NDCG
+	}
+	n := float64(len(all))
+	return Metrics{Recall: sum.Recall / n, MRR: sum.MRR / n, NDCG: sum.NDCG / n}
+}
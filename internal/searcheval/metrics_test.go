@@ -0,0 +1,38 @@
+package searcheval
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		results  []string
+		expected []string
+		k        int
+		want     Metrics
+	}{
+		{"perfect", []string{"a", "b"}, []string{"a"}, 5, Metrics{Recall: 1, MRR: 1, NDCG: 1}},
+		{"second rank", []string{"x", "a"}, []string{"a"}, 5, Metrics{Recall: 1, MRR: 0.5, NDCG: 1 / math.Log2(3)}},
+		{"beyond k", []string{"x", "y", "a"}, []string{"a"}, 2, Metrics{}},
+		{"partial recall", []string{"a", "x"}, []string{"a", "b"}, 5, Metrics{Recall: 0.5, MRR: 1, NDCG: 1 / (1 + 1/math.Log2(3))}},
+		{"no expected", []string{"a"}, nil, 5, Metrics{}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Score(tc.results, tc.expected, tc.k)
+			assert.InDelta(t, tc.want.Recall, got.Recall, 1e-9)
+			assert.InDelta(t, tc.want.MRR, got.MRR, 1e-9)
+			assert.InDelta(t, tc.want.NDCG, got.NDCG, 1e-9)
+		})
+	}
+}
+
+func TestMean(t *testing.T) {
+	got := Mean([]Metrics{{Recall: 1, MRR: 1, NDCG: 1}, {}})
+	assert.Equal(t, Metrics{Recall: 0.5, MRR: 0.5, NDCG: 0.5}, got)
+	assert.Equal(t, Metrics{}, Mean(nil))
+}
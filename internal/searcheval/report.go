@@ -0,0 +1,81 @@
+package searcheval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+)
+
+// Regression is a metric that dropped below its baseline.
+type Regression struct {
+	Strategy string  `json:"strategy"`
+	Metric   string  `json:"metric"`
+	Baseline float64 `json:"baseline"`
+	Current  float64 `json:"current"`
+}
+
+// WriteTable writes a per-strategy summary table.
+func WriteTable(w io.Writer, report Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "STRATEGY\tRECALL@%d\tMRR\tNDCG@%d\n", report.K, report.K)
+	for _, sr := range report.Strategies {
+		if sr.Skipped != "" {
+			_, _ = fmt.Fprintf(tw, "%s\tskipped: %s\t\t\n", sr.Strategy, sr.Skipped)
+			continue
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%.3f\t%.3f\t%.3f\n", sr.Strategy, sr.Metrics.Recall, sr.Metrics.MRR, sr.Metrics.NDCG)
+	}
+	return tw.Flush()
+}
+
+// WriteJSON writes the report as indented JSON.
+func WriteJSON(w io.Writer, report Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// LoadBaseline reads a report previously written with WriteBaseline.
+func LoadBaseline(path string) (Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Report{}, fmt.Errorf("read baseline: %w", err)
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return Report{}, fmt.Errorf("parse baseline %q: %w", path, err)
+	}
+	return report, nil
+}
+
+// WriteBaseline stores a report for later comparison.
+func WriteBaseline(path string, report Report) error {
+	return writeJSONFile(path, report)
+}
+
+// Compare returns the metrics that fell more than tolerance below baseline.
+// Strategies skipped in either report are not compared.
+func Compare(current, baseline Report, tolerance float64) []Regression {
+	byStrategy := make(map[string]StrategyReport, len(current.Strategies))
+	for _, sr := range current.Strategies {
+		byStrategy[sr.Strategy] = sr
+	}
+	var out []Regression
+	for _, base := range baseline.Strategies {
+		cur, ok := byStrategy[base.Strategy]
+		if !ok || base.Skipped != "" || cur.Skipped != "" {
+			continue
+		}
+		check := func(metric string, b, c float64) {
+			if c < b-tolerance {
+				out = append(out, Regression{Strategy: base.Strategy, Metric: metric, Baseline: b, Current: c})
+			}
+		}
+		check("recall", base.Metrics.Recall, cur.Metrics.Recall)
+		check("mrr", base.Metrics.MRR, cur.Metrics.MRR)
+		check("ndcg", base.Metrics.NDCG, cur.Metrics.NDCG)
+	}
+	return out
+}
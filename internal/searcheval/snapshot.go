@@ -0,0 +1,82 @@
+package searcheval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/jonwraymond/metatools-mcp/internal/toolset"
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// Snapshot is a recorded tool catalog that can stand in for live backends.
+type Snapshot struct {
+	Tools []SnapshotTool `json:"tools"`
+}
+
+// SnapshotTool is a tool with every backend registered for it.
+type SnapshotTool struct {
+	Tool     model.Tool          `json:"tool"`
+	Backends []model.ToolBackend `json:"backends"`
+}
+
+// SnapshotIndex records every tool in idx. The index must list all tools for
+// an empty query, which holds for the default lexical searcher.
+func SnapshotIndex(idx index.Index) (Snapshot, error) {
+	ids, err := toolset.ListToolIDs(idx)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("list tools: %w", err)
+	}
+	snap := Snapshot{Tools: make([]SnapshotTool, 0, len(ids))}
+	for _, id := range ids {
+		tool, _, err := idx.GetTool(id)
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("get tool %q: %w", id, err)
+		}
+		backends, err := idx.GetAllBackends(id)
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("get backends for %q: %w", id, err)
+		}
+		snap.Tools = append(snap.Tools, SnapshotTool{Tool: tool, Backends: backends})
+	}
+	return snap, nil
+}
+
+// Register adds every recorded tool and backend to idx.
+func (s Snapshot) Register(idx index.Index) error {
+	for _, entry := range s.Tools {
+		for _, backend := range entry.Backends {
+			if err := idx.RegisterTool(entry.Tool, backend); err != nil {
+				return fmt.Errorf("register tool %q: %w", entry.Tool.ToolID(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// LoadSnapshot reads a snapshot written by WriteSnapshot.
+func LoadSnapshot(path string) (Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("read snapshot: %w", err)
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return Snapshot{}, fmt.Errorf("parse snapshot %q: %w", path, err)
+	}
+	return snap, nil
+}
+
+// WriteSnapshot writes the snapshot as indented JSON.
+func WriteSnapshot(path string, snap Snapshot) error {
+	return writeJSONFile(path, snap)
+}
+
+func writeJSONFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
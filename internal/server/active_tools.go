@@ -0,0 +1,123 @@
+package server
+
+import (
+	"log/slog"
+	"reflect"
+
+	"github.com/jonwraymond/metatools-mcp/internal/scope"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// sessionScope returns the Scope of ss, creating it on first use. The Scope
+// is dropped when the session ends, including when an idle streamable
+// session times out.
+func (s *Server) sessionScope(ss *mcp.ServerSession) *scope.Scope {
+	if v, ok := s.scopes.Load(ss); ok {
+		return v.(*scope.Scope)
+	}
+	v, loaded := s.scopes.LoadOrStore(ss, scope.New(s.config.Toolsets, s.publishActiveTools))
+	if !loaded {
+		go func() {
+			_ = ss.Wait()
+			s.scopes.Delete(ss)
+			s.publishActiveTools()
+		}()
+	}
+	return v.(*scope.Scope)
+}
+
+// loadScope returns the Scope of the request's session without creating one.
+func (s *Server) loadScope(req mcp.Request) *scope.Scope {
+	ss, ok := req.GetSession().(*mcp.ServerSession)
+	if !ok {
+		return nil
+	}
+	v, ok := s.scopes.Load(ss)
+	if !ok {
+		return nil
+	}
+	return v.(*scope.Scope)
+}
+
+// publishActiveTools updates the published tools after a session changes
+// its directly activated toolsets and tells clients to refetch their lists,
+// since a session's view can change without the server's tools changing.
+func (s *Server) publishActiveTools() {
+	s.syncActiveTools()
+	if s.config.NotifyToolListChanged {
+		s.reregisterTools()
+	}
+}
+
+// syncActiveTools publishes the members of toolsets that any session
+// activated with direct set. Each tool is only visible to the sessions that
+// activated one of its toolsets.
+func (s *Server) syncActiveTools() {
+	if s.config.Toolsets == nil {
+		return
+	}
+	ids := make(map[string]struct{})
+	s.scopes.Range(func(_, v any) bool {
+		for _, a := range v.(*scope.Scope).Active() {
+			if a.Direct {
+				ids[a.ID] = struct{}{}
+			}
+		}
+		return true
+	})
+	want := make(map[string]directTool)
+	for id := range ids {
+		ts, ok := s.config.Toolsets.Get(id)
+		if !ok || ts == nil {
+			continue
+		}
+		for _, ct := range ts.Tools {
+			if ct == nil {
+				continue
+			}
+			name := directToolName(ct.ID())
+			if _, ok := want[name]; ok {
+				continue
+			}
+			if _, ok := s.metatoolNames[name]; ok {
+				continue
+			}
+			tool, err := directMCPTool(name, ct)
+			if err != nil {
+				slog.Warn("cannot publish active tool", "tool_id", ct.ID(), "error", err)
+				continue
+			}
+			want[name] = directTool{toolID: ct.ID(), tool: tool}
+		}
+	}
+
+	s.publishMu.Lock()
+	defer s.publishMu.Unlock()
+	// Tools published for every session are left to their own sync.
+	shared := func(name string) bool {
+		_, direct := s.directTools[name]
+		_, skill := s.skillTools[name]
+		return direct || skill
+	}
+	for name := range want {
+		if shared(name) {
+			delete(want, name)
+		}
+	}
+	var removed []string
+	for name := range s.activeTools {
+		if _, ok := want[name]; !ok && !shared(name) {
+			removed = append(removed, name)
+		}
+	}
+	if len(removed) > 0 {
+		s.mcp.RemoveTools(removed...)
+	}
+	for name, dt := range want {
+		if cur, ok := s.activeTools[name]; ok && cur.toolID == dt.toolID && reflect.DeepEqual(cur.tool, dt.tool) {
+			continue
+		}
+		s.mcp.AddTool(dt.tool, s.directHandler(dt.toolID))
+	}
+	s.activeTools = want
+}
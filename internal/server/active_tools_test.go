@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jonwraymond/metatools-mcp/internal/toolset"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func callStructured(t *testing.T, session *mcp.ClientSession, name string, args map[string]any) map[string]any {
+	t.Helper()
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: args})
+	require.NoError(t, err)
+	require.False(t, res.IsError, "%s failed: %v", name, res.Content)
+	return res.StructuredContent.(map[string]any)
+}
+
+func TestServer_SessionToolsetActivation(t *testing.T) {
+	srv, runner, _, _, _ := newDirectTestServer(t, []toolset.Spec{
+		{Name: "core", AllowIDs: []string{"test:alpha"}},
+		{Name: "extra", AllowIDs: []string{"test:beta"}},
+	})
+	active, err := connectClient(t, srv, nil)
+	require.NoError(t, err)
+	other, err := connectClient(t, srv, nil)
+	require.NoError(t, err)
+
+	out := callStructured(t, active, "activate_toolset", map[string]any{"toolset_id": "extra", "direct": true})
+	assert.Equal(t, true, out["changed"])
+
+	// Only the activating session lists and can call the published member.
+	assert.Contains(t, toolNames(t, active), "test.beta")
+	assert.NotContains(t, toolNames(t, other), "test.beta")
+	_, err = other.CallTool(context.Background(), &mcp.CallToolParams{Name: "test.beta"})
+	require.ErrorContains(t, err, "unknown tool")
+	res, err := active.CallTool(context.Background(), &mcp.CallToolParams{Name: "test.beta"})
+	require.NoError(t, err)
+	require.False(t, res.IsError)
+	assert.Equal(t, []string{"test:beta"}, runner.calls)
+
+	// Listings are limited to the active toolsets.
+	tools := callStructured(t, active, "list_tools", nil)["tools"].([]any)
+	require.Len(t, tools, 1)
+	assert.Equal(t, "test:beta", tools[0].(map[string]any)["id"])
+	assert.Len(t, callStructured(t, other, "list_tools", nil)["tools"].([]any), 2)
+
+	callStructured(t, active, "deactivate_toolset", map[string]any{"toolset_id": "extra"})
+	assert.NotContains(t, toolNames(t, active), "test.beta")
+	assert.Len(t, callStructured(t, active, "list_tools", nil)["tools"].([]any), 2)
+}
+
+func TestServer_SessionScopeDroppedOnClose(t *testing.T) {
+	srv, _, _, _, _ := newDirectTestServer(t, []toolset.Spec{
+		{Name: "extra", AllowIDs: []string{"test:beta"}},
+	})
+	session, err := connectClient(t, srv, nil)
+	require.NoError(t, err)
+	callStructured(t, session, "activate_toolset", map[string]any{"toolset_id": "extra", "direct": true})
+
+	srv.publishMu.Lock()
+	require.Contains(t, srv.activeTools, "test.beta")
+	srv.publishMu.Unlock()
+
+	require.NoError(t, session.Close())
+	require.Eventually(t, func() bool {
+		srv.publishMu.Lock()
+		defer srv.publishMu.Unlock()
+		_, ok := srv.activeTools["test.beta"]
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+}
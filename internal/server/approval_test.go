@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jonwraymond/metatools-mcp/internal/adapters"
+	"github.com/jonwraymond/metatools-mcp/internal/approval"
+	"github.com/jonwraymond/metatools-mcp/internal/config"
+	"github.com/jonwraymond/metatools-mcp/internal/skills"
+	"github.com/jonwraymond/metatools-mcp/internal/toolset"
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolexec/run"
+	"github.com/jonwraymond/toolfoundation/model"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+type echoRunner struct{}
+
+func (echoRunner) Run(_ context.Context, toolID string, _ map[string]any) (run.RunResult, error) {
+	return run.RunResult{Structured: map[string]any{"tool": toolID}}, nil
+}
+
+func (echoRunner) RunStream(context.Context, string, map[string]any) (<-chan run.StreamEvent, error) {
+	return nil, run.ErrStreamNotSupported
+}
+
+func (echoRunner) RunChain(context.Context, []run.ChainStep) (run.RunResult, []run.StepResult, error) {
+	return run.RunResult{}, nil, nil
+}
+
+func newApprovalTestServer(t *testing.T) *Server {
+	t.Helper()
+	idx := index.NewInMemoryIndex()
+	destructive := true
+	require.NoError(t, idx.RegisterTool(model.Tool{
+		Namespace: "fs",
+		Tool: mcp.Tool{
+			Name:        "delete",
+			InputSchema: map[string]any{"type": "object"},
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructive},
+		},
+	}, model.ToolBackend{Kind: model.BackendKindLocal, Local: &model.LocalBackend{Name: "delete"}}))
+
+	gate := approval.NewGate(approval.Options{
+		Policy: approval.Policy{Destructive: true},
+		Tools:  adapters.NewIndexAdapter(idx),
+	})
+	srv, err := New(config.Config{
+		Index:     adapters.NewIndexAdapter(idx),
+		Docs:      &mockStore{},
+		Runner:    adapters.NewRunnerAdapter(approval.WrapRunner(echoRunner{}, gate)),
+		Toolsets:  toolset.NewRegistry(nil),
+		Skills:    skills.NewRegistry(nil),
+		Providers: config.DefaultAppConfig().Providers,
+		Approval:  gate,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = srv.Close() })
+	return srv
+}
+
+func connectClientWithOptions(t *testing.T, srv *Server, opts *mcp.ClientOptions) *mcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := srv.MCPServer().Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "metatools-test-client"}, opts)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = session.Close() })
+	return session
+}
+
+func runDelete(t *testing.T, session *mcp.ClientSession) *mcp.CallToolResult {
+	t.Helper()
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "run_tool",
+		Arguments: map[string]any{"tool_id": "fs:delete", "args": map[string]any{"path": "/tmp/x", "token": "t"}},
+	})
+	require.NoError(t, err)
+	return res
+}
+
+func errorCode(t *testing.T, res *mcp.CallToolResult) string {
+	t.Helper()
+	structured, ok := res.StructuredContent.(map[string]any)
+	require.True(t, ok)
+	errObj, ok := structured["error"].(map[string]any)
+	require.True(t, ok)
+	code, _ := errObj["code"].(string)
+	return code
+}
+
+func TestServer_ApprovalRequiredWithoutElicitation(t *testing.T) {
+	session := connectClientWithOptions(t, newApprovalTestServer(t), nil)
+
+	res := runDelete(t, session)
+	require.True(t, res.IsError)
+	require.Equal(t, "approval_required", errorCode(t, res))
+}
+
+func TestServer_ApprovalViaElicitation(t *testing.T) {
+	var prompts []string
+	action := "accept"
+	session := connectClientWithOptions(t, newApprovalTestServer(t), &mcp.ClientOptions{
+		ElicitationHandler: func(_ context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+			prompts = append(prompts, req.Params.Message)
+			return &mcp.ElicitResult{Action: action}, nil
+		},
+	})
+
+	res := runDelete(t, session)
+	require.False(t, res.IsError)
+	require.Len(t, prompts, 1)
+	require.Contains(t, prompts[0], "fs:delete")
+	require.Contains(t, prompts[0], `"token":"[REDACTED]"`)
+
+	action = "decline"
+	res = runDelete(t, session)
+	require.True(t, res.IsError)
+	require.Equal(t, "approval_denied", errorCode(t, res))
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// cancelGrace bounds how long a call whose request context has ended waits
+// for the client's cancellation reason. The SDK cancels the request as soon
+// as it reads notifications/cancelled, just before the notification itself
+// is handled.
+const cancelGrace = 100 * time.Millisecond
+
+// callKey identifies an in-flight tools/call request.
+type callKey struct {
+	session *mcp.ServerSession
+	id      any
+}
+
+// callCancels maps in-flight tools/call requests to their cancel functions,
+// so a client's notifications/cancelled ends the call with its reason as the
+// context's cause.
+type callCancels struct {
+	mu    sync.Mutex
+	calls map[callKey]context.CancelCauseFunc
+}
+
+func newCallCancels() *callCancels {
+	return &callCancels{calls: make(map[callKey]context.CancelCauseFunc)}
+}
+
+// start returns the context for a tools/call request from ss. It ends with
+// the client's reason when the client cancels the call, and with ctx's cause
+// when ctx ends for any other reason. done releases the call once its
+// handler has returned.
+func (c *callCancels) start(ctx context.Context, ss *mcp.ServerSession) (_ context.Context, done func()) {
+	id, ok := requestID(ctx)
+	if !ok {
+		return ctx, func() {}
+	}
+	callCtx, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+	key := callKey{session: ss, id: id}
+	c.mu.Lock()
+	c.calls[key] = cancel
+	c.mu.Unlock()
+
+	stop := context.AfterFunc(ctx, func() {
+		timer := time.NewTimer(cancelGrace)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			cancel(context.Cause(ctx))
+		case <-callCtx.Done():
+		}
+	})
+	return callCtx, func() {
+		stop()
+		c.mu.Lock()
+		delete(c.calls, key)
+		c.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// cancel ends the in-flight call id from ss with reason as its cause.
+func (c *callCancels) cancel(ss *mcp.ServerSession, id any, reason string) {
+	c.mu.Lock()
+	cancel := c.calls[callKey{session: ss, id: normalizeID(id)}]
+	c.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cause := context.Canceled
+	if reason != "" {
+		cause = errors.New(reason)
+	}
+	cancel(cause)
+}
+
+// normalizeID maps a decoded JSON-RPC ID onto the form the SDK keeps:
+// an int64 or a string.
+func normalizeID(id any) any {
+	switch v := id.(type) {
+	case float64:
+		return int64(v)
+	case int:
+		return int64(v)
+	default:
+		return v
+	}
+}
+
+// sdkIDKey caches the SDK's context key for the incoming request ID.
+var sdkIDKey atomic.Value
+
+// requestID returns the JSON-RPC ID of the request ctx was created for. The
+// SDK keeps it under an unexported context key with no accessor, so the key
+// is found once by its type along ctx's parents.
+func requestID(ctx context.Context) (any, bool) {
+	key := sdkIDKey.Load()
+	if key == nil {
+		if key = findIDKey(ctx); key == nil {
+			return nil, false
+		}
+		sdkIDKey.Store(key)
+	}
+	id, ok := ctx.Value(key).(interface{ Raw() any })
+	if !ok || id.Raw() == nil {
+		return nil, false
+	}
+	return id.Raw(), true
+}
+
+func findIDKey(ctx context.Context) any {
+	sdk := reflect.TypeFor[mcp.Implementation]().PkgPath()
+	v := reflect.ValueOf(ctx)
+	for v.IsValid() {
+		switch v.Kind() {
+		case reflect.Interface, reflect.Pointer:
+			if v.IsNil() {
+				return nil
+			}
+			v = v.Elem()
+			continue
+		case reflect.Struct:
+		default:
+			return nil
+		}
+		if k := v.FieldByName("key"); k.IsValid() && k.Kind() == reflect.Interface && !k.IsNil() {
+			if t := k.Elem().Type(); t.PkgPath() == sdk && t.Name() == "idContextKey" {
+				return reflect.Zero(t).Interface()
+			}
+		}
+		v = v.FieldByName("Context")
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jonwraymond/metatools-mcp/internal/adapters"
+	"github.com/jonwraymond/metatools-mcp/internal/config"
+	"github.com/jonwraymond/metatools-mcp/internal/skills"
+	"github.com/jonwraymond/metatools-mcp/internal/toolset"
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolexec/run"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// causeRunner blocks every call until its context ends and reports the cause.
+type causeRunner struct {
+	echoRunner
+	causes chan error
+}
+
+func (r causeRunner) Run(ctx context.Context, _ string, _ map[string]any) (run.RunResult, error) {
+	<-ctx.Done()
+	r.causes <- context.Cause(ctx)
+	return run.RunResult{}, ctx.Err()
+}
+
+func TestServer_ClientCancelReasonIsCallCause(t *testing.T) {
+	runner := causeRunner{causes: make(chan error, 1)}
+	srv, err := New(config.Config{
+		Index:     adapters.NewIndexAdapter(index.NewInMemoryIndex()),
+		Docs:      &mockStore{},
+		Runner:    adapters.NewRunnerAdapter(runner),
+		Toolsets:  toolset.NewRegistry(nil),
+		Skills:    skills.NewRegistry(nil),
+		Providers: config.DefaultAppConfig().Providers,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = srv.Close() })
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := srv.MCPServer().Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+	client := mcp.NewClient(&mcp.Implementation{Name: "metatools-test-client"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = session.Close() })
+
+	// The client cancels with its context's error as the reason.
+	callCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = session.CallTool(callCtx, &mcp.CallToolParams{
+		Name:      "run_tool",
+		Arguments: map[string]any{"tool_id": "demo:slow"},
+	})
+	require.Error(t, err)
+
+	select {
+	case cause := <-runner.causes:
+		require.EqualError(t, cause, context.DeadlineExceeded.Error())
+		require.NotErrorIs(t, cause, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("call was not canceled")
+	}
+}
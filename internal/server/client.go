@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jonwraymond/metatools-mcp/internal/approval"
+	"github.com/jonwraymond/metatools-mcp/internal/jobs"
+	"github.com/jonwraymond/metatools-mcp/internal/mcplog"
+	"github.com/jonwraymond/metatools-mcp/internal/results"
+	"github.com/jonwraymond/metatools-mcp/internal/roots"
+	"github.com/jonwraymond/metatools-mcp/internal/sampling"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// rootsTimeout bounds a roots/list request to the client.
+const rootsTimeout = 10 * time.Second
+
+// registerClientSessions lets tool calls reach back to the calling client.
+// Every call carries the session for log forwarding, for the results it
+// keeps for read_result and for owning its jobs. Calls from clients that support elicitation carry
+// it for the approval gate, and calls from clients that support sampling
+// carry it for llm:sample with a fresh sampling budget. When roots are
+// tracked and the client declares the roots capability, its roots are
+// fetched once it is initialized and again whenever it reports a change,
+// and every call carries them; other clients are never sent roots/list.
+// Kept results, remembered approvals and tracked roots end with the
+// session, and a call the client cancels ends with the client's reason as
+// its context's cause.
+func (s *Server) registerClientSessions() {
+	gate := s.config.Approval
+	tracker := s.config.Roots
+	calls := newCallCancels()
+	// owners names each session for the jobs it submits without a
+	// principal. Session IDs are empty on stdio and in-memory transports.
+	var owners sync.Map
+	s.mcp.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			ss, ok := req.GetSession().(*mcp.ServerSession)
+			if !ok {
+				return next(ctx, method, req)
+			}
+			switch method {
+			case "initialize":
+				res, err := next(ctx, method, req)
+				if err == nil {
+					if tracker != nil && supportsRoots(ss) {
+						tracker.Track(ss)
+					}
+					go func() {
+						_ = ss.Wait()
+						s.results.DropSession(ss)
+						owners.Delete(ss)
+						if gate != nil {
+							gate.Forget(ss.ID())
+						}
+						if tracker != nil {
+							tracker.Forget(ss)
+						}
+					}()
+				}
+				return res, err
+			case "notifications/initialized", "notifications/roots/list_changed":
+				if tracker != nil && supportsRoots(ss) {
+					// Notifications are handled in order, so the client's
+					// answer cannot be read until this handler returns.
+					go func() {
+						ctx, cancel := context.WithTimeout(context.Background(), rootsTimeout)
+						defer cancel()
+						tracker.Refresh(ctx, ss)
+					}()
+				}
+			case "notifications/cancelled":
+				if params, ok := req.GetParams().(*mcp.CancelledParams); ok {
+					slog.Default().InfoContext(ctx, "client canceled request", "session_id", ss.ID(), "request_id", params.RequestID, "reason", params.Reason)
+					calls.cancel(ss, params.RequestID, params.Reason)
+				}
+			case "tools/call":
+				var done func()
+				ctx, done = calls.start(ctx, ss)
+				defer done()
+				ctx = mcplog.WithSession(ctx, ss)
+				ctx = results.WithSession(ctx, ss)
+				owner, _ := owners.LoadOrStore(ss, rand.Text())
+				ctx = jobs.WithSession(ctx, owner.(string))
+				if tracker != nil && supportsRoots(ss) {
+					fetchCtx, cancel := context.WithTimeout(ctx, rootsTimeout)
+					list, ok := tracker.Roots(fetchCtx, ss)
+					cancel()
+					if ok {
+						ctx = roots.WithRoots(ctx, list)
+					}
+				}
+				params := ss.InitializeParams()
+				if params == nil || params.Capabilities == nil {
+					break
+				}
+				if gate != nil && params.Capabilities.Elicitation != nil {
+					ctx = approval.WithSession(ctx, ss)
+				}
+				if params.Capabilities.Sampling != nil {
+					ctx = sampling.WithSession(ctx, ss)
+				}
+			}
+			return next(ctx, method, req)
+		}
+	})
+}
+
+// supportsRoots reports whether the client of ss declared the roots
+// capability, without which it must not be sent roots/list.
+func supportsRoots(ss *mcp.ServerSession) bool {
+	params := ss.InitializeParams()
+	return params != nil && params.Capabilities != nil && params.Capabilities.RootsV2 != nil
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jonwraymond/metatools-mcp/internal/adapters"
+	"github.com/jonwraymond/metatools-mcp/internal/config"
+	"github.com/jonwraymond/metatools-mcp/internal/handlers"
+	"github.com/jonwraymond/metatools-mcp/internal/mcplog"
+	"github.com/jonwraymond/metatools-mcp/internal/skills"
+	"github.com/jonwraymond/metatools-mcp/internal/toolset"
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// printingExecutor answers every call with stdout.
+type printingExecutor struct {
+	stdout string
+}
+
+func (e printingExecutor) ExecuteCode(context.Context, handlers.ExecuteParams) (handlers.ExecuteResult, error) {
+	return handlers.ExecuteResult{Value: "done", Stdout: e.stdout}, nil
+}
+
+func TestServer_ExecuteCodeOutputOnlyReachesTheSession(t *testing.T) {
+	var processLog bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(mcplog.NewHandler(slog.NewTextHandler(&processLog, &slog.HandlerOptions{Level: slog.LevelDebug}))))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	stdout := "secret=" + strings.Repeat("x", 64<<10)
+	providers := config.DefaultAppConfig().Providers
+	providers.ExecuteCode.Enabled = true
+	srv, err := New(config.Config{
+		Index:     adapters.NewIndexAdapter(index.NewInMemoryIndex()),
+		Docs:      &mockStore{},
+		Runner:    adapters.NewRunnerAdapter(echoRunner{}),
+		Executor:  printingExecutor{stdout: stdout},
+		Toolsets:  toolset.NewRegistry(nil),
+		Skills:    skills.NewRegistry(nil),
+		Providers: providers,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = srv.Close() })
+
+	var mu sync.Mutex
+	var logs []*mcp.LoggingMessageParams
+	session := connectClientWithOptions(t, srv, &mcp.ClientOptions{
+		LoggingMessageHandler: func(_ context.Context, req *mcp.LoggingMessageRequest) {
+			mu.Lock()
+			logs = append(logs, req.Params)
+			mu.Unlock()
+		},
+	})
+	require.NoError(t, session.SetLoggingLevel(context.Background(), &mcp.SetLoggingLevelParams{Level: "info"}))
+
+	out := callStructured(t, session, "execute_code", map[string]any{"language": "go", "code": "x"})
+	require.Equal(t, stdout, out["stdout"])
+
+	var data map[string]any
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, params := range logs {
+			raw, _ := json.Marshal(params.Data)
+			var d map[string]any
+			if json.Unmarshal(raw, &d) == nil && d["stream"] == "stdout" {
+				data = d
+				return true
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+	require.Len(t, data["output"], 16<<10)
+	require.Equal(t, float64(len(stdout)-16<<10), data["truncated_bytes"])
+	require.NotContains(t, processLog.String(), "secret=")
+}
@@ -0,0 +1,249 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jonwraymond/metatools-mcp/internal/handlers"
+	"github.com/jonwraymond/metatools-mcp/internal/jsonshape"
+	"github.com/jonwraymond/metatools-mcp/internal/scope"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxCompletions is the most values a completion/complete result may carry.
+const maxCompletions = 100
+
+// argsPrefix marks completions of a selected tool's arguments, e.g.
+// "args.repo" for the repo argument of the tool named by tool_id.
+const argsPrefix = "args."
+
+// completer answers completion/complete for metatool arguments. MCP
+// references name prompts or resources, so requests are matched by argument
+// name whatever the reference.
+type completer struct {
+	index    handlers.Index
+	tools    handlers.ToolLookup // optional
+	docs     handlers.Store
+	toolsets handlers.ToolsetRegistry // optional
+	skills   handlers.SkillRegistry   // optional
+}
+
+func (c *completer) complete(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	arg := req.Params.Argument
+	var resolved map[string]string
+	if req.Params.Context != nil {
+		resolved = req.Params.Context.Arguments
+	}
+
+	var candidates []string
+	var err error
+	switch {
+	case arg.Name == "tool_id":
+		candidates, err = c.toolIDs(ctx)
+	case arg.Name == "namespace":
+		candidates, err = c.namespaces(ctx)
+	case arg.Name == "toolset_id" && c.toolsets != nil:
+		for _, ts := range c.toolsets.List() {
+			candidates = append(candidates, ts.ID)
+		}
+	case arg.Name == "skill_id" && c.skills != nil:
+		for _, s := range c.skills.List() {
+			candidates = append(candidates, s.ID)
+		}
+	case strings.HasPrefix(arg.Name, argsPrefix):
+		candidates, err = c.argValues(ctx, resolved["tool_id"], strings.TrimPrefix(arg.Name, argsPrefix))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	values := matchCompletions(candidates, arg.Value)
+	result := &mcp.CompleteResult{Completion: mcp.CompletionResultDetails{Values: values, Total: len(values)}}
+	if len(values) > maxCompletions {
+		result.Completion.Values = values[:maxCompletions]
+		result.Completion.HasMore = true
+	}
+	return result, nil
+}
+
+// toolIDs returns the IDs of the tools in the session's active toolsets, or
+// of every tool when it activated none.
+func (c *completer) toolIDs(ctx context.Context) ([]string, error) {
+	sc := scope.FromContext(ctx)
+	var ids []string
+	cursor := ""
+	for {
+		page, next, err := c.index.SearchPage(ctx, "", defaultPageSize, cursor)
+		if err != nil {
+			return nil, err
+		}
+		for _, summary := range page {
+			if sc.Allows(summary.ID) {
+				ids = append(ids, summary.ID)
+			}
+		}
+		if next == "" {
+			return ids, nil
+		}
+		cursor = next
+	}
+}
+
+// namespaces returns the namespaces with a tool in the session's active
+// toolsets, or every namespace when it activated none.
+func (c *completer) namespaces(ctx context.Context) ([]string, error) {
+	sc := scope.FromContext(ctx)
+	var out []string
+	cursor := ""
+	for {
+		page, next, err := c.index.ListNamespacesPage(ctx, defaultPageSize, cursor)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range page {
+			if sc.AllowsNamespace(ns) {
+				out = append(out, ns)
+			}
+		}
+		if next == "" {
+			return out, nil
+		}
+		cursor = next
+	}
+}
+
+// argValues returns the values suggested for the argument at path (dot
+// separated for nested objects) of toolID: schema enum, const, default and
+// examples, then the values used in the tool's documented examples. Tools
+// outside the session's active toolsets have nothing to suggest.
+func (c *completer) argValues(ctx context.Context, toolID, path string) ([]string, error) {
+	if toolID == "" || path == "" || c.tools == nil || !scope.FromContext(ctx).Allows(toolID) {
+		return nil, nil
+	}
+	tool, err := c.tools.GetTool(ctx, toolID)
+	if err != nil {
+		// An unknown tool has nothing to suggest; the client may still be
+		// typing its ID.
+		return nil, nil
+	}
+
+	var values []any
+	if prop := schemaProperty(tool.InputSchema, strings.Split(path, ".")); prop != nil {
+		if enum, ok := prop["enum"].([]any); ok {
+			values = append(values, enum...)
+		}
+		if v, ok := prop["const"]; ok {
+			values = append(values, v)
+		}
+		if v, ok := prop["default"]; ok {
+			values = append(values, v)
+		}
+		if examples, ok := prop["examples"].([]any); ok {
+			values = append(values, examples...)
+		}
+		if prop["type"] == "boolean" {
+			values = append(values, true, false)
+		}
+	}
+	if examples, err := c.docs.ListExamples(ctx, toolID, maxCompletions); err == nil {
+		for _, ex := range examples {
+			if v, ok := lookupPath(ex.Args, strings.Split(path, ".")); ok {
+				values = append(values, v)
+			}
+		}
+	}
+
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if s, ok := completionString(v); ok {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+// schemaProperty returns the schema of the property at path, descending
+// through nested object properties.
+func schemaProperty(schema any, path []string) map[string]any {
+	current, _ := jsonshape.Map(schema)
+	for _, name := range path {
+		props, _ := current["properties"].(map[string]any)
+		current, _ = props[name].(map[string]any)
+		if current == nil {
+			return nil
+		}
+	}
+	return current
+}
+
+func lookupPath(args map[string]any, path []string) (any, bool) {
+	var current any = args
+	for _, name := range path {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[name]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// completionString renders a scalar suggestion. Objects and arrays are not
+// suggested.
+func completionString(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case bool, float64, int, int64, json.Number:
+		return fmt.Sprint(v), true
+	default:
+		return "", false
+	}
+}
+
+// matchCompletions returns the distinct candidates matching value, case
+// insensitively: prefix matches first, then substring matches, then fuzzy
+// matches whose characters appear in order. Each group is sorted.
+func matchCompletions(candidates []string, value string) []string {
+	query := strings.ToLower(value)
+	seen := make(map[string]struct{}, len(candidates))
+	var prefix, substring, fuzzy []string
+	for _, candidate := range candidates {
+		if _, ok := seen[candidate]; ok {
+			continue
+		}
+		seen[candidate] = struct{}{}
+		lower := strings.ToLower(candidate)
+		switch {
+		case strings.HasPrefix(lower, query):
+			prefix = append(prefix, candidate)
+		case strings.Contains(lower, query):
+			substring = append(substring, candidate)
+		case isSubsequence(query, lower):
+			fuzzy = append(fuzzy, candidate)
+		}
+	}
+	sort.Strings(prefix)
+	sort.Strings(substring)
+	sort.Strings(fuzzy)
+	out := make([]string, 0, len(prefix)+len(substring)+len(fuzzy))
+	out = append(out, prefix...)
+	out = append(out, substring...)
+	return append(out, fuzzy...)
+}
+
+func isSubsequence(query, s string) bool {
+	for _, r := range query {
+		i := strings.IndexRune(s, r)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(string(r)):]
+	}
+	return true
+}
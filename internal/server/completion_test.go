@@ -0,0 +1,171 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jonwraymond/metatools-mcp/internal/adapters"
+	"github.com/jonwraymond/metatools-mcp/internal/config"
+	"github.com/jonwraymond/metatools-mcp/internal/handlers"
+	"github.com/jonwraymond/metatools-mcp/internal/skills"
+	"github.com/jonwraymond/metatools-mcp/internal/toolset"
+	"github.com/jonwraymond/metatools-mcp/pkg/metatools"
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolfoundation/model"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// examplesStore serves documented examples for github:create_issue.
+type examplesStore struct {
+	mockStore
+}
+
+func (s *examplesStore) ListExamples(_ context.Context, id string, _ int) ([]metatools.ToolExample, error) {
+	if id != "github:create_issue" {
+		return nil, nil
+	}
+	return []metatools.ToolExample{
+		{Title: "Bug", Args: map[string]any{"repo": map[string]any{"owner": "acme"}, "title": "Crash on start"}},
+	}, nil
+}
+
+func newCompletionTestServer(t *testing.T) *Server {
+	t.Helper()
+	idx := index.NewInMemoryIndex()
+	register := func(namespace, name string, schema map[string]any) {
+		require.NoError(t, idx.RegisterTool(model.Tool{
+			Namespace: namespace,
+			Tool:      mcp.Tool{Name: name, InputSchema: schema},
+		}, model.ToolBackend{Kind: model.BackendKindLocal, Local: &model.LocalBackend{Name: name}}))
+	}
+	register("github", "create_issue", map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"state": map[string]any{"type": "string", "enum": []any{"open", "closed"}},
+			"draft": map[string]any{"type": "boolean"},
+			"repo": map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"owner": map[string]any{"type": "string", "examples": []any{"octocat"}}},
+			},
+		},
+	})
+	register("github", "list_issues", map[string]any{"type": "object"})
+	register("gitlab", "merge", map[string]any{"type": "object"})
+
+	srv, err := New(config.Config{
+		Index:     adapters.NewIndexAdapter(idx),
+		Docs:      &examplesStore{},
+		Runner:    &mockRunner{},
+		Toolsets:  toolset.NewRegistry([]*toolset.Toolset{{ID: "triage"}, {ID: "release"}}),
+		Skills:    skills.NewRegistry([]*skills.Skill{{ID: "triage_issue"}}),
+		Providers: config.DefaultAppConfig().Providers,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = srv.Close() })
+	return srv
+}
+
+func complete(t *testing.T, session *mcp.ClientSession, name, value string, resolved map[string]string) mcp.CompletionResultDetails {
+	t.Helper()
+	params := &mcp.CompleteParams{
+		Ref:      &mcp.CompleteReference{Type: "ref/prompt", Name: "run_tool"},
+		Argument: mcp.CompleteParamsArgument{Name: name, Value: value},
+	}
+	if resolved != nil {
+		params.Context = &mcp.CompleteContext{Arguments: resolved}
+	}
+	res, err := session.Complete(context.Background(), params)
+	require.NoError(t, err)
+	return res.Completion
+}
+
+func TestServer_CompleteIdentifiers(t *testing.T) {
+	session := connectClientWithOptions(t, newCompletionTestServer(t), nil)
+	require.NotNil(t, session.InitializeResult().Capabilities.Completions)
+
+	// Prefix matches come before substring and fuzzy matches.
+	got := complete(t, session, "tool_id", "git", nil)
+	require.Equal(t, []string{"github:create_issue", "github:list_issues", "gitlab:merge"}, got.Values)
+	require.Equal(t, 3, got.Total)
+
+	got = complete(t, session, "tool_id", "issue", nil)
+	require.Equal(t, []string{"github:create_issue", "github:list_issues"}, got.Values)
+
+	got = complete(t, session, "tool_id", "ghci", nil)
+	require.Equal(t, []string{"github:create_issue"}, got.Values)
+
+	require.Equal(t, []string{"gitlab"}, complete(t, session, "namespace", "gitl", nil).Values)
+	require.Equal(t, []string{"release", "triage"}, complete(t, session, "toolset_id", "", nil).Values)
+	require.Equal(t, []string{"triage_issue"}, complete(t, session, "skill_id", "tri", nil).Values)
+	require.Empty(t, complete(t, session, "unknown", "", nil).Values)
+}
+
+func TestServer_CompleteToolArgs(t *testing.T) {
+	session := connectClientWithOptions(t, newCompletionTestServer(t), nil)
+	selected := map[string]string{"tool_id": "github:create_issue"}
+
+	require.Equal(t, []string{"closed", "open"}, complete(t, session, "args.state", "", selected).Values)
+	require.Equal(t, []string{"true"}, complete(t, session, "args.draft", "t", selected).Values)
+	require.Equal(t, []string{"Crash on start"}, complete(t, session, "args.title", "", selected).Values)
+
+	// Nested arguments combine schema examples with documented examples.
+	require.Equal(t, []string{"acme", "octocat"}, complete(t, session, "args.repo.owner", "", selected).Values)
+
+	require.Empty(t, complete(t, session, "args.state", "", nil).Values)
+	require.Empty(t, complete(t, session, "args.state", "", map[string]string{"tool_id": "missing:tool"}).Values)
+}
+
+func TestServer_CompleteWithinActiveToolsets(t *testing.T) {
+	srv, _, _, _, _ := newDirectTestServer(t, []toolset.Spec{
+		{Name: "extra", AllowIDs: []string{"test:beta"}},
+	})
+	active, err := connectClient(t, srv, nil)
+	require.NoError(t, err)
+	other, err := connectClient(t, srv, nil)
+	require.NoError(t, err)
+	callStructured(t, active, "activate_toolset", map[string]any{"toolset_id": "extra"})
+
+	require.Equal(t, []string{"test:beta"}, complete(t, active, "tool_id", "test", nil).Values)
+	require.Equal(t, []string{"test"}, complete(t, active, "namespace", "", nil).Values)
+	require.Equal(t, []string{"test:alpha", "test:beta"}, complete(t, other, "tool_id", "test", nil).Values)
+
+	callStructured(t, active, "deactivate_toolset", map[string]any{"toolset_id": "extra"})
+	require.Equal(t, []string{"test:alpha", "test:beta"}, complete(t, active, "tool_id", "test", nil).Values)
+}
+
+func TestMatchCompletions_Limit(t *testing.T) {
+	candidates := make([]string, 0, maxCompletions+5)
+	for i := range maxCompletions + 5 {
+		candidates = append(candidates, string(rune('a'+i%26))+string(rune('a'+i/26)))
+	}
+	c := &completer{index: staticIndex(candidates), docs: &mockStore{}}
+	res, err := c.complete(context.Background(), &mcp.CompleteRequest{Params: &mcp.CompleteParams{
+		Argument: mcp.CompleteParamsArgument{Name: "tool_id"},
+	}})
+	require.NoError(t, err)
+	require.Len(t, res.Completion.Values, maxCompletions)
+	require.Equal(t, maxCompletions+5, res.Completion.Total)
+	require.True(t, res.Completion.HasMore)
+}
+
+// staticIndex lists fixed tool IDs on a single page.
+type staticIndex []string
+
+func (s staticIndex) SearchPage(context.Context, string, int, string) ([]metatools.ToolSummary, string, error) {
+	out := make([]metatools.ToolSummary, len(s))
+	for i, id := range s {
+		out[i] = metatools.ToolSummary{ID: id}
+	}
+	return out, "", nil
+}
+
+func (s staticIndex) ListNamespacesPage(context.Context, int, string) ([]string, string, error) {
+	return nil, "", nil
+}
+
+func (s staticIndex) GetAllBackends(context.Context, string) ([]model.ToolBackend, error) {
+	return nil, nil
+}
+
+var _ handlers.Index = staticIndex(nil)
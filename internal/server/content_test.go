@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jonwraymond/metatools-mcp/internal/adapters"
+	"github.com/jonwraymond/metatools-mcp/internal/config"
+	"github.com/jonwraymond/metatools-mcp/internal/content"
+	"github.com/jonwraymond/metatools-mcp/internal/skills"
+	"github.com/jonwraymond/metatools-mcp/internal/toolset"
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolexec/run"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// imageRunner answers every call like an MCP backend returning a JSON text
+// block and an image.
+type imageRunner struct {
+	echoRunner
+}
+
+func (imageRunner) Run(_ context.Context, toolID string, _ map[string]any) (run.RunResult, error) {
+	return run.RunResult{
+		Structured: map[string]any{"tool": toolID},
+		MCPResult: &mcp.CallToolResult{Content: []mcp.Content{
+			&mcp.TextContent{Text: `{"tool":"` + toolID + `"}`},
+			&mcp.ImageContent{Data: []byte("chart image"), MIMEType: "image/png"},
+		}},
+	}, nil
+}
+
+func newContentTestServer(t *testing.T, opts content.Options) *Server {
+	t.Helper()
+	srv, err := New(config.Config{
+		Index:        adapters.NewIndexAdapter(index.NewInMemoryIndex()),
+		Docs:         &mockStore{},
+		Runner:       adapters.NewRunnerAdapter(imageRunner{}, adapters.WithContent(content.NewCollector(opts))),
+		ContentStore: opts.Store,
+		Toolsets:     toolset.NewRegistry(nil),
+		Skills:       skills.NewRegistry(nil),
+		Providers:    config.DefaultAppConfig().Providers,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = srv.Close() })
+	return srv
+}
+
+func TestServer_RunToolPassesContent(t *testing.T) {
+	session := connectClientWithOptions(t, newContentTestServer(t, content.Options{}), nil)
+
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "run_tool",
+		Arguments: map[string]any{"tool_id": "charts:render"},
+	})
+	require.NoError(t, err)
+	require.False(t, res.IsError)
+	require.Equal(t, "charts:render", res.StructuredContent.(map[string]any)["structured"].(map[string]any)["tool"])
+
+	// The output's JSON, which lists the image too, comes first, followed by
+	// the upstream image.
+	require.Len(t, res.Content, 2)
+	require.JSONEq(t, `{"structured":{"tool":"charts:render"},"content":[{"type":"image","mimeType":"image/png","data":"Y2hhcnQgaW1hZ2U="}]}`, res.Content[0].(*mcp.TextContent).Text)
+	image := res.Content[1].(*mcp.ImageContent)
+	require.Equal(t, []byte("chart image"), image.Data)
+	require.Equal(t, "image/png", image.MIMEType)
+
+	chain := callChain(t, session, []any{map[string]any{"tool_id": "charts:render"}})
+	require.False(t, chain.IsError)
+	step := chain.StructuredContent.(map[string]any)["results"].([]any)[0].(map[string]any)
+	blocks := step["content"].([]any)
+	require.Len(t, blocks, 1)
+	require.Equal(t, "image", blocks[0].(map[string]any)["type"])
+}
+
+func TestServer_RunToolLinksLargeContent(t *testing.T) {
+	store := content.NewStore(0, 0)
+	session := connectClientWithOptions(t, newContentTestServer(t, content.Options{LinkLargerThan: 4, Store: store}), nil)
+
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "run_tool",
+		Arguments: map[string]any{"tool_id": "charts:render"},
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Content, 2)
+	link := res.Content[1].(*mcp.ResourceLink)
+	require.Equal(t, "image/png", link.MIMEType)
+
+	read, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: link.URI})
+	require.NoError(t, err)
+	require.Equal(t, []byte("chart image"), read.Contents[0].Blob)
+}
+
+func TestServer_AsyncRunToolKeepsContent(t *testing.T) {
+	session := connectClientWithOptions(t, newContentTestServer(t, content.Options{}), nil)
+
+	out := callStructured(t, session, "run_tool", map[string]any{"tool_id": "charts:render", "async": true})
+	jobID := out["job_id"].(string)
+	var job map[string]any
+	require.Eventually(t, func() bool {
+		job = callStructured(t, session, "get_job", map[string]any{"job_id": jobID})["job"].(map[string]any)
+		return job["status"] == "succeeded"
+	}, 2*time.Second, 5*time.Millisecond)
+
+	blocks := job["result"].(map[string]any)["content"].([]any)
+	require.Len(t, blocks, 1)
+	require.Equal(t, "image", blocks[0].(map[string]any)["type"])
+	require.Equal(t, "Y2hhcnQgaW1hZ2U=", blocks[0].(map[string]any)["data"])
+}
+
+func TestContentBlocksDecodesJSONOutput(t *testing.T) {
+	data, err := json.Marshal([]any{
+		&mcp.ImageContent{Data: []byte("chart image"), MIMEType: "image/png"},
+		&mcp.ResourceLink{URI: "metatools://content/1", Name: "chart", MIMEType: "image/png"},
+	})
+	require.NoError(t, err)
+	var blocks []any
+	require.NoError(t, json.Unmarshal(data, &blocks))
+
+	decoded := contentBlocks(blocks)
+	require.Len(t, decoded, 2)
+	require.Equal(t, []byte("chart image"), decoded[0].(*mcp.ImageContent).Data)
+	require.Equal(t, "metatools://content/1", decoded[1].(*mcp.ResourceLink).URI)
+}
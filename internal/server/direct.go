@@ -0,0 +1,347 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+
+	"github.com/jonwraymond/metatools-mcp/internal/scope"
+	"github.com/jonwraymond/metatools-mcp/internal/toolset"
+	"github.com/jonwraymond/metatools-mcp/pkg/metatools"
+	"github.com/jonwraymond/toolfoundation/adapter"
+	"github.com/jonwraymond/toolfoundation/model"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Session toolset selection. A client picks a toolset for its session with
+// the ToolsetHeader HTTP header or the ToolsetMetaKey key in the _meta of its
+// initialize request. The value is a toolset ID or name.
+const (
+	ToolsetHeader  = "X-Metatools-Toolset"
+	ToolsetMetaKey = "metatools/toolset"
+)
+
+type directTool struct {
+	toolID string
+	tool   *mcp.Tool
+}
+
+// syncDirectTools publishes the members of direct and both toolsets as MCP
+// tools, removing ones that left and re-adding ones whose definition changed.
+func (s *Server) syncDirectTools() {
+	if s.config.Toolsets == nil {
+		return
+	}
+	want := make(map[string]directTool)
+	for _, ts := range s.config.Toolsets.List() {
+		if !ts.Direct() {
+			continue
+		}
+		for _, ct := range ts.Tools {
+			if ct == nil {
+				continue
+			}
+			name := directToolName(ct.ID())
+			if _, ok := want[name]; ok {
+				continue
+			}
+			if _, ok := s.metatoolNames[name]; ok {
+				slog.Warn("direct tool name collides with a metatool; skipping", "tool_id", ct.ID(), "name", name)
+				continue
+			}
+			tool, err := directMCPTool(name, ct)
+			if err != nil {
+				slog.Warn("cannot publish direct tool", "tool_id", ct.ID(), "error", err)
+				continue
+			}
+			want[name] = directTool{toolID: ct.ID(), tool: tool}
+		}
+	}
+
+	s.publishMu.Lock()
+	defer s.publishMu.Unlock()
+	for name, dt := range want {
+		if _, ok := s.skillTools[name]; ok {
+			slog.Warn("direct tool name collides with a skill tool; skipping", "tool_id", dt.toolID, "name", name)
+			delete(want, name)
+		}
+	}
+	var removed []string
+	for name := range s.directTools {
+		if _, ok := want[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	if len(removed) > 0 {
+		s.mcp.RemoveTools(removed...)
+	}
+	for name, dt := range want {
+		if cur, ok := s.directTools[name]; ok && cur.toolID == dt.toolID && reflect.DeepEqual(cur.tool, dt.tool) {
+			continue
+		}
+		s.mcp.AddTool(dt.tool, s.directHandler(dt.toolID))
+	}
+	s.directTools = want
+}
+
+// directToolName maps a tool ID to a valid MCP tool name: the namespace
+// separator becomes "." and other disallowed characters become "_".
+func directToolName(toolID string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+			return r
+		case r == ':':
+			return '.'
+		default:
+			return '_'
+		}
+	}, toolID)
+}
+
+func directMCPTool(name string, ct *adapter.CanonicalTool) (*mcp.Tool, error) {
+	raw, err := adapter.NewMCPAdapter().FromCanonical(ct)
+	if err != nil {
+		return nil, err
+	}
+	mt, ok := raw.(*model.Tool)
+	if !ok {
+		return nil, fmt.Errorf("unexpected tool type %T", raw)
+	}
+	tool := mt.Tool
+	tool.Name = name
+	if !isObjectSchema(tool.InputSchema) {
+		tool.InputSchema = map[string]any{"type": "object"}
+	}
+	if tool.OutputSchema != nil && !isObjectSchema(tool.OutputSchema) {
+		// MCP only allows object output schemas.
+		tool.OutputSchema = nil
+	}
+	return &tool, nil
+}
+
+func isObjectSchema(schema any) bool {
+	m, ok := schema.(map[string]any)
+	return ok && m["type"] == "object"
+}
+
+// directHandler proxies a direct tool call to the run_tool provider for
+// toolID, so provider middleware applies as it does to run_tool.
+func (s *Server) directHandler(toolID string) mcp.ToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args map[string]any
+		if req.Params != nil && len(req.Params.Arguments) > 0 {
+			if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+				return nil, &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: fmt.Sprintf("invalid arguments: %v", err)}
+			}
+		}
+		res, raw, err := s.runTool.Handle(ctx, req, map[string]any{"tool_id": toolID, "args": args})
+		if err != nil {
+			return nil, err
+		}
+		var out metatools.RunToolOutput
+		if data, err := json.Marshal(raw); err == nil {
+			_ = json.Unmarshal(data, &out)
+		}
+		if (res != nil && res.IsError) || out.Error != nil {
+			msg := "tool call failed"
+			if out.Error != nil {
+				msg = fmt.Sprintf("%s: %s", out.Error.Code, out.Error.Message)
+			}
+			return &mcp.CallToolResult{IsError: true, Content: []mcp.Content{&mcp.TextContent{Text: msg}}}, nil
+		}
+		res, err = structuredResult(out.Structured)
+		if err != nil {
+			return nil, err
+		}
+		if t := out.Truncated; t != nil {
+			note := fmt.Sprintf("[result truncated from %d bytes", t.TotalBytes)
+			if t.ResultID != "" {
+				note += fmt.Sprintf("; read the full result with read_result and result_id %q", t.ResultID)
+			}
+			res.Content = append(res.Content, &mcp.TextContent{Text: note + "]"})
+		}
+		res.Content = append(res.Content, contentBlocks(out.Content)...)
+		return res, nil
+	}
+}
+
+// contentBlocks decodes content blocks from a tool output's JSON back into
+// MCP content. Blocks that do not decode are dropped.
+func contentBlocks(blocks []any) []mcp.Content {
+	if len(blocks) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(map[string]any{"content": blocks})
+	if err != nil {
+		return nil
+	}
+	var res mcp.CallToolResult
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil
+	}
+	return res.Content
+}
+
+func structuredResult(v any) (*mcp.CallToolResult, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshal tool result: %w", err)
+	}
+	res := &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: string(data)}}}
+	if len(data) > 0 && data[0] == '{' {
+		res.StructuredContent = json.RawMessage(data)
+	}
+	return res, nil
+}
+
+// sessionToolsets records the toolset each session selected at initialize,
+// limits tools/list and tools/call to the session's view and gives tool calls
+// and completions the session's Scope.
+func (s *Server) sessionToolsets(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		switch method {
+		case "initialize":
+			id, err := s.requestedToolset(req)
+			if err != nil {
+				return nil, err
+			}
+			res, err := next(ctx, method, req)
+			if err != nil || id == "" {
+				return res, err
+			}
+			if ss, ok := req.GetSession().(*mcp.ServerSession); ok {
+				s.sessions.Store(ss, id)
+				go func() {
+					_ = ss.Wait()
+					s.sessions.Delete(ss)
+				}()
+			}
+			return res, nil
+		case "tools/list":
+			res, err := next(ctx, method, req)
+			if err != nil {
+				return res, err
+			}
+			if list, ok := res.(*mcp.ListToolsResult); ok {
+				view := s.sessionView(req)
+				visible := make([]*mcp.Tool, 0, len(list.Tools))
+				for _, tool := range list.Tools {
+					if s.visible(view, tool.Name) {
+						visible = append(visible, tool)
+					}
+				}
+				list.Tools = visible
+			}
+			return res, nil
+		case "tools/call":
+			if params, ok := req.GetParams().(*mcp.CallToolParamsRaw); ok && !s.visible(s.sessionView(req), params.Name) {
+				return nil, &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: fmt.Sprintf("unknown tool %q", params.Name)}
+			}
+			if ss, ok := req.GetSession().(*mcp.ServerSession); ok && s.config.Toolsets != nil {
+				ctx = scope.WithScope(ctx, s.sessionScope(ss))
+			}
+		case "completion/complete":
+			ctx = scope.WithScope(ctx, s.loadScope(req))
+		}
+		return next(ctx, method, req)
+	}
+}
+
+// requestedToolset returns the toolset ID selected by an initialize request,
+// or "" when none was selected.
+func (s *Server) requestedToolset(req mcp.Request) (string, error) {
+	var raw string
+	if extra := req.GetExtra(); extra != nil && extra.Header != nil {
+		raw = extra.Header.Get(ToolsetHeader)
+	}
+	if params, ok := req.GetParams().(*mcp.InitializeParams); ok && params != nil {
+		if v, ok := params.Meta[ToolsetMetaKey].(string); ok && v != "" {
+			raw = v
+		}
+	}
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+	if s.config.Toolsets != nil {
+		for _, id := range []string{raw, "toolset:" + raw} {
+			if _, ok := s.config.Toolsets.Get(id); ok {
+				return id, nil
+			}
+		}
+		for _, ts := range s.config.Toolsets.List() {
+			if ts.Name == raw {
+				return ts.ID, nil
+			}
+		}
+	}
+	return "", &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: fmt.Sprintf("unknown toolset %q", raw)}
+}
+
+// sessionToolset returns the toolset selected by the request's session. A
+// selected toolset that no longer exists yields nil, which shows only the
+// metatools.
+func (s *Server) sessionToolset(req mcp.Request) (*toolset.Toolset, bool) {
+	ss, ok := req.GetSession().(*mcp.ServerSession)
+	if !ok {
+		return nil, false
+	}
+	id, ok := s.sessions.Load(ss)
+	if !ok {
+		return nil, false
+	}
+	ts, _ := s.config.Toolsets.Get(id.(string))
+	return ts, true
+}
+
+// sessionView is what decides which published tools a session sees: the
+// toolset it selected at initialize, if any, and its Scope.
+type sessionView struct {
+	toolset  *toolset.Toolset
+	selected bool
+	scope    *scope.Scope
+}
+
+func (s *Server) sessionView(req mcp.Request) sessionView {
+	ts, selected := s.sessionToolset(req)
+	return sessionView{toolset: ts, selected: selected, scope: s.loadScope(req)}
+}
+
+// visible reports whether a tool is part of a session's view. Sessions that
+// selected no toolset see every tool except the members of other sessions'
+// directly activated toolsets. Skill tools are visible to sessions that
+// selected the skill's toolset.
+func (s *Server) visible(view sessionView, name string) bool {
+	ts := view.toolset
+	if _, ok := s.metatoolNames[name]; ok {
+		return !view.selected || ts == nil || ts.Metatools()
+	}
+	s.publishMu.Lock()
+	dt, isDirect := s.directTools[name]
+	st, isSkill := s.skillTools[name]
+	at, isActive := s.activeTools[name]
+	s.publishMu.Unlock()
+	switch {
+	case isSkill:
+		return !view.selected || (ts != nil && st.toolsetID == ts.ID)
+	case isActive:
+		return view.scope.Publishes(at.toolID)
+	case !isDirect:
+		return !view.selected
+	case !view.selected || view.scope.Publishes(dt.toolID):
+		return true
+	case ts == nil || !ts.Direct():
+		return false
+	}
+	for _, tool := range ts.Tools {
+		if tool != nil && tool.ID() == dt.toolID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+package server
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jonwraymond/metatools-mcp/internal/adapters"
+	"github.com/jonwraymond/metatools-mcp/internal/config"
+	"github.com/jonwraymond/metatools-mcp/internal/handlers"
+	"github.com/jonwraymond/metatools-mcp/internal/skills"
+	"github.com/jonwraymond/metatools-mcp/internal/toolset"
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolfoundation/model"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingRunner struct {
+	mu    sync.Mutex
+	calls []string
+	args  []map[string]any
+}
+
+func (r *recordingRunner) Run(_ context.Context, toolID string, args map[string]any) (handlers.RunResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, toolID)
+	r.args = append(r.args, args)
+	return handlers.RunResult{Structured: map[string]any{"tool": toolID}}, nil
+}
+
+func (r *recordingRunner) RunChain(_ context.Context, _ []handlers.ChainStep) (handlers.RunResult, []handlers.StepResult, error) {
+	return handlers.RunResult{}, nil, nil
+}
+
+// swapRegistry is a toolset registry whose contents can be replaced.
+type swapRegistry struct {
+	reg atomic.Pointer[toolset.Registry]
+}
+
+func (s *swapRegistry) List() []*toolset.Toolset { return s.reg.Load().List() }
+
+func (s *swapRegistry) Get(id string) (*toolset.Toolset, bool) { return s.reg.Load().Get(id) }
+
+func newDirectTestServer(t *testing.T, specs []toolset.Spec) (*Server, *recordingRunner, *swapRegistry, *stubReloader, index.Index) {
+	t.Helper()
+	idx := index.NewInMemoryIndex()
+	for _, name := range []string{"alpha", "beta"} {
+		require.NoError(t, idx.RegisterTool(model.Tool{
+			Namespace: "test",
+			Tool: mcp.Tool{
+				Name:         name,
+				Description:  name + " tool",
+				InputSchema:  map[string]any{"type": "object", "properties": map[string]any{"q": map[string]any{"type": "string"}}},
+				OutputSchema: map[string]any{"type": "object"},
+			},
+		}, model.ToolBackend{Kind: model.BackendKindLocal, Local: &model.LocalBackend{Name: name}}))
+	}
+	reg, err := toolset.BuildRegistry(idx, specs)
+	require.NoError(t, err)
+	toolsets := &swapRegistry{}
+	toolsets.reg.Store(reg)
+
+	runner := &recordingRunner{}
+	reloader := &stubReloader{}
+	srv, err := New(config.Config{
+		Index:     adapters.NewIndexAdapter(idx),
+		Docs:      &mockStore{},
+		Runner:    runner,
+		Toolsets:  toolsets,
+		Skills:    skills.NewRegistry(nil),
+		Providers: config.DefaultAppConfig().Providers,
+		Reloaders: []config.ReloadNotifier{reloader},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = srv.Close() })
+	return srv, runner, toolsets, reloader, idx
+}
+
+func connectClient(t *testing.T, srv *Server, meta mcp.Meta) (*mcp.ClientSession, error) {
+	t.Helper()
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := srv.MCPServer().Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "metatools-test-client"}, nil)
+	if meta != nil {
+		client.AddSendingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+			return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+				if params, ok := req.GetParams().(*mcp.InitializeParams); ok {
+					params.Meta = meta
+				}
+				return next(ctx, method, req)
+			}
+		})
+	}
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err == nil {
+		t.Cleanup(func() { _ = session.Close() })
+	}
+	return session, err
+}
+
+func toolNames(t *testing.T, session *mcp.ClientSession) []string {
+	t.Helper()
+	var names []string
+	for tool, err := range session.Tools(context.Background(), nil) {
+		require.NoError(t, err)
+		names = append(names, tool.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestServer_DirectToolsetExposure(t *testing.T) {
+	srv, runner, _, _, _ := newDirectTestServer(t, []toolset.Spec{
+		{Name: "core", AllowIDs: []string{"test:alpha"}, Exposure: toolset.ExposureDirect},
+		{Name: "hidden", AllowIDs: []string{"test:beta"}},
+	})
+	session, err := connectClient(t, srv, nil)
+	require.NoError(t, err)
+
+	names := toolNames(t, session)
+	assert.Contains(t, names, "test.alpha")
+	assert.Contains(t, names, "search_tools")
+	assert.NotContains(t, names, "test.beta", "metatools exposure keeps members behind the metatools")
+
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "test.alpha",
+		Arguments: map[string]any{"q": "hi"},
+	})
+	require.NoError(t, err)
+	require.False(t, res.IsError)
+	assert.Equal(t, map[string]any{"tool": "test:alpha"}, res.StructuredContent)
+	assert.Equal(t, []string{"test:alpha"}, runner.calls)
+	assert.Equal(t, map[string]any{"q": "hi"}, runner.args[0])
+}
+
+func TestServer_SessionToolsetSelection(t *testing.T) {
+	srv, _, _, _, _ := newDirectTestServer(t, []toolset.Spec{
+		{Name: "core", AllowIDs: []string{"test:alpha"}, Exposure: toolset.ExposureDirect},
+		{Name: "mixed", AllowIDs: []string{"test:beta"}, Exposure: toolset.ExposureBoth},
+		{Name: "hidden"},
+	})
+
+	direct, err := connectClient(t, srv, mcp.Meta{ToolsetMetaKey: "core"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"test.alpha"}, toolNames(t, direct))
+	_, err = direct.CallTool(context.Background(), &mcp.CallToolParams{Name: "search_tools"})
+	require.ErrorContains(t, err, "unknown tool")
+
+	mixed, err := connectClient(t, srv, mcp.Meta{ToolsetMetaKey: "toolset:mixed"})
+	require.NoError(t, err)
+	names := toolNames(t, mixed)
+	assert.Contains(t, names, "test.beta")
+	assert.Contains(t, names, "search_tools")
+	assert.NotContains(t, names, "test.alpha")
+
+	hidden, err := connectClient(t, srv, mcp.Meta{ToolsetMetaKey: "hidden"})
+	require.NoError(t, err)
+	names = toolNames(t, hidden)
+	assert.Contains(t, names, "search_tools")
+	assert.NotContains(t, names, "test.alpha")
+
+	_, err = connectClient(t, srv, mcp.Meta{ToolsetMetaKey: "missing"})
+	require.ErrorContains(t, err, "unknown toolset")
+}
+
+func TestServer_DirectToolsFollowReloads(t *testing.T) {
+	srv, _, toolsets, reloader, idx := newDirectTestServer(t, []toolset.Spec{
+		{Name: "core", AllowIDs: []string{"test:alpha"}, Exposure: toolset.ExposureBoth},
+	})
+	session, err := connectClient(t, srv, nil)
+	require.NoError(t, err)
+	require.Contains(t, toolNames(t, session), "test.alpha")
+
+	reg, err := toolset.BuildRegistry(idx, []toolset.Spec{
+		{Name: "core", AllowIDs: []string{"test:beta"}, Exposure: toolset.ExposureBoth},
+	})
+	require.NoError(t, err)
+	toolsets.reg.Store(reg)
+	reloader.reload()
+
+	names := toolNames(t, session)
+	assert.Contains(t, names, "test.beta")
+	assert.NotContains(t, names, "test.alpha")
+}
+
+func TestDirectToolName(t *testing.T) {
+	assert.Equal(t, "github.create_issue", directToolName("github:create_issue"))
+	assert.Equal(t, "ping", directToolName("ping"))
+	assert.Equal(t, "ns.a_b", directToolName("ns:a b"))
+}
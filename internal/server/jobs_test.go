@@ -0,0 +1,36 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_JobsBelongToTheirSession(t *testing.T) {
+	srv, _, _, _, _ := newDirectTestServer(t, nil)
+	owner, err := connectClient(t, srv, nil)
+	require.NoError(t, err)
+	other, err := connectClient(t, srv, nil)
+	require.NoError(t, err)
+
+	out := callStructured(t, owner, "run_tool", map[string]any{"tool_id": "test:alpha", "async": true})
+	jobID, ok := out["job_id"].(string)
+	require.True(t, ok, "run_tool returned %v", out)
+
+	listed := callStructured(t, owner, "list_jobs", map[string]any{})
+	require.Len(t, listed["jobs"], 1)
+
+	// Without auth, another session can neither see nor cancel the job.
+	listed = callStructured(t, other, "list_jobs", map[string]any{})
+	require.Empty(t, listed["jobs"])
+	for _, name := range []string{"get_job", "cancel_job"} {
+		res, err := other.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: map[string]any{"job_id": jobID}})
+		require.NoError(t, err)
+		require.True(t, res.IsError, "%s of another session's job succeeded", name)
+	}
+
+	got := callStructured(t, owner, "get_job", map[string]any{"job_id": jobID})
+	require.Equal(t, jobID, got["job"].(map[string]any)["job_id"])
+}
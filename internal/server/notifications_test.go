@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -18,7 +19,7 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func newTestServerWithIndex(t *testing.T, idx index.Index, notify bool, debounceMs int) *Server {
+func newTestServerWithIndex(t *testing.T, idx index.Index, notify bool, debounceMs int, reloaders ...config.ReloadNotifier) *Server {
 	t.Helper()
 
 	toolsets := toolset.NewRegistry(nil)
@@ -38,6 +39,7 @@ func newTestServerWithIndex(t *testing.T, idx index.Index, notify bool, debounce
 		Providers:                       config.DefaultAppConfig().Providers,
 		NotifyToolListChanged:           notify,
 		NotifyToolListChangedDebounceMs: debounceMs,
+		Reloaders:                       reloaders,
 	}
 
 	srv, err := New(cfg)
@@ -218,3 +220,82 @@ func TestServer_ToolListChangedNotification_Disabled(t *testing.T) {
 	case <-time.After(200 * time.Millisecond):
 	}
 }
+
+type stubReloader struct {
+	mu   sync.Mutex
+	fns  map[int]func()
+	next int
+}
+
+func (r *stubReloader) OnReload(fn func()) func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.fns == nil {
+		r.fns = make(map[int]func())
+	}
+	id := r.next
+	r.next++
+	r.fns[id] = fn
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.fns, id)
+	}
+}
+
+func (r *stubReloader) reload() {
+	r.mu.Lock()
+	fns := make([]func(), 0, len(r.fns))
+	for _, fn := range r.fns {
+		fns = append(fns, fn)
+	}
+	r.mu.Unlock()
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+func (r *stubReloader) subscribers() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.fns)
+}
+
+func TestServer_ToolListChangedNotification_Reload(t *testing.T) {
+	reloader := &stubReloader{}
+	srv := newTestServerWithIndex(t, index.NewInMemoryIndex(), true, 30, reloader)
+	require.NotZero(t, reloader.subscribers())
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := srv.MCPServer().Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, serverSession.Close())
+	}()
+
+	notifyCh := make(chan struct{}, 1)
+	client := mcp.NewClient(&mcp.Implementation{Name: "metatools-test-client"}, &mcp.ClientOptions{
+		ToolListChangedHandler: func(_ context.Context, _ *mcp.ToolListChangedRequest) {
+			notifyCh <- struct{}{}
+		},
+	})
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, clientSession.Close())
+	}()
+
+	drainNotifications(notifyCh, 50*time.Millisecond)
+
+	reloader.reload()
+
+	select {
+	case <-notifyCh:
+	case <-time.After(250 * time.Millisecond):
+		t.Fatal("expected tool list changed notification after reload")
+	}
+
+	require.NoError(t, srv.Close())
+	assert.Zero(t, reloader.subscribers(), "Close unsubscribes reloaders")
+}
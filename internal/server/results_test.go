@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jonwraymond/metatools-mcp/internal/adapters"
+	"github.com/jonwraymond/metatools-mcp/internal/config"
+	"github.com/jonwraymond/metatools-mcp/internal/results"
+	"github.com/jonwraymond/metatools-mcp/internal/skills"
+	"github.com/jonwraymond/metatools-mcp/internal/toolset"
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolexec/run"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// rowsRunner answers every call with a result of a thousand rows.
+type rowsRunner struct {
+	echoRunner
+}
+
+func (rowsRunner) Run(context.Context, string, map[string]any) (run.RunResult, error) {
+	rows := make([]any, 1000)
+	for i := range rows {
+		rows[i] = map[string]any{"n": i}
+	}
+	return run.RunResult{Structured: map[string]any{"rows": rows}}, nil
+}
+
+func TestServer_ReadTruncatedResult(t *testing.T) {
+	srv, err := New(config.Config{
+		Index:     adapters.NewIndexAdapter(index.NewInMemoryIndex()),
+		Docs:      &mockStore{},
+		Runner:    adapters.NewRunnerAdapter(rowsRunner{}),
+		Toolsets:  toolset.NewRegistry(nil),
+		Skills:    skills.NewRegistry(nil),
+		Results:   results.Options{MaxBytes: 1024},
+		Providers: config.DefaultAppConfig().Providers,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = srv.Close() })
+	session := connectClientWithOptions(t, srv, nil)
+	other := connectClientWithOptions(t, srv, nil)
+
+	out := callStructured(t, session, "run_tool", map[string]any{"tool_id": "db:query"})
+	truncated := out["truncated"].(map[string]any)
+	require.Equal(t, float64(1000), truncated["arrays"].(map[string]any)["$.rows"])
+	id := truncated["result_id"].(string)
+
+	read := callStructured(t, session, "read_result", map[string]any{"result_id": id, "path": "$.rows[999]"})
+	require.Equal(t, map[string]any{"n": float64(999)}, read["value"])
+
+	// Results belong to the session that ran the tool.
+	res, err := other.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "read_result",
+		Arguments: map[string]any{"result_id": id},
+	})
+	require.NoError(t, err)
+	require.True(t, res.IsError)
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jonwraymond/metatools-mcp/internal/adapters"
+	"github.com/jonwraymond/metatools-mcp/internal/config"
+	"github.com/jonwraymond/metatools-mcp/internal/roots"
+	"github.com/jonwraymond/metatools-mcp/internal/skills"
+	"github.com/jonwraymond/metatools-mcp/internal/toolset"
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolexec/run"
+	"github.com/jonwraymond/toolfoundation/model"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// rootsRunner answers every call with the roots on its context.
+type rootsRunner struct {
+	echoRunner
+}
+
+func (rootsRunner) Run(ctx context.Context, _ string, _ map[string]any) (run.RunResult, error) {
+	list, ok := roots.FromContext(ctx)
+	uris := make([]string, 0, len(list))
+	for _, r := range list {
+		uris = append(uris, r.URI)
+	}
+	return run.RunResult{Structured: map[string]any{"known": ok, "roots": strings.Join(uris, ",")}}, nil
+}
+
+// fsTools resolves every tool as one tagged fs.
+type fsTools struct{}
+
+func (fsTools) GetTool(_ context.Context, id string) (model.Tool, error) {
+	return model.Tool{Tool: mcp.Tool{Name: id}, Tags: []string{"fs"}}, nil
+}
+
+func TestServer_ClientRoots(t *testing.T) {
+	runner := roots.WrapRunner(rootsRunner{}, roots.NewGuard(roots.GuardOptions{Tools: fsTools{}}))
+	srv, err := New(config.Config{
+		Index:     adapters.NewIndexAdapter(index.NewInMemoryIndex()),
+		Docs:      &mockStore{},
+		Runner:    adapters.NewRunnerAdapter(runner),
+		Toolsets:  toolset.NewRegistry(nil),
+		Skills:    skills.NewRegistry(nil),
+		Roots:     roots.NewTracker(),
+		Providers: config.DefaultAppConfig().Providers,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = srv.Close() })
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := srv.MCPServer().Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+	client := mcp.NewClient(&mcp.Implementation{Name: "metatools-test-client"}, nil)
+	client.AddRoots(&mcp.Root{URI: "file:///work/repo"})
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = session.Close() })
+
+	out := callStructured(t, session, "run_tool", map[string]any{"tool_id": "fs:read", "args": map[string]any{"path": "/work/repo/a"}})
+	require.Equal(t, map[string]any{"known": true, "roots": "file:///work/repo"}, out["structured"])
+
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "run_tool",
+		Arguments: map[string]any{"tool_id": "fs:read", "args": map[string]any{"path": "/etc/passwd"}},
+	})
+	require.NoError(t, err)
+	require.True(t, res.IsError)
+	require.Equal(t, "path_outside_roots", errorCode(t, res))
+
+	// The client's change notification refreshes its roots.
+	client.AddRoots(&mcp.Root{URI: "file:///work/other"})
+	require.Eventually(t, func() bool {
+		out := callStructured(t, session, "run_tool", map[string]any{"tool_id": "fs:read"})
+		return out["structured"].(map[string]any)["roots"] == "file:///work/other,file:///work/repo"
+	}, 2*time.Second, 20*time.Millisecond)
+}
+
+func TestServer_ClientWithoutRootsIsNotAsked(t *testing.T) {
+	srv, err := New(config.Config{
+		Index:     adapters.NewIndexAdapter(index.NewInMemoryIndex()),
+		Docs:      &mockStore{},
+		Runner:    adapters.NewRunnerAdapter(rootsRunner{}),
+		Toolsets:  toolset.NewRegistry(nil),
+		Skills:    skills.NewRegistry(nil),
+		Roots:     roots.NewTracker(),
+		Providers: config.DefaultAppConfig().Providers,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = srv.Close() })
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := srv.MCPServer().Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+	// Explicit capabilities without roots replace the SDK's default roots
+	// capability.
+	client := mcp.NewClient(&mcp.Implementation{Name: "metatools-test-client"}, &mcp.ClientOptions{
+		Capabilities: &mcp.ClientCapabilities{},
+	})
+	var asked atomic.Bool
+	client.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method == "roots/list" {
+				asked.Store(true)
+			}
+			return next(ctx, method, req)
+		}
+	})
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = session.Close() })
+
+	out := callStructured(t, session, "run_tool", map[string]any{"tool_id": "fs:read"})
+	require.Equal(t, map[string]any{"known": false, "roots": ""}, out["structured"])
+	require.Never(t, asked.Load, 200*time.Millisecond, 20*time.Millisecond)
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jonwraymond/metatools-mcp/internal/adapters"
+	"github.com/jonwraymond/metatools-mcp/internal/config"
+	"github.com/jonwraymond/metatools-mcp/internal/sampling"
+	"github.com/jonwraymond/metatools-mcp/internal/skills"
+	"github.com/jonwraymond/metatools-mcp/internal/toolset"
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func newSamplingTestServer(t *testing.T) *Server {
+	t.Helper()
+	idx := index.NewInMemoryIndex()
+	require.NoError(t, sampling.Register(idx))
+	runner := sampling.WrapRunner(echoRunner{}, sampling.NewSampler(sampling.Options{MaxCallsPerRun: 1}))
+	srv, err := New(config.Config{
+		Index:     adapters.NewIndexAdapter(idx),
+		Docs:      &mockStore{},
+		Runner:    adapters.NewRunnerAdapter(runner),
+		Toolsets:  toolset.NewRegistry(nil),
+		Skills:    skills.NewRegistry(nil),
+		Providers: config.DefaultAppConfig().Providers,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = srv.Close() })
+	return srv
+}
+
+func callChain(t *testing.T, session *mcp.ClientSession, steps []any) *mcp.CallToolResult {
+	t.Helper()
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "run_chain",
+		Arguments: map[string]any{"steps": steps},
+	})
+	require.NoError(t, err)
+	return res
+}
+
+func TestServer_SampleViaClient(t *testing.T) {
+	var prompts []string
+	session := connectClientWithOptions(t, newSamplingTestServer(t), &mcp.ClientOptions{
+		CreateMessageHandler: func(_ context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+			prompts = append(prompts, req.Params.Messages[0].Content.(*mcp.TextContent).Text)
+			return &mcp.CreateMessageResult{Content: &mcp.TextContent{Text: "positive"}, Model: "m", Role: "assistant"}, nil
+		},
+	})
+
+	res := callChain(t, session, []any{
+		map[string]any{"id": "fetch", "tool_id": "test:fetch"},
+		map[string]any{"tool_id": sampling.ToolID, "args": map[string]any{"prompt": "Classify ${steps.fetch.result.tool}"}},
+	})
+	require.False(t, res.IsError)
+	require.Equal(t, []string{"Classify test:fetch"}, prompts)
+	structured := res.StructuredContent.(map[string]any)
+	require.Equal(t, "positive", structured["final"].(map[string]any)["text"])
+
+	// The sampling budget is per metatool call.
+	res = callChain(t, session, []any{
+		map[string]any{"tool_id": sampling.ToolID, "args": map[string]any{"prompt": "one"}},
+		map[string]any{"tool_id": sampling.ToolID, "args": map[string]any{"prompt": "two"}},
+	})
+	require.True(t, res.IsError)
+	require.Len(t, prompts, 2)
+}
+
+func TestServer_SampleWithoutClientSupport(t *testing.T) {
+	session := connectClientWithOptions(t, newSamplingTestServer(t), nil)
+
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "run_tool",
+		Arguments: map[string]any{"tool_id": sampling.ToolID, "args": map[string]any{"prompt": "hi"}},
+	})
+	require.NoError(t, err)
+	require.True(t, res.IsError)
+	require.Equal(t, "sampling_unavailable", errorCode(t, res))
+}
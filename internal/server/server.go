@@ -7,7 +7,11 @@ import (
 
 	"github.com/jonwraymond/metatools-mcp/internal/config"
 	"github.com/jonwraymond/metatools-mcp/internal/handlers"
+	"github.com/jonwraymond/metatools-mcp/internal/jobs"
+	"github.com/jonwraymond/metatools-mcp/internal/middleware"
+	"github.com/jonwraymond/metatools-mcp/internal/provider"
 	"github.com/jonwraymond/metatools-mcp/internal/provider/builtin"
+	"github.com/jonwraymond/metatools-mcp/internal/results"
 	"github.com/jonwraymond/tooldiscovery/index"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -35,6 +39,19 @@ type Server struct {
 	toolListMu        sync.Mutex
 	toolListTimer     *time.Timer
 	toolListUnsub     func()
+
+	runTool       provider.ToolProvider
+	middleware    *middleware.Chain
+	metatoolNames map[string]struct{}
+	publishMu     sync.Mutex // guards directTools, skillTools and activeTools
+	directTools   map[string]directTool
+	skillTools    map[string]skillTool
+	activeTools   map[string]directTool
+	publishUnsub  []func()
+	sessions      sync.Map // *mcp.ServerSession -> selected toolset ID
+	scopes        sync.Map // *mcp.ServerSession -> *scope.Scope
+	jobs          *jobs.Manager
+	results       *results.Store
 }
 
 // Handlers holds all the metatool handlers.
@@ -49,6 +66,9 @@ type Handlers struct {
 	Code       *handlers.CodeHandler
 	Toolsets   *handlers.ToolsetsHandler
 	Skills     *handlers.SkillsHandler
+	Runs       *handlers.RunsHandler
+	Jobs       *handlers.JobsHandler
+	Results    *handlers.ResultsHandler
 }
 
 // New creates a new metatools server.
@@ -67,32 +87,58 @@ func New(cfg config.Config) (*Server, error) {
 		listToolsHandler = handlers.NewListToolsHandler(cfg.Index)
 	}
 
+	// Dry runs and idempotency keys resolve tools through the index when it
+	// supports lookups.
+	tools, _ := cfg.Index.(handlers.ToolLookup)
+
+	jobManager := jobs.NewManager(cfg.Jobs)
+	resultStore := results.NewStore(cfg.Results)
+
+	var recorder *handlers.RunRecorder
+	if cfg.Runs != nil {
+		recorder = handlers.NewRunRecorder(cfg.Runs, cfg.RunRetention, tools)
+	}
+
 	h := &Handlers{
 		Search:     searchHandler,
 		ListTools:  listToolsHandler,
 		Namespaces: handlers.NewNamespacesHandler(cfg.Index),
 		Describe:   handlers.NewDescribeHandler(cfg.Docs),
 		Examples:   handlers.NewExamplesHandler(cfg.Docs),
-		Run:        handlers.NewRunHandler(cfg.Runner),
-		Chain:      handlers.NewChainHandler(cfg.Runner),
+		Run:        handlers.NewRunHandler(cfg.Runner, handlers.WithJobs(jobManager), handlers.WithResults(resultStore)),
+		Chain:      handlers.NewChainHandler(cfg.Runner, handlers.WithTools(tools), handlers.WithRuns(recorder), handlers.WithJobs(jobManager)),
+		Jobs:       handlers.NewJobsHandler(jobManager),
+		Results:    handlers.NewResultsHandler(resultStore),
 	}
 	if cfg.Executor != nil {
-		h.Code = handlers.NewCodeHandler(cfg.Executor)
+		h.Code = handlers.NewCodeHandler(cfg.Executor, handlers.WithJobs(jobManager))
 	}
 	if cfg.Toolsets != nil {
 		h.Toolsets = handlers.NewToolsetsHandler(cfg.Toolsets)
 	}
 	if cfg.Skills != nil {
-		h.Skills = handlers.NewSkillsHandler(cfg.Skills, cfg.Toolsets, cfg.Runner, cfg.SkillDefaults)
+		h.Skills = handlers.NewSkillsHandler(cfg.Skills, cfg.Toolsets, cfg.Runner, cfg.SkillDefaults, handlers.WithTools(tools), handlers.WithRuns(recorder))
+	}
+	if recorder != nil {
+		h.Runs = handlers.NewRunsHandler(recorder, h.Chain, h.Skills)
 	}
 
+	completions := &completer{
+		index:    cfg.Index,
+		tools:    tools,
+		docs:     cfg.Docs,
+		toolsets: cfg.Toolsets,
+		skills:   cfg.Skills,
+	}
 	serverOptions := &mcp.ServerOptions{
-		PageSize: defaultPageSize,
+		PageSize:          defaultPageSize,
+		CompletionHandler: completions.complete,
 	}
 	if !cfg.NotifyToolListChanged {
 		serverOptions.Capabilities = &mcp.ServerCapabilities{
-			Logging: &mcp.LoggingCapabilities{},
-			Tools:   &mcp.ToolCapabilities{},
+			Completions: &mcp.CompletionCapabilities{},
+			Logging:     &mcp.LoggingCapabilities{},
+			Tools:       &mcp.ToolCapabilities{},
 		}
 	}
 
@@ -101,10 +147,16 @@ func New(cfg config.Config) (*Server, error) {
 		Version: implementationVersion,
 	}, serverOptions)
 
+	if cfg.ContentStore != nil {
+		mcpServer.AddResourceTemplate(cfg.ContentStore.Template(), cfg.ContentStore.Read)
+	}
+
 	srv := &Server{
 		config:   cfg,
 		mcp:      mcpServer,
 		handlers: h,
+		jobs:     jobManager,
+		results:  resultStore,
 	}
 	registry := cfg.ProviderRegistry
 	if registry == nil {
@@ -119,6 +171,9 @@ func New(cfg config.Config) (*Server, error) {
 			Code:       h.Code,
 			Toolsets:   h.Toolsets,
 			Skills:     h.Skills,
+			Runs:       h.Runs,
+			Jobs:       h.Jobs,
+			Results:    h.Results,
 		}, builtin.RegistryOptions{Providers: cfg.Providers})
 		if err != nil {
 			return nil, err
@@ -132,10 +187,17 @@ func New(cfg config.Config) (*Server, error) {
 	if err := mwAdapter.ApplyToProviders(registry); err != nil {
 		return nil, err
 	}
+	srv.middleware = mwAdapter.Chain()
+	srv.runTool = builtin.NewRunToolProvider(h.Run, true)
+	if p, ok := registry.Get("run_tool"); ok {
+		srv.runTool = p
+	}
 	adapter := NewProviderAdapter(registry)
 	if err := adapter.RegisterTools(srv); err != nil {
 		return nil, err
 	}
+	srv.registerPublishedTools()
+	srv.registerClientSessions()
 	srv.registerToolListNotifications()
 	return srv, nil
 }
@@ -175,20 +237,36 @@ func registerTool[In, Out any](s *Server, tool *mcp.Tool, handler mcp.ToolHandle
 	})
 }
 
+// registerPublishedTools publishes direct toolset members and skill tools,
+// keeps them in sync with reloads, and installs per-session toolset
+// selection.
+func (s *Server) registerPublishedTools() {
+	s.metatoolNames = make(map[string]struct{}, len(s.tools))
+	for _, tool := range s.tools {
+		s.metatoolNames[tool.Name] = struct{}{}
+	}
+	s.mcp.AddReceivingMiddleware(s.sessionToolsets)
+	s.syncPublishedTools()
+	for _, r := range s.config.Reloaders {
+		s.publishUnsub = append(s.publishUnsub, r.OnReload(s.syncPublishedTools))
+	}
+}
+
+func (s *Server) syncPublishedTools() {
+	s.syncDirectTools()
+	s.syncSkillTools()
+	s.syncActiveTools()
+}
+
 func (s *Server) registerToolListNotifications() {
 	if !s.config.NotifyToolListChanged {
 		return
 	}
-	changeNotifier, ok := s.config.Index.(index.ChangeNotifier)
-	if !ok {
-		return
-	}
 	debounce := time.Duration(s.config.NotifyToolListChangedDebounceMs) * time.Millisecond
 	if debounce <= 0 {
 		debounce = 150 * time.Millisecond
 	}
-
-	s.toolListUnsub = changeNotifier.OnChange(func(_ index.ChangeEvent) {
+	changed := func() {
 		s.toolListMu.Lock()
 		defer s.toolListMu.Unlock()
 		if s.toolListTimer == nil {
@@ -196,7 +274,23 @@ func (s *Server) registerToolListNotifications() {
 			return
 		}
 		s.toolListTimer.Reset(debounce)
-	})
+	}
+
+	var unsubs []func()
+	if changeNotifier, ok := s.config.Index.(index.ChangeNotifier); ok {
+		unsubs = append(unsubs, changeNotifier.OnChange(func(_ index.ChangeEvent) { changed() }))
+	}
+	for _, r := range s.config.Reloaders {
+		unsubs = append(unsubs, r.OnReload(changed))
+	}
+	if len(unsubs) == 0 {
+		return
+	}
+	s.toolListUnsub = func() {
+		for _, unsub := range unsubs {
+			unsub()
+		}
+	}
 }
 
 func (s *Server) reregisterTools() {
@@ -220,5 +314,9 @@ func (s *Server) Close() error {
 		s.toolListUnsub()
 		s.toolListUnsub = nil
 	}
-	return nil
+	for _, unsub := range s.publishUnsub {
+		unsub()
+	}
+	s.publishUnsub = nil
+	return s.jobs.Close()
 }
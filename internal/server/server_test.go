@@ -80,7 +80,7 @@ func TestNewServer_RegistersAllTools(t *testing.T) {
 
 	// Verify all tools are registered
 	tools := srv.ListTools()
-	assert.Len(t, tools, 14)
+	assert.Len(t, tools, 20)
 
 	// Verify tool names
 	toolNames := make(map[string]bool)
@@ -98,10 +98,16 @@ func TestNewServer_RegistersAllTools(t *testing.T) {
 	assert.True(t, toolNames["execute_code"])
 	assert.True(t, toolNames["list_toolsets"])
 	assert.True(t, toolNames["describe_toolset"])
+	assert.True(t, toolNames["activate_toolset"])
+	assert.True(t, toolNames["deactivate_toolset"])
 	assert.True(t, toolNames["list_skills"])
 	assert.True(t, toolNames["describe_skill"])
 	assert.True(t, toolNames["plan_skill"])
 	assert.True(t, toolNames["run_skill"])
+	assert.True(t, toolNames["get_job"])
+	assert.True(t, toolNames["list_jobs"])
+	assert.True(t, toolNames["cancel_job"])
+	assert.True(t, toolNames["read_result"])
 }
 
 func TestNewServer_ToolsListReturnsAllTools(t *testing.T) {
@@ -131,7 +137,7 @@ func TestNewServer_ToolsListReturnsAllTools(t *testing.T) {
 	require.NoError(t, err)
 
 	tools := srv.ListTools()
-	assert.Equal(t, 14, len(tools))
+	assert.Equal(t, 20, len(tools))
 }
 
 func TestNewServer_WithoutExecutor(t *testing.T) {
@@ -159,7 +165,7 @@ func TestNewServer_WithoutExecutor(t *testing.T) {
 
 	// Should have all tools except execute_code.
 	tools := srv.ListTools()
-	assert.Len(t, tools, 13)
+	assert.Len(t, tools, 19)
 
 	// Verify execute_code is NOT present
 	for _, tool := range tools {
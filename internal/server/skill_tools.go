@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+
+	"github.com/jonwraymond/metatools-mcp/internal/provider"
+	"github.com/jonwraymond/metatools-mcp/internal/provider/builtin"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type skillTool struct {
+	skillID   string
+	toolsetID string
+	tool      *mcp.Tool
+}
+
+// syncSkillTools publishes each skill as its own MCP tool when the
+// skill_tools provider is enabled. Each tool runs through the provider
+// middleware chain, so auth, rate limits and audit apply per skill.
+func (s *Server) syncSkillTools() {
+	if !s.config.Providers.SkillTools.Enabled || s.config.Skills == nil || s.handlers.Skills == nil {
+		return
+	}
+	s.publishMu.Lock()
+	defer s.publishMu.Unlock()
+
+	want := make(map[string]skillTool)
+	providers := make(map[string]provider.ToolProvider)
+	for _, sk := range s.config.Skills.List() {
+		name := directToolName(sk.ID)
+		if _, ok := want[name]; ok {
+			continue
+		}
+		_, metatool := s.metatoolNames[name]
+		_, direct := s.directTools[name]
+		if metatool || direct {
+			slog.Warn("skill tool name collides with another tool; skipping", "skill_id", sk.ID, "name", name)
+			continue
+		}
+		var p provider.ToolProvider = builtin.NewSkillToolProvider(s.handlers.Skills, sk, name)
+		if s.middleware != nil {
+			p = s.middleware.Apply(p)
+		}
+		tool := p.Tool()
+		want[name] = skillTool{skillID: sk.ID, toolsetID: sk.ToolsetID, tool: &tool}
+		providers[name] = p
+	}
+
+	var removed []string
+	for name := range s.skillTools {
+		if _, ok := want[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	if len(removed) > 0 {
+		s.mcp.RemoveTools(removed...)
+	}
+	for name, st := range want {
+		if cur, ok := s.skillTools[name]; ok && reflect.DeepEqual(cur, st) {
+			continue
+		}
+		p := providers[name]
+		mcp.AddTool(s.mcp, st.tool, func(ctx context.Context, req *mcp.CallToolRequest, input map[string]any) (*mcp.CallToolResult, any, error) {
+			return p.Handle(ctx, req, input)
+		})
+	}
+	s.skillTools = want
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jonwraymond/metatools-mcp/internal/adapters"
+	"github.com/jonwraymond/metatools-mcp/internal/config"
+	"github.com/jonwraymond/metatools-mcp/internal/handlers"
+	"github.com/jonwraymond/metatools-mcp/internal/middleware"
+	"github.com/jonwraymond/metatools-mcp/internal/skills"
+	"github.com/jonwraymond/metatools-mcp/internal/toolset"
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolfoundation/model"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_SkillTools(t *testing.T) {
+	idx := index.NewInMemoryIndex()
+	require.NoError(t, idx.RegisterTool(model.Tool{
+		Namespace: "test",
+		Tool:      mcp.Tool{Name: "alpha", InputSchema: map[string]any{"type": "object"}},
+	}, model.ToolBackend{Kind: model.BackendKindLocal, Local: &model.LocalBackend{Name: "alpha"}}))
+	toolsets, err := toolset.BuildRegistry(idx, []toolset.Spec{{Name: "core", NamespaceFilters: []string{"test"}}})
+	require.NoError(t, err)
+	skillReg, err := skills.BuildRegistry(toolsets, []skills.Spec{{
+		Name:        "check",
+		Description: "Check things",
+		ToolsetID:   "toolset:core",
+		InputSchema: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"q": map[string]any{"type": "string"}},
+		},
+		Steps: []skills.StepSpec{{ID: "a", ToolID: "test:alpha", Inputs: map[string]any{"q": "{{ inputs.q }}"}}},
+	}})
+	require.NoError(t, err)
+
+	providers := config.DefaultAppConfig().Providers
+	providers.SkillTools.Enabled = true
+	defaults := config.DefaultAppConfig().SkillDefaults
+	runner := &recordingRunner{}
+	srv, err := New(config.Config{
+		Index:     adapters.NewIndexAdapter(idx),
+		Docs:      &mockStore{},
+		Runner:    runner,
+		Toolsets:  toolsets,
+		Skills:    skillReg,
+		Providers: providers,
+		SkillDefaults: handlers.SkillDefaults{
+			MaxSteps:     defaults.MaxSteps,
+			MaxToolCalls: defaults.MaxToolCalls,
+			Timeout:      defaults.Timeout,
+		},
+		Middleware: middleware.Config{
+			Chain: []string{"ratelimit"},
+			Configs: map[string]middleware.Entry{"ratelimit": {Config: map[string]any{
+				"rate":     1000.0,
+				"burst":    100,
+				"per_tool": map[string]any{"skill.check": map[string]any{"rate": 0.001, "burst": 1}},
+			}}},
+		},
+	})
+	require.NoError(t, err)
+	defer func() { _ = srv.Close() }()
+
+	session, err := connectClient(t, srv, nil)
+	require.NoError(t, err)
+
+	var tool *mcp.Tool
+	for tl, err := range session.Tools(context.Background(), nil) {
+		require.NoError(t, err)
+		if tl.Name == "skill.check" {
+			tool = tl
+		}
+	}
+	require.NotNil(t, tool, "skill is published as its own tool")
+	assert.Equal(t, "Check things", tool.Description)
+	assert.Contains(t, tool.InputSchema.(map[string]any)["properties"], "q")
+
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "skill.check",
+		Arguments: map[string]any{"q": "hi"},
+	})
+	require.NoError(t, err)
+	require.False(t, res.IsError)
+	assert.Equal(t, []string{"test:alpha"}, runner.calls)
+	assert.Equal(t, map[string]any{"q": "hi"}, runner.args[0])
+
+	// The middleware chain applies per skill.
+	res, err = session.CallTool(context.Background(), &mcp.CallToolParams{Name: "skill.check"})
+	require.True(t, err != nil || res.IsError, "second call should be rate limited")
+	assert.Len(t, runner.calls, 1)
+}
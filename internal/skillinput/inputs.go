@@ -0,0 +1,225 @@
+// Package skillinput renders {{ inputs.<name> }} templates in skill step
+// inputs from caller-supplied values validated against the skill's
+// input_schema.
+//
+// A string that is exactly one template is replaced by the input value with
+// its type intact; templates embedded in longer strings are interpolated as
+// text, with non-string values encoded as JSON. A template may walk into an
+// object input with dotted keys, e.g. {{ inputs.repo.owner }}. Inputs that
+// are declared but not supplied render as null (or an empty string when
+// embedded).
+package skillinput
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	merrors "github.com/jonwraymond/metatools-mcp/internal/errors"
+	"github.com/jonwraymond/metatools-mcp/internal/jsonshape"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// Errors returned by this package. Both wrap errors.ErrValidationInput.
+var (
+	ErrUndeclared = fmt.Errorf("%w: undeclared skill input", merrors.ErrValidationInput)
+	ErrInvalid    = fmt.Errorf("%w: invalid skill inputs", merrors.ErrValidationInput)
+)
+
+var templatePattern = regexp.MustCompile(`\{\{\s*inputs\.([A-Za-z_][A-Za-z0-9_-]*)((?:\.[A-Za-z0-9_-]+)*)\s*\}\}`)
+
+var validator = model.NewDefaultValidator()
+
+// Refs returns the sorted, de-duplicated input names referenced anywhere in v.
+func Refs(v any) []string {
+	seen := make(map[string]struct{})
+	walkStrings(v, func(s string) {
+		for _, m := range templatePattern.FindAllStringSubmatch(s, -1) {
+			seen[m[1]] = struct{}{}
+		}
+	})
+	return slices.Sorted(maps.Keys(seen))
+}
+
+// Declared returns the property names of an object input schema.
+func Declared(schema map[string]any) []string {
+	props, _ := schema["properties"].(map[string]any)
+	return slices.Sorted(maps.Keys(props))
+}
+
+// CheckSchema verifies that schema is an object schema and that every input
+// referenced by values is one of its properties. A nil schema declares no
+// inputs.
+func CheckSchema(schema map[string]any, values ...any) error {
+	if schema != nil {
+		if t, ok := schema["type"]; ok && t != "object" {
+			return fmt.Errorf("%w: input_schema type must be object", merrors.ErrValidationInput)
+		}
+		if props, ok := schema["properties"]; ok {
+			if _, isMap := props.(map[string]any); !isMap {
+				return fmt.Errorf("%w: input_schema properties must be an object", merrors.ErrValidationInput)
+			}
+		}
+	}
+	declared := Declared(schema)
+	for _, v := range values {
+		for _, name := range Refs(v) {
+			if !slices.Contains(declared, name) {
+				return fmt.Errorf("%w %q: add it to input_schema.properties", ErrUndeclared, name)
+			}
+		}
+	}
+	return nil
+}
+
+// Validate applies property defaults to inputs and validates the result
+// against schema. The returned map is a copy; inputs is not modified. With a
+// nil schema, any supplied input is an error.
+func Validate(schema map[string]any, inputs map[string]any) (map[string]any, error) {
+	if schema == nil {
+		if len(inputs) > 0 {
+			return nil, fmt.Errorf("%w: skill declares no input_schema", ErrInvalid)
+		}
+		return map[string]any{}, nil
+	}
+	out := make(map[string]any, len(inputs))
+	props, _ := schema["properties"].(map[string]any)
+	for name, prop := range props {
+		if p, ok := prop.(map[string]any); ok {
+			if def, ok := p["default"]; ok {
+				out[name] = def
+			}
+		}
+	}
+	maps.Copy(out, inputs)
+	instance, _ := jsonshape.Normalize(out)
+	if err := validator.Validate(schema, instance); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return out, nil
+}
+
+// Render returns a copy of v with every input template replaced.
+func Render(v any, inputs map[string]any) (any, error) {
+	switch val := v.(type) {
+	case map[string]any:
+		if val == nil {
+			return val, nil
+		}
+		out := make(map[string]any, len(val))
+		for k, child := range val {
+			rendered, err := Render(child, inputs)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = rendered
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(val))
+		for i, child := range val {
+			rendered, err := Render(child, inputs)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = rendered
+		}
+		return out, nil
+	case string:
+		return renderString(val, inputs)
+	default:
+		return v, nil
+	}
+}
+
+// RenderMap is Render for step inputs and fallback args.
+func RenderMap(m map[string]any, inputs map[string]any) (map[string]any, error) {
+	if m == nil {
+		return nil, nil
+	}
+	out, err := Render(m, inputs)
+	if err != nil {
+		return nil, err
+	}
+	return out.(map[string]any), nil
+}
+
+func renderString(s string, inputs map[string]any) (any, error) {
+	matches := templatePattern.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s, nil
+	}
+	if len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(s) {
+		return lookup(s, matches[0], inputs)
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		value, err := lookup(s, m, inputs)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(s[last:m[0]])
+		b.WriteString(text(value))
+		last = m[1]
+	}
+	b.WriteString(s[last:])
+	return b.String(), nil
+}
+
+func lookup(s string, m []int, inputs map[string]any) (any, error) {
+	name := s[m[2]:m[3]]
+	cur, ok := inputs[name]
+	if !ok {
+		return nil, nil
+	}
+	walked := "inputs." + name
+	if m[4] < m[5] {
+		for _, key := range strings.Split(s[m[4]+1:m[5]], ".") {
+			obj, err := jsonshape.Map(cur)
+			if err != nil || obj == nil {
+				return nil, fmt.Errorf("%w: %s is not an object", ErrInvalid, walked)
+			}
+			if cur, ok = obj[key]; !ok {
+				return nil, fmt.Errorf("%w: %s has no key %q", ErrInvalid, walked, key)
+			}
+			walked += "." + key
+		}
+	}
+	return cur, nil
+}
+
+func walkStrings(v any, visit func(string)) {
+	switch val := v.(type) {
+	case map[string]any:
+		for _, child := range val {
+			walkStrings(child, visit)
+		}
+	case []any:
+		for _, child := range val {
+			walkStrings(child, visit)
+		}
+	case string:
+		visit(val)
+	}
+}
+
+// text renders an interpolated value: strings verbatim, nil as empty, and
+// everything else as JSON.
+func text(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
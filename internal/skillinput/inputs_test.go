@@ -0,0 +1,101 @@
+package skillinput
+
+import (
+	"testing"
+
+	merrors "github.com/jonwraymond/metatools-mcp/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var repoSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"repo":  map[string]any{"type": "string"},
+		"limit": map[string]any{"type": "integer", "default": 10},
+		"filter": map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"label": map[string]any{"type": "string"}},
+		},
+	},
+	"required": []any{"repo"},
+}
+
+func TestRefs(t *testing.T) {
+	args := map[string]any{
+		"q":     "repo:{{ inputs.repo }} label:{{inputs.filter.label}}",
+		"n":     "{{ inputs.limit }}",
+		"list":  []any{"{{ inputs.repo }}"},
+		"plain": "${steps.a.result}",
+	}
+	assert.Equal(t, []string{"filter", "limit", "repo"}, Refs(args))
+	assert.Empty(t, Refs(map[string]any{"x": "{{ input.repo }}"}))
+}
+
+func TestCheckSchema(t *testing.T) {
+	args := map[string]any{"q": "{{ inputs.repo }}"}
+	assert.NoError(t, CheckSchema(repoSchema, args))
+
+	err := CheckSchema(repoSchema, map[string]any{"q": "{{ inputs.owner }}"})
+	assert.ErrorIs(t, err, ErrUndeclared)
+	assert.ErrorIs(t, err, merrors.ErrValidationInput)
+	assert.ErrorContains(t, err, `"owner"`)
+
+	assert.ErrorIs(t, CheckSchema(nil, args), ErrUndeclared)
+	assert.NoError(t, CheckSchema(nil, map[string]any{"q": "static"}))
+	assert.Error(t, CheckSchema(map[string]any{"type": "string"}))
+}
+
+func TestValidate(t *testing.T) {
+	values, err := Validate(repoSchema, map[string]any{"repo": "acme/api"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"repo": "acme/api", "limit": 10}, values, "defaults are applied")
+
+	_, err = Validate(repoSchema, map[string]any{})
+	assert.ErrorIs(t, err, ErrInvalid)
+	assert.ErrorContains(t, err, "repo")
+
+	_, err = Validate(repoSchema, map[string]any{"repo": 42})
+	assert.ErrorIs(t, err, merrors.ErrValidationInput)
+
+	_, err = Validate(nil, map[string]any{"repo": "x"})
+	assert.ErrorIs(t, err, ErrInvalid)
+	values, err = Validate(nil, nil)
+	require.NoError(t, err)
+	assert.Empty(t, values)
+}
+
+func TestRender(t *testing.T) {
+	inputs := map[string]any{
+		"repo":   "acme/api",
+		"limit":  5,
+		"filter": map[string]any{"label": "bug"},
+	}
+	args := map[string]any{
+		"repo":    "{{ inputs.repo }}",
+		"limit":   "{{ inputs.limit }}",
+		"query":   "repo:{{ inputs.repo }} label:{{ inputs.filter.label }} n={{ inputs.limit }}",
+		"filter":  "{{ inputs.filter }}",
+		"nested":  []any{map[string]any{"r": "{{inputs.repo}}"}},
+		"missing": "{{ inputs.owner }}",
+		"text":    "owner={{ inputs.owner }}",
+		"step":    "${steps.a.result}",
+	}
+	out, err := RenderMap(args, inputs)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"repo":    "acme/api",
+		"limit":   5,
+		"query":   "repo:acme/api label:bug n=5",
+		"filter":  map[string]any{"label": "bug"},
+		"nested":  []any{map[string]any{"r": "acme/api"}},
+		"missing": nil,
+		"text":    "owner=",
+		"step":    "${steps.a.result}",
+	}, out)
+	assert.Equal(t, "{{ inputs.repo }}", args["repo"], "args are not modified")
+
+	_, err = RenderMap(map[string]any{"x": "{{ inputs.repo.owner }}"}, inputs)
+	assert.ErrorIs(t, err, ErrInvalid)
+	assert.ErrorContains(t, err, "inputs.repo is not an object")
+}
@@ -0,0 +1,40 @@
+package skills
+
+import (
+	"context"
+
+	"github.com/jonwraymond/metatools-mcp/internal/dryrun"
+	"github.com/jonwraymond/toolcompose/skill"
+)
+
+// DryRun walks a compiled plan without calling any tool; see dryrun.Run.
+// When every step's output is known, from a mock or the tool's output
+// schema, the skill's output mapping is resolved against those outputs too.
+func DryRun(ctx context.Context, plan Plan, opts dryrun.Options) ([]dryrun.StepTrace, map[string]any, error) {
+	if plan.graph == nil {
+		return nil, nil, errNotCompiled
+	}
+	steps := make([]dryrun.Step, len(plan.indexed))
+	for i, step := range plan.indexed {
+		steps[i] = dryrun.Step{
+			ID:      step.ID,
+			ToolID:  step.ToolID,
+			Args:    step.Inputs,
+			OnError: plan.OnError[step.ID],
+		}
+	}
+	traces, err := dryrun.Run(ctx, plan.graph, steps, opts)
+	if err != nil {
+		return traces, nil, err
+	}
+
+	results := make([]StepResult, 0, len(traces))
+	for _, tr := range traces {
+		if tr.Source == dryrun.SourceUnknown {
+			return traces, nil, nil
+		}
+		results = append(results, StepResult{StepResult: skill.StepResult{StepID: tr.ID, Value: tr.Output}})
+	}
+	output, err := BuildOutput(plan, results)
+	return traces, output, err
+}
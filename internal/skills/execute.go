@@ -0,0 +1,106 @@
+package skills
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jonwraymond/metatools-mcp/internal/stepgraph"
+	"github.com/jonwraymond/metatools-mcp/internal/stepref"
+	"github.com/jonwraymond/toolcompose/skill"
+)
+
+// errNotCompiled is returned when Execute gets a plan not built by CompilePlan.
+var errNotCompiled = errors.New("skill plan was not compiled")
+
+// StepResult is a step outcome along with its applied error policy, if any.
+type StepResult struct {
+	skill.StepResult
+	// ToolID is the tool the step calls.
+	ToolID string
+	Policy *stepgraph.Report
+}
+
+// Checkpoint carries the state of an earlier attempt of a run.
+type Checkpoint struct {
+	// Done maps the IDs of steps that already succeeded to their values.
+	// They are not run again, and their values feed step references.
+	Done map[string]any
+	// OnResult, when set, is called with each step's result as it finishes,
+	// in topological order and never concurrently.
+	OnResult func(index int, result StepResult)
+}
+
+// Execute runs a compiled plan. Steps whose dependencies have succeeded run
+// concurrently up to opts.MaxParallel, and step references in inputs are
+// resolved against earlier results before each call. Failing steps follow
+// their on_error policy; retries and fallbacks are charged to opts.Budget.
+// Results are returned in topological order; after the first failure no new
+// steps start.
+func Execute(ctx context.Context, plan Plan, runner skill.Runner, opts stepgraph.Options) ([]StepResult, error) {
+	return ExecuteWithCheckpoint(ctx, plan, runner, opts, Checkpoint{})
+}
+
+// ExecuteWithCheckpoint is Execute resuming from cp. Indexes passed to
+// cp.OnResult are positions in plan.Steps.
+func ExecuteWithCheckpoint(ctx context.Context, plan Plan, runner skill.Runner, opts stepgraph.Options, cp Checkpoint) ([]StepResult, error) {
+	if runner == nil {
+		return nil, skill.ErrInvalidRunner
+	}
+	if plan.graph == nil {
+		return nil, errNotCompiled
+	}
+
+	refs := stepref.NewResults()
+	values := make([]any, len(plan.indexed))
+	reports := make([]*stepgraph.Report, len(plan.indexed))
+	// position maps the graph's step indexes, those of plan.indexed, to
+	// positions in plan.Steps.
+	position := make([]int, len(plan.indexed))
+	for pos, i := range plan.graph.Order() {
+		position[i] = pos
+	}
+	var results []StepResult
+	onStep := opts.OnStep
+	opts.OnStep = func(i int, err error) {
+		if err == nil && reports[i].Continued() {
+			err = reports[i].Cause
+		}
+		res := StepResult{
+			StepResult: skill.StepResult{StepID: plan.indexed[i].ID, Value: values[i], Err: err},
+			ToolID:     plan.indexed[i].ToolID,
+			Policy:     reports[i],
+		}
+		results = append(results, res)
+		if cp.OnResult != nil {
+			cp.OnResult(position[i], res)
+		}
+		if onStep != nil {
+			onStep(i, err)
+		}
+	}
+
+	_, err := plan.graph.Run(ctx, opts, func(ctx context.Context, i int) error {
+		step := plan.indexed[i]
+		if value, ok := cp.Done[step.ID]; ok {
+			values[i] = value
+			refs.Set(i, step.ID, value)
+			return nil
+		}
+		call := func(ctx context.Context, toolID string, inputs map[string]any) error {
+			resolved, err := stepref.Resolve(inputs, refs)
+			if err != nil {
+				return err
+			}
+			values[i], err = runner.Run(ctx, skill.Step{ID: step.ID, ToolID: toolID, Inputs: resolved})
+			return err
+		}
+		report, err := plan.OnError[step.ID].Apply(ctx, opts.Budget, step.ToolID, step.Inputs, call)
+		reports[i] = report
+		if err != nil || report.Continued() {
+			return err
+		}
+		refs.Set(i, step.ID, values[i])
+		return nil
+	})
+	return results, err
+}
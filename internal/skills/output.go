@@ -0,0 +1,74 @@
+package skills
+
+import (
+	"fmt"
+
+	merrors "github.com/jonwraymond/metatools-mcp/internal/errors"
+	"github.com/jonwraymond/metatools-mcp/internal/jsonshape"
+	"github.com/jonwraymond/metatools-mcp/internal/stepgraph"
+	"github.com/jonwraymond/metatools-mcp/internal/stepref"
+	"github.com/jonwraymond/toolcompose/skill"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+var outputValidator = model.NewDefaultValidator()
+
+// checkOutput verifies that every step reference in output names a step of
+// the skill. steps[<n>] counts in ID order, as in step inputs.
+func checkOutput(steps []skill.Step, output map[string]any) error {
+	refs, err := stepref.Collect(output)
+	if err != nil {
+		return fmt.Errorf("output: %w", err)
+	}
+	for _, ref := range refs {
+		if ref.Index >= 0 {
+			if ref.Index >= len(steps) {
+				return fmt.Errorf("output: %w %q", stepgraph.ErrUnknownStep, ref.Step())
+			}
+			continue
+		}
+		found := false
+		for _, step := range steps {
+			if step.ID == ref.StepID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("output: %w %q", stepgraph.ErrUnknownStep, ref.StepID)
+		}
+	}
+	return nil
+}
+
+// BuildOutput resolves the plan's output mapping against step results and
+// validates it against the output schema. It returns nil when the skill
+// declares no output. Failures wrap errors.ErrValidationOutput.
+func BuildOutput(plan Plan, results []StepResult) (map[string]any, error) {
+	if plan.Output == nil {
+		return nil, nil
+	}
+	position := make(map[string]int, len(plan.indexed))
+	for i, step := range plan.indexed {
+		position[step.ID] = i
+	}
+	refs := stepref.NewResults()
+	for _, res := range results {
+		if res.Err != nil || res.Policy.Continued() {
+			continue
+		}
+		refs.Set(position[res.StepID], res.StepID, res.Value)
+	}
+
+	output, err := stepref.Resolve(plan.Output, refs)
+	if err != nil {
+		return nil, fmt.Errorf("%w: skill output: %v", merrors.ErrValidationOutput, err)
+	}
+	if plan.OutputSchema != nil {
+		instance, _ := jsonshape.Normalize(output)
+		if err := outputValidator.Validate(plan.OutputSchema, instance); err != nil {
+			return nil, fmt.Errorf("%w: skill output: %v", merrors.ErrValidationOutput, err)
+		}
+	}
+	return output, nil
+}
@@ -2,18 +2,168 @@
 // toolcompose skills with deterministic behavior and guard validation.
 package skills
 
-import "github.com/jonwraymond/toolcompose/skill"
+import (
+	"fmt"
+
+	"github.com/jonwraymond/metatools-mcp/internal/skillinput"
+	"github.com/jonwraymond/metatools-mcp/internal/stepgraph"
+	"github.com/jonwraymond/toolcompose/skill"
+)
+
+// Definition is a skill ready for planning: the toolcompose skill plus the
+// step dependencies toolcompose does not model.
+type Definition struct {
+	skill.Skill
+	// DependsOn maps step IDs to the step IDs they wait for. When nil, steps
+	// run one at a time in ID order.
+	DependsOn map[string][]string
+	// OnError maps step IDs to their error policies.
+	OnError map[string]*stepgraph.Policy
+	// InputSchema declares the inputs step templates may reference.
+	InputSchema map[string]any
+	// Output maps step results into the run's final output; nil when the
+	// skill returns only step results. OutputSchema validates it.
+	Output       map[string]any
+	OutputSchema map[string]any
+}
+
+// WithInputs validates inputs against the input schema and returns a copy of
+// the definition with {{ inputs.<name> }} templates in step inputs and
+// fallback args replaced by their values.
+func (d Definition) WithInputs(inputs map[string]any) (Definition, error) {
+	if err := skillinput.CheckSchema(d.InputSchema, append(templated(d.Steps, d.OnError), d.Output)...); err != nil {
+		return Definition{}, fmt.Errorf("skill %q: %w", d.Name, err)
+	}
+	values, err := skillinput.Validate(d.InputSchema, inputs)
+	if err != nil {
+		return Definition{}, fmt.Errorf("skill %q: %w", d.Name, err)
+	}
+
+	out := d
+	out.Steps = make([]skill.Step, len(d.Steps))
+	for i, step := range d.Steps {
+		if step.Inputs, err = skillinput.RenderMap(step.Inputs, values); err != nil {
+			return Definition{}, fmt.Errorf("skill %q step %q: %w", d.Name, step.ID, err)
+		}
+		out.Steps[i] = step
+	}
+	if out.Output, err = skillinput.RenderMap(d.Output, values); err != nil {
+		return Definition{}, fmt.Errorf("skill %q output: %w", d.Name, err)
+	}
+	if d.OnError != nil {
+		out.OnError = make(map[string]*stepgraph.Policy, len(d.OnError))
+		for id, policy := range d.OnError {
+			if policy != nil && policy.Args != nil {
+				rendered := *policy
+				if rendered.Args, err = skillinput.RenderMap(policy.Args, values); err != nil {
+					return Definition{}, fmt.Errorf("skill %q step %q: %w", d.Name, id, err)
+				}
+				policy = &rendered
+			}
+			out.OnError[id] = policy
+		}
+	}
+	return out, nil
+}
+
+// templated returns the values that may contain input templates.
+func templated(steps []skill.Step, onError map[string]*stepgraph.Policy) []any {
+	out := make([]any, 0, len(steps))
+	for _, step := range steps {
+		out = append(out, step.Inputs)
+		if policy := onError[step.ID]; policy != nil {
+			out = append(out, policy.Args)
+		}
+	}
+	return out
+}
+
+// Plan is a compiled skill plan. Steps are in execution (topological) order.
+type Plan struct {
+	skill.Plan
+	// DependsOn maps each step ID to the steps it waits for, including those
+	// implied by step references. It is nil for sequential plans.
+	DependsOn map[string][]string
+	OnError   map[string]*stepgraph.Policy
+	// Output and OutputSchema are carried over from the definition; see
+	// BuildOutput.
+	Output       map[string]any
+	OutputSchema map[string]any
+
+	graph *stepgraph.Graph
+	// indexed holds steps in ID order; steps[<n>] references count in it.
+	indexed []skill.Step
+}
 
 // CompilePlan validates guards and builds a deterministic plan.
-func CompilePlan(def skill.Skill, guards []skill.Guard) (skill.Plan, error) {
+// Step references in inputs must point at steps that run earlier: in ID order
+// for sequential skills, or anywhere in the skill once depends_on is used, in
+// which case the reference becomes a dependency. Dependency cycles are
+// rejected.
+func CompilePlan(def Definition, guards []skill.Guard) (Plan, error) {
 	for _, guard := range guards {
 		if guard == nil {
 			continue
 		}
-		if err := guard.Validate(def); err != nil {
-			return skill.Plan{}, err
+		if err := guard.Validate(def.Skill); err != nil {
+			return Plan{}, err
+		}
+		// Fallback tools must pass the same guards as the steps they replace.
+		for _, step := range def.Steps {
+			policy := def.OnError[step.ID]
+			if policy == nil || policy.Action != stepgraph.ActionFallback {
+				continue
+			}
+			fallback := skill.Skill{Name: def.Name, Steps: []skill.Step{{ID: step.ID, ToolID: policy.ToolID}}}
+			if err := guard.Validate(fallback); err != nil {
+				return Plan{}, fmt.Errorf("step %q fallback: %w", step.ID, err)
+			}
 		}
 	}
 	planner := skill.NewPlanner()
-	return planner.Plan(def)
+	plan, err := planner.Plan(def.Skill)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	specs := make([]stepgraph.Spec, len(plan.Steps))
+	for i, step := range plan.Steps {
+		specs[i] = stepgraph.Spec{ID: step.ID, Args: step.Inputs, OnError: def.OnError[step.ID]}
+		if def.DependsOn != nil {
+			specs[i].DependsOn = def.DependsOn[step.ID]
+			if specs[i].DependsOn == nil {
+				specs[i].DependsOn = []string{}
+			}
+		}
+	}
+	graph, err := stepgraph.Build(specs)
+	if err != nil {
+		return Plan{}, fmt.Errorf("skill %q: %w", def.Name, err)
+	}
+	if err := checkOutput(plan.Steps, def.Output); err != nil {
+		return Plan{}, fmt.Errorf("skill %q: %w", def.Name, err)
+	}
+
+	out := Plan{
+		OnError:      def.OnError,
+		Output:       def.Output,
+		OutputSchema: def.OutputSchema,
+		graph:        graph,
+		indexed:      plan.Steps,
+	}
+	out.Name = plan.Name
+	for _, i := range graph.Order() {
+		out.Steps = append(out.Steps, plan.Steps[i])
+	}
+	if def.DependsOn != nil {
+		out.DependsOn = make(map[string][]string, len(plan.Steps))
+		for i, step := range plan.Steps {
+			deps := make([]string, 0)
+			for _, dep := range graph.DependsOn(i) {
+				deps = append(deps, plan.Steps[dep].ID)
+			}
+			out.DependsOn[step.ID] = deps
+		}
+	}
+	return out, nil
 }
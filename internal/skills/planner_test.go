@@ -1,20 +1,26 @@
 package skills
 
 import (
+	"context"
+	"errors"
+	"sync"
 	"testing"
 
+	merrors "github.com/jonwraymond/metatools-mcp/internal/errors"
+	"github.com/jonwraymond/metatools-mcp/internal/stepgraph"
 	"github.com/jonwraymond/toolcompose/skill"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestCompilePlan_EnforcesGuards(t *testing.T) {
-	def := skill.Skill{
+	def := Definition{Skill: skill.Skill{
 		Name: "test-skill",
 		Steps: []skill.Step{
 			{ID: "a", ToolID: "tool:a"},
 			{ID: "b", ToolID: "tool:b"},
 		},
-	}
+	}}
 
 	_, err := CompilePlan(def, []skill.Guard{skill.MaxStepsGuard(1)})
 	require.ErrorIs(t, err, skill.ErrMaxStepsExceeded)
@@ -22,3 +28,179 @@ func TestCompilePlan_EnforcesGuards(t *testing.T) {
 	_, err = CompilePlan(def, []skill.Guard{skill.AllowedToolIDsGuard([]string{"tool:a"})})
 	require.ErrorIs(t, err, skill.ErrToolNotAllowed)
 }
+
+func TestCompilePlan_OrdersByDependencies(t *testing.T) {
+	def := Definition{
+		Skill: skill.Skill{
+			Name: "triage",
+			Steps: []skill.Step{
+				{ID: "a-report", ToolID: "tool:report", Inputs: map[string]any{"count": "${steps.z-issues.result.count}"}},
+				{ID: "b-pulls", ToolID: "tool:pulls"},
+				{ID: "z-issues", ToolID: "tool:issues"},
+			},
+		},
+		DependsOn: map[string][]string{"a-report": {"b-pulls"}},
+	}
+
+	plan, err := CompilePlan(def, nil)
+	require.NoError(t, err)
+	ids := make([]string, len(plan.Steps))
+	for i, step := range plan.Steps {
+		ids[i] = step.ID
+	}
+	assert.Equal(t, []string{"b-pulls", "z-issues", "a-report"}, ids)
+	assert.Equal(t, []string{"b-pulls", "z-issues"}, plan.DependsOn["a-report"])
+	assert.Empty(t, plan.DependsOn["b-pulls"])
+}
+
+func TestCompilePlan_RejectsCycles(t *testing.T) {
+	def := Definition{
+		Skill: skill.Skill{
+			Name: "loop",
+			Steps: []skill.Step{
+				{ID: "a", ToolID: "tool:a"},
+				{ID: "b", ToolID: "tool:b"},
+			},
+		},
+		DependsOn: map[string][]string{"a": {"b"}, "b": {"a"}},
+	}
+
+	_, err := CompilePlan(def, nil)
+	require.ErrorIs(t, err, stepgraph.ErrCycle)
+	require.ErrorIs(t, err, merrors.ErrValidationInput)
+	assert.Contains(t, err.Error(), `"a" -> "b" -> "a"`)
+}
+
+type recordingRunner struct {
+	mu     sync.Mutex
+	inputs map[string]map[string]any
+}
+
+func (r *recordingRunner) Run(_ context.Context, step skill.Step) (any, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inputs[step.ID] = step.Inputs
+	return map[string]any{"id": step.ID}, nil
+}
+
+func TestExecute_ResolvesReferencesAcrossBranches(t *testing.T) {
+	def := Definition{
+		Skill: skill.Skill{
+			Name: "fan-in",
+			Steps: []skill.Step{
+				{ID: "join", ToolID: "tool:join", Inputs: map[string]any{
+					"left":  "${steps.left.result.id}",
+					"right": "${steps.right.result.id}",
+				}},
+				{ID: "left", ToolID: "tool:left"},
+				{ID: "right", ToolID: "tool:right"},
+			},
+		},
+		DependsOn: map[string][]string{"left": {}, "right": {}},
+	}
+	plan, err := CompilePlan(def, nil)
+	require.NoError(t, err)
+
+	runner := &recordingRunner{inputs: make(map[string]map[string]any)}
+	results, err := Execute(context.Background(), plan, runner, stepgraph.Options{MaxParallel: 2})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, "join", results[2].StepID)
+	assert.Equal(t, map[string]any{"left": "left", "right": "right"}, runner.inputs["join"])
+}
+
+func TestExecute_RequiresCompiledPlan(t *testing.T) {
+	_, err := Execute(context.Background(), Plan{}, &recordingRunner{}, stepgraph.Options{})
+	require.Error(t, err)
+}
+
+func TestCompilePlan_GuardsFallbackTools(t *testing.T) {
+	def := Definition{
+		Skill: skill.Skill{
+			Name:  "guarded",
+			Steps: []skill.Step{{ID: "a", ToolID: "tool:a"}},
+		},
+		OnError: map[string]*stepgraph.Policy{"a": {Action: stepgraph.ActionFallback, ToolID: "tool:b"}},
+	}
+
+	_, err := CompilePlan(def, []skill.Guard{skill.AllowedToolIDsGuard([]string{"tool:a"})})
+	require.ErrorIs(t, err, skill.ErrToolNotAllowed)
+	assert.Contains(t, err.Error(), `step "a" fallback`)
+
+	_, err = CompilePlan(def, []skill.Guard{skill.AllowedToolIDsGuard([]string{"tool:a", "tool:b"})})
+	require.NoError(t, err)
+}
+
+func TestBuildRegistry_ValidatesErrorPolicies(t *testing.T) {
+	_, err := BuildRegistry(nil, []Spec{{
+		Name:  "bad",
+		Steps: []StepSpec{{ID: "a", ToolID: "tool:a", OnError: &stepgraph.Policy{Action: stepgraph.ActionFallback}}},
+	}})
+	require.ErrorIs(t, err, merrors.ErrValidationInput)
+	assert.Contains(t, err.Error(), "requires tool_id")
+
+	reg, err := BuildRegistry(nil, []Spec{{
+		Name:  "ok",
+		Steps: []StepSpec{{ID: "a", ToolID: "tool:a", OnError: &stepgraph.Policy{Action: stepgraph.ActionContinue}}},
+	}})
+	require.NoError(t, err)
+	s, ok := reg.Get("skill:ok")
+	require.True(t, ok)
+	assert.Equal(t, stepgraph.ActionContinue, s.Definition().OnError["a"].Action)
+}
+
+func TestBuildRegistry_ChecksInputTemplates(t *testing.T) {
+	schema := map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"repo": map[string]any{"type": "string"}},
+	}
+	_, err := BuildRegistry(nil, []Spec{{
+		Name:        "bad",
+		InputSchema: schema,
+		Steps: []StepSpec{{ID: "a", ToolID: "tool:a", OnError: &stepgraph.Policy{
+			Action: stepgraph.ActionFallback,
+			ToolID: "tool:b",
+			Args:   map[string]any{"owner": "{{ inputs.owner }}"},
+		}}},
+	}})
+	require.ErrorIs(t, err, merrors.ErrValidationInput)
+	assert.Contains(t, err.Error(), `undeclared skill input "owner"`)
+
+	reg, err := BuildRegistry(nil, []Spec{{
+		Name:        "ok",
+		InputSchema: schema,
+		Steps:       []StepSpec{{ID: "a", ToolID: "tool:a", Inputs: map[string]any{"repo": "{{ inputs.repo }}"}}},
+	}})
+	require.NoError(t, err)
+	s, _ := reg.Get("skill:ok")
+	def, err := s.Definition().WithInputs(map[string]any{"repo": "acme/api"})
+	require.NoError(t, err)
+	assert.Equal(t, "acme/api", def.Steps[0].Inputs["repo"])
+	assert.Equal(t, "{{ inputs.repo }}", s.Steps[0].Inputs["repo"], "registry skill is not modified")
+}
+
+func TestBuildRegistry_ChecksOutputReferences(t *testing.T) {
+	_, err := BuildRegistry(nil, []Spec{{
+		Name:   "bad",
+		Steps:  []StepSpec{{ID: "a", ToolID: "tool:a"}},
+		Output: map[string]any{"v": "${steps[1].result}"},
+	}})
+	require.ErrorIs(t, err, stepgraph.ErrUnknownStep)
+
+	reg, err := BuildRegistry(nil, []Spec{{
+		Name:   "ok",
+		Steps:  []StepSpec{{ID: "a", ToolID: "tool:a"}},
+		Output: map[string]any{"v": "${steps.a.result.value}"},
+	}})
+	require.NoError(t, err)
+	s, _ := reg.Get("skill:ok")
+	plan, err := CompilePlan(s.Definition(), nil)
+	require.NoError(t, err)
+
+	out, err := BuildOutput(plan, []StepResult{{StepResult: skill.StepResult{StepID: "a", Value: map[string]any{"value": 3}}}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"v": 3}, out)
+
+	_, err = BuildOutput(plan, []StepResult{{StepResult: skill.StepResult{StepID: "a", Err: errors.New("boom")}}})
+	require.ErrorIs(t, err, merrors.ErrValidationOutput)
+}
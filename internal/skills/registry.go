@@ -5,6 +5,8 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/jonwraymond/metatools-mcp/internal/skillinput"
+	"github.com/jonwraymond/metatools-mcp/internal/stepgraph"
 	"github.com/jonwraymond/metatools-mcp/internal/toolset"
 	"github.com/jonwraymond/toolcompose/skill"
 )
@@ -18,7 +20,31 @@ type Skill struct {
 	Description string
 	ToolsetID   string
 	Steps       []skill.Step
-	Guards      []skill.Guard
+	// DependsOn maps step IDs to the steps they declare as dependencies. It is
+	// nil when no step declares depends_on.
+	DependsOn map[string][]string
+	// OnError maps step IDs to their error policies.
+	OnError map[string]*stepgraph.Policy
+	// InputSchema is the JSON Schema for {{ inputs.<name> }} values; nil
+	// when the skill takes no inputs.
+	InputSchema map[string]any
+	// Output maps step results into the final run output; OutputSchema
+	// validates it. Both are nil when not configured.
+	Output       map[string]any
+	OutputSchema map[string]any
+	Guards       []skill.Guard
+}
+
+// Definition returns the skill in planning form.
+func (s *Skill) Definition() Definition {
+	return Definition{
+		Skill:        skill.Skill{Name: s.Name, Steps: s.Steps},
+		DependsOn:    s.DependsOn,
+		OnError:      s.OnError,
+		InputSchema:  s.InputSchema,
+		Output:       s.Output,
+		OutputSchema: s.OutputSchema,
+	}
 }
 
 // GuardSpec defines guard configuration.
@@ -29,9 +55,11 @@ type GuardSpec struct {
 
 // StepSpec defines a skill step.
 type StepSpec struct {
-	ID     string
-	ToolID string
-	Inputs map[string]any
+	ID        string
+	ToolID    string
+	Inputs    map[string]any
+	DependsOn []string
+	OnError   *stepgraph.Policy
 }
 
 // Spec defines a skill configuration.
@@ -39,8 +67,12 @@ type Spec struct {
 	Name        string
 	Description string
 	ToolsetID   string
-	Steps       []StepSpec
-	Guards      GuardSpec
+	InputSchema map[string]any
+	// Output maps step results into the run output; OutputSchema validates it.
+	Output       map[string]any
+	OutputSchema map[string]any
+	Steps        []StepSpec
+	Guards       GuardSpec
 }
 
 // Registry stores skills keyed by ID.
@@ -120,6 +152,8 @@ func BuildRegistry(toolsets *toolset.Registry, specs []Spec) (*Registry, error)
 		}
 
 		steps := make([]skill.Step, len(cfg.Steps))
+		var dependsOn map[string][]string
+		var onError map[string]*stepgraph.Policy
 		for i, stepCfg := range cfg.Steps {
 			step := skill.Step{
 				ID:     stepCfg.ID,
@@ -130,6 +164,28 @@ func BuildRegistry(toolsets *toolset.Registry, specs []Spec) (*Registry, error)
 				return nil, fmt.Errorf("skill %q step %d invalid: %w", cfg.Name, i, err)
 			}
 			steps[i] = step
+			if stepCfg.DependsOn != nil {
+				if dependsOn == nil {
+					dependsOn = make(map[string][]string)
+				}
+				dependsOn[step.ID] = stepCfg.DependsOn
+			}
+			if stepCfg.OnError != nil {
+				if err := stepCfg.OnError.Validate(); err != nil {
+					return nil, fmt.Errorf("skill %q step %q: %w", cfg.Name, step.ID, err)
+				}
+				if onError == nil {
+					onError = make(map[string]*stepgraph.Policy)
+				}
+				onError[step.ID] = stepCfg.OnError
+			}
+		}
+
+		if err := skillinput.CheckSchema(cfg.InputSchema, append(templated(steps, onError), cfg.Output)...); err != nil {
+			return nil, fmt.Errorf("skill %q: %w", cfg.Name, err)
+		}
+		if err := checkOutput(steps, cfg.Output); err != nil {
+			return nil, fmt.Errorf("skill %q: %w", cfg.Name, err)
 		}
 
 		allowedIDs := resolveAllowedIDs(cfg.Guards.AllowIDs, toolsetIDs)
@@ -142,12 +198,17 @@ func BuildRegistry(toolsets *toolset.Registry, specs []Spec) (*Registry, error)
 		}
 
 		out = append(out, &Skill{
-			ID:          id,
-			Name:        cfg.Name,
-			Description: cfg.Description,
-			ToolsetID:   cfg.ToolsetID,
-			Steps:       steps,
-			Guards:      guards,
+			ID:           id,
+			Name:         cfg.Name,
+			Description:  cfg.Description,
+			ToolsetID:    cfg.ToolsetID,
+			Steps:        steps,
+			DependsOn:    dependsOn,
+			OnError:      onError,
+			InputSchema:  cfg.InputSchema,
+			Output:       cfg.Output,
+			OutputSchema: cfg.OutputSchema,
+			Guards:       guards,
 		})
 	}
 
@@ -5,11 +5,9 @@ import (
 	"database/sql"
 	"embed"
 	"fmt"
-	"io/fs"
-	"sort"
-	"strings"
 	"time"
 
+	"github.com/jonwraymond/metatools-mcp/internal/state"
 	_ "modernc.org/sqlite" // register sqlite driver
 )
 
@@ -40,7 +38,7 @@ func NewSQLiteStore(db *sql.DB) (*SQLiteStore, error) {
 	if db == nil {
 		return nil, fmt.Errorf("sqlite db is nil")
 	}
-	if err := applyMigrations(db); err != nil {
+	if err := state.ApplyMigrations(db, migrationsFS, "migrations"); err != nil {
 		return nil, err
 	}
 	return &SQLiteStore{db: db}, nil
@@ -95,34 +93,3 @@ func (s *SQLiteStore) Save(ctx context.Context, limits RuntimeLimits) error {
 	}
 	return nil
 }
-
-func applyMigrations(db *sql.DB) error {
-	entries, err := fs.ReadDir(migrationsFS, "migrations")
-	if err != nil {
-		return fmt.Errorf("read migrations: %w", err)
-	}
-	sort.Slice(entries, func(i, j int) bool {
-		return entries[i].Name() < entries[j].Name()
-	})
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-		name := entry.Name()
-		content, err := migrationsFS.ReadFile("migrations/" + name)
-		if err != nil {
-			return fmt.Errorf("read migration %s: %w", name, err)
-		}
-		statements := strings.Split(string(content), ";")
-		for _, stmt := range statements {
-			stmt = strings.TrimSpace(stmt)
-			if stmt == "" {
-				continue
-			}
-			if _, err := db.Exec(stmt); err != nil {
-				return fmt.Errorf("apply migration %s: %w", name, err)
-			}
-		}
-	}
-	return nil
-}
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS runs (
+    id TEXT PRIMARY KEY,
+    kind TEXT NOT NULL,
+    status TEXT NOT NULL,
+    request TEXT NOT NULL,
+    plan TEXT,
+    error TEXT NOT NULL DEFAULT '',
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL,
+    finished_at INTEGER
+);
+
+CREATE INDEX IF NOT EXISTS runs_finished_at ON runs (finished_at);
+
+CREATE TABLE IF NOT EXISTS run_steps (
+    run_id TEXT NOT NULL,
+    step_index INTEGER NOT NULL,
+    step_id TEXT NOT NULL DEFAULT '',
+    tool_id TEXT NOT NULL,
+    status TEXT NOT NULL,
+    value TEXT,
+    error TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (run_id, step_index)
+);
@@ -0,0 +1,227 @@
+package runs
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jonwraymond/metatools-mcp/internal/state"
+	_ "modernc.org/sqlite" // register sqlite driver
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// SQLiteStore persists run records in SQLite.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens a SQLite database and applies migrations.
+func OpenSQLite(path string) (*SQLiteStore, func() error, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open sqlite: %w", err)
+	}
+	// Concurrent runs record steps at once; one connection serializes the
+	// writes instead of failing them with SQLITE_BUSY.
+	db.SetMaxOpenConns(1)
+	store, err := NewSQLiteStore(db)
+	if err != nil {
+		_ = db.Close()
+		return nil, nil, err
+	}
+	return store, db.Close, nil
+}
+
+// NewSQLiteStore creates a store and applies migrations.
+func NewSQLiteStore(db *sql.DB) (*SQLiteStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("sqlite db is nil")
+	}
+	if err := state.ApplyMigrations(db, migrationsFS, "migrations"); err != nil {
+		return nil, err
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// Create inserts a new run.
+func (s *SQLiteStore) Create(ctx context.Context, run Run) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("sqlite store not configured")
+	}
+	now := time.Now().UTC()
+	if run.CreatedAt.IsZero() {
+		run.CreatedAt = now
+	}
+	var plan any
+	if len(run.Plan) > 0 {
+		plan = string(run.Plan)
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO runs (id, kind, status, request, plan, error, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, run.ID, run.Kind, run.Status, string(run.Request), plan, run.Error,
+		run.CreatedAt.UnixMilli(), now.UnixMilli())
+	if err != nil {
+		return fmt.Errorf("create run: %w", err)
+	}
+	return nil
+}
+
+// Get returns a run with its steps.
+func (s *SQLiteStore) Get(ctx context.Context, id string) (Run, error) {
+	if s == nil || s.db == nil {
+		return Run{}, fmt.Errorf("sqlite store not configured")
+	}
+	row := s.db.QueryRowContext(ctx, `
+		SELECT id, kind, status, request, plan, error, created_at, updated_at, finished_at
+		FROM runs
+		WHERE id = ?`, id)
+
+	var run Run
+	var request string
+	var plan sql.NullString
+	var createdAt, updatedAt int64
+	var finishedAt sql.NullInt64
+	if err := row.Scan(&run.ID, &run.Kind, &run.Status, &request, &plan, &run.Error, &createdAt, &updatedAt, &finishedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Run{}, fmt.Errorf("%w: %q", ErrNotFound, id)
+		}
+		return Run{}, fmt.Errorf("load run: %w", err)
+	}
+	run.Request = json.RawMessage(request)
+	if plan.Valid {
+		run.Plan = json.RawMessage(plan.String)
+	}
+	run.CreatedAt = time.UnixMilli(createdAt).UTC()
+	run.UpdatedAt = time.UnixMilli(updatedAt).UTC()
+	if finishedAt.Valid {
+		run.FinishedAt = time.UnixMilli(finishedAt.Int64).UTC()
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT step_index, step_id, tool_id, status, value, error
+		FROM run_steps
+		WHERE run_id = ?
+		ORDER BY step_index`, id)
+	if err != nil {
+		return Run{}, fmt.Errorf("load run steps: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var step Step
+		var value sql.NullString
+		if err := rows.Scan(&step.Index, &step.ID, &step.ToolID, &step.Status, &value, &step.Error); err != nil {
+			return Run{}, fmt.Errorf("load run steps: %w", err)
+		}
+		if value.Valid {
+			if err := json.Unmarshal([]byte(value.String), &step.Value); err != nil {
+				return Run{}, fmt.Errorf("decode step %d value: %w", step.Index, err)
+			}
+		}
+		run.Steps = append(run.Steps, step)
+	}
+	if err := rows.Err(); err != nil {
+		return Run{}, fmt.Errorf("load run steps: %w", err)
+	}
+	return run, nil
+}
+
+// SaveStep records the outcome of a step, replacing an earlier one.
+func (s *SQLiteStore) SaveStep(ctx context.Context, runID string, step Step) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("sqlite store not configured")
+	}
+	var value any
+	if step.Value != nil {
+		data, err := json.Marshal(step.Value)
+		if err != nil {
+			return fmt.Errorf("encode step %d value: %w", step.Index, err)
+		}
+		value = string(data)
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("save run step: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO run_steps (run_id, step_index, step_id, tool_id, status, value, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(run_id, step_index) DO UPDATE SET
+			step_id = excluded.step_id,
+			tool_id = excluded.tool_id,
+			status = excluded.status,
+			value = excluded.value,
+			error = excluded.error
+	`, runID, step.Index, step.ID, step.ToolID, step.Status, value, step.Error); err != nil {
+		return fmt.Errorf("save run step: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE runs SET updated_at = ? WHERE id = ?`,
+		time.Now().UTC().UnixMilli(), runID); err != nil {
+		return fmt.Errorf("save run step: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("save run step: %w", err)
+	}
+	return nil
+}
+
+// SetStatus updates a run's status and error.
+func (s *SQLiteStore) SetStatus(ctx context.Context, id, status, errMsg string) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("sqlite store not configured")
+	}
+	now := time.Now().UTC().UnixMilli()
+	var finishedAt any
+	if status != StatusRunning {
+		finishedAt = now
+	}
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE runs SET status = ?, error = ?, updated_at = ?, finished_at = ?
+		WHERE id = ?`, status, errMsg, now, finishedAt, id)
+	if err != nil {
+		return fmt.Errorf("update run status: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: %q", ErrNotFound, id)
+	}
+	return nil
+}
+
+// Prune deletes runs that finished before the given time.
+func (s *SQLiteStore) Prune(ctx context.Context, finishedBefore time.Time) (int, error) {
+	if s == nil || s.db == nil {
+		return 0, fmt.Errorf("sqlite store not configured")
+	}
+	cutoff := finishedBefore.UTC().UnixMilli()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("prune runs: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM run_steps WHERE run_id IN (
+			SELECT id FROM runs WHERE finished_at IS NOT NULL AND finished_at < ?
+		)`, cutoff); err != nil {
+		return 0, fmt.Errorf("prune runs: %w", err)
+	}
+	res, err := tx.ExecContext(ctx, `
+		DELETE FROM runs WHERE finished_at IS NOT NULL AND finished_at < ?`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("prune runs: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("prune runs: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("prune runs: %w", err)
+	}
+	return int(n), nil
+}
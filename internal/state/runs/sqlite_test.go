@@ -0,0 +1,117 @@
+package runs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+	store, err := NewSQLiteStore(db)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	return store
+}
+
+func TestSQLiteStore_RunLifecycle(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if _, err := store.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get(missing) error = %v, want ErrNotFound", err)
+	}
+
+	err := store.Create(ctx, Run{
+		ID:      "r1",
+		Kind:    KindSkill,
+		Status:  StatusRunning,
+		Request: json.RawMessage(`{"skill_id":"skill:demo"}`),
+		Plan:    json.RawMessage(`{"name":"demo"}`),
+	})
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+	if err := store.SaveStep(ctx, "r1", Step{Index: 1, ID: "b", ToolID: "t:b", Status: StatusFailed, Error: "boom"}); err != nil {
+		t.Fatalf("SaveStep() error: %v", err)
+	}
+	if err := store.SaveStep(ctx, "r1", Step{Index: 0, ID: "a", ToolID: "t:a", Status: StatusSucceeded, Value: map[string]any{"n": 1}}); err != nil {
+		t.Fatalf("SaveStep() error: %v", err)
+	}
+	// A later attempt replaces the earlier outcome.
+	if err := store.SaveStep(ctx, "r1", Step{Index: 1, ID: "b", ToolID: "t:b", Status: StatusSucceeded, Value: "ok"}); err != nil {
+		t.Fatalf("SaveStep() error: %v", err)
+	}
+
+	run, err := store.Get(ctx, "r1")
+	if err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+	if run.Finished() || !run.FinishedAt.IsZero() {
+		t.Fatalf("run finished early: status=%s finished_at=%v", run.Status, run.FinishedAt)
+	}
+	if string(run.Request) != `{"skill_id":"skill:demo"}` || string(run.Plan) != `{"name":"demo"}` {
+		t.Fatalf("request/plan = %s / %s", run.Request, run.Plan)
+	}
+	if len(run.Steps) != 2 || run.Steps[0].ID != "a" || run.Steps[1].Status != StatusSucceeded || run.Steps[1].Error != "" {
+		t.Fatalf("steps = %+v", run.Steps)
+	}
+	if v, ok := run.Steps[0].Value.(map[string]any); !ok || v["n"] != float64(1) {
+		t.Fatalf("step value = %#v", run.Steps[0].Value)
+	}
+
+	if err := store.SetStatus(ctx, "r1", StatusFailed, "timeout"); err != nil {
+		t.Fatalf("SetStatus() error: %v", err)
+	}
+	run, err = store.Get(ctx, "r1")
+	if err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+	if run.Status != StatusFailed || run.Error != "timeout" || run.FinishedAt.IsZero() {
+		t.Fatalf("after SetStatus: %+v", run)
+	}
+	if err := store.SetStatus(ctx, "missing", StatusFailed, ""); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("SetStatus(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSQLiteStore_Prune(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	for _, id := range []string{"done", "active"} {
+		if err := store.Create(ctx, Run{ID: id, Kind: KindChain, Status: StatusRunning, Request: json.RawMessage(`{}`)}); err != nil {
+			t.Fatalf("Create(%s) error: %v", id, err)
+		}
+		if err := store.SaveStep(ctx, id, Step{Index: 0, ToolID: "t:a", Status: StatusSucceeded}); err != nil {
+			t.Fatalf("SaveStep(%s) error: %v", id, err)
+		}
+	}
+	if err := store.SetStatus(ctx, "done", StatusSucceeded, ""); err != nil {
+		t.Fatalf("SetStatus() error: %v", err)
+	}
+
+	n, err := store.Prune(ctx, time.Now().Add(-time.Hour))
+	if err != nil || n != 0 {
+		t.Fatalf("Prune(past) = %d, %v; want 0", n, err)
+	}
+	n, err = store.Prune(ctx, time.Now().Add(time.Minute))
+	if err != nil || n != 1 {
+		t.Fatalf("Prune(now) = %d, %v; want 1", n, err)
+	}
+	if _, err := store.Get(ctx, "done"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("pruned run still present: %v", err)
+	}
+	if _, err := store.Get(ctx, "active"); err != nil {
+		t.Fatalf("running run was pruned: %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+// Package runs persists run_chain and run_skill executions so they can be
+// inspected, resumed and canceled.
+package runs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Run kinds.
+const (
+	KindChain = "chain"
+	KindSkill = "skill"
+)
+
+// Run and step statuses.
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCanceled  = "canceled"
+)
+
+// ErrNotFound is returned for unknown run IDs.
+var ErrNotFound = errors.New("run not found")
+
+// Run is the record of one chain or skill execution.
+type Run struct {
+	ID     string
+	Kind   string
+	Status string
+	// Request is the run_chain or run_skill input the run started from.
+	Request json.RawMessage
+	// Plan is the compiled plan for skill runs; empty for chains.
+	Plan  json.RawMessage
+	Error string
+	// Steps are the recorded step outcomes ordered by index.
+	Steps      []Step
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	FinishedAt time.Time
+}
+
+// Finished reports whether the run reached a final status.
+func (r Run) Finished() bool {
+	return r.Status != StatusRunning
+}
+
+// Step is the latest outcome of one step of a run.
+type Step struct {
+	// Index is the step's position in the chain, or in the skill plan's
+	// step order.
+	Index  int
+	ID     string
+	ToolID string
+	Status string
+	Value  any
+	Error  string
+}
+
+// Store persists run records.
+type Store interface {
+	// Create inserts a new run.
+	Create(ctx context.Context, run Run) error
+	// Get returns a run with its steps. Unknown IDs return ErrNotFound.
+	Get(ctx context.Context, id string) (Run, error)
+	// SaveStep records the outcome of a step, replacing an earlier one.
+	SaveStep(ctx context.Context, runID string, step Step) error
+	// SetStatus updates a run's status and error. Statuses other than
+	// StatusRunning also set the finish time.
+	SetStatus(ctx context.Context, id, status, errMsg string) error
+	// Prune deletes runs that finished before the given time and returns how
+	// many were removed.
+	Prune(ctx context.Context, finishedBefore time.Time) (int, error)
+}
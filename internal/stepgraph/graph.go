@@ -0,0 +1,217 @@
+// Package stepgraph orders chain and skill steps by their dependencies and
+// runs independent steps concurrently with a bounded worker pool.
+package stepgraph
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	merrors "github.com/jonwraymond/metatools-mcp/internal/errors"
+	"github.com/jonwraymond/metatools-mcp/internal/stepref"
+)
+
+// DefaultMaxParallel is the worker pool size used when none is configured.
+const DefaultMaxParallel = 4
+
+// Errors returned by Build. Both wrap errors.ErrValidationInput.
+var (
+	ErrCycle       = fmt.Errorf("%w: dependency cycle", merrors.ErrValidationInput)
+	ErrUnknownStep = fmt.Errorf("%w: unknown step", merrors.ErrValidationInput)
+)
+
+// Spec describes a step for graph construction.
+type Spec struct {
+	ID        string
+	DependsOn []string
+	// Args is scanned for step references, which become dependencies.
+	Args map[string]any
+	// UsePrevious makes the step depend on the step declared before it.
+	UsePrevious bool
+	// OnError is validated, and references in its fallback args also become
+	// dependencies.
+	OnError *Policy
+}
+
+// refArgs returns the args scanned for references.
+func (s Spec) refArgs() map[string]any {
+	if s.OnError == nil || s.OnError.Args == nil {
+		return s.Args
+	}
+	return map[string]any{"args": s.Args, "on_error": s.OnError.Args}
+}
+
+// Graph is a validated, acyclic step dependency graph. Step indexes refer to
+// positions in the specs passed to Build.
+type Graph struct {
+	ids   []string
+	deps  [][]int
+	order []int
+}
+
+// Build validates dependencies and computes a topological order.
+//
+// When no step declares DependsOn, each step depends on the one before it, so
+// steps run strictly in sequence. Otherwise steps depend only on what they
+// declare, reference, or consume through UsePrevious, and independent steps
+// may run concurrently. Ties in the order keep declaration order.
+func Build(specs []Spec) (*Graph, error) {
+	index := make(map[string]int, len(specs))
+	for i, spec := range specs {
+		if spec.ID == "" {
+			continue
+		}
+		if _, dup := index[spec.ID]; dup {
+			return nil, fmt.Errorf("%w: duplicate step id %q", merrors.ErrValidationInput, spec.ID)
+		}
+		index[spec.ID] = i
+	}
+	sequential := !slices.ContainsFunc(specs, func(s Spec) bool { return s.DependsOn != nil })
+
+	g := &Graph{ids: make([]string, len(specs)), deps: make([][]int, len(specs))}
+	for i, spec := range specs {
+		g.ids[i] = spec.ID
+		if err := spec.OnError.Validate(); err != nil {
+			return nil, fmt.Errorf("step %s: %w", g.label(i), err)
+		}
+		var deps []int
+		add := func(dep int) {
+			if !slices.Contains(deps, dep) {
+				deps = append(deps, dep)
+			}
+		}
+
+		if sequential {
+			prior := make([]string, i)
+			copy(prior, g.ids[:i])
+			if err := stepref.Check(spec.refArgs(), prior); err != nil {
+				return nil, fmt.Errorf("step %s: %w", g.label(i), err)
+			}
+			if i > 0 {
+				add(i - 1)
+			}
+			g.deps[i] = deps
+			continue
+		}
+
+		for _, name := range spec.DependsOn {
+			dep, ok := index[name]
+			if !ok {
+				return nil, fmt.Errorf("%w %q in depends_on of step %s", ErrUnknownStep, name, g.label(i))
+			}
+			add(dep)
+		}
+		refs, err := stepref.Collect(spec.refArgs())
+		if err != nil {
+			return nil, fmt.Errorf("step %s: %w", g.label(i), err)
+		}
+		for _, ref := range refs {
+			dep := ref.Index
+			if dep < 0 {
+				var ok bool
+				if dep, ok = index[ref.StepID]; !ok {
+					return nil, fmt.Errorf("%w %q referenced by step %s", ErrUnknownStep, ref.StepID, g.label(i))
+				}
+			} else if dep >= len(specs) {
+				return nil, fmt.Errorf("%w %q referenced by step %s", ErrUnknownStep, ref.Step(), g.label(i))
+			}
+			add(dep)
+		}
+		if spec.UsePrevious && i > 0 {
+			add(i - 1)
+		}
+		slices.Sort(deps)
+		g.deps[i] = deps
+	}
+
+	order, err := g.topoSort()
+	if err != nil {
+		return nil, err
+	}
+	g.order = order
+	return g, nil
+}
+
+// Len returns the number of steps.
+func (g *Graph) Len() int {
+	return len(g.ids)
+}
+
+// Order returns step indexes in topological order.
+func (g *Graph) Order() []int {
+	return slices.Clone(g.order)
+}
+
+// DependsOn returns the indexes step i waits for.
+func (g *Graph) DependsOn(i int) []int {
+	return slices.Clone(g.deps[i])
+}
+
+// topoSort runs Kahn's algorithm, always releasing the lowest ready index.
+func (g *Graph) topoSort() ([]int, error) {
+	n := len(g.ids)
+	indegree := make([]int, n)
+	dependents := make([][]int, n)
+	for i, deps := range g.deps {
+		indegree[i] = len(deps)
+		for _, dep := range deps {
+			dependents[dep] = append(dependents[dep], i)
+		}
+	}
+	var ready []int
+	for i := range n {
+		if indegree[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	order := make([]int, 0, n)
+	for len(ready) > 0 {
+		next := ready[0]
+		ready = ready[1:]
+		order = append(order, next)
+		for _, d := range dependents[next] {
+			indegree[d]--
+			if indegree[d] == 0 {
+				pos, _ := slices.BinarySearch(ready, d)
+				ready = slices.Insert(ready, pos, d)
+			}
+		}
+	}
+	if len(order) < n {
+		return nil, fmt.Errorf("%w: %s", ErrCycle, g.describeCycle(indegree))
+	}
+	return order, nil
+}
+
+// describeCycle walks unreleased steps to name one cycle, e.g. "a -> b -> a".
+func (g *Graph) describeCycle(indegree []int) string {
+	start := slices.IndexFunc(indegree, func(d int) bool { return d > 0 })
+	seen := make(map[int]int)
+	var path []int
+	for cur := start; ; {
+		if at, ok := seen[cur]; ok {
+			path = append(path[at:], cur)
+			break
+		}
+		seen[cur] = len(path)
+		path = append(path, cur)
+		for _, dep := range g.deps[cur] {
+			if indegree[dep] > 0 {
+				cur = dep
+				break
+			}
+		}
+	}
+	names := make([]string, len(path))
+	for i, idx := range path {
+		names[len(path)-1-i] = g.label(idx)
+	}
+	return strings.Join(names, " -> ")
+}
+
+func (g *Graph) label(i int) string {
+	if g.ids[i] != "" {
+		return fmt.Sprintf("%q", g.ids[i])
+	}
+	return fmt.Sprintf("%d", i)
+}
@@ -0,0 +1,209 @@
+package stepgraph
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	merrors "github.com/jonwraymond/metatools-mcp/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild_SequentialWithoutDependsOn(t *testing.T) {
+	g, err := Build([]Spec{{ID: "a"}, {ID: "b"}, {}})
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2}, g.Order())
+	assert.Equal(t, []int{1}, g.DependsOn(2))
+
+	_, err = Build([]Spec{{ID: "a", Args: map[string]any{"x": "${steps.b.result}"}}, {ID: "b"}})
+	assert.ErrorIs(t, err, merrors.ErrValidationInput)
+	assert.ErrorContains(t, err, `step "b" does not run before this step`)
+}
+
+func TestBuild_DependsOn(t *testing.T) {
+	g, err := Build([]Spec{
+		{ID: "report", DependsOn: []string{"issues", "pulls"}},
+		{ID: "issues", DependsOn: []string{}},
+		{ID: "pulls"},
+		{ID: "notify", Args: map[string]any{"text": "${steps.report.result}"}},
+		{ID: "log", UsePrevious: true},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 0, 3, 4}, g.Order())
+	assert.Equal(t, []int{1, 2}, g.DependsOn(0))
+	assert.Equal(t, []int{0}, g.DependsOn(3))
+	assert.Equal(t, []int{3}, g.DependsOn(4))
+	assert.Empty(t, g.DependsOn(2))
+}
+
+func TestBuild_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		specs []Spec
+		is    error
+		want  string
+	}{
+		{
+			name:  "cycle",
+			specs: []Spec{{ID: "a", DependsOn: []string{"c"}}, {ID: "b", DependsOn: []string{"a"}}, {ID: "c", DependsOn: []string{"b"}}},
+			is:    ErrCycle,
+			want:  `"a" -> "b" -> "c" -> "a"`,
+		},
+		{
+			name:  "self reference",
+			specs: []Spec{{ID: "a", DependsOn: []string{}, Args: map[string]any{"x": "${steps.a.result}"}}},
+			is:    ErrCycle,
+			want:  `"a" -> "a"`,
+		},
+		{
+			name:  "unknown dependency",
+			specs: []Spec{{ID: "a", DependsOn: []string{"missing"}}},
+			is:    ErrUnknownStep,
+			want:  `"missing" in depends_on of step "a"`,
+		},
+		{
+			name:  "unknown reference",
+			specs: []Spec{{ID: "a", DependsOn: []string{}, Args: map[string]any{"x": "${steps[3].result}"}}},
+			is:    ErrUnknownStep,
+		},
+		{
+			name:  "duplicate id",
+			specs: []Spec{{ID: "a"}, {ID: "a"}},
+			want:  `duplicate step id "a"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Build(tt.specs)
+			require.Error(t, err)
+			assert.ErrorIs(t, err, merrors.ErrValidationInput)
+			if tt.is != nil {
+				assert.ErrorIs(t, err, tt.is)
+			}
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestRun_ParallelBranchesReportInTopologicalOrder(t *testing.T) {
+	g, err := Build([]Spec{
+		{ID: "slow", DependsOn: []string{}},
+		{ID: "fast", DependsOn: []string{}},
+		{ID: "join", DependsOn: []string{"slow", "fast"}},
+	})
+	require.NoError(t, err)
+
+	// Both roots must be running at once to get past the barrier.
+	var barrier sync.WaitGroup
+	barrier.Add(2)
+	var mu sync.Mutex
+	var started []int
+	var reported []int
+	ran, err := g.Run(context.Background(), Options{
+		MaxParallel: 2,
+		OnStep:      func(idx int, _ error) { reported = append(reported, idx) },
+	}, func(_ context.Context, idx int) error {
+		mu.Lock()
+		started = append(started, idx)
+		mu.Unlock()
+		if idx == 2 {
+			return nil
+		}
+		barrier.Done()
+		waited := make(chan struct{})
+		go func() {
+			barrier.Wait()
+			close(waited)
+		}()
+		select {
+		case <-waited:
+		case <-time.After(time.Second):
+			return errors.New("roots did not run in parallel")
+		}
+		if idx == 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2}, ran)
+	assert.Equal(t, []int{0, 1, 2}, reported)
+	assert.Equal(t, 2, started[2], "join starts after both roots")
+}
+
+func TestRun_StopsAfterFailure(t *testing.T) {
+	g, err := Build([]Spec{
+		{ID: "a", DependsOn: []string{}},
+		{ID: "b", DependsOn: []string{"a"}},
+		{ID: "c", DependsOn: []string{}},
+	})
+	require.NoError(t, err)
+
+	boom := errors.New("boom")
+	ran, err := g.Run(context.Background(), Options{MaxParallel: 1}, func(_ context.Context, idx int) error {
+		if idx == 0 {
+			return boom
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, boom)
+	assert.Equal(t, []int{0}, ran, "no step starts after a failure")
+}
+
+func TestRun_ReportsFinishedStepsBehindUnstartedOnes(t *testing.T) {
+	g, err := Build([]Spec{
+		{ID: "a", DependsOn: []string{}},
+		{ID: "b", DependsOn: []string{"a"}},
+		{ID: "c", DependsOn: []string{}},
+	})
+	require.NoError(t, err)
+
+	boom := errors.New("boom")
+	var reported []int
+	ran, err := g.Run(context.Background(), Options{
+		MaxParallel: 2,
+		OnStep:      func(idx int, _ error) { reported = append(reported, idx) },
+	}, func(_ context.Context, idx int) error {
+		if idx == 0 {
+			time.Sleep(10 * time.Millisecond)
+			return boom
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, boom)
+	assert.Equal(t, []int{0, 2}, ran)
+	assert.Equal(t, []int{0, 2}, reported)
+}
+
+func TestRun_BudgetSharedAcrossBranches(t *testing.T) {
+	g, err := Build([]Spec{
+		{ID: "a", DependsOn: []string{}},
+		{ID: "b", DependsOn: []string{}},
+		{ID: "c", DependsOn: []string{}},
+	})
+	require.NoError(t, err)
+
+	budget := NewCallBudget(2)
+	ran, err := g.Run(context.Background(), Options{MaxParallel: 1, Budget: budget}, func(context.Context, int) error {
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrMaxCalls)
+	assert.Equal(t, []int{0, 1, 2}, ran)
+	assert.Equal(t, 2, budget.Used())
+}
+
+func TestRun_ContextCanceled(t *testing.T) {
+	g, err := Build([]Spec{{ID: "a"}, {ID: "b"}})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ran, err := g.Run(ctx, Options{}, func(context.Context, int) error {
+		cancel()
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []int{0}, ran)
+}
@@ -0,0 +1,157 @@
+package stepgraph
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	merrors "github.com/jonwraymond/metatools-mcp/internal/errors"
+)
+
+// Error policy actions.
+const (
+	ActionFail     = "fail"
+	ActionContinue = "continue"
+	ActionRetry    = "retry"
+	ActionFallback = "fallback"
+)
+
+// Retry defaults and limits.
+const (
+	DefaultRetryAttempts = 3
+	MaxRetryAttempts     = 10
+	DefaultRetryBackoff  = 100 * time.Millisecond
+)
+
+// Policy is a step's on_error policy. The zero value fails the step.
+type Policy struct {
+	Action string
+	// Attempts is the total number of calls for retry, including the first.
+	Attempts int
+	// Backoff is the delay before the first retry; it doubles per attempt.
+	Backoff time.Duration
+	// ToolID and Args describe the fallback call. Nil Args reuse the step's
+	// own args.
+	ToolID string
+	Args   map[string]any
+}
+
+// Validate checks the policy for configuration errors.
+func (p *Policy) Validate() error {
+	if p == nil {
+		return nil
+	}
+	switch p.Action {
+	case "", ActionFail, ActionContinue:
+	case ActionRetry:
+		if p.Attempts < 0 || p.Attempts > MaxRetryAttempts {
+			return fmt.Errorf("%w: on_error attempts must be 0-%d", merrors.ErrValidationInput, MaxRetryAttempts)
+		}
+		if p.Backoff < 0 {
+			return fmt.Errorf("%w: on_error backoff cannot be negative", merrors.ErrValidationInput)
+		}
+	case ActionFallback:
+		if p.ToolID == "" {
+			return fmt.Errorf("%w: on_error fallback requires tool_id", merrors.ErrValidationInput)
+		}
+	default:
+		return fmt.Errorf("%w: unknown on_error action %q (want fail, continue, retry or fallback)", merrors.ErrValidationInput, p.Action)
+	}
+	return nil
+}
+
+// Report describes how a policy was applied to a failing step.
+type Report struct {
+	Action string
+	// Attempts counts calls to the step's own tool when retrying.
+	Attempts int
+	// FallbackToolID is set when the fallback tool ran.
+	FallbackToolID string
+	// Cause is the step's own error when the policy recovered from it, or
+	// the error recorded by continue.
+	Cause error
+}
+
+// Continued reports whether the step's error was recorded by continue rather
+// than failing the run.
+func (r *Report) Continued() bool {
+	return r != nil && r.Action == ActionContinue
+}
+
+// CallFunc calls toolID with args. Step references in args are resolved by
+// the callee so fallback args can use them too.
+type CallFunc func(ctx context.Context, toolID string, args map[string]any) error
+
+// Apply calls toolID and handles a failure according to the policy. Extra
+// calls for retries and fallbacks are charged to budget.
+//
+// The returned report is nil when the first call succeeded or the policy is
+// fail. A non-nil error fails the step; with continue, the error is returned
+// in Report.Cause and err is nil.
+func (p *Policy) Apply(ctx context.Context, budget *CallBudget, toolID string, args map[string]any, call CallFunc) (*Report, error) {
+	err := call(ctx, toolID, args)
+	if err == nil || p == nil || ctx.Err() != nil {
+		return nil, err
+	}
+
+	switch p.Action {
+	case ActionContinue:
+		return &Report{Action: ActionContinue, Cause: err}, nil
+	case ActionRetry:
+		attempts := p.Attempts
+		if attempts == 0 {
+			attempts = DefaultRetryAttempts
+		}
+		backoff := p.Backoff
+		if backoff == 0 {
+			backoff = DefaultRetryBackoff
+		}
+		report := &Report{Action: ActionRetry, Attempts: 1}
+		for report.Attempts < attempts && retryable(err, toolID) {
+			if waitErr := sleep(ctx, backoff); waitErr != nil {
+				return report, err
+			}
+			if takeErr := budget.Take(); takeErr != nil {
+				return report, takeErr
+			}
+			backoff *= 2
+			report.Attempts++
+			cause := err
+			if err = call(ctx, toolID, args); err == nil {
+				report.Cause = cause
+				return report, nil
+			}
+		}
+		return report, err
+	case ActionFallback:
+		if takeErr := budget.Take(); takeErr != nil {
+			return nil, takeErr
+		}
+		fallbackArgs := p.Args
+		if fallbackArgs == nil {
+			fallbackArgs = args
+		}
+		report := &Report{Action: ActionFallback, FallbackToolID: p.ToolID, Cause: err}
+		if fbErr := call(ctx, p.ToolID, fallbackArgs); fbErr != nil {
+			return report, fmt.Errorf("fallback %s: %w (after %v)", p.ToolID, fbErr, err)
+		}
+		return report, nil
+	default:
+		return nil, err
+	}
+}
+
+func retryable(err error, toolID string) bool {
+	return merrors.MapToolError(err, toolID, nil, -1).Retryable
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,154 @@
+package stepgraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	merrors "github.com/jonwraymond/metatools-mcp/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyCall fails the first failures calls to toolID with err.
+func flakyCall(failures int, err error, calls *[]string) CallFunc {
+	return func(_ context.Context, toolID string, _ map[string]any) error {
+		*calls = append(*calls, toolID)
+		if len(*calls) <= failures {
+			return err
+		}
+		return nil
+	}
+}
+
+var errFlaky = fmt.Errorf("%w: flaky", merrors.ErrExecution)
+
+func TestPolicy_Validate(t *testing.T) {
+	valid := []*Policy{nil, {}, {Action: ActionFail}, {Action: ActionContinue}, {Action: ActionRetry}, {Action: ActionFallback, ToolID: "x"}}
+	for _, p := range valid {
+		assert.NoError(t, p.Validate())
+	}
+
+	invalid := []*Policy{
+		{Action: "skip"},
+		{Action: ActionRetry, Attempts: MaxRetryAttempts + 1},
+		{Action: ActionRetry, Backoff: -time.Second},
+		{Action: ActionFallback},
+	}
+	for _, p := range invalid {
+		assert.ErrorIs(t, p.Validate(), merrors.ErrValidationInput, p.Action)
+	}
+}
+
+func TestPolicy_ApplyFailAndNil(t *testing.T) {
+	var calls []string
+	report, err := (*Policy)(nil).Apply(context.Background(), nil, "t", nil, flakyCall(1, errFlaky, &calls))
+	assert.ErrorIs(t, err, errFlaky)
+	assert.Nil(t, report)
+
+	calls = nil
+	report, err = (&Policy{Action: ActionRetry}).Apply(context.Background(), nil, "t", nil, flakyCall(0, nil, &calls))
+	assert.NoError(t, err)
+	assert.Nil(t, report, "no report when the first call succeeds")
+}
+
+func TestPolicy_ApplyContinue(t *testing.T) {
+	var calls []string
+	report, err := (&Policy{Action: ActionContinue}).Apply(context.Background(), nil, "t", nil, flakyCall(1, errFlaky, &calls))
+	require.NoError(t, err)
+	assert.True(t, report.Continued())
+	assert.ErrorIs(t, report.Cause, errFlaky)
+}
+
+func TestPolicy_ApplyRetry(t *testing.T) {
+	policy := &Policy{Action: ActionRetry, Attempts: 3, Backoff: time.Millisecond}
+
+	var calls []string
+	report, err := policy.Apply(context.Background(), nil, "t", nil, flakyCall(2, errFlaky, &calls))
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Attempts)
+	assert.ErrorIs(t, report.Cause, errFlaky)
+	assert.Len(t, calls, 3)
+
+	calls = nil
+	report, err = policy.Apply(context.Background(), nil, "t", nil, flakyCall(5, errFlaky, &calls))
+	assert.ErrorIs(t, err, errFlaky)
+	assert.Equal(t, 3, report.Attempts)
+
+	// Only retryable errors are retried.
+	calls = nil
+	invalid := fmt.Errorf("%w: bad arg", merrors.ErrValidationInput)
+	report, err = policy.Apply(context.Background(), nil, "t", nil, flakyCall(1, invalid, &calls))
+	assert.ErrorIs(t, err, invalid)
+	assert.Equal(t, 1, report.Attempts)
+	assert.Len(t, calls, 1)
+}
+
+func TestPolicy_ApplyRetryChargesBudget(t *testing.T) {
+	budget := NewCallBudget(2)
+	require.NoError(t, budget.Take())
+
+	var calls []string
+	policy := &Policy{Action: ActionRetry, Attempts: 5, Backoff: time.Millisecond}
+	report, err := policy.Apply(context.Background(), budget, "t", nil, flakyCall(5, errFlaky, &calls))
+	assert.ErrorIs(t, err, ErrMaxCalls)
+	assert.Equal(t, 2, report.Attempts)
+	assert.Len(t, calls, 2)
+}
+
+func TestPolicy_ApplyFallback(t *testing.T) {
+	var gotTool string
+	var gotArgs map[string]any
+	call := func(_ context.Context, toolID string, args map[string]any) error {
+		if toolID == "primary" {
+			return errFlaky
+		}
+		gotTool, gotArgs = toolID, args
+		return nil
+	}
+
+	policy := &Policy{Action: ActionFallback, ToolID: "backup", Args: map[string]any{"mode": "cached"}}
+	report, err := policy.Apply(context.Background(), nil, "primary", map[string]any{"q": "x"}, call)
+	require.NoError(t, err)
+	assert.Equal(t, "backup", report.FallbackToolID)
+	assert.ErrorIs(t, report.Cause, errFlaky)
+	assert.Equal(t, "backup", gotTool)
+	assert.Equal(t, map[string]any{"mode": "cached"}, gotArgs)
+
+	// Without args the step's own args are reused.
+	policy.Args = nil
+	_, err = policy.Apply(context.Background(), nil, "primary", map[string]any{"q": "x"}, call)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"q": "x"}, gotArgs)
+
+	// A failing fallback fails the step with both errors.
+	boom := errors.New("backup down")
+	report, err = policy.Apply(context.Background(), nil, "primary", nil, func(_ context.Context, toolID string, _ map[string]any) error {
+		if toolID == "primary" {
+			return errFlaky
+		}
+		return boom
+	})
+	assert.ErrorIs(t, err, boom)
+	assert.ErrorContains(t, err, "flaky")
+	assert.Equal(t, "backup", report.FallbackToolID)
+}
+
+func TestBuild_ValidatesPolicyAndFallbackReferences(t *testing.T) {
+	_, err := Build([]Spec{{ID: "a", OnError: &Policy{Action: "skip"}}})
+	assert.ErrorIs(t, err, merrors.ErrValidationInput)
+	assert.ErrorContains(t, err, `step "a"`)
+
+	g, err := Build([]Spec{
+		{ID: "use", DependsOn: []string{}, OnError: &Policy{
+			Action: ActionFallback,
+			ToolID: "backup",
+			Args:   map[string]any{"v": "${steps.src.result}"},
+		}},
+		{ID: "src", DependsOn: []string{}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, g.DependsOn(0))
+}
@@ -0,0 +1,179 @@
+package stepgraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync/atomic"
+)
+
+// ErrMaxCalls is returned when a step would exceed the call budget.
+var ErrMaxCalls = errors.New("max tool calls exceeded")
+
+// CallBudget caps the number of tool calls shared by every branch of a run.
+//
+// Contract:
+// - Concurrency: safe for concurrent use.
+type CallBudget struct {
+	max  int64
+	used atomic.Int64
+}
+
+// NewCallBudget returns a budget of max calls. max <= 0 means unlimited.
+func NewCallBudget(max int) *CallBudget {
+	return &CallBudget{max: int64(max)}
+}
+
+// Take reserves one call, returning ErrMaxCalls once the budget is spent.
+func (b *CallBudget) Take() error {
+	if b == nil {
+		return nil
+	}
+	used := b.used.Add(1)
+	if b.max > 0 && used > b.max {
+		b.used.Add(-1)
+		return fmt.Errorf("%w (limit %d)", ErrMaxCalls, b.max)
+	}
+	return nil
+}
+
+// Used returns the number of calls taken so far.
+func (b *CallBudget) Used() int {
+	if b == nil {
+		return 0
+	}
+	return int(b.used.Load())
+}
+
+// Options configures Run.
+type Options struct {
+	// MaxParallel bounds concurrently running steps. Zero or less uses
+	// DefaultMaxParallel.
+	MaxParallel int
+	// Budget, when set, is charged one call before each step starts.
+	Budget *CallBudget
+	// OnStep is called once per finished step, in topological order and never
+	// concurrently.
+	OnStep func(index int, err error)
+}
+
+// StepFunc executes the step at index. It runs only after every dependency
+// has succeeded and may be called concurrently for independent steps.
+type StepFunc func(ctx context.Context, index int) error
+
+type outcome struct {
+	index int
+	err   error
+}
+
+// Run executes the graph with a bounded worker pool.
+//
+// After the first failure no new steps start; steps already running finish
+// and are reported. Run returns the indexes of the steps that ran, in
+// topological order, and the error of the first failed step in that order.
+// If ctx is canceled before every step has run, the context error is
+// returned.
+func (g *Graph) Run(ctx context.Context, opts Options, fn StepFunc) ([]int, error) {
+	workers := opts.MaxParallel
+	if workers <= 0 {
+		workers = DefaultMaxParallel
+	}
+
+	n := len(g.ids)
+	pending := make([]int, n)
+	dependents := make([][]int, n)
+	for i, deps := range g.deps {
+		pending[i] = len(deps)
+		for _, dep := range deps {
+			dependents[dep] = append(dependents[dep], i)
+		}
+	}
+	// position ranks ready steps so they start in topological order.
+	position := make([]int, n)
+	for pos, idx := range g.order {
+		position[idx] = pos
+	}
+	var ready []int
+	release := func(idx int) {
+		at, _ := slices.BinarySearchFunc(ready, idx, func(a, b int) int { return position[a] - position[b] })
+		ready = slices.Insert(ready, at, idx)
+	}
+	for i := range n {
+		if pending[i] == 0 {
+			release(i)
+		}
+	}
+
+	finished := make([]bool, n)
+	errs := make([]error, n)
+	var ran []int
+	reported := 0
+	// report emits finished steps in topological order. Until the run is over
+	// it waits on the next unfinished step; at the end it skips steps that
+	// never started.
+	report := func(final bool) {
+		for ; reported < n; reported++ {
+			idx := g.order[reported]
+			if !finished[idx] {
+				if final {
+					continue
+				}
+				return
+			}
+			ran = append(ran, idx)
+			if opts.OnStep != nil {
+				opts.OnStep(idx, errs[idx])
+			}
+		}
+	}
+
+	done := make(chan outcome)
+	running := 0
+	failed := false
+	for {
+		for !failed && running < workers && len(ready) > 0 && ctx.Err() == nil {
+			idx := ready[0]
+			ready = ready[1:]
+			if err := opts.Budget.Take(); err != nil {
+				finished[idx], errs[idx], failed = true, err, true
+				break
+			}
+			running++
+			go func() {
+				done <- outcome{index: idx, err: fn(ctx, idx)}
+			}()
+		}
+		if running == 0 {
+			report(true)
+			break
+		}
+		report(false)
+
+		out := <-done
+		running--
+		finished[out.index], errs[out.index] = true, out.err
+		if out.err != nil {
+			failed = true
+			continue
+		}
+		for _, d := range dependents[out.index] {
+			pending[d]--
+			if pending[d] == 0 {
+				release(d)
+			}
+		}
+	}
+
+	for _, idx := range ran {
+		if errs[idx] != nil {
+			return ran, errs[idx]
+		}
+	}
+	if len(ran) < n {
+		if err := ctx.Err(); err != nil {
+			return ran, err
+		}
+	}
+	return ran, nil
+}
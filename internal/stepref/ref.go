@@ -0,0 +1,232 @@
+// Package stepref resolves references to earlier step results inside the
+// arguments of run_chain steps and skill step inputs.
+//
+// Two forms are recognized:
+//
+//	{"$ref": "steps.search.structured.items[0].id"}
+//	"${steps[1].result.url}"
+//
+// A reference starts with steps.<id> or steps[<index>], selects the step's
+// structured result with .structured (or its alias .result), and then walks
+// object keys (.key or ["key"]) and array indexes ([n]). A "$ref" object or
+// a string that is exactly one ${...} template is replaced by the referenced
+// value with its type intact; templates embedded in longer strings are
+// interpolated as text. Strings and objects that do not reference steps are
+// left untouched, so shell-style ${VAR} text and JSON Schema "$ref" values
+// pass through.
+package stepref
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	merrors "github.com/jonwraymond/metatools-mcp/internal/errors"
+)
+
+// Errors returned by Parse and Resolve. Both wrap errors.ErrValidationInput
+// so they surface as validation_input to callers.
+var (
+	ErrInvalidReference = fmt.Errorf("%w: invalid step reference", merrors.ErrValidationInput)
+	ErrUnresolved       = fmt.Errorf("%w: unresolved step reference", merrors.ErrValidationInput)
+)
+
+const refKey = "$ref"
+
+// Ref is a parsed step reference.
+type Ref struct {
+	// Raw is the reference text as written.
+	Raw string
+	// StepID is set for steps.<id> references.
+	StepID string
+	// Index is set for steps[<index>] references and is -1 otherwise.
+	Index int
+	Path  []Segment
+}
+
+// Segment is one step of a path into a result.
+type Segment struct {
+	Key     string
+	Index   int
+	IsIndex bool
+}
+
+func (s Segment) String() string {
+	if s.IsIndex {
+		return "[" + strconv.Itoa(s.Index) + "]"
+	}
+	return "." + s.Key
+}
+
+// Step returns the step selector as written, e.g. "steps.search" or "steps[1]".
+func (r Ref) Step() string {
+	if r.Index >= 0 {
+		return "steps[" + strconv.Itoa(r.Index) + "]"
+	}
+	return "steps." + r.StepID
+}
+
+// IsReference reports whether text looks like a step reference and should be
+// parsed rather than passed through.
+func IsReference(text string) bool {
+	text = strings.TrimSpace(text)
+	return strings.HasPrefix(text, "steps.") || strings.HasPrefix(text, "steps[")
+}
+
+// Parse parses a reference such as "steps.search.structured.items[0].id".
+func Parse(text string) (Ref, error) {
+	raw := text
+	text = strings.TrimSpace(text)
+	fail := func(format string, args ...any) (Ref, error) {
+		return Ref{}, fmt.Errorf("%w %q: %s", ErrInvalidReference, raw, fmt.Sprintf(format, args...))
+	}
+	if !IsReference(text) {
+		return fail("must start with steps.<id> or steps[<index>]")
+	}
+
+	segments, err := parsePath(text[len("steps"):])
+	if err != nil {
+		return fail("%v", err)
+	}
+	ref := Ref{Raw: raw, Index: -1}
+	if segments[0].IsIndex {
+		ref.Index = segments[0].Index
+	} else {
+		ref.StepID = segments[0].Key
+	}
+	if len(segments) < 2 || segments[1].IsIndex ||
+		(segments[1].Key != "structured" && segments[1].Key != "result") {
+		return fail("%s must be followed by .structured or .result", ref.Step())
+	}
+	ref.Path = segments[2:]
+	return ref, nil
+}
+
+func parsePath(text string) ([]Segment, error) {
+	var out []Segment
+	for i := 0; i < len(text); {
+		switch text[i] {
+		case '.':
+			j := i + 1
+			for j < len(text) && text[j] != '.' && text[j] != '[' {
+				j++
+			}
+			key := text[i+1 : j]
+			if key == "" {
+				return nil, errors.New("empty key")
+			}
+			out = append(out, Segment{Key: key})
+			i = j
+		case '[':
+			end := strings.IndexByte(text[i:], ']')
+			if end < 0 {
+				return nil, errors.New("unterminated [")
+			}
+			inner := text[i+1 : i+end]
+			i += end + 1
+			if strings.HasPrefix(inner, `"`) {
+				key, err := strconv.Unquote(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid quoted key %s", inner)
+				}
+				out = append(out, Segment{Key: key})
+				continue
+			}
+			n, err := strconv.Atoi(inner)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid index [%s]", inner)
+			}
+			out = append(out, Segment{Index: n, IsIndex: true})
+		default:
+			return nil, fmt.Errorf("unexpected %q", text[i])
+		}
+	}
+	if len(out) == 0 {
+		return nil, errors.New("missing step selector")
+	}
+	return out, nil
+}
+
+// Collect returns every reference in args, failing on the first malformed one.
+func Collect(args map[string]any) ([]Ref, error) {
+	var refs []Ref
+	err := walk(args, func(text string) error {
+		ref, err := Parse(text)
+		if err != nil {
+			return err
+		}
+		refs = append(refs, ref)
+		return nil
+	})
+	return refs, err
+}
+
+func walk(v any, visit func(string) error) error {
+	switch val := v.(type) {
+	case map[string]any:
+		if text, ok := refValue(val); ok {
+			return visit(text)
+		}
+		for _, k := range slices.Sorted(maps.Keys(val)) {
+			if err := walk(val[k], visit); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, child := range val {
+			if err := walk(child, visit); err != nil {
+				return err
+			}
+		}
+	case string:
+		for _, tmpl := range templates(val) {
+			if err := visit(tmpl.text); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// refValue returns the reference of a {"$ref": "steps..."} object.
+func refValue(m map[string]any) (string, bool) {
+	if len(m) != 1 {
+		return "", false
+	}
+	text, ok := m[refKey].(string)
+	if !ok || !IsReference(text) {
+		return "", false
+	}
+	return text, true
+}
+
+type template struct {
+	start, end int
+	text       string
+}
+
+// templates returns the ${steps...} placeholders in s.
+func templates(s string) []template {
+	var out []template
+	for i := 0; i < len(s); {
+		start := strings.Index(s[i:], "${")
+		if start < 0 {
+			break
+		}
+		start += i
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start + 1
+		text := s[start+2 : end-1]
+		if IsReference(text) {
+			out = append(out, template{start: start, end: end, text: text})
+		}
+		i = end
+	}
+	return out
+}
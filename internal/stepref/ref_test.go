@@ -0,0 +1,121 @@
+package stepref
+
+import (
+	"testing"
+
+	merrors "github.com/jonwraymond/metatools-mcp/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	ref, err := Parse("steps.search.structured.items[0].id")
+	require.NoError(t, err)
+	assert.Equal(t, "search", ref.StepID)
+	assert.Equal(t, -1, ref.Index)
+	assert.Equal(t, []Segment{{Key: "items"}, {Index: 0, IsIndex: true}, {Key: "id"}}, ref.Path)
+
+	ref, err = Parse(`steps[1].result["a.b"]`)
+	require.NoError(t, err)
+	assert.Equal(t, 1, ref.Index)
+	assert.Equal(t, []Segment{{Key: "a.b"}}, ref.Path)
+
+	for _, bad := range []string{
+		"steps.search",
+		"steps.search.items",
+		"steps[x].structured",
+		"steps..structured",
+		"steps[0.structured",
+	} {
+		_, err := Parse(bad)
+		assert.ErrorIs(t, err, ErrInvalidReference, bad)
+		assert.ErrorIs(t, err, merrors.ErrValidationInput, bad)
+	}
+}
+
+func testResults() *Results {
+	results := NewResults()
+	results.Add("owner", map[string]any{"owner": "octo", "repo": "demo"})
+	results.Add("search", map[string]any{
+		"items": []any{map[string]any{"id": 42.0, "url": "https://example.com/42"}},
+	})
+	return results
+}
+
+func TestResolve(t *testing.T) {
+	args := map[string]any{
+		"issue_id": map[string]any{"$ref": "steps.search.structured.items[0].id"},
+		"owner":    "${steps[0].result.owner}",
+		"title":    "Fix ${steps.owner.result.repo} #${steps.search.structured.items[0].id}",
+		"nested":   []any{map[string]any{"url": "${steps[1].structured.items[0].url}"}},
+		"schema":   map[string]any{"$ref": "#/definitions/x"},
+		"shell":    "echo ${HOME}",
+	}
+
+	out, err := Resolve(args, testResults())
+	require.NoError(t, err)
+	assert.Equal(t, 42.0, out["issue_id"])
+	assert.Equal(t, "octo", out["owner"])
+	assert.Equal(t, "Fix demo #42", out["title"])
+	assert.Equal(t, []any{map[string]any{"url": "https://example.com/42"}}, out["nested"])
+	assert.Equal(t, map[string]any{"$ref": "#/definitions/x"}, out["schema"])
+	assert.Equal(t, "echo ${HOME}", out["shell"])
+
+	// The input is not modified.
+	assert.Equal(t, map[string]any{"$ref": "steps.search.structured.items[0].id"}, args["issue_id"])
+}
+
+func TestResolve_Errors(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{"steps.missing.structured", `step "missing" has not run before this step`},
+		{"steps[5].structured", "step 5 has not run before this step"},
+		{"steps.search.structured.items[3]", "steps.search.structured.items has 1 items, index 3 out of range"},
+		{"steps.search.structured.nope", `steps.search.structured has no key "nope"`},
+		{"steps.owner.structured.owner.x", "steps.owner.structured.owner is a string, not an object"},
+		{"steps.owner.structured[0]", "steps.owner.structured is an object, not an array"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			_, err := Resolve(map[string]any{"id": map[string]any{"$ref": tt.ref}}, testResults())
+			require.Error(t, err)
+			assert.ErrorIs(t, err, ErrUnresolved)
+			assert.ErrorIs(t, err, merrors.ErrValidationInput)
+			assert.Contains(t, err.Error(), "args.id: ")
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestResolve_TypedResults(t *testing.T) {
+	type item struct {
+		Name string `json:"name"`
+	}
+	results := NewResults()
+	results.Add("typed", struct {
+		Items []item `json:"items"`
+	}{Items: []item{{Name: "first"}}})
+
+	out, err := Resolve(map[string]any{"name": "${steps.typed.structured.items[0].name}"}, results)
+	require.NoError(t, err)
+	assert.Equal(t, "first", out["name"])
+}
+
+func TestCheck(t *testing.T) {
+	args := map[string]any{
+		"a": map[string]any{"$ref": "steps.first.structured.x"},
+		"b": "${steps[0].result}",
+	}
+	require.NoError(t, Check(args, []string{"first"}))
+
+	err := Check(args, nil)
+	assert.ErrorIs(t, err, ErrUnresolved)
+
+	err = Check(map[string]any{"a": "${steps.later.structured}"}, []string{"first"})
+	assert.ErrorContains(t, err, `step "later" does not run before this step`)
+
+	err = Check(map[string]any{"a": "${steps.first}"}, []string{"first"})
+	assert.ErrorIs(t, err, ErrInvalidReference)
+}
@@ -0,0 +1,252 @@
+package stepref
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/jonwraymond/metatools-mcp/internal/jsonshape"
+)
+
+// Results records completed step results by step position.
+//
+// Contract:
+// - Concurrency: safe for concurrent use by steps running in parallel.
+type Results struct {
+	mu     sync.RWMutex
+	values map[int]any
+	ids    map[string]int
+	next   int
+}
+
+// NewResults creates an empty result set.
+func NewResults() *Results {
+	return &Results{values: make(map[int]any), ids: make(map[string]int)}
+}
+
+// Add records the structured result of the next step in sequence. id may be
+// empty.
+func (r *Results) Add(id string, value any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.set(r.next, id, value)
+}
+
+// Set records the structured result of the step at index. id may be empty.
+func (r *Results) Set(index int, id string, value any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.set(index, id, value)
+}
+
+func (r *Results) set(index int, id string, value any) {
+	if id != "" {
+		r.ids[id] = index
+	}
+	r.values[index] = value
+	if index >= r.next {
+		r.next = index + 1
+	}
+}
+
+// Len returns the number of recorded steps.
+func (r *Results) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.values)
+}
+
+// Lookup returns the value a reference points to.
+func (r *Results) Lookup(ref Ref) (any, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	idx := ref.Index
+	if idx < 0 {
+		var ok bool
+		if idx, ok = r.ids[ref.StepID]; !ok {
+			return nil, fmt.Errorf("%w %q: step %q has not run before this step", ErrUnresolved, ref.Raw, ref.StepID)
+		}
+	}
+	value, ok := r.values[idx]
+	if !ok {
+		return nil, fmt.Errorf("%w %q: step %d has not run before this step", ErrUnresolved, ref.Raw, idx)
+	}
+
+	cur := value
+	walked := ref.Step() + ".structured"
+	for _, seg := range ref.Path {
+		cur = normalize(cur)
+		if seg.IsIndex {
+			list, ok := cur.([]any)
+			if !ok {
+				return nil, fmt.Errorf("%w %q: %s is %s, not an array", ErrUnresolved, ref.Raw, walked, kind(cur))
+			}
+			if seg.Index >= len(list) {
+				return nil, fmt.Errorf("%w %q: %s has %d items, index %d out of range", ErrUnresolved, ref.Raw, walked, len(list), seg.Index)
+			}
+			cur = list[seg.Index]
+		} else {
+			obj, ok := cur.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%w %q: %s is %s, not an object", ErrUnresolved, ref.Raw, walked, kind(cur))
+			}
+			next, ok := obj[seg.Key]
+			if !ok {
+				return nil, fmt.Errorf("%w %q: %s has no key %q", ErrUnresolved, ref.Raw, walked, seg.Key)
+			}
+			cur = next
+		}
+		walked += seg.String()
+	}
+	return cur, nil
+}
+
+// Resolve returns a copy of args with every step reference replaced by its
+// value. args itself is not modified; errors name the argument path.
+func Resolve(args map[string]any, results *Results) (map[string]any, error) {
+	if args == nil {
+		return nil, nil
+	}
+	if results == nil {
+		results = NewResults()
+	}
+	out, err := resolveValue(args, "args", results)
+	if err != nil {
+		return nil, err
+	}
+	return out.(map[string]any), nil
+}
+
+func resolveValue(v any, path string, results *Results) (any, error) {
+	switch val := v.(type) {
+	case map[string]any:
+		if text, ok := refValue(val); ok {
+			return resolveRef(text, path, results)
+		}
+		out := make(map[string]any, len(val))
+		for _, k := range slices.Sorted(maps.Keys(val)) {
+			resolved, err := resolveValue(val[k], path+"."+k, results)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = resolved
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(val))
+		for i, child := range val {
+			resolved, err := resolveValue(child, fmt.Sprintf("%s[%d]", path, i), results)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	case string:
+		return resolveString(val, path, results)
+	default:
+		return v, nil
+	}
+}
+
+func resolveString(s, path string, results *Results) (any, error) {
+	tmpls := templates(s)
+	if len(tmpls) == 0 {
+		return s, nil
+	}
+	if len(tmpls) == 1 && tmpls[0].start == 0 && tmpls[0].end == len(s) {
+		return resolveRef(tmpls[0].text, path, results)
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, tmpl := range tmpls {
+		value, err := resolveRef(tmpl.text, path, results)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(s[last:tmpl.start])
+		b.WriteString(text(value))
+		last = tmpl.end
+	}
+	b.WriteString(s[last:])
+	return b.String(), nil
+}
+
+func resolveRef(text, path string, results *Results) (any, error) {
+	ref, err := Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	value, err := results.Lookup(ref)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return value, nil
+}
+
+// text renders an interpolated value: strings verbatim, everything else as JSON.
+func text(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// normalize converts typed results (structs, typed maps and slices) into the
+// generic JSON shape so paths can walk them.
+func normalize(v any) any {
+	switch v.(type) {
+	case map[string]any, []any, nil, string, bool, float64:
+		return v
+	}
+	out, _ := jsonshape.Normalize(v)
+	return out
+}
+
+func kind(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "an object"
+	case []any:
+		return "an array"
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case float64:
+		return "a number"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// Check verifies that every reference in args is well formed and names a
+// step in prior, the IDs of the steps that run before this one in order.
+func Check(args map[string]any, prior []string) error {
+	refs, err := Collect(args)
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		if ref.Index >= 0 {
+			if ref.Index >= len(prior) {
+				return fmt.Errorf("%w %q: only %d step(s) run before this step", ErrUnresolved, ref.Raw, len(prior))
+			}
+			continue
+		}
+		if !slices.Contains(prior, ref.StepID) {
+			return fmt.Errorf("%w %q: step %q does not run before this step", ErrUnresolved, ref.Raw, ref.StepID)
+		}
+	}
+	return nil
+}
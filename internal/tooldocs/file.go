@@ -0,0 +1,208 @@
+// Package tooldocs loads authored tool documentation from a directory of
+// YAML and Markdown files and merges it into the tooldoc store.
+//
+// Each file targets one or more tools by ID or glob (e.g. "github:*") and may
+// provide a summary, notes, examples and external references. Example args
+// are validated against the target tool's input schema when applied.
+package tooldocs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jonwraymond/metatools-mcp/internal/jsonshape"
+)
+
+// Doc is a parsed documentation file.
+type Doc struct {
+	// Source is the file the doc was loaded from.
+	Source string
+	// Tools lists tool IDs or glob patterns the doc applies to.
+	Tools        []string
+	Summary      string
+	Notes        string
+	Examples     []Example
+	ExternalRefs []string
+}
+
+// Example is an authored usage example.
+type Example struct {
+	ID          string         `yaml:"id"`
+	Title       string         `yaml:"title"`
+	Description string         `yaml:"description"`
+	Args        map[string]any `yaml:"args"`
+	ResultHint  string         `yaml:"result_hint"`
+}
+
+type fileDoc struct {
+	Tool         string    `yaml:"tool"`
+	Tools        []string  `yaml:"tools"`
+	Summary      string    `yaml:"summary"`
+	Notes        string    `yaml:"notes"`
+	Examples     []Example `yaml:"examples"`
+	ExternalRefs []string  `yaml:"external_refs"`
+}
+
+// LoadDir reads every .yaml, .yml and .md file under dir, in path order.
+func LoadDir(dir string) ([]Doc, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !isDocFile(p) {
+			return nil
+		}
+		paths = append(paths, p)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read docs dir: %w", err)
+	}
+	sort.Strings(paths)
+
+	docs := make([]Doc, 0, len(paths))
+	var errs []error
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		doc, err := ParseFile(p, data)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		docs = append(docs, doc)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// ParseFile parses a YAML doc, or a Markdown doc with optional YAML front
+// matter whose body becomes the notes. A Markdown file without a tool field
+// targets the tool named by its file name.
+func ParseFile(name string, data []byte) (Doc, error) {
+	var fd fileDoc
+	var body string
+	if strings.EqualFold(filepath.Ext(name), ".md") {
+		front, rest := splitFrontMatter(data)
+		body = strings.TrimSpace(rest)
+		data = front
+	}
+	if err := yaml.Unmarshal(data, &fd); err != nil {
+		return Doc{}, fmt.Errorf("%s: %w", name, err)
+	}
+
+	doc := Doc{
+		Source:       name,
+		Summary:      strings.TrimSpace(fd.Summary),
+		Notes:        joinNotes(strings.TrimSpace(fd.Notes), body),
+		Examples:     fd.Examples,
+		ExternalRefs: fd.ExternalRefs,
+	}
+	if fd.Tool != "" {
+		doc.Tools = append(doc.Tools, fd.Tool)
+	}
+	doc.Tools = append(doc.Tools, fd.Tools...)
+	if len(doc.Tools) == 0 && strings.EqualFold(filepath.Ext(name), ".md") {
+		doc.Tools = []string{strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))}
+	}
+	if err := doc.validate(); err != nil {
+		return Doc{}, fmt.Errorf("%s: %w", name, err)
+	}
+	// Round-trip example args through JSON so YAML-decoded values take the
+	// same shapes as MCP tool arguments.
+	for i := range doc.Examples {
+		args, err := jsonshape.Map(doc.Examples[i].Args)
+		if err != nil {
+			return Doc{}, fmt.Errorf("%s: example %d: args must be JSON-compatible: %w", name, i, err)
+		}
+		if args == nil {
+			args = map[string]any{}
+		}
+		doc.Examples[i].Args = args
+	}
+	return doc, nil
+}
+
+func (d Doc) validate() error {
+	if len(d.Tools) == 0 {
+		return errors.New("tool or tools is required")
+	}
+	for _, pattern := range d.Tools {
+		if strings.TrimSpace(pattern) == "" {
+			return errors.New("tool pattern cannot be empty")
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid tool pattern %q: %w", pattern, err)
+		}
+	}
+	for i, ex := range d.Examples {
+		if strings.TrimSpace(ex.Title) == "" {
+			return fmt.Errorf("example %d: title is required", i)
+		}
+	}
+	return nil
+}
+
+// matches reports whether the doc applies to toolID and whether the match is exact.
+func (d Doc) matches(toolID string) (matched, exact bool) {
+	for _, pattern := range d.Tools {
+		if pattern == toolID {
+			return true, true
+		}
+		if ok, _ := path.Match(pattern, toolID); ok {
+			matched = true
+		}
+	}
+	return matched, false
+}
+
+func splitFrontMatter(data []byte) (front []byte, body string) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	normalized := bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	if !bytes.HasPrefix(normalized, []byte("---\n")) {
+		return nil, string(normalized)
+	}
+	rest := normalized[len("---\n"):]
+	end := bytes.Index(rest, []byte("\n---"))
+	if end < 0 {
+		return nil, string(normalized)
+	}
+	front = rest[:end]
+	body = string(rest[end+len("\n---"):])
+	body = strings.TrimPrefix(body, "\n")
+	return front, body
+}
+
+func joinNotes(parts ...string) string {
+	var kept []string
+	for _, p := range parts {
+		if p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, "\n\n")
+}
+
+func isDocFile(p string) bool {
+	switch strings.ToLower(filepath.Ext(p)) {
+	case ".yaml", ".yml", ".md":
+		return true
+	default:
+		return false
+	}
+}
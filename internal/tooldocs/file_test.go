@@ -0,0 +1,80 @@
+package tooldocs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFile_YAML(t *testing.T) {
+	doc, err := ParseFile("github.yaml", []byte(`
+tools: ["github:*"]
+summary: GitHub tools
+notes: Requires a token with repo scope.
+examples:
+  - title: Open a PR
+    args:
+      owner: acme
+      count: 2
+external_refs: [https://docs.github.com/rest]
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"github:*"}, doc.Tools)
+	assert.Equal(t, "GitHub tools", doc.Summary)
+	require.Len(t, doc.Examples, 1)
+	assert.Equal(t, map[string]any{"owner": "acme", "count": float64(2)}, doc.Examples[0].Args)
+	assert.Equal(t, []string{"https://docs.github.com/rest"}, doc.ExternalRefs)
+}
+
+func TestParseFile_MarkdownFrontMatter(t *testing.T) {
+	doc, err := ParseFile("pr.md", []byte("---\ntool: github:create_pull_request\nnotes: Prefer drafts.\n---\n\n# Usage\n\nSet `draft: true` for WIP.\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"github:create_pull_request"}, doc.Tools)
+	assert.Equal(t, "Prefer drafts.\n\n# Usage\n\nSet `draft: true` for WIP.", doc.Notes)
+}
+
+func TestParseFile_MarkdownToolFromFileName(t *testing.T) {
+	doc, err := ParseFile("docs/local:ping.md", []byte("Returns pong.\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"local:ping"}, doc.Tools)
+	assert.Equal(t, "Returns pong.", doc.Notes)
+}
+
+func TestParseFile_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"missing tool":    "summary: nothing\n",
+		"bad pattern":     "tool: \"github:[\"\n",
+		"untitled sample": "tool: a\nexamples:\n  - args: {}\n",
+		"bad yaml":        "tool: [\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseFile("doc.yaml", []byte(content))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMerge_ExactOverridesGlob(t *testing.T) {
+	docs := []Doc{
+		{Source: "exact.yaml", Tools: []string{"github:list_issues"}, Summary: "List issues", Notes: "Exact notes.",
+			ExternalRefs: []string{"https://a"}},
+		{Source: "glob.yaml", Tools: []string{"github:*"}, Summary: "GitHub", Notes: "Glob notes.",
+			Examples: []Example{{Title: "glob example"}}, ExternalRefs: []string{"https://a", "https://b"}},
+	}
+
+	entry, ok := Merge(docs, "github:list_issues")
+	require.True(t, ok)
+	assert.Equal(t, "List issues", entry.Summary)
+	assert.Equal(t, "Glob notes.\n\nExact notes.", entry.Notes)
+	assert.Len(t, entry.Examples, 1)
+	assert.Equal(t, []string{"https://a", "https://b"}, entry.ExternalRefs)
+
+	entry, ok = Merge(docs, "github:create_pull_request")
+	require.True(t, ok)
+	assert.Equal(t, "GitHub", entry.Summary)
+
+	_, ok = Merge(docs, "local:ping")
+	assert.False(t, ok)
+}
@@ -0,0 +1,297 @@
+package tooldocs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/jonwraymond/metatools-mcp/internal/toolset"
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/tooldiscovery/tooldoc"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// DefaultWatchInterval is the polling interval used by Watch when none is set.
+const DefaultWatchInterval = 2 * time.Second
+
+// Registrar is the subset of tooldoc.InMemoryStore used to publish docs.
+type Registrar interface {
+	RegisterDoc(id string, entry tooldoc.DocEntry) error
+}
+
+// Options configures a Manager.
+type Options struct {
+	// Dir is the directory containing doc files.
+	Dir string
+	// Index resolves tools for glob matching and schema validation.
+	Index index.Index
+	// Store receives merged docs.
+	Store Registrar
+	// Validator checks example args; defaults to model.NewDefaultValidator.
+	Validator model.SchemaValidator
+	// WatchInterval is the polling interval for Watch.
+	WatchInterval time.Duration
+	// Logger receives reload and validation warnings; defaults to slog.Default.
+	Logger *slog.Logger
+}
+
+// Manager keeps file-based docs merged into a tooldoc store.
+//
+// Contract:
+//   - Concurrency: safe for concurrent use.
+//   - Errors: Load fails on parse or validation errors; background reloads log
+//     them and keep serving the last good docs.
+type Manager struct {
+	opts Options
+
+	mu          sync.Mutex
+	docs        []Doc
+	applied     map[string]struct{}
+	fingerprint uint64
+}
+
+// NewManager creates a docs manager. Call Load to read the directory.
+func NewManager(opts Options) *Manager {
+	if opts.Validator == nil {
+		opts.Validator = model.NewDefaultValidator()
+	}
+	if opts.WatchInterval <= 0 {
+		opts.WatchInterval = DefaultWatchInterval
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	return &Manager{opts: opts, applied: make(map[string]struct{})}
+}
+
+// Load reads the docs directory and applies docs to every indexed tool.
+// Invalid examples are reported as errors.
+func (m *Manager) Load() error {
+	fp, err := dirFingerprint(m.opts.Dir)
+	if err != nil {
+		return err
+	}
+	docs, err := LoadDir(m.opts.Dir)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.docs = docs
+	m.fingerprint = fp
+	m.mu.Unlock()
+	return m.ApplyAll()
+}
+
+// ApplyAll merges docs for every indexed tool and clears docs for tools that
+// no longer match any file.
+func (m *Manager) ApplyAll() error {
+	ids, err := toolset.ListToolIDs(m.opts.Index)
+	if err != nil {
+		return fmt.Errorf("list tools: %w", err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seen := make(map[string]struct{}, len(ids))
+	var errs []error
+	for _, id := range ids {
+		seen[id] = struct{}{}
+		if err := m.applyLocked(id); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for id := range m.applied {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		if err := m.applyLocked(id); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Apply merges docs for a single tool.
+func (m *Manager) Apply(toolID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.applyLocked(toolID)
+}
+
+func (m *Manager) applyLocked(toolID string) error {
+	entry, ok := Merge(m.docs, toolID)
+	if !ok {
+		if _, had := m.applied[toolID]; had {
+			delete(m.applied, toolID)
+			return m.opts.Store.RegisterDoc(toolID, tooldoc.DocEntry{})
+		}
+		return nil
+	}
+
+	var errs []error
+	if tool, _, err := m.opts.Index.GetTool(toolID); err == nil && tool.InputSchema != nil {
+		valid := entry.Examples[:0]
+		for _, ex := range entry.Examples {
+			if err := m.opts.Validator.ValidateInput(&tool, ex.Args); err != nil {
+				errs = append(errs, fmt.Errorf("%s: example %q: %w", toolID, ex.Title, err))
+				continue
+			}
+			valid = append(valid, ex)
+		}
+		entry.Examples = valid
+	}
+
+	if err := m.opts.Store.RegisterDoc(toolID, entry); err != nil {
+		errs = append(errs, fmt.Errorf("%s: %w", toolID, err))
+	} else {
+		m.applied[toolID] = struct{}{}
+	}
+	return errors.Join(errs...)
+}
+
+// Listen re-applies docs when tools are registered, updated or refreshed.
+// The returned function unsubscribes.
+func (m *Manager) Listen() func() {
+	notifier, ok := m.opts.Index.(index.ChangeNotifier)
+	if !ok {
+		return func() {}
+	}
+	return notifier.OnChange(func(ev index.ChangeEvent) {
+		var err error
+		switch ev.Type {
+		case index.ChangeRegistered, index.ChangeUpdated:
+			err = m.Apply(ev.ToolID)
+		case index.ChangeRefreshed:
+			err = m.ApplyAll()
+		default:
+			return
+		}
+		if err != nil {
+			m.opts.Logger.Warn("tool docs validation failed", "error", err)
+		}
+	})
+}
+
+// Watch polls the docs directory and reloads on change until ctx is done.
+// Reload failures are logged and the previous docs stay in effect.
+func (m *Manager) Watch(ctx context.Context) {
+	ticker := time.NewTicker(m.opts.WatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.reloadIfChanged()
+		}
+	}
+}
+
+func (m *Manager) reloadIfChanged() {
+	fp, err := dirFingerprint(m.opts.Dir)
+	if err != nil {
+		m.opts.Logger.Warn("tool docs watch failed", "dir", m.opts.Dir, "error", err)
+		return
+	}
+	m.mu.Lock()
+	unchanged := fp == m.fingerprint
+	m.mu.Unlock()
+	if unchanged {
+		return
+	}
+
+	docs, err := LoadDir(m.opts.Dir)
+	m.mu.Lock()
+	m.fingerprint = fp
+	if err == nil {
+		m.docs = docs
+	}
+	m.mu.Unlock()
+	if err != nil {
+		m.opts.Logger.Warn("tool docs reload failed", "dir", m.opts.Dir, "error", err)
+		return
+	}
+	if err := m.ApplyAll(); err != nil {
+		m.opts.Logger.Warn("tool docs validation failed", "error", err)
+	}
+	m.opts.Logger.Info("tool docs reloaded", "dir", m.opts.Dir, "files", len(docs))
+}
+
+// Merge combines every doc matching toolID into a single entry. Glob matches
+// are applied first and exact matches last, so exact docs win the summary;
+// notes, examples and references accumulate.
+func Merge(docs []Doc, toolID string) (tooldoc.DocEntry, bool) {
+	var globs, exact []Doc
+	for _, doc := range docs {
+		matched, isExact := doc.matches(toolID)
+		switch {
+		case isExact:
+			exact = append(exact, doc)
+		case matched:
+			globs = append(globs, doc)
+		}
+	}
+	ordered := append(globs, exact...)
+	if len(ordered) == 0 {
+		return tooldoc.DocEntry{}, false
+	}
+
+	var entry tooldoc.DocEntry
+	var notes []string
+	refs := make(map[string]struct{})
+	for _, doc := range ordered {
+		if doc.Summary != "" {
+			entry.Summary = doc.Summary
+		}
+		if doc.Notes != "" {
+			notes = append(notes, doc.Notes)
+		}
+		for _, ex := range doc.Examples {
+			entry.Examples = append(entry.Examples, tooldoc.ToolExample{
+				ID:          ex.ID,
+				Title:       ex.Title,
+				Description: ex.Description,
+				Args:        ex.Args,
+				ResultHint:  ex.ResultHint,
+			})
+		}
+		for _, ref := range doc.ExternalRefs {
+			if _, dup := refs[ref]; dup {
+				continue
+			}
+			refs[ref] = struct{}{}
+			entry.ExternalRefs = append(entry.ExternalRefs, ref)
+		}
+	}
+	entry.Notes = joinNotes(notes...)
+	return entry, true
+}
+
+// dirFingerprint hashes doc file paths, sizes and modification times.
+func dirFingerprint(dir string) (uint64, error) {
+	h := fnv.New64a()
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !isDocFile(p) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(h, "%s\x00%d\x00%d\x01", p, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("read docs dir: %w", err)
+	}
+	return h.Sum64(), nil
+}
@@ -0,0 +1,141 @@
+package tooldocs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/tooldiscovery/tooldoc"
+	"github.com/jonwraymond/toolfoundation/model"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func registerTool(t *testing.T, idx index.Index, ns, name string) {
+	t.Helper()
+	err := idx.RegisterTool(model.Tool{
+		Tool: mcp.Tool{
+			Name:        name,
+			Description: "upstream " + name,
+			InputSchema: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"owner": map[string]any{"type": "string"}},
+				"required":   []any{"owner"},
+			},
+		},
+		Namespace: ns,
+	}, model.ToolBackend{Kind: model.BackendKindMCP, MCP: &model.MCPBackend{ServerName: ns}})
+	require.NoError(t, err)
+}
+
+func writeDoc(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+}
+
+func newTestManager(t *testing.T) (*Manager, *index.InMemoryIndex, *tooldoc.InMemoryStore, string) {
+	t.Helper()
+	dir := t.TempDir()
+	idx := index.NewInMemoryIndex()
+	store := tooldoc.NewInMemoryStore(tooldoc.StoreOptions{Index: idx})
+	m := NewManager(Options{Dir: dir, Index: idx, Store: store})
+	return m, idx, store, dir
+}
+
+func TestManager_LoadAppliesDocs(t *testing.T) {
+	m, idx, store, dir := newTestManager(t)
+	registerTool(t, idx, "github", "list_issues")
+	registerTool(t, idx, "github", "create_pull_request")
+	writeDoc(t, dir, "github.yaml", "tool: \"github:*\"\nnotes: Needs a token.\n")
+	writeDoc(t, dir, "issues.yaml", `
+tool: github:list_issues
+summary: List repository issues
+examples:
+  - title: Acme issues
+    args: {owner: acme}
+`)
+
+	require.NoError(t, m.Load())
+
+	doc, err := store.DescribeTool("github:list_issues", tooldoc.DetailFull)
+	require.NoError(t, err)
+	assert.Equal(t, "List repository issues", doc.Summary)
+	assert.Equal(t, "Needs a token.", doc.Notes)
+	require.Len(t, doc.Examples, 1)
+
+	doc, err = store.DescribeTool("github:create_pull_request", tooldoc.DetailFull)
+	require.NoError(t, err)
+	assert.Equal(t, "upstream create_pull_request", doc.Summary)
+	assert.Equal(t, "Needs a token.", doc.Notes)
+}
+
+func TestManager_RejectsExamplesViolatingSchema(t *testing.T) {
+	m, idx, store, dir := newTestManager(t)
+	registerTool(t, idx, "github", "list_issues")
+	writeDoc(t, dir, "issues.yaml", `
+tool: github:list_issues
+examples:
+  - title: good
+    args: {owner: acme}
+  - title: missing owner
+    args: {}
+`)
+
+	err := m.Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing owner")
+
+	examples, err := store.ListExamples("github:list_issues", 10)
+	require.NoError(t, err)
+	require.Len(t, examples, 1)
+	assert.Equal(t, "good", examples[0].Title)
+}
+
+func TestManager_ListenAppliesOnRegistration(t *testing.T) {
+	m, idx, store, dir := newTestManager(t)
+	writeDoc(t, dir, "issues.yaml", "tool: github:list_issues\nsummary: Curated\n")
+	require.NoError(t, m.Load())
+	stop := m.Listen()
+	defer stop()
+
+	registerTool(t, idx, "github", "list_issues")
+
+	doc, err := store.DescribeTool("github:list_issues", tooldoc.DetailSummary)
+	require.NoError(t, err)
+	assert.Equal(t, "Curated", doc.Summary)
+}
+
+func TestManager_ReloadClearsRemovedDocs(t *testing.T) {
+	m, idx, store, dir := newTestManager(t)
+	registerTool(t, idx, "github", "list_issues")
+	writeDoc(t, dir, "issues.yaml", "tool: github:list_issues\nsummary: Curated\n")
+	require.NoError(t, m.Load())
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "issues.yaml")))
+	writeDoc(t, dir, "other.yaml", "tool: github:other\nsummary: Other\n")
+	// Ensure the fingerprint changes even on coarse mtime filesystems.
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "other.yaml"), future, future))
+	m.reloadIfChanged()
+
+	doc, err := store.DescribeTool("github:list_issues", tooldoc.DetailSummary)
+	require.NoError(t, err)
+	assert.Equal(t, "upstream list_issues", doc.Summary)
+}
+
+func TestManager_ReloadKeepsLastGoodDocs(t *testing.T) {
+	m, idx, store, dir := newTestManager(t)
+	registerTool(t, idx, "github", "list_issues")
+	writeDoc(t, dir, "issues.yaml", "tool: github:list_issues\nsummary: Curated\n")
+	require.NoError(t, m.Load())
+
+	writeDoc(t, dir, "broken.yaml", "tool: [\n")
+	m.reloadIfChanged()
+
+	doc, err := store.DescribeTool("github:list_issues", tooldoc.DetailSummary)
+	require.NoError(t, err)
+	assert.Equal(t, "Curated", doc.Summary)
+}
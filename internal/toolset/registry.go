@@ -16,11 +16,22 @@ const (
 	pageSize        = 200
 )
 
+// Exposure modes control how a toolset's members are published over MCP.
+const (
+	// ExposureMetatools keeps members behind the metatools (the default).
+	ExposureMetatools = "metatools"
+	// ExposureDirect publishes each member as its own MCP tool.
+	ExposureDirect = "direct"
+	// ExposureBoth publishes members directly and keeps the metatools.
+	ExposureBoth = "both"
+)
+
 // Toolset wraps a composed toolset with metadata.
 type Toolset struct {
 	ID          string
 	Name        string
 	Description string
+	Exposure    string
 	Tools       []*adapter.CanonicalTool
 }
 
@@ -33,6 +44,7 @@ type Spec struct {
 	AllowIDs         []string
 	DenyIDs          []string
 	Policy           string
+	Exposure         string
 }
 
 // ToolIDs returns tool IDs sorted lexicographically.
@@ -48,6 +60,16 @@ func (t *Toolset) ToolIDs() []string {
 	return ids
 }
 
+// Direct reports whether members are published as their own MCP tools.
+func (t *Toolset) Direct() bool {
+	return t.Exposure == ExposureDirect || t.Exposure == ExposureBoth
+}
+
+// Metatools reports whether the metatools are published alongside the toolset.
+func (t *Toolset) Metatools() bool {
+	return t.Exposure != ExposureDirect
+}
+
 // Registry stores toolsets keyed by ID.
 type Registry struct {
 	sets  map[string]*Toolset
@@ -141,6 +163,11 @@ func BuildRegistry(idx index.Index, specs []Spec) (*Registry, error) {
 			builder.WithPolicy(policy)
 		}
 
+		exposure, err := exposureFromConfig(cfg.Exposure)
+		if err != nil {
+			return nil, err
+		}
+
 		ts, err := builder.Build()
 		if err != nil {
 			return nil, err
@@ -150,6 +177,7 @@ func BuildRegistry(idx index.Index, specs []Spec) (*Registry, error) {
 			ID:          id,
 			Name:        cfg.Name,
 			Description: cfg.Description,
+			Exposure:    exposure,
 			Tools:       ts.Tools(),
 		})
 	}
@@ -158,7 +186,7 @@ func BuildRegistry(idx index.Index, specs []Spec) (*Registry, error) {
 }
 
 func listCanonicalTools(idx index.Index) ([]*adapter.CanonicalTool, error) {
-	ids, err := listToolIDs(idx)
+	ids, err := ListToolIDs(idx)
 	if err != nil {
 		return nil, err
 	}
@@ -178,7 +206,9 @@ func listCanonicalTools(idx index.Index) ([]*adapter.CanonicalTool, error) {
 	return tools, nil
 }
 
-func listToolIDs(idx index.Index) ([]string, error) {
+// ListToolIDs returns the ID of every tool in idx, sorted, paging through the
+// empty-query listing with its cursor.
+func ListToolIDs(idx index.Index) ([]string, error) {
 	cursor := ""
 	ids := make([]string, 0)
 	seen := make(map[string]struct{})
@@ -225,6 +255,17 @@ func policyFromConfig(raw string) (toolset.Policy, error) {
 	}
 }
 
+func exposureFromConfig(raw string) (string, error) {
+	switch exposure := strings.ToLower(strings.TrimSpace(raw)); exposure {
+	case "":
+		return ExposureMetatools, nil
+	case ExposureMetatools, ExposureDirect, ExposureBoth:
+		return exposure, nil
+	default:
+		return "", fmt.Errorf("unknown toolset exposure %q", raw)
+	}
+}
+
 func slugify(name string) string {
 	name = strings.ToLower(strings.TrimSpace(name))
 	var b strings.Builder
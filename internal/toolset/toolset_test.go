@@ -88,3 +88,30 @@ func backend(name string) model.ToolBackend {
 		},
 	}
 }
+
+func TestBuildRegistryFromConfig_Exposure(t *testing.T) {
+	idx := index.NewInMemoryIndex(index.IndexOptions{})
+
+	reg, err := BuildRegistry(idx, []Spec{
+		{Name: "hidden"},
+		{Name: "direct", Exposure: "Direct"},
+		{Name: "both", Exposure: ExposureBoth},
+	})
+	require.NoError(t, err)
+
+	hidden, _ := reg.Get("toolset:hidden")
+	require.Equal(t, ExposureMetatools, hidden.Exposure)
+	require.False(t, hidden.Direct())
+	require.True(t, hidden.Metatools())
+
+	direct, _ := reg.Get("toolset:direct")
+	require.True(t, direct.Direct())
+	require.False(t, direct.Metatools())
+
+	both, _ := reg.Get("toolset:both")
+	require.True(t, both.Direct())
+	require.True(t, both.Metatools())
+
+	_, err = BuildRegistry(idx, []Spec{{Name: "bad", Exposure: "public"}})
+	require.ErrorContains(t, err, "unknown toolset exposure")
+}
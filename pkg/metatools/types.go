@@ -1,6 +1,9 @@
 package metatools
 
-import "errors"
+import (
+	"errors"
+	"strconv"
+)
 
 // ToolSummary represents a minimal tool summary for search results
 type ToolSummary struct {
@@ -139,8 +142,10 @@ type ListNamespacesOutput struct {
 // DescribeToolInput is the input for describe_tool
 type DescribeToolInput struct {
 	ToolID      string `json:"tool_id"`
-	DetailLevel string `json:"detail_level"` // summary, schema, full
+	DetailLevel string `json:"detail_level"` // summary, schema, full, compact
 	ExamplesMax *int   `json:"examples_max,omitempty"`
+	// MaxTokens bounds compact output; detail is elided until it fits.
+	MaxTokens *int `json:"max_tokens,omitempty"`
 }
 
 // Validate checks that the input is valid
@@ -149,11 +154,19 @@ func (d *DescribeToolInput) Validate() error {
 		return errors.New("tool_id is required")
 	}
 	switch d.DetailLevel {
-	case "summary", "schema", "full":
-		return nil
+	case "summary", "schema", "full", "compact":
 	default:
-		return errors.New("detail_level must be one of: summary, schema, full")
+		return errors.New("detail_level must be one of: summary, schema, full, compact")
+	}
+	if d.MaxTokens != nil {
+		if d.DetailLevel != "compact" {
+			return errors.New("max_tokens requires detail_level compact")
+		}
+		if *d.MaxTokens <= 0 {
+			return errors.New("max_tokens must be positive")
+		}
 	}
+	return nil
 }
 
 // DescribeToolOutput is the output for describe_tool
@@ -164,6 +177,12 @@ type DescribeToolOutput struct {
 	Notes        *string       `json:"notes,omitempty"`
 	Examples     []ToolExample `json:"examples,omitempty"`
 	ExternalRefs []string      `json:"externalRefs,omitempty"`
+	// Signature is the TypeScript-like rendering returned at compact level.
+	Signature string `json:"signature,omitempty"`
+	// Elided lists detail dropped to fit max_tokens, in the order it was dropped.
+	Elided []string `json:"elided,omitempty"`
+	// TokenEstimate approximates the size of compact output in tokens.
+	TokenEstimate int `json:"tokenEstimate,omitempty"`
 }
 
 // ToolExample represents a usage example for a tool
@@ -220,6 +239,10 @@ type RunToolInput struct {
 	IncludeBackend   bool             `json:"include_backend,omitempty"`
 	IncludeMCPResult bool             `json:"include_mcp_result,omitempty"`
 	BackendOverride  *BackendOverride `json:"backend_override,omitempty"`
+	// Async returns a job_id at once and runs the tool in the background.
+	Async bool `json:"async,omitempty"`
+	// MaxResultBytes overrides the result size limit for this call.
+	MaxResultBytes int `json:"max_result_bytes,omitempty"`
 }
 
 // Validate checks that the input is valid
@@ -227,6 +250,9 @@ func (r *RunToolInput) Validate() error {
 	if r.ToolID == "" {
 		return errors.New("tool_id is required")
 	}
+	if r.MaxResultBytes < 0 {
+		return errors.New("max_result_bytes must be >= 0")
+	}
 	return nil
 }
 
@@ -238,6 +264,68 @@ type RunToolOutput struct {
 	Backend    any          `json:"backend,omitempty"`
 	MCPResult  any          `json:"mcpResult,omitempty"`
 	DurationMs *int         `json:"durationMs,omitempty"`
+	// JobID identifies the background job of an async call.
+	JobID string `json:"job_id,omitempty"`
+	// Content holds the tool's non-text MCP content blocks. run_tool also
+	// returns them as native MCP content.
+	Content []any `json:"content,omitempty"`
+	// Truncated is set when Structured is a preview of a result over the
+	// size limit.
+	Truncated *TruncatedResult `json:"truncated,omitempty"`
+}
+
+// TruncatedResult describes a result cut down to fit a size limit. The full
+// result can be read with read_result while it is kept.
+type TruncatedResult struct {
+	// ResultID is empty when the result was too large to keep.
+	ResultID   string `json:"result_id,omitempty"`
+	TotalBytes int    `json:"total_bytes"`
+	// Arrays and Strings map the JSON paths of cut arrays and strings to
+	// their full lengths.
+	Arrays  map[string]int `json:"arrays,omitempty"`
+	Strings map[string]int `json:"strings,omitempty"`
+}
+
+// ReadResultInput is the input for read_result. Path selects part of the
+// result as JSON; without it, Offset and Length select a byte range of the
+// result's JSON text.
+type ReadResultInput struct {
+	ResultID string `json:"result_id"`
+	Path     string `json:"path,omitempty"`
+	Offset   int    `json:"offset,omitempty"`
+	Length   int    `json:"length,omitempty"`
+	// MaxBytes overrides the size limit of the returned value or range.
+	MaxBytes int `json:"max_bytes,omitempty"`
+}
+
+// Validate checks that the input is valid
+func (r *ReadResultInput) Validate() error {
+	if r.ResultID == "" {
+		return errors.New("result_id is required")
+	}
+	if r.Offset < 0 || r.Length < 0 || r.MaxBytes < 0 {
+		return errors.New("offset, length and max_bytes must be >= 0")
+	}
+	if r.Path != "" && (r.Offset > 0 || r.Length > 0) {
+		return errors.New("path cannot be combined with offset or length")
+	}
+	return nil
+}
+
+// ReadResultOutput is the output for read_result. Value is set for path
+// reads and Data for byte ranges.
+type ReadResultOutput struct {
+	ResultID   string `json:"result_id"`
+	TotalBytes int    `json:"total_bytes"`
+	Path       string `json:"path,omitempty"`
+	Value      any    `json:"value,omitempty"`
+	Data       string `json:"data,omitempty"`
+	Offset     *int   `json:"offset,omitempty"`
+	// NextOffset is the offset of the next range, if any.
+	NextOffset *int `json:"next_offset,omitempty"`
+	// Truncated is set when Value is a preview of a selection over the size
+	// limit; narrow the path to read more.
+	Truncated *TruncatedResult `json:"truncated,omitempty"`
 }
 
 // ToolsetSummary represents a minimal toolset summary.
@@ -246,6 +334,8 @@ type ToolsetSummary struct {
 	Name        string `json:"name"`
 	Description string `json:"description,omitempty"`
 	ToolCount   int    `json:"toolCount"`
+	// Active marks toolsets activated by the calling session.
+	Active bool `json:"active,omitempty"`
 }
 
 // ListToolsetsInput is the input for list_toolsets.
@@ -272,6 +362,50 @@ func (d *DescribeToolsetInput) Validate() error {
 	return nil
 }
 
+// ActivateToolsetInput is the input for activate_toolset.
+type ActivateToolsetInput struct {
+	ToolsetID string `json:"toolset_id"`
+	// Direct publishes the toolset's members as MCP tools for the session.
+	Direct bool `json:"direct,omitempty"`
+}
+
+// Validate checks that the input is valid.
+func (a *ActivateToolsetInput) Validate() error {
+	if a.ToolsetID == "" {
+		return errors.New("toolset_id is required")
+	}
+	return nil
+}
+
+// DeactivateToolsetInput is the input for deactivate_toolset.
+type DeactivateToolsetInput struct {
+	ToolsetID string `json:"toolset_id"`
+}
+
+// Validate checks that the input is valid.
+func (d *DeactivateToolsetInput) Validate() error {
+	if d.ToolsetID == "" {
+		return errors.New("toolset_id is required")
+	}
+	return nil
+}
+
+// ActiveToolset is a toolset activated by a session.
+type ActiveToolset struct {
+	ID        string `json:"id"`
+	Name      string `json:"name,omitempty"`
+	Direct    bool   `json:"direct,omitempty"`
+	ToolCount int    `json:"toolCount"`
+}
+
+// ActiveToolsetsOutput is the output for activate_toolset and
+// deactivate_toolset: the session's active toolsets after the call.
+type ActiveToolsetsOutput struct {
+	Active []ActiveToolset `json:"active"`
+	// Changed reports whether the call changed the active toolsets.
+	Changed bool `json:"changed"`
+}
+
 // ToolsetDetail is the detailed view of a toolset.
 type ToolsetDetail struct {
 	ID          string        `json:"id"`
@@ -285,28 +419,58 @@ type DescribeToolsetOutput struct {
 	Toolset ToolsetDetail `json:"toolset"`
 }
 
-// SkillStep defines a skill step.
+// SkillStep defines a skill step. When any step sets DependsOn, the skill
+// runs as a dependency graph and independent steps may run in parallel.
 type SkillStep struct {
-	ID     string         `json:"id"`
-	ToolID string         `json:"tool_id"`
-	Inputs map[string]any `json:"inputs,omitempty"`
-}
-
-// SkillDefinition describes a skill.
+	ID        string         `json:"id"`
+	ToolID    string         `json:"tool_id"`
+	Inputs    map[string]any `json:"inputs,omitempty"`
+	DependsOn []string       `json:"depends_on,omitempty"`
+	OnError   *StepOnError   `json:"on_error,omitempty"`
+}
+
+// StepOnError configures how a failing chain or skill step is handled.
+// Action is one of fail (default), continue, retry or fallback.
+type StepOnError struct {
+	Action string `json:"action"`
+	// Attempts and BackoffMs apply to retry. Only retryable errors are retried.
+	Attempts  int `json:"attempts,omitempty"`
+	BackoffMs int `json:"backoff_ms,omitempty"`
+	// ToolID and Args apply to fallback. Without Args the step's args are reused.
+	ToolID string         `json:"tool_id,omitempty"`
+	Args   map[string]any `json:"args,omitempty"`
+}
+
+// StepPolicyResult reports how a step's on_error policy was applied.
+type StepPolicyResult struct {
+	Action         string       `json:"action"`
+	Attempts       int          `json:"attempts,omitempty"`
+	FallbackToolID string       `json:"fallback_tool_id,omitempty"`
+	Cause          *ErrorObject `json:"cause,omitempty"`
+}
+
+// SkillDefinition describes a skill. Step inputs may reference caller inputs
+// with {{ inputs.<name> }} templates; every referenced name must be declared
+// in InputSchema.properties. Output builds the run's final object from step
+// results using step references, and OutputSchema validates it.
 type SkillDefinition struct {
-	Name        string      `json:"name"`
-	Description string      `json:"description,omitempty"`
-	Steps       []SkillStep `json:"steps"`
-	ToolsetID   string      `json:"toolset_id,omitempty"`
+	Name         string         `json:"name"`
+	Description  string         `json:"description,omitempty"`
+	InputSchema  map[string]any `json:"input_schema,omitempty"`
+	Steps        []SkillStep    `json:"steps"`
+	Output       map[string]any `json:"output,omitempty"`
+	OutputSchema map[string]any `json:"output_schema,omitempty"`
+	ToolsetID    string         `json:"toolset_id,omitempty"`
 }
 
 // SkillSummary represents a minimal skill summary.
 type SkillSummary struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description,omitempty"`
-	StepCount   int    `json:"stepCount"`
-	ToolsetID   string `json:"toolset_id,omitempty"`
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	StepCount   int            `json:"stepCount"`
+	ToolsetID   string         `json:"toolset_id,omitempty"`
+	InputSchema map[string]any `json:"input_schema,omitempty"`
 }
 
 // ListSkillsInput is the input for list_skills.
@@ -342,6 +506,9 @@ type DescribeSkillOutput struct {
 type PlanSkillInput struct {
 	SkillID string           `json:"skill_id,omitempty"`
 	Skill   *SkillDefinition `json:"skill,omitempty"`
+	// Inputs are validated against the skill's input_schema and substituted
+	// into the plan.
+	Inputs map[string]any `json:"inputs,omitempty"`
 }
 
 // Validate checks that the input is valid.
@@ -360,8 +527,9 @@ func (p *PlanSkillInput) Validate() error {
 
 // SkillPlan is the deterministic plan returned by plan_skill.
 type SkillPlan struct {
-	Name  string      `json:"name"`
-	Steps []SkillStep `json:"steps"`
+	Name   string         `json:"name"`
+	Steps  []SkillStep    `json:"steps"`
+	Output map[string]any `json:"output,omitempty"`
 }
 
 // PlanSkillOutput is the output for plan_skill.
@@ -373,9 +541,17 @@ type PlanSkillOutput struct {
 type RunSkillInput struct {
 	SkillID      string           `json:"skill_id,omitempty"`
 	Skill        *SkillDefinition `json:"skill,omitempty"`
+	Inputs       map[string]any   `json:"inputs,omitempty"`
 	MaxSteps     *int             `json:"max_steps,omitempty"`
 	MaxToolCalls *int             `json:"max_tool_calls,omitempty"`
 	TimeoutMs    *int             `json:"timeout_ms,omitempty"`
+	// IncludeStepResults defaults to true. When false, Results lists only
+	// failed steps.
+	IncludeStepResults *bool `json:"include_step_results,omitempty"`
+	// DryRun validates the skill without calling any tool; Mocks maps tool
+	// IDs to canned structured outputs.
+	DryRun bool           `json:"dry_run,omitempty"`
+	Mocks  map[string]any `json:"mocks,omitempty"`
 }
 
 // Validate checks that the input is valid.
@@ -394,23 +570,38 @@ func (r *RunSkillInput) Validate() error {
 
 // SkillStepResult is the output for a skill step.
 type SkillStepResult struct {
-	StepID string       `json:"step_id"`
-	Value  any          `json:"value,omitempty"`
-	Error  *ErrorObject `json:"error,omitempty"`
+	StepID string            `json:"step_id"`
+	Value  any               `json:"value,omitempty"`
+	Error  *ErrorObject      `json:"error,omitempty"`
+	Policy *StepPolicyResult `json:"policy,omitempty"`
+	// Content holds the step's non-text MCP content blocks.
+	Content []any `json:"content,omitempty"`
 }
 
 // RunSkillOutput is the output for run_skill.
 type RunSkillOutput struct {
+	// Output is the skill's output mapping resolved against step results.
+	Output     map[string]any    `json:"output,omitempty"`
 	Results    []SkillStepResult `json:"results,omitempty"`
 	Error      *ErrorObject      `json:"error,omitempty"`
 	DurationMs *int              `json:"durationMs,omitempty"`
+	// RunID identifies the run record when run records are enabled.
+	RunID string `json:"run_id,omitempty"`
+	// Trace is the would-be execution of a dry run.
+	Trace []DryRunStep `json:"trace,omitempty"`
 }
 
-// ChainStep represents a single step in a chain
+// ChainStep represents a single step in a chain.
+// Args may reference earlier results with {"$ref": "steps.<id>.structured..."}
+// or "${steps[<index>].result...}". When any step sets DependsOn, the chain
+// runs as a dependency graph and independent steps may run in parallel.
 type ChainStep struct {
+	ID          string         `json:"id,omitempty"`
 	ToolID      string         `json:"tool_id"`
 	Args        map[string]any `json:"args,omitempty"`
 	UsePrevious bool           `json:"use_previous,omitempty"`
+	DependsOn   []string       `json:"depends_on,omitempty"`
+	OnError     *StepOnError   `json:"on_error,omitempty"`
 }
 
 // RunChainInput is the input for run_chain
@@ -418,6 +609,12 @@ type RunChainInput struct {
 	Steps           []ChainStep `json:"steps"`
 	IncludeBackends *bool       `json:"include_backends,omitempty"`
 	IncludeTools    *bool       `json:"include_tools,omitempty"`
+	// DryRun validates the chain without calling any tool; see DryRunStep.
+	DryRun bool `json:"dry_run,omitempty"`
+	// Mocks maps tool IDs to canned structured outputs for dry runs.
+	Mocks map[string]any `json:"mocks,omitempty"`
+	// Async returns a job_id at once and runs the chain in the background.
+	Async bool `json:"async,omitempty"`
 }
 
 // Validate checks that the input is valid
@@ -425,10 +622,21 @@ func (r *RunChainInput) Validate() error {
 	if len(r.Steps) == 0 {
 		return errors.New("steps must not be empty")
 	}
+	if r.Async && r.DryRun {
+		return errors.New("async cannot be combined with dry_run")
+	}
+	seen := make(map[string]struct{}, len(r.Steps))
 	for i, step := range r.Steps {
 		if step.ToolID == "" {
 			return errors.New("step " + string(rune('0'+i)) + " missing tool_id")
 		}
+		if step.ID == "" {
+			continue
+		}
+		if _, dup := seen[step.ID]; dup {
+			return errors.New("duplicate step id " + strconv.Quote(step.ID))
+		}
+		seen[step.ID] = struct{}{}
 	}
 	return nil
 }
@@ -453,11 +661,15 @@ func (r *RunChainInput) GetIncludeTools() bool {
 
 // ChainStepResult represents the result of a single chain step
 type ChainStepResult struct {
-	ToolID     string       `json:"tool_id"`
-	Structured any          `json:"structured,omitempty"`
-	Backend    any          `json:"backend,omitempty"`
-	Tool       any          `json:"tool,omitempty"`
-	Error      *ErrorObject `json:"error,omitempty"`
+	ID         string            `json:"id,omitempty"`
+	ToolID     string            `json:"tool_id"`
+	Structured any               `json:"structured,omitempty"`
+	Backend    any               `json:"backend,omitempty"`
+	Tool       any               `json:"tool,omitempty"`
+	Error      *ErrorObject      `json:"error,omitempty"`
+	Policy     *StepPolicyResult `json:"policy,omitempty"`
+	// Content holds the step's non-text MCP content blocks.
+	Content []any `json:"content,omitempty"`
 }
 
 // RunChainOutput is the output for run_chain
@@ -465,6 +677,76 @@ type RunChainOutput struct {
 	Results []ChainStepResult `json:"results"`
 	Final   any               `json:"final,omitempty"`
 	Error   *ErrorObject      `json:"error,omitempty"`
+	// RunID identifies the run record when run records are enabled.
+	RunID string `json:"run_id,omitempty"`
+	// JobID identifies the background job of an async call.
+	JobID string `json:"job_id,omitempty"`
+	// Trace is the would-be execution of a dry run.
+	Trace []DryRunStep `json:"trace,omitempty"`
+}
+
+// DryRunStep is one step of a dry-run trace. Output comes from a mock, is
+// derived from the tool's output schema, or is unknown.
+type DryRunStep struct {
+	ID           string         `json:"id,omitempty"`
+	ToolID       string         `json:"tool_id"`
+	Args         map[string]any `json:"args,omitempty"`
+	Output       any            `json:"output,omitempty"`
+	OutputSource string         `json:"output_source"`
+	Note         string         `json:"note,omitempty"`
+	Error        *ErrorObject   `json:"error,omitempty"`
+}
+
+// RunIDInput is the input for get_run, resume_run and cancel_run.
+type RunIDInput struct {
+	RunID string `json:"run_id"`
+}
+
+// Validate checks that the input is valid.
+func (r *RunIDInput) Validate() error {
+	if r.RunID == "" {
+		return errors.New("run_id is required")
+	}
+	return nil
+}
+
+// RunRecord is a persisted run_chain or run_skill execution.
+type RunRecord struct {
+	RunID  string `json:"run_id"`
+	Kind   string `json:"kind"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// Request is the run_chain or run_skill input the run started from.
+	Request any `json:"request"`
+	// Plan is the compiled skill plan; omitted for chains.
+	Plan       any             `json:"plan,omitempty"`
+	Steps      []RunStepRecord `json:"steps"`
+	CreatedAt  string          `json:"created_at"`
+	UpdatedAt  string          `json:"updated_at"`
+	FinishedAt string          `json:"finished_at,omitempty"`
+}
+
+// RunStepRecord is the latest outcome of one step of a run.
+type RunStepRecord struct {
+	Index  int    `json:"index"`
+	ID     string `json:"id,omitempty"`
+	ToolID string `json:"tool_id"`
+	Status string `json:"status"`
+	Value  any    `json:"value,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// RunRecordOutput is the output for get_run and cancel_run.
+type RunRecordOutput struct {
+	Run RunRecord `json:"run"`
+}
+
+// ResumeRunOutput is the output for resume_run. Chain or Skill holds the
+// result of the resumed execution, matching the run's kind.
+type ResumeRunOutput struct {
+	Run   RunRecord       `json:"run"`
+	Chain *RunChainOutput `json:"chain,omitempty"`
+	Skill *RunSkillOutput `json:"skill,omitempty"`
 }
 
 // ExecuteCodeInput is the input for execute_code
@@ -473,6 +755,8 @@ type ExecuteCodeInput struct {
 	Code         string `json:"code"`
 	TimeoutMs    *int   `json:"timeout_ms,omitempty"`
 	MaxToolCalls *int   `json:"max_tool_calls,omitempty"`
+	// Async returns a job_id at once and runs the code in the background.
+	Async bool `json:"async,omitempty"`
 }
 
 // ExecuteCodeOutput is the output for execute_code
@@ -481,4 +765,64 @@ type ExecuteCodeOutput struct {
 	Stdout     string `json:"stdout,omitempty"`
 	Stderr     string `json:"stderr,omitempty"`
 	DurationMs int    `json:"durationMs"`
+	// JobID identifies the background job of an async call.
+	JobID string `json:"job_id,omitempty"`
+}
+
+// JobIDInput is the input for get_job and cancel_job.
+type JobIDInput struct {
+	JobID string `json:"job_id"`
+}
+
+// Validate checks that the input is valid.
+func (j *JobIDInput) Validate() error {
+	if j.JobID == "" {
+		return errors.New("job_id is required")
+	}
+	return nil
+}
+
+// ListJobsInput is the input for list_jobs.
+type ListJobsInput struct {
+	// Status, when set, lists only jobs with that status.
+	Status string `json:"status,omitempty"`
+}
+
+// Validate checks that the input is valid.
+func (l *ListJobsInput) Validate() error { return nil }
+
+// Job is an async run_tool, run_chain or execute_code call.
+type Job struct {
+	JobID    string       `json:"job_id"`
+	Kind     string       `json:"kind"`
+	Status   string       `json:"status"`
+	Progress *JobProgress `json:"progress,omitempty"`
+	// Partial is the output produced so far, e.g. finished chain steps.
+	Partial any `json:"partial,omitempty"`
+	// Result is the call's output once the job finishes. IsError marks a
+	// result that reports a tool error.
+	Result     any    `json:"result,omitempty"`
+	IsError    bool   `json:"is_error,omitempty"`
+	Error      string `json:"error,omitempty"`
+	CreatedAt  string `json:"created_at"`
+	StartedAt  string `json:"started_at,omitempty"`
+	FinishedAt string `json:"finished_at,omitempty"`
+}
+
+// JobProgress is the latest progress reported by a job.
+type JobProgress struct {
+	Progress float64 `json:"progress"`
+	Total    float64 `json:"total,omitempty"`
+	Message  string  `json:"message,omitempty"`
+}
+
+// JobOutput is the output for get_job and cancel_job.
+type JobOutput struct {
+	Job Job `json:"job"`
+}
+
+// ListJobsOutput is the output for list_jobs. Results and partial output are
+// omitted; use get_job for them.
+type ListJobsOutput struct {
+	Jobs []Job `json:"jobs"`
 }